@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shamank/snet-sdk-go/pkg/config"
+	"github.com/shamank/snet-sdk-go/pkg/sdk"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// configFlags maps the flags that override the configuration to the
+// configuration file path of the field they set.
+var configFlags = []struct {
+	name, field, usage string
+}{
+	{"network", "network", "network name or chain ID (default sepolia)"},
+	{"rpc", "rpc_addr", "Ethereum JSON-RPC endpoint"},
+	{"registry", "registry_addr", "Registry contract address override"},
+	{"private-key", "private_key", "hex private key of the signer"},
+	{"keystore", "keystore_path", "keystore file of the signer (passphrase from <prefix>_KEYSTORE_PASSPHRASE)"},
+	{"clef", "clef_url", "Clef endpoint signing for -signer-address"},
+	{"signer-address", "signer_address", "account to select in the keystore or Clef"},
+	{"ipfs", "ipfs_url", "IPFS gateway"},
+	{"lighthouse", "lighthouse_url", "Lighthouse gateway"},
+}
+
+// globalFlags are accepted by every command.
+type globalFlags struct {
+	configPath string
+	profile    string
+	envPrefix  string
+	timeout    time.Duration
+	overrides  map[string]string
+}
+
+// app holds the state shared by the commands of one run.
+type app struct {
+	stdout, stderr io.Writer
+	global         globalFlags
+	sdk            sdk.SnetSDK
+	closers        []func()
+}
+
+// flagSet returns a flag set for the command name with the global flags
+// registered, so they can be given before or after the command.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	if a.global.overrides == nil {
+		a.global.overrides = make(map[string]string)
+		a.global.envPrefix = config.DefaultEnvPrefix
+	}
+	g := &a.global
+	fs.StringVar(&g.configPath, "config", g.configPath, "YAML or JSON configuration file (default $<prefix>_CONFIG)")
+	fs.StringVar(&g.profile, "profile", g.profile, "profile of the configuration file (default $<prefix>_PROFILE)")
+	fs.StringVar(&g.envPrefix, "env-prefix", g.envPrefix, "prefix of the configuration environment variables")
+	fs.DurationVar(&g.timeout, "timeout", g.timeout, "overall deadline of the command, 0 for none")
+	for _, f := range configFlags {
+		fs.Func(f.name, f.usage, func(v string) error {
+			g.overrides[f.field] = v
+			return nil
+		})
+	}
+	fs.BoolFunc("debug", "enable debug logging", func(v string) error {
+		g.overrides["debug"] = v
+		return nil
+	})
+	return fs
+}
+
+// loadConfig reads the configuration file, or the environment alone when
+// there is none, with the flag overrides applied on top.
+func (a *app) loadConfig() (*config.Config, error) {
+	g := a.global
+	// The overrides go through the environment so that they win over the
+	// file and the environment, and are parsed and validated like them.
+	for field, v := range g.overrides {
+		if err := os.Setenv(envName(g.envPrefix, field), v); err != nil {
+			return nil, err
+		}
+	}
+
+	path := g.configPath
+	if path == "" {
+		path = os.Getenv(envName(g.envPrefix, "config"))
+	}
+	if path == "" {
+		return config.FromEnv(g.envPrefix)
+	}
+	return config.Load(path, config.WithProfile(g.profile), config.WithEnvPrefix(g.envPrefix))
+}
+
+// envName mirrors the configuration package: SNET_RPC_ADDR for "rpc_addr".
+func envName(prefix, field string) string {
+	name := strings.ToUpper(field)
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// client returns the SDK, creating it on first use.
+func (a *app) client() (sdk.SnetSDK, error) {
+	if a.sdk != nil {
+		return a.sdk, nil
+	}
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	s, err := sdk.NewSDK(cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.sdk = s
+	a.closers = append(a.closers, s.Close)
+	return s, nil
+}
+
+// core returns the concrete SDK, which gives access to the configuration and
+// the EVM client.
+func (a *app) core() (*sdk.Core, error) {
+	s, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	core, ok := s.(*sdk.Core)
+	if !ok {
+		return nil, fmt.Errorf("unexpected SDK implementation %T", s)
+	}
+	return core, nil
+}
+
+// close releases the clients opened by the commands, newest first.
+func (a *app) close() {
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		a.closers[i]()
+	}
+}
+
+// serviceFlags select an organization, service and group.
+type serviceFlags struct {
+	org, service, group string
+}
+
+func (f *serviceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.org, "org", "", "organization ID")
+	fs.StringVar(&f.service, "service", "", "service ID")
+	fs.StringVar(&f.group, "group", "default_group", "payment group name")
+}
+
+// check reports a usage error when the organization, or the service if
+// needService is set, is missing.
+func (f *serviceFlags) check(fs *flag.FlagSet, needService bool) error {
+	if f.org == "" {
+		return usagef(fs, "-org is required")
+	}
+	if needService && f.service == "" {
+		return usagef(fs, "-service is required")
+	}
+	return nil
+}
+
+// serviceClient creates the client of the selected service. It is closed
+// when the run ends.
+func (a *app) serviceClient(ctx context.Context, f serviceFlags) (sdk.Service, error) {
+	s, err := a.client()
+	if err != nil {
+		return nil, err
+	}
+	service, err := s.NewServiceClientContext(ctx, f.org, f.service, f.group)
+	if err != nil {
+		return nil, err
+	}
+	a.closers = append(a.closers, service.Close)
+	return service, nil
+}
+
+// print writes v to standard output as indented JSON. Protobuf messages are
+// encoded with protojson, raw JSON is re-indented.
+func (a *app) print(v any) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch v := v.(type) {
+	case proto.Message:
+		data, err = protojson.MarshalOptions{Multiline: true, Indent: "  ", EmitUnpopulated: true}.Marshal(v)
+	case json.RawMessage:
+		data, err = indent(v)
+	default:
+		data, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("encode output: %w", err)
+	}
+	_, err = fmt.Fprintf(a.stdout, "%s\n", data)
+	return err
+}
+
+func indent(raw []byte) ([]byte, error) {
+	if len(raw) == 0 {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shamank/snet-sdk-go/pkg/grpc"
+	"github.com/shamank/snet-sdk-go/pkg/sdk"
+)
+
+// paymentFlags select the payment strategy of a service client.
+type paymentFlags struct {
+	strategy string
+	calls    uint64
+}
+
+// register adds the payment flags to fs with the default strategy def.
+func (f *paymentFlags) register(fs *flag.FlagSet, def string) {
+	fs.StringVar(&f.strategy, "payment", def, "payment strategy: auto, free, paid, prepaid, free-paid or free-prepaid")
+	fs.Uint64Var(&f.calls, "calls", 1, "number of calls to prepay with prepaid and free-prepaid")
+}
+
+func (f *paymentFlags) check(fs *flag.FlagSet) error {
+	switch f.strategy {
+	case "auto", "free", "paid", "prepaid", "free-paid", "free-prepaid":
+	default:
+		return usagef(fs, "unknown payment strategy %q", f.strategy)
+	}
+	if f.calls == 0 {
+		return usagef(fs, "-calls must be positive")
+	}
+	return nil
+}
+
+// apply sets the selected payment strategy on service. With "auto" the
+// service picks one on the first call: free calls falling back to escrow when
+// the group offers them, escrow otherwise.
+func (f *paymentFlags) apply(ctx context.Context, service sdk.Service) error {
+	switch f.strategy {
+	case "auto":
+		return nil
+	case "free":
+		return service.SetFreePaymentStrategyContext(ctx)
+	case "paid":
+		return service.SetPaidPaymentStrategyContext(ctx)
+	case "prepaid":
+		return service.SetPrePaidPaymentStrategyContext(ctx, f.calls)
+	case "free-paid":
+		return service.SetFreeWithPaidFallbackStrategyContext(ctx)
+	case "free-prepaid":
+		return service.SetFreeWithPrePaidFallbackStrategyContext(ctx, f.calls)
+	default:
+		return fmt.Errorf("unknown payment strategy %q", f.strategy)
+	}
+}
+
+func runCall(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("call")
+	var (
+		sf serviceFlags
+		pf paymentFlags
+	)
+	sf.register(fs)
+	pf.register(fs, "auto")
+	method := fs.String("method", "", "method to call, as \"Method\" or \"package.Service/Method\"")
+	input := fs.String("input", "{}", "JSON request, @file to read it from a file or - for standard input")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := sf.check(fs, true); err != nil {
+		return err
+	}
+	if err := pf.check(fs); err != nil {
+		return err
+	}
+	if *method == "" {
+		return usagef(fs, "-method is required")
+	}
+	req, err := readInput(*input, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	service, err := a.serviceClient(ctx, sf)
+	if err != nil {
+		return err
+	}
+	if err := pf.apply(ctx, service); err != nil {
+		return fmt.Errorf("set %s payment strategy: %w", pf.strategy, err)
+	}
+	resp, err := service.CallWithJSONContext(ctx, *method, req)
+	if err != nil {
+		return err
+	}
+	return a.print(json.RawMessage(resp))
+}
+
+// readInput returns the JSON given on the command line, read from the file
+// named after "@", or read from stdin for "-".
+func readInput(arg string, stdin io.Reader) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch {
+	case arg == "-":
+		data, err = io.ReadAll(stdin)
+	case strings.HasPrefix(arg, "@"):
+		data, err = os.ReadFile(arg[1:])
+	default:
+		data = []byte(arg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read input: %w", err)
+	}
+	if !json.Valid(data) {
+		return nil, errors.New("input is not valid JSON")
+	}
+	return data, nil
+}
+
+// endpointStatus is the JSON form of grpc.EndpointStatus.
+type endpointStatus struct {
+	URL         string    `json:"url"`
+	Healthy     bool      `json:"healthy"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastChecked time.Time `json:"last_checked"`
+}
+
+func runHealth(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("health")
+	var sf serviceFlags
+	sf.register(fs)
+	protocol := fs.String("protocol", "grpc", "healthcheck protocol: grpc, web-grpc, http, or endpoints to probe every endpoint of the group")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := sf.check(fs, true); err != nil {
+		return err
+	}
+	switch *protocol {
+	case "grpc", "web-grpc", "http", "endpoints":
+	default:
+		return usagef(fs, "unknown protocol %q", *protocol)
+	}
+
+	service, err := a.serviceClient(ctx, sf)
+	if err != nil {
+		return err
+	}
+	hc := service.Healthcheck()
+	switch *protocol {
+	case "web-grpc":
+		resp, err := hc.WebGRPCContext(ctx)
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	case "http":
+		resp, err := hc.HTTPContext(ctx)
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	case "endpoints":
+		return a.printEndpoints(service.CheckEndpoints(ctx))
+	default:
+		resp, err := hc.GRPCContext(ctx)
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	}
+}
+
+// printEndpoints prints the probed endpoints and fails when none is healthy.
+func (a *app) printEndpoints(statuses []grpc.EndpointStatus) error {
+	out := make([]endpointStatus, 0, len(statuses))
+	healthy := false
+	for _, st := range statuses {
+		e := endpointStatus{URL: st.URL, Healthy: st.Healthy, LastChecked: st.LastChecked}
+		if st.LastError != nil {
+			e.LastError = st.LastError.Error()
+		}
+		healthy = healthy || st.Healthy
+		out = append(out, e)
+	}
+	if err := a.print(out); err != nil {
+		return err
+	}
+	if !healthy {
+		return errors.New("no healthy endpoint")
+	}
+	return nil
+}
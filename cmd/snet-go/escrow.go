@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shopspring/decimal"
+)
+
+// balances is the output of the balance command. Amounts are in AASI, the
+// *_asi fields repeat them in ASI.
+type balances struct {
+	Address      common.Address  `json:"address"`
+	Token        *big.Int        `json:"token"`
+	Escrow       *big.Int        `json:"escrow"`
+	Allowance    *big.Int        `json:"allowance"`
+	TokenASI     decimal.Decimal `json:"token_asi"`
+	EscrowASI    decimal.Decimal `json:"escrow_asi"`
+	AllowanceASI decimal.Decimal `json:"allowance_asi"`
+}
+
+func runBalance(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("balance")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	s, err := a.client()
+	if err != nil {
+		return err
+	}
+	b, err := s.GetBalancesContext(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(balances{
+		Address:      b.Address,
+		Token:        b.Token,
+		Escrow:       b.Escrow,
+		Allowance:    b.Allowance,
+		TokenASI:     blockchain.AasiToAsi(b.Token),
+		EscrowASI:    blockchain.AasiToAsi(b.Escrow),
+		AllowanceASI: blockchain.AasiToAsi(b.Allowance),
+	})
+}
+
+func runDeposit(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("deposit")
+	amount := fs.String("amount", "", "amount of ASI to deposit")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := checkAmount(fs, *amount); err != nil {
+		return err
+	}
+	s, err := a.client()
+	if err != nil {
+		return err
+	}
+	ev, err := s.DepositEscrowContext(ctx, *amount)
+	if err != nil {
+		return err
+	}
+	return a.print(ev)
+}
+
+func runWithdraw(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("withdraw")
+	amount := fs.String("amount", "", "amount of ASI to withdraw")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := checkAmount(fs, *amount); err != nil {
+		return err
+	}
+	s, err := a.client()
+	if err != nil {
+		return err
+	}
+	ev, err := s.WithdrawEscrowContext(ctx, *amount)
+	if err != nil {
+		return err
+	}
+	return a.print(ev)
+}
+
+// checkAmount reports a usage error unless amount is a positive number of ASI.
+func checkAmount(fs *flag.FlagSet, amount string) error {
+	if amount == "" {
+		return usagef(fs, "-amount is required")
+	}
+	d, err := decimal.NewFromString(amount)
+	if err != nil || !d.IsPositive() {
+		return usagef(fs, "invalid -amount %q: want a positive number of ASI", amount)
+	}
+	return nil
+}
+
+// channel is the JSON form of blockchain.ChannelInfo.
+type channel struct {
+	ID           *big.Int       `json:"channel_id"`
+	Sender       common.Address `json:"sender"`
+	Signer       common.Address `json:"signer"`
+	Recipient    common.Address `json:"recipient"`
+	GroupID      string         `json:"group_id"`
+	Value        *big.Int       `json:"value"`
+	Nonce        *big.Int       `json:"nonce"`
+	Expiration   *big.Int       `json:"expiration"`
+	SignedAmount *big.Int       `json:"signed_amount,omitempty"`
+	Available    *big.Int       `json:"available"`
+	Expired      bool           `json:"expired"`
+}
+
+func newChannel(c *blockchain.ChannelInfo) channel {
+	return channel{
+		ID:           c.ChannelID,
+		Sender:       c.Sender,
+		Signer:       c.Signer,
+		Recipient:    c.Recipient,
+		GroupID:      base64.StdEncoding.EncodeToString(c.GroupID[:]),
+		Value:        c.Value,
+		Nonce:        c.Nonce,
+		Expiration:   c.Expiration,
+		SignedAmount: c.SignedAmount,
+		Available:    c.Available(),
+		Expired:      c.Expired,
+	}
+}
+
+const channelsUsage = `Usage: snet-go channels <list|extend|add-funds|claim> [flags]
+
+  list       list the channels of the signer
+  extend     move the expiration of a channel, optionally adding funds
+  add-funds  add funds from the escrow balance to a channel
+  claim      reclaim the value of an expired channel, or of all with -all
+`
+
+func runChannels(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(a.stderr, channelsUsage)
+		return usageError{fmt.Errorf("missing channels subcommand")}
+	}
+	sub, args := args[0], args[1:]
+
+	fs := a.flagSet("channels " + sub)
+	var sf serviceFlags
+	sf.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: snet-go channels %s [flags]\n\nWith -org and -service the signed amounts are read from the daemon of the group.\n\nFlags:\n", sub)
+		fs.PrintDefaults()
+	}
+	id := new(big.Int)
+	expiration := ""
+	amount := ""
+	all := false
+	switch sub {
+	case "list":
+	case "extend":
+		fs.Func("id", "channel ID", parseBig(id))
+		fs.StringVar(&expiration, "expiration", "", "new expiration block, or +N for N blocks from the current block")
+		fs.StringVar(&amount, "amount", "", "ASI to add to the channel in the same transaction")
+	case "add-funds":
+		fs.Func("id", "channel ID", parseBig(id))
+		fs.StringVar(&amount, "amount", "", "ASI to add to the channel")
+	case "claim":
+		fs.Func("id", "channel ID", parseBig(id))
+		fs.BoolVar(&all, "all", false, "claim every expired channel that still holds value")
+	default:
+		fmt.Fprint(a.stderr, channelsUsage)
+		return usageError{fmt.Errorf("unknown channels subcommand %q", sub)}
+	}
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if sf.service != "" {
+		if err := sf.check(fs, true); err != nil {
+			return err
+		}
+	}
+	idSet := isSet(fs, "id")
+	switch sub {
+	case "extend":
+		if !idSet || expiration == "" {
+			return usagef(fs, "-id and -expiration are required")
+		}
+		if amount != "" {
+			if err := checkAmount(fs, amount); err != nil {
+				return err
+			}
+		}
+	case "add-funds":
+		if !idSet {
+			return usagef(fs, "-id is required")
+		}
+		if err := checkAmount(fs, amount); err != nil {
+			return err
+		}
+	case "claim":
+		if idSet == all {
+			return usagef(fs, "exactly one of -id and -all is required")
+		}
+	}
+
+	m, err := a.channelManager(ctx, sf)
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "extend":
+		exp, err := a.expirationBlock(ctx, expiration)
+		if err != nil {
+			return err
+		}
+		if amount == "" {
+			ev, err := m.ChannelExtend(ctx, id, exp)
+			if err != nil {
+				return err
+			}
+			return a.print(ev)
+		}
+		value, err := blockchain.AsiToAasi(amount)
+		if err != nil {
+			return err
+		}
+		extend, funds, err := m.ChannelExtendAndAddFunds(ctx, id, exp, value)
+		if err != nil {
+			return err
+		}
+		return a.print(map[string]any{"extend": extend, "add_funds": funds})
+	case "add-funds":
+		value, err := blockchain.AsiToAasi(amount)
+		if err != nil {
+			return err
+		}
+		ev, err := m.ChannelAddFunds(ctx, id, value)
+		if err != nil {
+			return err
+		}
+		return a.print(ev)
+	case "claim":
+		if all {
+			claims, err := m.ClaimExpiredChannels(ctx)
+			if perr := a.print(claims); err == nil {
+				err = perr
+			}
+			return err
+		}
+		ev, err := m.ChannelClaimTimeout(ctx, id)
+		if err != nil {
+			return err
+		}
+		return a.print(ev)
+	default:
+		list, err := m.ListChannels(ctx)
+		if err != nil {
+			return err
+		}
+		out := make([]channel, 0, len(list))
+		for _, c := range list {
+			out = append(out, newChannel(c))
+		}
+		return a.print(out)
+	}
+}
+
+// channelManager returns the channel manager of the signer. With a service
+// selected it reports the signed amounts recorded by the service's daemon.
+func (a *app) channelManager(ctx context.Context, sf serviceFlags) (*blockchain.ChannelManager, error) {
+	if sf.service != "" {
+		service, err := a.serviceClient(ctx, sf)
+		if err != nil {
+			return nil, err
+		}
+		return service.ChannelManager()
+	}
+	core, err := a.core()
+	if err != nil {
+		return nil, err
+	}
+	s, err := core.RequireSigner()
+	if err != nil {
+		return nil, err
+	}
+	return core.GetEvm().ChannelManager(s)
+}
+
+// expirationBlock resolves an -expiration value: a block number, or +N for N
+// blocks after the current block.
+func (a *app) expirationBlock(ctx context.Context, v string) (*big.Int, error) {
+	rel, isRel := strings.CutPrefix(v, "+")
+	n, ok := new(big.Int).SetString(rel, 10)
+	if !ok || n.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid expiration %q: want a block number or +blocks", v)
+	}
+	if !isRel {
+		return n, nil
+	}
+	core, err := a.core()
+	if err != nil {
+		return nil, err
+	}
+	block, err := core.GetEvm().GetCurrentBlockNumberCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return n.Add(n, block), nil
+}
+
+// parseBig returns a flag.Func parser storing a non-negative decimal integer
+// in v.
+func parseBig(v *big.Int) func(string) error {
+	return func(s string) error {
+		if _, ok := v.SetString(s, 10); !ok || v.Sign() < 0 {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		return nil
+	}
+}
+
+// isSet reports whether the flag name was given on the command line.
+func isSet(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		found = found || f.Name == name
+	})
+	return found
+}
@@ -0,0 +1,165 @@
+// Command snet-go is a command-line client for the SingularityNET marketplace
+// built on the Go SDK. It lists organizations and services, prints service
+// metadata and pricing, exports proto files, calls service methods with JSON
+// input, manages MPE escrow and payment channels, runs healthchecks and drives
+// training workflows.
+//
+// Usage:
+//
+//	snet-go [flags] <command> [command flags]
+//
+// The configuration is read from the file given by -config (or SNET_CONFIG),
+// otherwise from the environment alone (see config.Load and config.FromEnv).
+// Flags such as -rpc, -network and -private-key override both. Flags can be
+// given before or after the command name.
+//
+// Every command prints its result as JSON on standard output, so it can be
+// piped into jq or other scripts. Errors go to standard error and make the
+// command exit with status 1; invalid usage exits with status 2.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+)
+
+// command is a snet-go subcommand. run receives the arguments that follow
+// the command name.
+type command struct {
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"orgs":     {"list the organizations in the registry", runOrgs},
+	"services": {"list the services of an organization", runServices},
+	"metadata": {"print organization or service metadata", runMetadata},
+	"pricing":  {"print the pricing of a service group", runPricing},
+	"protos":   {"export the proto files of a service", runProtos},
+	"call":     {"call a service method with JSON input", runCall},
+	"health":   {"run a healthcheck against a service", runHealth},
+	"balance":  {"show token and escrow balances of the signer", runBalance},
+	"deposit":  {"deposit ASI into the MPE escrow", runDeposit},
+	"withdraw": {"withdraw ASI from the MPE escrow", runWithdraw},
+	"channels": {"list, extend, fund and claim payment channels", runChannels},
+	"training": {"manage and train service models", runTraining},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the exit status.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	a := &app{stdout: stdout, stderr: stderr}
+	defer a.close()
+
+	fs := a.flagSet("snet-go")
+	fs.Usage = func() { a.usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return exitCode(err)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "snet-go: unknown command %q\n", name)
+		fs.Usage()
+		return 2
+	}
+
+	if a.global.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.global.timeout)
+		defer cancel()
+	}
+	if err := cmd.run(ctx, a, fs.Args()[1:]); err != nil {
+		if code := exitCode(err); code != 1 {
+			return code
+		}
+		fmt.Fprintf(stderr, "snet-go %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// exitCode maps an error of run to an exit status: 0 for -help, 2 for
+// invalid usage and 1 for everything else.
+func exitCode(err error) int {
+	var usage usageError
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usage):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// usageError reports invalid command-line usage. Errors returned by
+// flag.FlagSet.Parse have already been printed with the usage.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// parse parses the flags of a command, which takes no positional arguments,
+// and wraps parse failures in usageError.
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err}
+	}
+	if fs.NArg() > 0 {
+		return usagef(fs, "unexpected arguments %q", fs.Args())
+	}
+	return nil
+}
+
+// usagef prints a usage problem and the usage of fs, and returns a usageError.
+func usagef(fs *flag.FlagSet, format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	fmt.Fprintf(fs.Output(), "%s: %v\n", fs.Name(), err)
+	fs.Usage()
+	return usageError{err}
+}
+
+func (a *app) usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: snet-go [flags] <command> [command flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\nRun 'snet-go <command> -help' for the flags of a command.")
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const testPrefix = "SNETGOTEST"
+
+// isolateEnv clears the variables a test may set through flag overrides, so
+// t.Setenv restores them afterwards.
+func isolateEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"CONFIG", "PROFILE", "RPC_ADDR", "DEBUG", "NETWORK", "IPFS_URL"} {
+		t.Setenv(testPrefix+"_"+name, "")
+	}
+}
+
+func parseGlobal(t *testing.T, args ...string) *app {
+	t.Helper()
+	a := &app{stdout: new(bytes.Buffer), stderr: new(bytes.Buffer)}
+	fs := a.flagSet("test")
+	if err := fs.Parse(append([]string{"-env-prefix", testPrefix}, args...)); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return a
+}
+
+func TestLoadConfig_FlagsOverrideFileAndEnv(t *testing.T) {
+	isolateEnv(t)
+	path := filepath.Join(t.TempDir(), "snet.yaml")
+	file := "rpc_addr: wss://file\nipfs_url: https://ipfs.file\nprofiles:\n  dev:\n    rpc_addr: wss://profile\n"
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(testPrefix+"_IPFS_URL", "https://ipfs.env")
+
+	cfg, err := parseGlobal(t, "-config", path, "-profile", "dev").loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.RPCAddr != "wss://profile" || cfg.IpfsURL != "https://ipfs.env" || cfg.Debug {
+		t.Fatalf("without overrides got rpc %q, ipfs %q, debug %v", cfg.RPCAddr, cfg.IpfsURL, cfg.Debug)
+	}
+
+	cfg, err = parseGlobal(t, "-config", path, "-profile", "dev", "-rpc", "wss://flag", "-ipfs", "https://ipfs.flag", "-debug").loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.RPCAddr != "wss://flag" || cfg.IpfsURL != "https://ipfs.flag" || !cfg.Debug {
+		t.Fatalf("with overrides got rpc %q, ipfs %q, debug %v", cfg.RPCAddr, cfg.IpfsURL, cfg.Debug)
+	}
+}
+
+func TestLoadConfig_EnvironmentWithoutFile(t *testing.T) {
+	isolateEnv(t)
+	if _, err := parseGlobal(t).loadConfig(); err == nil || !strings.Contains(err.Error(), "rpc_addr") {
+		t.Fatalf("loadConfig without RPC address: err = %v; want an rpc_addr error", err)
+	}
+
+	t.Setenv(testPrefix+"_RPC_ADDR", "wss://env")
+	cfg, err := parseGlobal(t, "-network", "mainnet").loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.RPCAddr != "wss://env" || cfg.Network.ChainID != "1" {
+		t.Fatalf("got rpc %q on chain %q; want wss://env on chain 1", cfg.RPCAddr, cfg.Network.ChainID)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+		msg  string
+	}{
+		{nil, 2, "Commands:"},
+		{[]string{"-help"}, 0, "Commands:"},
+		{[]string{"nope"}, 2, `unknown command "nope"`},
+		{[]string{"services"}, 2, "-org is required"},
+		{[]string{"call", "-org", "snet"}, 2, "-service is required"},
+		{[]string{"call", "-org", "snet", "-service", "calc", "-method", "add", "-payment", "card"}, 2, `unknown payment strategy "card"`},
+		{[]string{"deposit", "-amount", "-1"}, 2, "want a positive number of ASI"},
+		{[]string{"channels"}, 2, "Usage: snet-go channels"},
+		{[]string{"channels", "claim", "-id", "3", "-all"}, 2, "exactly one of -id and -all"},
+		{[]string{"training", "train", "-org", "snet", "-service", "calc"}, 2, "-model is required"},
+		{[]string{"orgs", "extra"}, 2, "unexpected arguments"},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), tt.args, &stdout, &stderr)
+		if code != tt.code || !strings.Contains(stderr.String(), tt.msg) {
+			t.Errorf("run %q = %d, stderr %q; want %d and %q", tt.args, code, stderr.String(), tt.code, tt.msg)
+		}
+		if stdout.Len() != 0 {
+			t.Errorf("run %q wrote %q to stdout", tt.args, stdout.String())
+		}
+	}
+}
+
+func TestReadInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "req.json")
+	if err := os.WriteFile(path, []byte(`{"a": 1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for arg, want := range map[string]string{
+		`{"b": 2}`: `{"b": 2}`,
+		"@" + path: `{"a": 1}`,
+		"-":        `{"c": 3}`,
+	} {
+		got, err := readInput(arg, strings.NewReader(`{"c": 3}`))
+		if err != nil || string(got) != want {
+			t.Errorf("readInput(%q) = %q, %v; want %q", arg, got, err, want)
+		}
+	}
+	if _, err := readInput("{", nil); err == nil {
+		t.Error("readInput accepted invalid JSON")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	var out bytes.Buffer
+	a := &app{stdout: &out}
+
+	if err := a.print(json.RawMessage(`{"value":5}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.print(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.print(trainingStatus{ModelID: "7", Status: "TRAINING"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := json.NewDecoder(&out)
+	var raw, health, status map[string]any
+	for _, v := range []*map[string]any{&raw, &health, &status} {
+		if err := dec.Decode(v); err != nil {
+			t.Fatalf("output is not a JSON stream: %v", err)
+		}
+	}
+	if raw["value"] != 5.0 || health["status"] != "SERVING" || status["model_id"] != "7" {
+		t.Fatalf("decoded %v, %v, %v", raw, health, status)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shamank/snet-sdk-go/pkg/model"
+)
+
+func runOrgs(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("orgs")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	s, err := a.client()
+	if err != nil {
+		return err
+	}
+	orgs, err := s.GetOrganizationsContext(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(orgs)
+}
+
+func runServices(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("services")
+	org := fs.String("org", "", "organization ID")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *org == "" {
+		return usagef(fs, "-org is required")
+	}
+	s, err := a.client()
+	if err != nil {
+		return err
+	}
+	o, err := s.NewOrganizationClientContext(ctx, *org, "")
+	if err != nil {
+		return err
+	}
+	services, err := o.ListServicesContext(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(services)
+}
+
+// runMetadata prints the service metadata, or the organization metadata when
+// no service is selected.
+func runMetadata(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("metadata")
+	var sf serviceFlags
+	sf.register(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := sf.check(fs, false); err != nil {
+		return err
+	}
+	if sf.service == "" {
+		s, err := a.client()
+		if err != nil {
+			return err
+		}
+		o, err := s.NewOrganizationClientContext(ctx, sf.org, sf.group)
+		if err != nil {
+			return err
+		}
+		return a.print(o.GetOrgMetadata())
+	}
+	service, err := a.serviceClient(ctx, sf)
+	if err != nil {
+		return err
+	}
+	return a.print(service.GetServiceMetadata())
+}
+
+// pricing is the output of the pricing command.
+type pricing struct {
+	Org            string          `json:"org_id"`
+	Service        string          `json:"service_id"`
+	Group          string          `json:"group_name"`
+	PaymentAddress string          `json:"payment_address,omitempty"`
+	FreeCalls      int             `json:"free_calls"`
+	Pricing        []model.Pricing `json:"pricing"`
+}
+
+func runPricing(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("pricing")
+	var sf serviceFlags
+	sf.register(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := sf.check(fs, true); err != nil {
+		return err
+	}
+	service, err := a.serviceClient(ctx, sf)
+	if err != nil {
+		return err
+	}
+	group := service.GetCurrentServiceGroup()
+	if group == nil {
+		return fmt.Errorf("service %s/%s has no group %q", sf.org, sf.service, sf.group)
+	}
+	out := pricing{
+		Org:       sf.org,
+		Service:   sf.service,
+		Group:     group.GroupName,
+		FreeCalls: group.FreeCalls,
+		Pricing:   group.Pricing,
+	}
+	if g := service.GetCurrentOrgGroup(); g != nil {
+		out.PaymentAddress = g.PaymentDetails.PaymentAddress
+	}
+	return a.print(out)
+}
+
+// runProtos saves the proto files of a service to a directory or ZIP archive,
+// or prints them keyed by file name when neither is given.
+func runProtos(ctx context.Context, a *app, args []string) error {
+	fs := a.flagSet("protos")
+	var sf serviceFlags
+	sf.register(fs)
+	dir := fs.String("out", "", "directory to write the proto files to")
+	zip := fs.String("zip", "", "ZIP archive to write the proto files to")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := sf.check(fs, true); err != nil {
+		return err
+	}
+	if *dir != "" && *zip != "" {
+		return usagef(fs, "-out and -zip are mutually exclusive")
+	}
+	service, err := a.serviceClient(ctx, sf)
+	if err != nil {
+		return err
+	}
+	protos := service.ProtoFiles()
+	switch {
+	case *dir != "":
+		if err := protos.Save(*dir); err != nil {
+			return err
+		}
+		return a.print(map[string]string{"dir": *dir})
+	case *zip != "":
+		if err := protos.SaveAsZip(*zip); err != nil {
+			return err
+		}
+		return a.print(map[string]string{"zip": *zip})
+	default:
+		return a.print(protos.Get())
+	}
+}
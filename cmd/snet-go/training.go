@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/shamank/snet-sdk-go/pkg/training"
+)
+
+const trainingUsage = `Usage: snet-go training <subcommand> [flags]
+
+  metadata         print the training metadata of the service
+  method-metadata  print the training metadata of one method
+  models           list models
+  model            print a model
+  create           create a model
+  update           update the name, description or access list of a model
+  delete           delete a model
+  upload           upload a training data ZIP archive and validate the model
+  validate-price   print the price of validating a model
+  validate         validate a model against uploaded training data
+  train-price      print the price of training a model
+  train            train a model
+`
+
+// trainingStatus is the output of training subcommands that return a status.
+type trainingStatus struct {
+	ModelID string `json:"model_id"`
+	Status  string `json:"status"`
+}
+
+// trainingPrice is the output of the price subcommands, in cogs.
+type trainingPrice struct {
+	ModelID string `json:"model_id"`
+	Price   uint64 `json:"price"`
+}
+
+// trainingFlags are the flags of the training subcommands; each subcommand
+// registers the ones it uses.
+type trainingFlags struct {
+	modelID, name, description string
+	grpcService, grpcMethod    string
+	addresses                  string
+	public                     *bool
+	dataLink, zip              string
+	price                      *big.Int
+	batchSize                  uint64
+	page, pageSize             uint64
+	createdBy                  string
+}
+
+func runTraining(ctx context.Context, a *app, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(a.stderr, trainingUsage)
+		return usageError{errors.New("missing training subcommand")}
+	}
+	sub, args := args[0], args[1:]
+
+	fs := a.flagSet("training " + sub)
+	var (
+		sf serviceFlags
+		pf paymentFlags
+		tf trainingFlags
+	)
+	sf.register(fs)
+	pf.register(fs, "paid")
+
+	modelID := func() { fs.StringVar(&tf.modelID, "model", "", "model ID") }
+	method := func() {
+		fs.StringVar(&tf.grpcService, "grpc-service", "", "gRPC service of the model, e.g. example_service.Calculator")
+		fs.StringVar(&tf.grpcMethod, "grpc-method", "", "gRPC method of the model")
+	}
+	details := func() {
+		fs.StringVar(&tf.name, "name", "", "model name")
+		fs.StringVar(&tf.description, "description", "", "model description")
+		fs.StringVar(&tf.addresses, "addresses", "", "comma-separated addresses allowed to use the model")
+	}
+	public := func(usage string) {
+		fs.BoolFunc("public", usage, func(v string) error {
+			b, err := strconv.ParseBool(v)
+			tf.public = &b
+			return err
+		})
+	}
+	required := []string{"model"}
+
+	switch sub {
+	case "metadata":
+		required = nil
+	case "method-metadata":
+		modelID()
+		method()
+		required = nil
+	case "models":
+		method()
+		fs.StringVar(&tf.name, "name", "", "only models with this name")
+		fs.StringVar(&tf.createdBy, "created-by", "", "only models created by this address")
+		public("only public (or, with -public=false, private) models")
+		fs.Uint64Var(&tf.page, "page", 0, "page number, from 0")
+		fs.Uint64Var(&tf.pageSize, "page-size", 100, "models per page")
+		required = nil
+	case "model", "delete", "train-price", "train":
+		modelID()
+	case "create":
+		method()
+		details()
+		public("make the model usable by everyone")
+		required = []string{"name", "grpc-method"}
+	case "update":
+		modelID()
+		details()
+	case "upload":
+		modelID()
+		fs.StringVar(&tf.zip, "zip", "", "ZIP archive of the training data")
+		tf.price = new(big.Int)
+		fs.Func("price", "price of the upload in cogs, as returned by validate-price", parseBig(tf.price))
+		fs.Uint64Var(&tf.batchSize, "batch-size", 0, "upload chunk size in bytes (default of the training client)")
+		required = append(required, "zip")
+	case "validate-price", "validate":
+		modelID()
+		fs.StringVar(&tf.dataLink, "data-link", "", "link to the training data")
+		required = append(required, "data-link")
+	default:
+		fmt.Fprint(a.stderr, trainingUsage)
+		return usageError{fmt.Errorf("unknown training subcommand %q", sub)}
+	}
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := sf.check(fs, true); err != nil {
+		return err
+	}
+	if err := pf.check(fs); err != nil {
+		return err
+	}
+	if pf.strategy == "auto" {
+		return usagef(fs, "training needs an explicit -payment strategy")
+	}
+	for _, name := range required {
+		if !isSet(fs, name) {
+			return usagef(fs, "-%s is required", name)
+		}
+	}
+
+	service, err := a.serviceClient(ctx, sf)
+	if err != nil {
+		return err
+	}
+	if err := pf.apply(ctx, service); err != nil {
+		return fmt.Errorf("set %s payment strategy: %w", pf.strategy, err)
+	}
+	return a.runTrainingSub(ctx, service.Training(), sub, tf)
+}
+
+func (a *app) runTrainingSub(ctx context.Context, c training.Client, sub string, tf trainingFlags) error {
+	status := func(st training.Status, err error) error {
+		if err != nil {
+			return err
+		}
+		return a.print(trainingStatus{ModelID: tf.modelID, Status: st.String()})
+	}
+	price := func(p uint64, err error) error {
+		if err != nil {
+			return err
+		}
+		return a.print(trainingPrice{ModelID: tf.modelID, Price: p})
+	}
+
+	switch sub {
+	case "metadata":
+		md, err := c.GetMetadataContext(ctx)
+		if err != nil {
+			return err
+		}
+		return a.print(md)
+	case "method-metadata":
+		md, err := c.GetMethodMetadataContext(ctx, &training.MethodMetadataRequest{
+			ModelId:         tf.modelID,
+			GrpcServiceName: tf.grpcService,
+			GrpcMethodName:  tf.grpcMethod,
+		})
+		if err != nil {
+			return err
+		}
+		return a.print(md)
+	case "models":
+		models, err := c.GetAllModelsContext(ctx, training.GetAllModelsFilters{
+			IsPublic:         tf.public,
+			GrpcMethodName:   tf.grpcMethod,
+			GrpcServiceName:  tf.grpcService,
+			Name:             tf.name,
+			CreatedByAddress: tf.createdBy,
+			PageSize:         tf.pageSize,
+			Page:             tf.page,
+		})
+		if err != nil {
+			return err
+		}
+		return a.print(models)
+	case "model":
+		m, err := c.GetModelContext(ctx, tf.modelID)
+		if err != nil {
+			return err
+		}
+		return a.print(m)
+	case "create":
+		m, err := c.CreateModelContext(ctx, &training.ModelParams{
+			Name:            tf.name,
+			Description:     tf.description,
+			GrpcMethodName:  tf.grpcMethod,
+			GrpcServiceName: tf.grpcService,
+			AddressList:     splitList(tf.addresses),
+			IsPublic:        tf.public != nil && *tf.public,
+		})
+		if err != nil {
+			return err
+		}
+		return a.print(m)
+	case "update":
+		req := &training.UpdateModelRequest{ModelId: tf.modelID, AddressList: splitList(tf.addresses)}
+		if tf.name != "" {
+			req.ModelName = &tf.name
+		}
+		if tf.description != "" {
+			req.Description = &tf.description
+		}
+		m, err := c.UpdateModelContext(ctx, req)
+		if err != nil {
+			return err
+		}
+		return a.print(m)
+	case "delete":
+		return status(c.DeleteModelContext(ctx, tf.modelID))
+	case "upload":
+		err := c.UploadAndValidateContext(ctx, &training.UploadValidateRequest{
+			ModelID:     tf.modelID,
+			ZipPath:     tf.zip,
+			PriceInCogs: tf.price,
+			BatchSize:   tf.batchSize,
+		})
+		if err != nil {
+			return err
+		}
+		return a.print(map[string]string{"model_id": tf.modelID, "zip": tf.zip})
+	case "validate-price":
+		return price(c.ValidateModelPriceContext(ctx, tf.modelID, tf.dataLink))
+	case "validate":
+		return status(c.ValidateModelContext(ctx, tf.modelID, tf.dataLink))
+	case "train-price":
+		return price(c.TrainModelPriceContext(ctx, tf.modelID))
+	case "train":
+		return status(c.TrainModelContext(ctx, tf.modelID))
+	}
+	return fmt.Errorf("unknown training subcommand %q", sub)
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
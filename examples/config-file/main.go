@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/shamank/snet-sdk-go/pkg/config"
+	"github.com/shamank/snet-sdk-go/pkg/sdk"
+)
+
+func main() {
+	// Load snet.yaml with the profile from SNET_PROFILE (default: sepolia-dev).
+	// Any field can still be overridden from the environment, for example
+	// SNET_PRIVATE_KEY or SNET_TIMEOUTS_GRPC_UNARY=1m.
+	profile := os.Getenv("SNET_PROFILE")
+	if profile == "" {
+		profile = "sepolia-dev"
+	}
+	cfg, err := config.Load("snet.yaml", config.WithProfile(profile))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Printf("Using %s (chain %s) via %s\n", profile, cfg.Network.ChainID, cfg.RPCAddr)
+
+	// Create SDK instance
+	snetSDK, err := sdk.NewSDK(cfg)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer snetSDK.Close()
+
+	// Create service client
+	service, err := snetSDK.NewServiceClient("your_org_id", "your_service_id", "default_group")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer service.Close()
+
+	// Set free payment strategy and call the service
+	if err := service.SetFreePaymentStrategy(); err != nil {
+		log.Println(err)
+		return
+	}
+	resp, err := service.CallWithMap("your_method_name", map[string]any{"text": "test"})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Printf("Response from service: %v\n", resp)
+}
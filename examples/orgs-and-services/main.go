@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shamank/snet-sdk-go/pkg/config"
@@ -18,7 +19,10 @@ func main() {
 		Network:    config.Sepolia,
 	}
 
-	core := sdk.NewSDK(&cfg)
+	core, err := sdk.NewSDK(&cfg)
+	if err != nil {
+		log.Fatalln(err)
+	}
 	defer core.Close()
 
 	// Example 1: Creating a new organization
@@ -109,22 +113,23 @@ func main() {
 	}
 
 	// Example 5: Create new service
-	serviceMetadata := &model.ServiceMetadata{
-		Version:     1,
-		DisplayName: "My AI Service",
-		Groups: []*model.ServiceGroup{
-			{
-				GroupName: "default_group",
-				Endpoints: []string{"https://my-service-endpoint.com"},
-			},
-		},
-	}
-
-	txHash, err = org.CreateService("my-ai-service", serviceMetadata)
+	serviceMetadata, err := model.NewServiceMetadataBuilder("My AI Service").
+		MPEAddress("0x5e592F9b1d303183d963635f895f0f0C48284f4e"). // MultiPartyEscrow contract of the network
+		APISource("ipfs://QmYourProtoArchive").
+		Group("default_group", []string{"https://my-service-endpoint.com"},
+			model.FixedPrice(big.NewInt(1))).
+		Build()
 	if err != nil {
-		log.Printf("Failed to create service: %v", err)
+		log.Printf("Invalid service metadata: %v", err)
+	} else if err := org.ValidateService(serviceMetadata, sdk.WithEndpointProbe()); err != nil {
+		log.Printf("Service is not ready to be published: %v", err)
 	} else {
-		fmt.Printf("Service created! Transaction hash: %s\n", txHash.Hex())
+		txHash, err = org.CreateService("my-ai-service", serviceMetadata)
+		if err != nil {
+			log.Printf("Failed to create service: %v", err)
+		} else {
+			fmt.Printf("Service created! Transaction hash: %s\n", txHash.Hex())
+		}
 	}
 
 	// Example 6: Work with existing service
@@ -140,22 +145,21 @@ func main() {
 	fmt.Printf("Service ID: %s\n", service.GetServiceID())
 
 	// Update service metadata
-	updatedServiceMetadata := &model.ServiceMetadata{
-		Version:     1,
-		DisplayName: "Updated AI Service",
-		Groups: []*model.ServiceGroup{
-			{
-				GroupName: "default_group",
-				Endpoints: []string{"https://updated-endpoint.com"},
-			},
-		},
-	}
-
-	txHash, err = service.UpdateServiceMetadata(updatedServiceMetadata)
+	updatedServiceMetadata, err := model.NewServiceMetadataBuilder("Updated AI Service").
+		MPEAddress(serviceData.MPEAddress).
+		APISource(serviceData.ServiceApiSource).
+		Group("default_group", []string{"https://updated-endpoint.com"},
+			model.FixedPrice(big.NewInt(2))).
+		Build()
 	if err != nil {
-		log.Printf("Failed to update service metadata: %v", err)
+		log.Printf("Invalid service metadata: %v", err)
 	} else {
-		fmt.Printf("Service metadata updated! Transaction hash: %s\n", txHash.Hex())
+		txHash, err = service.UpdateServiceMetadata(updatedServiceMetadata)
+		if err != nil {
+			log.Printf("Failed to update service metadata: %v", err)
+		} else {
+			fmt.Printf("Service metadata updated! Transaction hash: %s\n", txHash.Hex())
+		}
 	}
 
 	// Example 7: Remove members from organization
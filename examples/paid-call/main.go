@@ -18,7 +18,10 @@ func main() {
 	}
 
 	// Create SDK instance
-	snetSDK := sdk.NewSDK(&c)
+	snetSDK, err := sdk.NewSDK(&c)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
 	// Create service client
 	service, err := snetSDK.NewServiceClient("your_org_id", "your_service_id", "default_group")
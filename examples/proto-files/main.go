@@ -16,7 +16,10 @@ func main() {
 		Network:    config.Sepolia,
 	}
 
-	snetSDK := sdk.NewSDK(&c)
+	snetSDK, err := sdk.NewSDK(&c)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
 	service, err := snetSDK.NewServiceClient("your_org_id", "your_service_id", "default_group")
 	if err != nil {
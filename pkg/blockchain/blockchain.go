@@ -11,6 +11,7 @@ package blockchain
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/shamank/snet-sdk-go/pkg/storage"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
 	contracts "github.com/singnet/snet-ecosystem-contracts"
 	"go.uber.org/zap"
 )
@@ -39,6 +41,15 @@ type EVMClient struct {
 	MPE        *MultiPartyEscrow
 	FetchToken *FetchToken
 	Storage    storage.Storage
+	// MPEAddress is the address of the bound MultiPartyEscrow contract.
+	MPEAddress common.Address
+
+	log   *zap.Logger
+	tel   *telemetry.Telemetry
+	cache *storage.Cache
+	// mpeDeployTx is the transaction that deployed the MPE contract, when
+	// snet-ecosystem-contracts lists it for the network; zero otherwise.
+	mpeDeployTx common.Hash
 }
 
 type Evm interface {
@@ -49,9 +60,71 @@ type Evm interface {
 }
 
 // networks is a helper type that mirrors the JSON payload produced by
-// snet-ecosystem-contracts (network name → contract address).
+// snet-ecosystem-contracts (network name → contract address and the
+// transaction that deployed it).
 type networks map[string]struct {
-	Address string `json:"address"`
+	Address         string `json:"address"`
+	TransactionHash string `json:"transactionHash"`
+}
+
+// ErrNoContractCode is returned by InitEvm when an SNET contract address has
+// no bytecode on the connected chain, usually because the address belongs to
+// another network.
+var ErrNoContractCode = errors.New("no contract code at address")
+
+// evmOptions holds the contract address overrides, logger and telemetry of
+// InitEvm.
+type evmOptions struct {
+	mpe   common.Address
+	token common.Address
+	log   *zap.Logger
+	tel   *telemetry.Telemetry
+	cache *storage.Cache
+}
+
+// EvmOption configures InitEvm.
+type EvmOption func(*evmOptions)
+
+// WithMPEAddress binds the MultiPartyEscrow contract at addr instead of the
+// address snet-ecosystem-contracts knows for the network.
+func WithMPEAddress(addr common.Address) EvmOption {
+	return func(o *evmOptions) {
+		o.mpe = addr
+	}
+}
+
+// WithTokenAddress binds the token contract at addr instead of the token
+// reported by the MultiPartyEscrow contract.
+func WithTokenAddress(addr common.Address) EvmOption {
+	return func(o *evmOptions) {
+		o.token = addr
+	}
+}
+
+// WithLogger makes the client and the org and service clients derived from it
+// log to l instead of zap.L().
+func WithLogger(l *zap.Logger) EvmOption {
+	return func(o *evmOptions) {
+		o.log = l
+	}
+}
+
+// WithTelemetry makes the client trace its JSON-RPC requests, contract calls
+// included, and WaitForTransaction with t instead of telemetry.Global().
+func WithTelemetry(t *telemetry.Telemetry) EvmOption {
+	return func(o *evmOptions) {
+		o.tel = t
+	}
+}
+
+// WithCache makes the client keep the metadata URIs it reads from the
+// Registry in c, so that organization and service clients can be created
+// from the cache. Pass the same cache to storage.NewCachedStorage for the
+// metadata files themselves.
+func WithCache(c *storage.Cache) EvmOption {
+	return func(o *evmOptions) {
+		o.cache = c
+	}
 }
 
 // InitEvm dials an Ethereum endpoint and initializes typed bindings for
@@ -59,24 +132,37 @@ type networks map[string]struct {
 // snet-ecosystem-contracts for the given network. It also discovers the
 // FetchToken address via MPE and binds it.
 //
+// Every address can be overridden, which is required on chains that
+// snet-ecosystem-contracts does not know (private chains, local devnets).
+// InitEvm checks that contract bytecode exists at each address and returns an
+// error wrapping ErrNoContractCode otherwise.
+//
 // Parameters:
 //   - network: chain/network key as used by snet-ecosystem-contracts (e.g. "11155111").
 //   - endpoint: RPC/WS endpoint URL to dial.
 //   - registryAddress: optional registry contract address override. Provide an
 //     empty string to resolve it from snet-ecosystem-contracts metadata.
+//   - opts: optional MPE and token address overrides (WithMPEAddress,
+//     WithTokenAddress), logger (WithLogger) and telemetry (WithTelemetry).
 //
 // Returns a ready-to-use EVMClient or an error.
-func InitEvm(network, endpoint, registryAddress string, storage storage.Storage) (*EVMClient, error) {
-	registryNetworksRaw := contracts.GetNetworks(contracts.Registry)
-	var rn networks
-	err := json.Unmarshal(registryNetworksRaw, &rn)
+func InitEvm(network, endpoint, registryAddress string, storage storage.Storage, opts ...EvmOption) (*EVMClient, error) {
+	var o evmOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var registryAddr common.Address
+	if registryAddress != "" {
+		registryAddr = common.HexToAddress(registryAddress)
+	}
+	registryAddr, err := contractAddress(contracts.Registry, network, registryAddr)
 	if err != nil {
-		zap.L().Error("Failed to unmarshal", zap.Error(err))
 		return nil, err
 	}
-
-	if registryAddress == "" {
-		registryAddress = rn[network].Address
+	mpeAddr, err := contractAddress(contracts.MultiPartyEscrow, network, o.mpe)
+	if err != nil {
+		return nil, err
 	}
 
 	ctx := context.Background()
@@ -84,75 +170,209 @@ func InitEvm(network, endpoint, registryAddress string, storage storage.Storage)
 	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	var eth = new(EVMClient)
+	var eth = &EVMClient{log: o.log, tel: o.tel, cache: o.cache}
 	eth.Client, err = ethclient.DialContext(ctx, endpoint)
 	if err != nil {
-		zap.L().Error("Failed to ethdial", zap.Error(err))
+		eth.logger().Error("Failed to ethdial", zap.Error(err))
+		return nil, err
+	}
+	fail := func(err error) (*EVMClient, error) {
+		eth.Client.Close()
 		return nil, err
 	}
 
-	eth.Registry, err = NewRegistry(common.HexToAddress(registryAddress), eth.Client)
+	if err := eth.checkCode(ctx, contracts.Registry, network, registryAddr); err != nil {
+		return fail(err)
+	}
+	eth.Registry, err = NewRegistry(registryAddr, eth.traced())
 	if err != nil {
-		return eth, err
+		return fail(err)
 	}
 
-	MPENetworksRaw := contracts.GetNetworks(contracts.MultiPartyEscrow)
-	var mpen networks
-	err = json.Unmarshal(MPENetworksRaw, &mpen)
+	if err := eth.checkCode(ctx, contracts.MultiPartyEscrow, network, mpeAddr); err != nil {
+		return fail(err)
+	}
+	eth.MPEAddress = mpeAddr
+	eth.mpeDeployTx = deploymentTx(contracts.MultiPartyEscrow, network, mpeAddr)
+	eth.MPE, err = NewMultiPartyEscrow(eth.MPEAddress, eth.traced())
 	if err != nil {
-		zap.L().Error("Failed to unmarshal", zap.Error(err))
-		return nil, err
+		return fail(fmt.Errorf("bind MultiPartyEscrow: %w", err))
 	}
-	MPEAddress := mpen[network].Address
-	eth.MPE, err = NewMultiPartyEscrow(common.HexToAddress(MPEAddress), eth.Client)
 
-	callOpts := &bind.CallOpts{}
-	tokenAddr, err := eth.MPE.Token(callOpts)
-	if err != nil {
-		zap.L().Error("Failed to get token address", zap.Error(err))
-		return nil, err
+	tokenAddr := o.token
+	if tokenAddr == (common.Address{}) {
+		tokenAddr, err = eth.MPE.Token(&bind.CallOpts{Context: ctx})
+		if err != nil {
+			eth.logger().Error("Failed to get token address", zap.Error(err))
+			return fail(err)
+		}
+	}
+	if err := eth.checkCode(ctx, contracts.FetchToken, network, tokenAddr); err != nil {
+		return fail(err)
 	}
 
-	eth.FetchToken, err = NewFetchToken(tokenAddr, eth.Client)
+	eth.FetchToken, err = NewFetchToken(tokenAddr, eth.traced())
 	if err != nil {
-		zap.L().Error("Failed to get FetchToken", zap.Error(err))
-		return nil, err
+		eth.logger().Error("Failed to get FetchToken", zap.Error(err))
+		return fail(err)
 	}
 
 	eth.Storage = storage
 
-	return eth, err
+	return eth, nil
+}
+
+// contractAddress returns override, or the address snet-ecosystem-contracts
+// lists for contract on network when override is zero.
+func contractAddress(contract contracts.SnetContract, network string, override common.Address) (common.Address, error) {
+	if override != (common.Address{}) {
+		return override, nil
+	}
+	var known networks
+	if err := json.Unmarshal(contracts.GetNetworks(contract), &known); err != nil {
+		return common.Address{}, fmt.Errorf("parse %s networks: %w", contract, err)
+	}
+	addr := known[network].Address
+	if addr == "" {
+		return common.Address{}, fmt.Errorf("no %s address known for chain %s: set it explicitly", contract, network)
+	}
+	return common.HexToAddress(addr), nil
+}
+
+// deploymentTx returns the transaction snet-ecosystem-contracts lists as the
+// deployment of contract on network, or the zero hash when it lists none or
+// lists another address.
+func deploymentTx(contract contracts.SnetContract, network string, addr common.Address) common.Hash {
+	var known networks
+	if err := json.Unmarshal(contracts.GetNetworks(contract), &known); err != nil {
+		return common.Hash{}
+	}
+	n := known[network]
+	if n.TransactionHash == "" || !common.IsHexAddress(n.Address) || common.HexToAddress(n.Address) != addr {
+		return common.Hash{}
+	}
+	return common.HexToHash(n.TransactionHash)
+}
+
+// mpeDeploymentBlock returns the block the MPE contract was deployed in, or
+// 0 when its deployment transaction is unknown.
+func (evm *EVMClient) mpeDeploymentBlock(ctx context.Context) (uint64, error) {
+	if evm.mpeDeployTx == (common.Hash{}) {
+		return 0, nil
+	}
+	receipt, err := evm.traced().TransactionReceipt(ctx, evm.mpeDeployTx)
+	if err != nil {
+		return 0, fmt.Errorf("read MultiPartyEscrow deployment receipt: %w", err)
+	}
+	return receipt.BlockNumber.Uint64(), nil
+}
+
+// checkCode returns an error wrapping ErrNoContractCode when addr has no
+// bytecode.
+func (evm *EVMClient) checkCode(ctx context.Context, contract contracts.SnetContract, network string, addr common.Address) error {
+	code, err := evm.traced().CodeAt(ctx, addr, nil)
+	if err != nil {
+		return fmt.Errorf("read %s code at %s: %w", contract, addr.Hex(), err)
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("%s at %s on chain %s: %w", contract, addr.Hex(), network, ErrNoContractCode)
+	}
+	return nil
+}
+
+// logger returns the logger set with WithLogger, or zap.L().
+func (evm *EVMClient) logger() *zap.Logger {
+	if evm.log != nil {
+		return evm.log
+	}
+	return zap.L()
 }
 
 // GetCurrentBlockNumber returns the latest block number using a non-cancellable
 // background context. Prefer GetCurrentBlockNumberCtx if you need cancellation.
 func (evm *EVMClient) GetCurrentBlockNumber() (*big.Int, error) {
-	header, err := evm.Client.HeaderByNumber(context.Background(), nil)
+	header, err := evm.traced().HeaderByNumber(context.Background(), nil)
 	if err != nil {
-		zap.L().Error("failed to get last block number", zap.Error(err))
+		evm.logger().Error("failed to get last block number", zap.Error(err))
 		return nil, err
 	}
 	return header.Number, nil
 }
 
 // getOrgMetadataUri returns the organization metadata URI (as stored in Registry)
-// for the given orgID. It panics if the organization cannot be retrieved or is not found.
+// for the given orgID. It returns an error wrapping ErrOrganizationNotFound if
+// the Registry has no such organization.
 //
 // Note: the method name uses "Uri" for historical reasons; it returns a URI string.
-func (evm *EVMClient) getOrgMetadataUri(orgID string) string {
-	orgId := StringToBytes32(orgID)
-	org, err := evm.Registry.GetOrganizationById(nil, orgId)
-	if err != nil || &org == nil || !org.Found {
-		zap.L().Panic("Error Retrieving contract details for the Given Organization and Service Ids ",
-			zap.String("OrganizationId", orgID))
+func (evm *EVMClient) getOrgMetadataUri(ctx context.Context, orgID string) (string, error) {
+	return evm.registryLookup(ctx, orgCacheKey(orgID), func(ctx context.Context) ([]byte, error) {
+		org, err := evm.Registry.GetOrganizationById(&bind.CallOpts{Context: ctx}, StringToBytes32(orgID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read organization %q from registry: %w", orgID, err)
+		}
+		if !org.Found {
+			return nil, fmt.Errorf("%w: %q", ErrOrganizationNotFound, orgID)
+		}
+		return org.OrgMetadataURI, nil
+	})
+}
+
+// registryLookup returns the Registry value read by fetch, through the cache
+// of the client when it has one.
+func (evm *EVMClient) registryLookup(ctx context.Context, key string, fetch func(ctx context.Context) ([]byte, error)) (string, error) {
+	var (
+		value []byte
+		err   error
+	)
+	if evm.cache != nil {
+		value, err = evm.cache.Lookup(ctx, key, fetch)
+	} else {
+		value, err = fetch(ctx)
+	}
+	return string(value), err
+}
+
+// Cache returns the cache set with WithCache, or nil.
+func (evm *EVMClient) Cache() *storage.Cache {
+	if evm == nil {
+		return nil
+	}
+	return evm.cache
+}
+
+// InvalidateRegistryCache drops the cached Registry lookups of an
+// organization, or of one of its services when serviceID is not empty, so
+// that the next client created for them reads the Registry again.
+func (evm *EVMClient) InvalidateRegistryCache(orgID, serviceID string) {
+	if evm.cache == nil {
+		return
+	}
+	key := orgCacheKey(orgID)
+	if serviceID != "" {
+		key = serviceCacheKey(orgID, serviceID)
+	}
+	if err := evm.cache.Delete(key); err != nil {
+		evm.logger().Warn("failed to invalidate registry cache", zap.String("key", key), zap.Error(err))
 	}
-	return string(org.OrgMetadataURI[:])
+}
+
+func orgCacheKey(orgID string) string {
+	return "registry/org/" + orgID
+}
+
+func serviceCacheKey(orgID, serviceID string) string {
+	return "registry/service/" + orgID + "/" + serviceID
 }
 
 // GetOrganizations returns organization IDs from the on-chain Registry.
 // On error, it logs and returns nil.
 func (evm *EVMClient) GetOrganizations() ([]string, error) {
-	organizations, err := evm.Registry.ListOrganizations(nil)
+	return evm.GetOrganizationsCtx(context.Background())
+}
+
+// GetOrganizationsCtx is like GetOrganizations but reads the Registry with ctx.
+func (evm *EVMClient) GetOrganizationsCtx(ctx context.Context) ([]string, error) {
+	organizations, err := evm.Registry.ListOrganizations(&bind.CallOpts{Context: ctx})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list organizations: %w", err)
 	}
@@ -1,13 +1,16 @@
 package blockchain
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func TestInitEvm_Unreachable(t *testing.T) {
 	start := time.Now()
-	_, err := InitEvm("devnet", "http://127.0.0.1:1", "", nil)
+	_, err := InitEvm("11155111", "http://127.0.0.1:1", "", nil)
 	if err == nil {
 		t.Fatal("expected error dialing")
 	}
@@ -15,3 +18,24 @@ func TestInitEvm_Unreachable(t *testing.T) {
 		t.Fatalf("InitEvm took too long")
 	}
 }
+
+func TestInitEvm_UnknownNetworkNeedsAddresses(t *testing.T) {
+	_, err := InitEvm("31337", "http://127.0.0.1:1", "", nil)
+	if err == nil || !strings.Contains(err.Error(), "no Registry address known for chain 31337") {
+		t.Fatalf("err = %v; want missing Registry address", err)
+	}
+
+	registry := "0x00000000000000000000000000000000000000aa"
+	_, err = InitEvm("31337", "http://127.0.0.1:1", registry, nil)
+	if err == nil || !strings.Contains(err.Error(), "no MultiPartyEscrow address known for chain 31337") {
+		t.Fatalf("err = %v; want missing MultiPartyEscrow address", err)
+	}
+
+	// With every address given, InitEvm gets as far as the code check.
+	_, err = InitEvm("31337", "http://127.0.0.1:1", registry, nil,
+		WithMPEAddress(common.HexToAddress("0x00000000000000000000000000000000000000bb")),
+		WithTokenAddress(common.HexToAddress("0x00000000000000000000000000000000000000cc")))
+	if err == nil || !strings.Contains(err.Error(), "read Registry code") {
+		t.Fatalf("err = %v; want the code check to fail on the unreachable endpoint", err)
+	}
+}
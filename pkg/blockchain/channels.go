@@ -0,0 +1,447 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"go.uber.org/zap"
+)
+
+// receiptBackoff caps the polling interval while waiting for channel and
+// escrow transactions to be mined.
+const receiptBackoff = 30 * time.Second
+
+// defaultScanRange is the number of blocks ListChannels asks for ChannelOpen
+// events at once. minScanRange is the smallest range it splits a rejected
+// query into before giving up.
+const (
+	defaultScanRange = 100_000
+	minScanRange     = 1_000
+)
+
+// ErrChannelNotFound is returned when the MPE contract has no channel with the
+// requested ID.
+var ErrChannelNotFound = errors.New("payment channel not found")
+
+// SignedAmountFunc returns the amount signed for a channel as recorded by the
+// daemon serving it. It returns (nil, nil) when it cannot tell, for example
+// because the channel belongs to a service group it does not serve.
+type SignedAmountFunc func(ctx context.Context, channel *ChannelInfo) (*big.Int, error)
+
+// ChannelInfo is the on-chain state of a payment channel together with the
+// daemon's signed amount.
+type ChannelInfo struct {
+	ChannelID *big.Int
+	MultiPartyEscrowChannel
+	// SignedAmount is the amount the daemon has recorded as signed; nil when
+	// unknown (no SignedAmountFunc, or the daemon could not be asked).
+	SignedAmount *big.Int
+	// Expired reports whether the expiration block has been reached, so the
+	// sender can reclaim the channel value with ChannelClaimTimeout.
+	Expired bool
+}
+
+// Available returns the part of the channel value not yet signed away, or the
+// whole value when the signed amount is unknown.
+func (c *ChannelInfo) Available() *big.Int {
+	if c.Value == nil {
+		return big.NewInt(0)
+	}
+	if c.SignedAmount == nil {
+		return new(big.Int).Set(c.Value)
+	}
+	return availableAmount(c.Value, c.SignedAmount)
+}
+
+// ChannelManager lists and manages the MPE payment channels of one sender:
+// it reads their on-chain state and extends, tops up or reclaims them. Every
+// transaction waits for its receipt and returns the event it emitted.
+type ChannelManager struct {
+	evm          *EVMClient
+	signer       signer.Signer
+	sender       common.Address
+	signedAmount SignedAmountFunc
+	scanRange    uint64
+	startBlock   *uint64
+}
+
+// ChannelManagerOption configures a ChannelManager.
+type ChannelManagerOption func(*ChannelManager)
+
+// WithSignedAmountFunc makes ListChannels and Channel report the daemon's
+// signed amount of each channel using fn.
+func WithSignedAmountFunc(fn SignedAmountFunc) ChannelManagerOption {
+	return func(m *ChannelManager) {
+		m.signedAmount = fn
+	}
+}
+
+// WithScanRange sets the number of blocks ListChannels filters ChannelOpen
+// events over in one request (100000 by default). Lower it for RPC providers
+// that limit the block range of log queries.
+func WithScanRange(blocks uint64) ChannelManagerOption {
+	return func(m *ChannelManager) {
+		if blocks > 0 {
+			m.scanRange = blocks
+		}
+	}
+}
+
+// WithStartBlock makes ListChannels look for ChannelOpen events from block on.
+// By default it starts at the block the MPE contract was deployed in when
+// snet-ecosystem-contracts lists its deployment for the network, and at block
+// 0 otherwise (for example with WithMPEAddress on a private chain).
+func WithStartBlock(block uint64) ChannelManagerOption {
+	return func(m *ChannelManager) {
+		m.startBlock = &block
+	}
+}
+
+// ChannelManager returns a manager for the channels whose sender is the
+// address of s. The signer signs every channel transaction.
+func (evm *EVMClient) ChannelManager(s signer.Signer, opts ...ChannelManagerOption) (*ChannelManager, error) {
+	if s == nil {
+		return nil, errors.New("signer is required to manage payment channels")
+	}
+	m := &ChannelManager{
+		evm:       evm,
+		signer:    s,
+		sender:    s.Address(),
+		scanRange: defaultScanRange,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// Sender returns the address whose channels are managed.
+func (m *ChannelManager) Sender() common.Address {
+	return m.sender
+}
+
+// ListChannels returns every channel opened by the sender, ordered by channel
+// ID, with its current on-chain value, nonce and expiration. Unlike
+// FilterChannels it is not restricted to one recipient or payment group.
+// ChannelOpen events are filtered in block ranges (see WithScanRange) from the
+// start block (see WithStartBlock).
+func (m *ChannelManager) ListChannels(ctx context.Context) ([]*ChannelInfo, error) {
+	block, err := m.evm.GetCurrentBlockNumberCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from, err := m.scanStart(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var ids []*big.Int
+	err = scanBlocks(ctx, from, block.Uint64(), m.scanRange, func(opts *bind.FilterOpts) error {
+		it, err := m.evm.MPE.FilterChannelOpen(opts, []common.Address{m.sender}, nil, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := it.Close(); cerr != nil {
+				m.evm.logger().Error("error closing channel open iterator", zap.Error(cerr))
+			}
+		}()
+		for it.Next() {
+			id := it.Event.ChannelId
+			if !seen[id.String()] {
+				seen[id.String()] = true
+				ids = append(ids, id)
+			}
+		}
+		return it.Error()
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) < 0 })
+
+	channels := make([]*ChannelInfo, 0, len(ids))
+	for _, id := range ids {
+		info, err := m.channel(ctx, id, block)
+		if errors.Is(err, ErrChannelNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.Sender != m.sender {
+			continue
+		}
+		channels = append(channels, info)
+	}
+	return channels, nil
+}
+
+// scanStart returns the first block ListChannels filters: the one set with
+// WithStartBlock, or the deployment block of the MPE contract.
+func (m *ChannelManager) scanStart(ctx context.Context) (uint64, error) {
+	if m.startBlock != nil {
+		return *m.startBlock, nil
+	}
+	return m.evm.mpeDeploymentBlock(ctx)
+}
+
+// scanBlocks calls filter for consecutive block ranges of at most size blocks
+// covering from to to (inclusive). Providers reject log queries that span too
+// many blocks or return too many results, so a range filter fails on is split
+// in halves and retried, down to minScanRange blocks; the error of the
+// smallest range is returned. filter may see events of a retried range twice.
+func scanBlocks(ctx context.Context, from, to, size uint64, filter func(*bind.FilterOpts) error) error {
+	for from <= to {
+		end := to
+		if to-from >= size {
+			end = from + size - 1
+		}
+		if err := filter(&bind.FilterOpts{Start: from, End: &end, Context: ctx}); err != nil {
+			if end-from < minScanRange || ctx.Err() != nil {
+				return fmt.Errorf("filter blocks %d to %d: %w", from, end, err)
+			}
+			size = (end - from + 1) / 2
+			continue
+		}
+		if end == to {
+			break
+		}
+		from = end + 1
+	}
+	return nil
+}
+
+// Channel returns the current state of one channel.
+func (m *ChannelManager) Channel(ctx context.Context, channelID *big.Int) (*ChannelInfo, error) {
+	block, err := m.evm.GetCurrentBlockNumberCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.channel(ctx, channelID, block)
+}
+
+// channel reads the channel at block and asks the daemon for its signed
+// amount when a SignedAmountFunc is configured. A daemon failure is logged and
+// leaves SignedAmount nil.
+func (m *ChannelManager) channel(ctx context.Context, channelID, block *big.Int) (*ChannelInfo, error) {
+	ch, err := m.evm.MPE.Channels(&bind.CallOpts{Context: ctx, BlockNumber: block}, channelID)
+	if err != nil {
+		return nil, err
+	}
+	var zero common.Address
+	if ch.Sender == zero {
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotFound, channelID)
+	}
+	info := &ChannelInfo{
+		ChannelID: new(big.Int).Set(channelID),
+		MultiPartyEscrowChannel: MultiPartyEscrowChannel{
+			Sender:     ch.Sender,
+			Recipient:  ch.Recipient,
+			GroupID:    ch.GroupId,
+			Value:      ch.Value,
+			Nonce:      ch.Nonce,
+			Expiration: ch.Expiration,
+			Signer:     ch.Signer,
+		},
+		Expired: ch.Expiration != nil && block.Cmp(ch.Expiration) >= 0,
+	}
+	if m.signedAmount != nil {
+		signed, err := m.signedAmount(ctx, info)
+		if err != nil {
+			m.evm.logger().Warn("failed to read signed amount from daemon", zap.String("channel", channelID.String()), zap.Error(err))
+		} else {
+			info.SignedAmount = signed
+		}
+	}
+	return info, nil
+}
+
+// ChannelExtend moves the expiration of the channel to newExpiration, which
+// must be later than the current one.
+func (m *ChannelManager) ChannelExtend(ctx context.Context, channelID, newExpiration *big.Int) (*MultiPartyEscrowChannelExtend, error) {
+	info, err := m.owned(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkExtend(info, newExpiration); err != nil {
+		return nil, err
+	}
+	opts, err := m.evm.GetTransactOptsCtx(ctx, m.signer)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := m.evm.MPE.ChannelExtend(opts, channelID, newExpiration)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := m.evm.WaitForTransaction(ctx, tx.Hash(), receiptBackoff)
+	if err != nil {
+		return nil, err
+	}
+	return eventFromReceipt(receipt, m.evm.MPE.ParseChannelExtend)
+}
+
+// ChannelAddFunds moves amount from the sender's escrow balance into the
+// channel. The escrow balance must cover amount.
+func (m *ChannelManager) ChannelAddFunds(ctx context.Context, channelID, amount *big.Int) (*MultiPartyEscrowChannelAddFunds, error) {
+	if _, err := m.owned(ctx, channelID); err != nil {
+		return nil, err
+	}
+	if err := m.checkEscrow(ctx, amount); err != nil {
+		return nil, err
+	}
+	opts, err := m.evm.GetTransactOptsCtx(ctx, m.signer)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := m.evm.MPE.ChannelAddFunds(opts, channelID, amount)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := m.evm.WaitForTransaction(ctx, tx.Hash(), receiptBackoff)
+	if err != nil {
+		return nil, err
+	}
+	return eventFromReceipt(receipt, m.evm.MPE.ParseChannelAddFunds)
+}
+
+// ChannelExtendAndAddFunds extends the channel to newExpiration and adds
+// amount from the escrow balance in a single transaction.
+func (m *ChannelManager) ChannelExtendAndAddFunds(ctx context.Context, channelID, newExpiration, amount *big.Int) (*MultiPartyEscrowChannelExtend, *MultiPartyEscrowChannelAddFunds, error) {
+	info, err := m.owned(ctx, channelID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkExtend(info, newExpiration); err != nil {
+		return nil, nil, err
+	}
+	if err := m.checkEscrow(ctx, amount); err != nil {
+		return nil, nil, err
+	}
+	opts, err := m.evm.GetTransactOptsCtx(ctx, m.signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	tx, err := m.evm.MPE.ChannelExtendAndAddFunds(opts, channelID, newExpiration, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	receipt, err := m.evm.WaitForTransaction(ctx, tx.Hash(), receiptBackoff)
+	if err != nil {
+		return nil, nil, err
+	}
+	extended, err := eventFromReceipt(receipt, m.evm.MPE.ParseChannelExtend)
+	if err != nil {
+		return nil, nil, err
+	}
+	added, err := eventFromReceipt(receipt, m.evm.MPE.ParseChannelAddFunds)
+	if err != nil {
+		return nil, nil, err
+	}
+	return extended, added, nil
+}
+
+// ChannelClaimTimeout returns the unspent value of an expired channel to the
+// sender's escrow balance. It fails before the expiration block is reached.
+func (m *ChannelManager) ChannelClaimTimeout(ctx context.Context, channelID *big.Int) (*MultiPartyEscrowChannelSenderClaim, error) {
+	info, err := m.owned(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Expired {
+		return nil, fmt.Errorf("channel %s has not expired yet (expiration block %s)", channelID, info.Expiration)
+	}
+	opts, err := m.evm.GetTransactOptsCtx(ctx, m.signer)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := m.evm.MPE.ChannelClaimTimeout(opts, channelID)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := m.evm.WaitForTransaction(ctx, tx.Hash(), receiptBackoff)
+	if err != nil {
+		return nil, err
+	}
+	return eventFromReceipt(receipt, m.evm.MPE.ParseChannelSenderClaim)
+}
+
+// ClaimExpiredChannels calls ChannelClaimTimeout for every expired channel of
+// the sender that still holds value and returns the claims made. It stops at
+// the first failure and returns the claims made so far with the error.
+func (m *ChannelManager) ClaimExpiredChannels(ctx context.Context) ([]*MultiPartyEscrowChannelSenderClaim, error) {
+	channels, err := m.ListChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var claims []*MultiPartyEscrowChannelSenderClaim
+	for _, ch := range channels {
+		if !ch.Expired || ch.Value == nil || ch.Value.Sign() == 0 {
+			continue
+		}
+		claim, err := m.ChannelClaimTimeout(ctx, ch.ChannelID)
+		if err != nil {
+			return claims, fmt.Errorf("claim channel %s: %w", ch.ChannelID, err)
+		}
+		claims = append(claims, claim)
+	}
+	return claims, nil
+}
+
+// owned returns the channel and verifies that it belongs to the sender, since
+// the contract only lets the sender extend, fund or reclaim it.
+func (m *ChannelManager) owned(ctx context.Context, channelID *big.Int) (*ChannelInfo, error) {
+	if channelID == nil {
+		return nil, errors.New("channel id is required")
+	}
+	info, err := m.Channel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if info.Sender != m.sender {
+		return nil, fmt.Errorf("channel %s belongs to %s, not %s", channelID, info.Sender.Hex(), m.sender.Hex())
+	}
+	return info, nil
+}
+
+// checkEscrow verifies that the sender's escrow balance covers amount.
+func (m *ChannelManager) checkEscrow(ctx context.Context, amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return errors.New("amount must be positive")
+	}
+	return m.evm.checkEscrowBalance(ctx, m.sender, amount)
+}
+
+// checkExtend verifies that newExpiration moves the channel expiration forward.
+func checkExtend(info *ChannelInfo, newExpiration *big.Int) error {
+	if newExpiration == nil {
+		return errors.New("new expiration is required")
+	}
+	if info.Expiration != nil && newExpiration.Cmp(info.Expiration) <= 0 {
+		return fmt.Errorf("new expiration %s must be after the current expiration %s", newExpiration, info.Expiration)
+	}
+	return nil
+}
+
+// eventFromReceipt returns the first log of receipt that parse accepts.
+func eventFromReceipt[E any](receipt *types.Receipt, parse func(types.Log) (*E, error)) (*E, error) {
+	for _, l := range receipt.Logs {
+		if l == nil {
+			continue
+		}
+		if ev, err := parse(*l); err == nil {
+			return ev, nil
+		}
+	}
+	return nil, fmt.Errorf("event not found in receipt of tx %s", receipt.TxHash.Hex())
+}
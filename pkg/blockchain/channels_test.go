@@ -0,0 +1,158 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	contracts "github.com/singnet/snet-ecosystem-contracts"
+)
+
+func TestChannelInfoAvailable(t *testing.T) {
+	tests := []struct {
+		name   string
+		info   ChannelInfo
+		expect int64
+	}{
+		{"no value", ChannelInfo{}, 0},
+		{"signed amount unknown", ChannelInfo{MultiPartyEscrowChannel: MultiPartyEscrowChannel{Value: big.NewInt(100)}}, 100},
+		{"partly signed", ChannelInfo{MultiPartyEscrowChannel: MultiPartyEscrowChannel{Value: big.NewInt(100)}, SignedAmount: big.NewInt(30)}, 70},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.Available(); got.Cmp(big.NewInt(tt.expect)) != 0 {
+				t.Fatalf("Available() = %s, want %d", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestCheckExtend(t *testing.T) {
+	info := &ChannelInfo{MultiPartyEscrowChannel: MultiPartyEscrowChannel{Expiration: big.NewInt(500)}}
+	if err := checkExtend(info, big.NewInt(500)); err == nil {
+		t.Fatal("expected error for an unchanged expiration")
+	}
+	if err := checkExtend(info, nil); err == nil {
+		t.Fatal("expected error for a missing expiration")
+	}
+	if err := checkExtend(info, big.NewInt(501)); err != nil {
+		t.Fatalf("checkExtend: %v", err)
+	}
+}
+
+func TestEventFromReceipt(t *testing.T) {
+	wanted := &types.Log{Index: 2}
+	receipt := &types.Receipt{Logs: []*types.Log{{Index: 1}, nil, wanted}}
+	parse := func(l types.Log) (*MultiPartyEscrowChannelExtend, error) {
+		if l.Index != 2 {
+			return nil, errors.New("event signature mismatch")
+		}
+		return &MultiPartyEscrowChannelExtend{ChannelId: big.NewInt(7), Raw: l}, nil
+	}
+
+	ev, err := eventFromReceipt(receipt, parse)
+	if err != nil {
+		t.Fatalf("eventFromReceipt: %v", err)
+	}
+	if ev.ChannelId.Int64() != 7 || ev.Raw.Index != 2 {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+
+	if _, err := eventFromReceipt(&types.Receipt{}, parse); err == nil {
+		t.Fatal("expected error when the receipt has no matching log")
+	}
+}
+
+func TestScanBlocks(t *testing.T) {
+	// The provider rejects ranges wider than 3000 blocks.
+	tooWide := errors.New("query exceeds max block range 3000")
+	var next uint64
+	err := scanBlocks(context.Background(), 0, 20_000, 10_000, func(opts *bind.FilterOpts) error {
+		if *opts.End-opts.Start >= 3000 {
+			return tooWide
+		}
+		if opts.Start != next {
+			t.Fatalf("range starts at %d; want %d", opts.Start, next)
+		}
+		next = *opts.End + 1
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("scanBlocks: %v", err)
+	}
+	if next != 20_001 {
+		t.Fatalf("scanned up to block %d; want 20000", next-1)
+	}
+
+	// Block 5000 is rejected even in the smallest range.
+	err = scanBlocks(context.Background(), 0, 20_000, 10_000, func(opts *bind.FilterOpts) error {
+		if opts.Start <= 5000 && *opts.End >= 5000 {
+			return tooWide
+		}
+		return nil
+	})
+	if !errors.Is(err, tooWide) || !strings.Contains(err.Error(), "filter blocks 5000 to") {
+		t.Fatalf("scanBlocks error = %v; want the rejected range", err)
+	}
+}
+
+// receiptService answers eth_getTransactionReceipt with a receipt mined in
+// block.
+type receiptService struct {
+	block uint64
+	asked *common.Hash
+}
+
+func (s receiptService) GetTransactionReceipt(hash common.Hash) (map[string]any, error) {
+	*s.asked = hash
+	return map[string]any{
+		"transactionHash":   hash,
+		"blockNumber":       hexutil.Uint64(s.block),
+		"cumulativeGasUsed": hexutil.Uint64(0),
+		"gasUsed":           hexutil.Uint64(0),
+		"logsBloom":         types.Bloom{},
+		"logs":              []*types.Log{},
+		"status":            hexutil.Uint64(1),
+	}, nil
+}
+
+func TestChannelManagerScanStart(t *testing.T) {
+	mainnetMPE := common.HexToAddress("0xdF8d4826E016aFA8803d94A716FEB70aD5D2B8Ac")
+	deployTx := deploymentTx(contracts.MultiPartyEscrow, "1", mainnetMPE)
+	if deployTx == (common.Hash{}) {
+		t.Fatal("no deployment transaction known for the mainnet MultiPartyEscrow")
+	}
+	if got := deploymentTx(contracts.MultiPartyEscrow, "1", common.HexToAddress("0xbb")); got != (common.Hash{}) {
+		t.Fatalf("deployment transaction of another address = %s; want none", got)
+	}
+
+	var asked common.Hash
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", receiptService{block: 8_000_000, asked: &asked}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	evm := &EVMClient{Client: ethclient.NewClient(rpc.DialInProc(server)), mpeDeployTx: deployTx}
+	ctx := context.Background()
+
+	m := &ChannelManager{evm: evm}
+	if from, err := m.scanStart(ctx); err != nil || from != 8_000_000 || asked != deployTx {
+		t.Fatalf("scanStart = %d, %v; want the deployment block 8000000", from, err)
+	}
+	WithStartBlock(9_000_000)(m)
+	if from, err := m.scanStart(ctx); err != nil || from != 9_000_000 {
+		t.Fatalf("scanStart with WithStartBlock = %d, %v; want 9000000", from, err)
+	}
+	unknown := &ChannelManager{evm: &EVMClient{}}
+	if from, err := unknown.scanStart(ctx); err != nil || from != 0 {
+		t.Fatalf("scanStart without a known deployment = %d, %v; want 0", from, err)
+	}
+}
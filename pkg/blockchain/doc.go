@@ -46,6 +46,10 @@
 //   - Approve/transfer operations
 //   - Balance queries
 //
+// InitEvm takes the addresses from the published deployments for the chain.
+// WithMPEAddress and WithTokenAddress override them (required on other
+// chains), and the token address defaults to the one the MPE contract reports.
+//
 // # Payment Channels
 //
 // The MPE integration (mpe.go) handles the complete lifecycle of payment channels:
@@ -58,20 +62,46 @@
 //		expirationBlock,
 //	)
 //
-// Adding Funds:
+// Managing Channels:
 //
-//	err := evm.AddFundsToChannel(channelID, additionalAmount)
+// A ChannelManager lists every channel of a sender, with the on-chain value,
+// nonce and expiration next to the daemon's signed amount (when a
+// SignedAmountFunc is configured), and extends, funds or reclaims them. Each
+// transaction waits for its receipt and returns the emitted event:
 //
-// Extending Expiration:
+//	manager, err := evm.ChannelManager(s) // s is a signer.Signer
+//	channels, err := manager.ListChannels(ctx)
+//	for _, ch := range channels {
+//		fmt.Println(ch.ChannelID, ch.Value, ch.Nonce, ch.Expiration, ch.Available())
+//	}
 //
-//	err := evm.ExtendChannel(channelID, newExpirationBlock)
+// ListChannels filters ChannelOpen events in ranges of 100000 blocks and
+// splits a range the RPC provider rejects; WithScanRange sets a smaller range
+// for providers with a known limit. It starts at the block the MPE contract
+// was deployed in on the networks snet-ecosystem-contracts lists; use
+// WithStartBlock to start later, or on other networks.
 //
-// Watching Events:
+//	extended, err := manager.ChannelExtend(ctx, channelID, newExpirationBlock)
+//	added, err := manager.ChannelAddFunds(ctx, channelID, additionalAmount)
+//	extended, added, err := manager.ChannelExtendAndAddFunds(ctx, channelID, newExpirationBlock, additionalAmount)
 //
-//	events, err := evm.WatchChannelEvents(channelID)
-//	for event := range events {
-//		// Handle ChannelOpen, ChannelAddFunds, etc.
-//	}
+// Recovering unspent funds of expired channels into the escrow balance:
+//
+//	claim, err := manager.ChannelClaimTimeout(ctx, channelID)
+//	claims, err := manager.ClaimExpiredChannels(ctx)
+//
+// # Escrow Balance
+//
+// Channels are funded from the sender's MPE escrow balance. GetBalances reads
+// the token, escrow and allowance balances of an address; the escrow
+// operations take amounts in AASI (see AsiToAasi), wait for the receipt and
+// return the emitted event:
+//
+//	balances, err := evm.GetBalances(ctx, address)
+//	amount, err := blockchain.AsiToAasi("10")
+//	deposit, err := evm.DepositToEscrow(ctx, s, amount)   // approves MPE if needed
+//	withdraw, err := evm.WithdrawFromEscrow(ctx, s, amount)
+//	transfer, err := evm.TransferEscrow(ctx, s, receiver, amount)
 //
 // # Organization Operations
 //
@@ -149,6 +179,10 @@
 //
 // Common blockchain errors:
 //
+//   - ErrOrganizationNotFound, ErrServiceNotFound, ErrGroupNotFound: Registry
+//     lookups for unknown IDs (wrapped; test with errors.Is)
+//   - ErrNoContractCode: InitEvm found no contract at the Registry, MPE or
+//     token address, usually a wrong address or a wrong chain
 //   - Insufficient gas: Wallet lacks ETH for transaction fees
 //   - Insufficient FET: Cannot fund payment channels
 //   - Transaction reverted: Contract rejected the operation
@@ -170,22 +204,35 @@
 //
 // # Event Watching
 //
-// Subscribe to on-chain events for real-time updates:
+// Subscribe to Registry events for real-time updates (needs a WebSocket RPC):
 //
-//	// Watch for new services
-//	events, err := evm.WatchServiceEvents(orgID)
-//	for event := range events {
-//		fmt.Printf("New service: %s\n", event.ServiceID)
+//	events := make(chan blockchain.RegistryEvent)
+//	sub, err := evm.WatchRegistryEventsCtx(ctx, nil, events, orgID)
+//	if err != nil {
+//		return err
+//	}
+//	defer sub.Unsubscribe()
+//	for {
+//		select {
+//		case ev := <-events:
+//			fmt.Printf("%s: %s/%s\n", ev.Type, ev.OrgID, ev.ServiceID)
+//		case err := <-sub.Err():
+//			return err
+//		}
 //	}
 //
 // # Private Key Management
 //
-// The package uses ECDSA private keys for signing:
+// Transactions and messages are signed through a signer.Signer, so the key
+// can live in process memory, in an encrypted keystore file or in an external
+// signer such as Clef:
 //
-//	// Parsed automatically from Config.PrivateKey
-//	address, key, err := blockchain.ParsePrivateKeyECDSA(hexKey)
+//	s, err := signer.ParsePrivateKey(hexKey)
+//	opts, err := evm.GetTransactOptsCtx(ctx, s)
+//	sig, err := blockchain.SignMessage(ctx, s, message) // personal-sign of keccak256(message)
 //
-// Replace with your actual private key:
+// The sdk package builds the signer from Config.PrivateKey, Config.KeystorePath
+// or Config.ClefURL:
 //
 //	cfg.PrivateKey = "YOUR_PRIVATE_KEY"
 //
@@ -225,7 +272,7 @@
 //	}
 //
 //	// Perform operations
-//	services, _ := orgClient.ListServices()
+//	services, _ := evm.ListServices(orgClient.OrgID)
 //	fmt.Printf("Found %d services\n", len(services))
 //
 // # See Also
@@ -0,0 +1,170 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+)
+
+// Balances is the token and escrow position of an address. All amounts are in
+// AASI (see AasiToAsi to display them in ASI).
+type Balances struct {
+	Address common.Address
+	// Token is the ERC-20 token balance held by the address itself.
+	Token *big.Int
+	// Escrow is the MPE balance of the address, available to fund channels,
+	// withdraw or transfer.
+	Escrow *big.Int
+	// Allowance is the amount of tokens the MPE contract may still pull from
+	// the address on deposit.
+	Allowance *big.Int
+}
+
+// GetBalances returns the token balance, MPE escrow balance and MPE allowance
+// of address.
+func (evm *EVMClient) GetBalances(ctx context.Context, address common.Address) (*Balances, error) {
+	call := &bind.CallOpts{Context: ctx, From: address}
+
+	token, err := evm.FetchToken.BalanceOf(call, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token balance: %w", err)
+	}
+	escrow, err := evm.MPE.Balances(call, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read escrow balance: %w", err)
+	}
+	allowance, err := evm.FetchToken.Allowance(call, address, evm.MPEAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowance: %w", err)
+	}
+	return &Balances{
+		Address:   address,
+		Token:     token,
+		Escrow:    escrow,
+		Allowance: allowance,
+	}, nil
+}
+
+// DepositToEscrow moves amount (in AASI) of tokens from the signer's address into
+// its MPE escrow balance. It approves the MPE contract first when the current
+// allowance does not cover amount, then waits for the deposit receipt.
+func (evm *EVMClient) DepositToEscrow(ctx context.Context, s signer.Signer, amount *big.Int) (*MultiPartyEscrowDepositFunds, error) {
+	from, err := escrowSender(s, amount)
+	if err != nil {
+		return nil, err
+	}
+	call := &bind.CallOpts{Context: ctx, From: from}
+
+	balance, err := evm.FetchToken.BalanceOf(call, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token balance: %w", err)
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("insufficient token balance: have %s, need %s", balance, amount)
+	}
+
+	opts, err := evm.GetTransactOptsCtx(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if err := evm.ensureAllowance(ctx, from, evm.MPEAddress, amount, call, opts); err != nil {
+		return nil, fmt.Errorf("failed to approve escrow deposit: %w", err)
+	}
+
+	tx, err := evm.MPE.Deposit(opts, amount)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := evm.WaitForTransaction(ctx, tx.Hash(), receiptBackoff)
+	if err != nil {
+		return nil, err
+	}
+	return eventFromReceipt(receipt, evm.MPE.ParseDepositFunds)
+}
+
+// WithdrawFromEscrow moves amount (in AASI) from the signer's MPE escrow balance
+// back to its token balance and waits for the receipt. Funds locked in
+// channels are not part of the escrow balance; reclaim expired channels first
+// (see ChannelManager.ChannelClaimTimeout).
+func (evm *EVMClient) WithdrawFromEscrow(ctx context.Context, s signer.Signer, amount *big.Int) (*MultiPartyEscrowWithdrawFunds, error) {
+	from, err := escrowSender(s, amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := evm.checkEscrowBalance(ctx, from, amount); err != nil {
+		return nil, err
+	}
+
+	opts, err := evm.GetTransactOptsCtx(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := evm.MPE.Withdraw(opts, amount)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := evm.WaitForTransaction(ctx, tx.Hash(), receiptBackoff)
+	if err != nil {
+		return nil, err
+	}
+	return eventFromReceipt(receipt, evm.MPE.ParseWithdrawFunds)
+}
+
+// TransferEscrow moves amount (in AASI) from the signer's MPE escrow balance to
+// the escrow balance of receiver and waits for the receipt. No tokens leave
+// the MPE contract.
+func (evm *EVMClient) TransferEscrow(ctx context.Context, s signer.Signer, receiver common.Address, amount *big.Int) (*MultiPartyEscrowTransferFunds, error) {
+	from, err := escrowSender(s, amount)
+	if err != nil {
+		return nil, err
+	}
+	if receiver == (common.Address{}) {
+		return nil, errors.New("receiver address is required")
+	}
+	if err := evm.checkEscrowBalance(ctx, from, amount); err != nil {
+		return nil, err
+	}
+
+	opts, err := evm.GetTransactOptsCtx(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := evm.MPE.Transfer(opts, receiver, amount)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := evm.WaitForTransaction(ctx, tx.Hash(), receiptBackoff)
+	if err != nil {
+		return nil, err
+	}
+	return eventFromReceipt(receipt, evm.MPE.ParseTransferFunds)
+}
+
+// checkEscrowBalance verifies that the MPE escrow balance of from covers amount.
+func (evm *EVMClient) checkEscrowBalance(ctx context.Context, from common.Address, amount *big.Int) error {
+	balance, err := evm.MPE.Balances(&bind.CallOpts{Context: ctx, From: from}, from)
+	if err != nil {
+		return fmt.Errorf("failed to read escrow balance: %w", err)
+	}
+	if balance.Cmp(amount) < 0 {
+		return fmt.Errorf("insufficient escrow balance: have %s, need %s", balance, amount)
+	}
+	return nil
+}
+
+// escrowSender validates the inputs of an escrow operation and returns the
+// address of s.
+func escrowSender(s signer.Signer, amount *big.Int) (common.Address, error) {
+	if s == nil {
+		return common.Address{}, errors.New("signer is required for escrow operations")
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return common.Address{}, errors.New("amount must be positive")
+	}
+	return s.Address(), nil
+}
@@ -0,0 +1,37 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+)
+
+func TestEscrowSender(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	s, err := signer.NewPrivateKeySigner(key)
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner: %v", err)
+	}
+
+	if _, err := escrowSender(nil, big.NewInt(1)); err == nil {
+		t.Fatal("expected error without a signer")
+	}
+	for _, amount := range []*big.Int{nil, big.NewInt(0), big.NewInt(-1)} {
+		if _, err := escrowSender(s, amount); err == nil {
+			t.Fatalf("expected error for amount %v", amount)
+		}
+	}
+
+	from, err := escrowSender(s, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("escrowSender: %v", err)
+	}
+	if from != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("sender = %s, want %s", from.Hex(), crypto.PubkeyToAddress(key.PublicKey).Hex())
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -156,7 +158,7 @@ func (evm *EVMClient) FilterChannels(senders, recipients []common.Address, group
 	}
 	defer func() {
 		if cerr := it.Close(); cerr != nil {
-			zap.L().Error("error closing channel open iterator", zap.Error(cerr))
+			evm.logger().Error("error closing channel open iterator", zap.Error(cerr))
 		}
 	}()
 
@@ -164,7 +166,7 @@ func (evm *EVMClient) FilterChannels(senders, recipients []common.Address, group
 	for it.Next() {
 		ev := it.Event
 		if ev.Sender == senders[0] && ev.Signer == senders[0] && ev.Recipient == recipients[0] && ev.GroupId == groupIDs[0] {
-			zap.L().Debug("Filtered eventChannelOpen", zap.Any("eventChannelOpen", ev))
+			evm.logger().Debug("Filtered eventChannelOpen", zap.Any("eventChannelOpen", ev))
 			filtered = ev
 		}
 	}
@@ -338,15 +340,15 @@ func (evm *EVMClient) getChannelStateFromBlockchain(channelID *big.Int) (*MultiP
 		Expiration: ch.Expiration,
 		Signer:     ch.Signer,
 	}
-	zap.L().Debug("Channel state from blockchain", zap.Any("channel", channel))
+	evm.logger().Debug("Channel state from blockchain", zap.Any("channel", channel))
 	return channel, true, nil
 }
 
 // GetCurrentBlockNumberCtx returns the latest block number using the provided context.
 func (evm *EVMClient) GetCurrentBlockNumberCtx(ctx context.Context) (*big.Int, error) {
-	header, err := evm.Client.HeaderByNumber(ctx, nil)
+	header, err := evm.traced().HeaderByNumber(ctx, nil)
 	if err != nil {
-		zap.L().Error("failed to get last block number", zap.Error(err))
+		evm.logger().Error("failed to get last block number", zap.Error(err))
 		return nil, err
 	}
 	return header.Number, nil
@@ -355,10 +357,14 @@ func (evm *EVMClient) GetCurrentBlockNumberCtx(ctx context.Context) (*big.Int, e
 // WaitForTransaction polls for a transaction receipt with exponential backoff,
 // until receipt is available, context is done, or an error occurs. If maxBackoff
 // is non-zero, backoff will not exceed it. It returns an error if the tx is reverted.
-func (evm *EVMClient) WaitForTransaction(ctx context.Context, txHash common.Hash, maxBackoff time.Duration) (*types.Receipt, error) {
+// The wait is traced as one span with a child span per receipt request.
+func (evm *EVMClient) WaitForTransaction(ctx context.Context, txHash common.Hash, maxBackoff time.Duration) (receipt *types.Receipt, err error) {
+	ctx, span := evm.tel.Start(ctx, "blockchain.WaitForTransaction", attribute.String("eth.tx_hash", txHash.Hex()))
+	defer func() { telemetry.End(span, err) }()
+
 	backoff := time.Second
 	for {
-		receipt, err := evm.Client.TransactionReceipt(ctx, txHash)
+		receipt, err = evm.traced().TransactionReceipt(ctx, txHash)
 		switch {
 		case err == nil:
 			if receipt.Status == types.ReceiptStatusFailed {
@@ -388,7 +394,7 @@ func (evm *EVMClient) GetMPEBalance(callOpts *bind.CallOpts) (*big.Int, error) {
 	if err != nil {
 		return nil, err
 	}
-	zap.L().Debug("MPE Balance", zap.Any("mpeBalance", bal))
+	evm.logger().Debug("MPE Balance", zap.Any("mpeBalance", bal))
 	return bal, nil
 }
 
@@ -2,17 +2,35 @@ package blockchain
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 	"github.com/shamank/snet-sdk-go/pkg/storage"
 	"go.uber.org/zap"
 )
 
+// ErrOrganizationNotFound is returned when an organization ID is not registered
+// in the Registry contract.
+var ErrOrganizationNotFound = errors.New("organization not found")
+
+// ErrServiceNotFound is returned when a service ID is not registered for the
+// organization in the Registry contract.
+var ErrServiceNotFound = errors.New("service not found")
+
+// ErrGroupNotFound is returned when the requested group is missing from the
+// service metadata.
+var ErrGroupNotFound = errors.New("group not found")
+
+// errUnsignedTransaction is returned by the legacy Registry writes that have no
+// signer to submit a transaction with.
+var errUnsignedTransaction = errors.New("registry transactions require a signer")
+
 // OrgClient represents a blockchain client for organization operations.
 // It embeds EVMClient and organization metadata for blockchain interactions.
 type OrgClient struct {
@@ -27,17 +45,15 @@ type OrgClient struct {
 func (evm *EVMClient) NewOrgClient(orgID, groupName string) (*OrgClient, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
+	return evm.NewOrgClientCtx(ctx, orgID, groupName)
+}
 
-	orgHash := evm.getOrgMetadataUri(orgID)
-
-	rawOrgMetadata, err := evm.Storage.ReadFile(ctx, orgHash)
+// NewOrgClientCtx is like NewOrgClient but reads the Registry and the metadata
+// file with ctx instead of a fixed 120s timeout.
+func (evm *EVMClient) NewOrgClientCtx(ctx context.Context, orgID, groupName string) (*OrgClient, error) {
+	orgMetadata, err := evm.GetOrgMetadataCtx(ctx, orgID)
 	if err != nil {
-		return nil, fmt.Errorf("can't read orgMetadata file: %w", err)
-	}
-
-	var orgMetadata model.OrganizationMetaData
-	if err := json.Unmarshal(rawOrgMetadata, &orgMetadata); err != nil {
-		return nil, fmt.Errorf("can't parse orgMetadata: %w", err)
+		return nil, err
 	}
 
 	var currentOrgGroup *model.OrganizationGroup
@@ -48,123 +64,193 @@ func (evm *EVMClient) NewOrgClient(orgID, groupName string) (*OrgClient, error)
 		}
 	}
 
-	return &OrgClient{evm, &orgMetadata, evm.Storage, currentOrgGroup}, nil
+	return &OrgClient{evm, orgMetadata, evm.Storage, currentOrgGroup}, nil
 }
 
-// getServiceHash retrieves the service metadata URI (hash) from the Registry contract.
-// It queries the Registry for the service registration using the organization and service IDs.
-func (orgClient *OrgClient) getServiceHash(srvID string) string {
-	orgId := StringToBytes32(orgClient.OrgID)
-	serviceId := StringToBytes32(srvID)
-	serviceRegistration, err := orgClient.Registry.GetServiceRegistrationById(nil, orgId, serviceId)
-	if err != nil || &serviceRegistration == nil || !serviceRegistration.Found {
-		zap.L().Panic("Error Retrieving contract details for the Given Organization and Service Ids ",
-			zap.String("OrganizationId", orgClient.OrgID),
-			zap.String("ServiceId", srvID))
+// GetOrgMetadata reads the current metadata of an organization: its URI from
+// the Registry, then the file from storage.
+func (evm *EVMClient) GetOrgMetadata(orgID string) (*model.OrganizationMetaData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+	return evm.GetOrgMetadataCtx(ctx, orgID)
+}
+
+// GetOrgMetadataCtx is like GetOrgMetadata but reads the Registry and the
+// metadata file with ctx.
+func (evm *EVMClient) GetOrgMetadataCtx(ctx context.Context, orgID string) (*model.OrganizationMetaData, error) {
+	orgHash, err := evm.getOrgMetadataUri(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	rawOrgMetadata, err := evm.Storage.ReadFile(ctx, orgHash)
+	if err != nil {
+		return nil, fmt.Errorf("can't read orgMetadata file: %w", err)
 	}
 
-	return string(serviceRegistration.MetadataURI[:])
+	var orgMetadata model.OrganizationMetaData
+	if err := json.Unmarshal(rawOrgMetadata, &orgMetadata); err != nil {
+		return nil, fmt.Errorf("can't parse orgMetadata: %w", err)
+	}
+	return &orgMetadata, nil
+}
+
+// getServiceHash retrieves the service metadata URI (hash) from the Registry contract.
+// It queries the Registry for the service registration using the organization and
+// service IDs and returns an error wrapping ErrServiceNotFound if there is none.
+func (orgClient *OrgClient) getServiceHash(ctx context.Context, srvID string) (string, error) {
+	return orgClient.GetServiceMetadataURICtx(ctx, orgClient.OrgID, srvID)
+}
+
+// GetServiceMetadataURI returns the metadata URI a service is registered
+// with, or an error wrapping ErrServiceNotFound if there is none.
+func (evm *EVMClient) GetServiceMetadataURI(orgID, srvID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+	return evm.GetServiceMetadataURICtx(ctx, orgID, srvID)
+}
+
+// GetServiceMetadataURICtx is like GetServiceMetadataURI but reads the Registry with ctx.
+func (evm *EVMClient) GetServiceMetadataURICtx(ctx context.Context, orgID, srvID string) (string, error) {
+	return evm.registryLookup(ctx, serviceCacheKey(orgID, srvID), func(ctx context.Context) ([]byte, error) {
+		orgId := StringToBytes32(orgID)
+		serviceId := StringToBytes32(srvID)
+		serviceRegistration, err := evm.Registry.GetServiceRegistrationById(&bind.CallOpts{Context: ctx}, orgId, serviceId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service %q of organization %q from registry: %w", srvID, orgID, err)
+		}
+		if !serviceRegistration.Found {
+			return nil, fmt.Errorf("%w: %q in organization %q", ErrServiceNotFound, srvID, orgID)
+		}
+		return serviceRegistration.MetadataURI, nil
+	})
 }
 
 // GetServices returns service IDs for the given organization ID.
 // If the organization is not found or a read error occurs, it logs and returns nil.
 func (orgClient *OrgClient) GetServices() []string {
-	organizations, err := orgClient.Registry.ListServicesForOrganization(nil, StringToBytes32(orgClient.OrgID))
+	return orgClient.GetServicesCtx(context.Background())
+}
+
+// GetServicesCtx is like GetServices but reads the Registry with ctx.
+func (orgClient *OrgClient) GetServicesCtx(ctx context.Context) []string {
+	services, err := orgClient.ListServicesCtx(ctx, orgClient.OrgID)
 	if err != nil {
-		zap.L().Error("Failed to list organizations", zap.Error(err))
-		return nil
-	}
-	if !organizations.Found {
-		zap.L().Error("Organization not found", zap.String("OrganizationID", orgClient.OrgID))
+		orgClient.logger().Error("Failed to list services", zap.String("OrganizationID", orgClient.OrgID), zap.Error(err))
 		return nil
 	}
-	return Bytes32ArrayToStrings(organizations.ServiceIds)
+	return services
 }
 
-// UpdateOrgMetadata updates the organization metadata URI in the Registry contract.
-// Returns the transaction hash.
-func (orgClient *OrgClient) UpdateOrgMetadata(uri string) common.Hash {
-	resp, err := orgClient.Registry.ChangeOrganizationMetadataURI(nil, StringToBytes32(orgClient.OrgID), []byte(uri))
-	if err != nil || &resp == nil {
-		zap.L().Panic("Error UpdateOrgMetadata ",
-			zap.String("OrganizationId", orgClient.OrgID),
-			zap.String("uri", uri))
+// ListServices returns the IDs of the services registered by an
+// organization, or an error wrapping ErrOrganizationNotFound if the
+// organization does not exist.
+func (evm *EVMClient) ListServices(orgID string) ([]string, error) {
+	return evm.ListServicesCtx(context.Background(), orgID)
+}
+
+// ListServicesCtx is like ListServices but reads the Registry with ctx.
+func (evm *EVMClient) ListServicesCtx(ctx context.Context, orgID string) ([]string, error) {
+	services, err := evm.Registry.ListServicesForOrganization(&bind.CallOpts{Context: ctx}, StringToBytes32(orgID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services of organization %q: %w", orgID, err)
+	}
+	if !services.Found {
+		return nil, fmt.Errorf("%w: %q", ErrOrganizationNotFound, orgID)
 	}
+	return Bytes32ArrayToStrings(services.ServiceIds), nil
+}
 
-	return resp.Hash()
+// UpdateOrgMetadata updates the organization metadata URI in the Registry contract.
+//
+// Deprecated: the Registry rejects unsigned transactions, so UpdateOrgMetadata
+// always fails. Use UpdateOrgMetadataWithAuth.
+func (orgClient *OrgClient) UpdateOrgMetadata(uri string) (common.Hash, error) {
+	return common.Hash{}, fmt.Errorf("update metadata of organization %q: %w", orgClient.OrgID, errUnsignedTransaction)
 }
 
 // AddMember adds new members to the organization.
-// Returns the transaction hash.
-func (orgClient *OrgClient) AddMember(newMembers []common.Address) common.Hash {
-	resp, err := orgClient.Registry.AddOrganizationMembers(nil, StringToBytes32(orgClient.OrgID), newMembers)
-	if err != nil || &resp == nil {
-		zap.L().Panic("Error UpdateOrgMetadata ",
-			zap.String("OrganizationId", orgClient.OrgID))
-	}
-
-	return resp.Hash()
+//
+// Deprecated: the Registry rejects unsigned transactions, so AddMember always
+// fails. Use AddOrganizationMembersWithAuth.
+func (orgClient *OrgClient) AddMember(newMembers []common.Address) (common.Hash, error) {
+	return common.Hash{}, fmt.Errorf("add members to organization %q: %w", orgClient.OrgID, errUnsignedTransaction)
 }
 
 // CreateOrganization creates a new organization in the Registry contract.
-func (evm *EVMClient) CreateOrganization(pk *ecdsa.PrivateKey, orgID string, orgMetadataURI []byte, members []common.Address) (common.Hash, error) {
-	opts, err := evm.GetTransactOpts(pk)
+func (evm *EVMClient) CreateOrganization(auth signer.Signer, orgID string, orgMetadataURI []byte, members []common.Address) (common.Hash, error) {
+	return evm.CreateOrganizationCtx(context.Background(), auth, orgID, orgMetadataURI, members)
+}
+
+// CreateOrganizationCtx is like CreateOrganization but submits the transaction with ctx.
+func (evm *EVMClient) CreateOrganizationCtx(ctx context.Context, auth signer.Signer, orgID string, orgMetadataURI []byte, members []common.Address) (common.Hash, error) {
+	opts, err := evm.GetTransactOptsCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create transact opts: %w", err)
 	}
 
 	tx, err := evm.Registry.CreateOrganization(opts, StringToBytes32(orgID), orgMetadataURI, members)
 	if err != nil {
-		zap.L().Error("Failed to create organization",
+		evm.logger().Error("Failed to create organization",
 			zap.String("orgId", orgID),
 			zap.Error(err))
 		return common.Hash{}, fmt.Errorf("failed to create organization: %w", err)
 	}
 
-	zap.L().Info("Organization creation transaction sent",
+	evm.logger().Info("Organization creation transaction sent",
 		zap.String("orgId", orgID),
 		zap.String("txHash", tx.Hash().Hex()))
 	return tx.Hash(), nil
 }
 
 // DeleteOrganization deletes an organization from the Registry contract.
-func (orgClient *OrgClient) DeleteOrganization(pk *ecdsa.PrivateKey) (common.Hash, error) {
-	opts, err := orgClient.GetTransactOpts(pk)
+func (orgClient *OrgClient) DeleteOrganization(auth signer.Signer) (common.Hash, error) {
+	return orgClient.DeleteOrganizationCtx(context.Background(), auth)
+}
+
+// DeleteOrganizationCtx is like DeleteOrganization but submits the transaction with ctx.
+func (orgClient *OrgClient) DeleteOrganizationCtx(ctx context.Context, auth signer.Signer) (common.Hash, error) {
+	opts, err := orgClient.GetTransactOptsCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create transact opts: %w", err)
 	}
 
 	tx, err := orgClient.Registry.DeleteOrganization(opts, StringToBytes32(orgClient.OrgID))
 	if err != nil {
-		zap.L().Error("Failed to delete organization",
+		orgClient.logger().Error("Failed to delete organization",
 			zap.String("orgId", orgClient.OrgID),
 			zap.Error(err))
 		return common.Hash{}, fmt.Errorf("failed to delete organization: %w", err)
 	}
 
-	zap.L().Info("Organization deletion transaction sent",
+	orgClient.logger().Info("Organization deletion transaction sent",
 		zap.String("orgId", orgClient.OrgID),
 		zap.String("txHash", tx.Hash().Hex()))
 	return tx.Hash(), nil
 }
 
 // ChangeOrganizationOwner changes the owner of the organization.
-func (orgClient *OrgClient) ChangeOrganizationOwner(pk *ecdsa.PrivateKey, newOwner common.Address) (common.Hash, error) {
-	opts, err := orgClient.GetTransactOpts(pk)
+func (orgClient *OrgClient) ChangeOrganizationOwner(auth signer.Signer, newOwner common.Address) (common.Hash, error) {
+	return orgClient.ChangeOrganizationOwnerCtx(context.Background(), auth, newOwner)
+}
+
+// ChangeOrganizationOwnerCtx is like ChangeOrganizationOwner but submits the transaction with ctx.
+func (orgClient *OrgClient) ChangeOrganizationOwnerCtx(ctx context.Context, auth signer.Signer, newOwner common.Address) (common.Hash, error) {
+	opts, err := orgClient.GetTransactOptsCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create transact opts: %w", err)
 	}
 
 	tx, err := orgClient.Registry.ChangeOrganizationOwner(opts, StringToBytes32(orgClient.OrgID), newOwner)
 	if err != nil {
-		zap.L().Error("Failed to change organization owner",
+		orgClient.logger().Error("Failed to change organization owner",
 			zap.String("orgId", orgClient.OrgID),
 			zap.String("newOwner", newOwner.Hex()),
 			zap.Error(err))
 		return common.Hash{}, fmt.Errorf("failed to change organization owner: %w", err)
 	}
 
-	zap.L().Info("Organization owner change transaction sent",
+	orgClient.logger().Info("Organization owner change transaction sent",
 		zap.String("orgId", orgClient.OrgID),
 		zap.String("newOwner", newOwner.Hex()),
 		zap.String("txHash", tx.Hash().Hex()))
@@ -172,43 +258,53 @@ func (orgClient *OrgClient) ChangeOrganizationOwner(pk *ecdsa.PrivateKey, newOwn
 }
 
 // RemoveOrganizationMembers removes members from the organization.
-func (orgClient *OrgClient) RemoveOrganizationMembers(pk *ecdsa.PrivateKey, members []common.Address) (common.Hash, error) {
-	opts, err := orgClient.GetTransactOpts(pk)
+func (orgClient *OrgClient) RemoveOrganizationMembers(auth signer.Signer, members []common.Address) (common.Hash, error) {
+	return orgClient.RemoveOrganizationMembersCtx(context.Background(), auth, members)
+}
+
+// RemoveOrganizationMembersCtx is like RemoveOrganizationMembers but submits the transaction with ctx.
+func (orgClient *OrgClient) RemoveOrganizationMembersCtx(ctx context.Context, auth signer.Signer, members []common.Address) (common.Hash, error) {
+	opts, err := orgClient.GetTransactOptsCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create transact opts: %w", err)
 	}
 
 	tx, err := orgClient.Registry.RemoveOrganizationMembers(opts, StringToBytes32(orgClient.OrgID), members)
 	if err != nil {
-		zap.L().Error("Failed to remove organization members",
+		orgClient.logger().Error("Failed to remove organization members",
 			zap.String("orgId", orgClient.OrgID),
 			zap.Error(err))
 		return common.Hash{}, fmt.Errorf("failed to remove organization members: %w", err)
 	}
 
-	zap.L().Info("Organization members removal transaction sent",
+	orgClient.logger().Info("Organization members removal transaction sent",
 		zap.String("orgId", orgClient.OrgID),
 		zap.String("txHash", tx.Hash().Hex()))
 	return tx.Hash(), nil
 }
 
 // UpdateOrgMetadataWithAuth updates organization metadata URI with authentication.
-func (orgClient *OrgClient) UpdateOrgMetadataWithAuth(pk *ecdsa.PrivateKey, uri string) (common.Hash, error) {
-	opts, err := orgClient.GetTransactOpts(pk)
+func (orgClient *OrgClient) UpdateOrgMetadataWithAuth(auth signer.Signer, uri string) (common.Hash, error) {
+	return orgClient.UpdateOrgMetadataWithAuthCtx(context.Background(), auth, uri)
+}
+
+// UpdateOrgMetadataWithAuthCtx is like UpdateOrgMetadataWithAuth but submits the transaction with ctx.
+func (orgClient *OrgClient) UpdateOrgMetadataWithAuthCtx(ctx context.Context, auth signer.Signer, uri string) (common.Hash, error) {
+	opts, err := orgClient.GetTransactOptsCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create transact opts: %w", err)
 	}
 
 	tx, err := orgClient.Registry.ChangeOrganizationMetadataURI(opts, StringToBytes32(orgClient.OrgID), []byte(uri))
 	if err != nil {
-		zap.L().Error("Failed to update organization metadata",
+		orgClient.logger().Error("Failed to update organization metadata",
 			zap.String("orgId", orgClient.OrgID),
 			zap.String("uri", uri),
 			zap.Error(err))
 		return common.Hash{}, fmt.Errorf("failed to update organization metadata: %w", err)
 	}
 
-	zap.L().Info("Organization metadata update transaction sent",
+	orgClient.logger().Info("Organization metadata update transaction sent",
 		zap.String("orgId", orgClient.OrgID),
 		zap.String("uri", uri),
 		zap.String("txHash", tx.Hash().Hex()))
@@ -216,21 +312,26 @@ func (orgClient *OrgClient) UpdateOrgMetadataWithAuth(pk *ecdsa.PrivateKey, uri
 }
 
 // AddOrganizationMembersWithAuth adds members to the organization with authentication.
-func (orgClient *OrgClient) AddOrganizationMembersWithAuth(pk *ecdsa.PrivateKey, members []common.Address) (common.Hash, error) {
-	opts, err := orgClient.GetTransactOpts(pk)
+func (orgClient *OrgClient) AddOrganizationMembersWithAuth(auth signer.Signer, members []common.Address) (common.Hash, error) {
+	return orgClient.AddOrganizationMembersWithAuthCtx(context.Background(), auth, members)
+}
+
+// AddOrganizationMembersWithAuthCtx is like AddOrganizationMembersWithAuth but submits the transaction with ctx.
+func (orgClient *OrgClient) AddOrganizationMembersWithAuthCtx(ctx context.Context, auth signer.Signer, members []common.Address) (common.Hash, error) {
+	opts, err := orgClient.GetTransactOptsCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create transact opts: %w", err)
 	}
 
 	tx, err := orgClient.Registry.AddOrganizationMembers(opts, StringToBytes32(orgClient.OrgID), members)
 	if err != nil {
-		zap.L().Error("Failed to add organization members",
+		orgClient.logger().Error("Failed to add organization members",
 			zap.String("orgId", orgClient.OrgID),
 			zap.Error(err))
 		return common.Hash{}, fmt.Errorf("failed to add organization members: %w", err)
 	}
 
-	zap.L().Info("Organization members addition transaction sent",
+	orgClient.logger().Info("Organization members addition transaction sent",
 		zap.String("orgId", orgClient.OrgID),
 		zap.String("txHash", tx.Hash().Hex()))
 	return tx.Hash(), nil
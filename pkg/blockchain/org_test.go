@@ -1,9 +1,15 @@
 package blockchain
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
 	"testing"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/shamank/snet-sdk-go/pkg/model"
 )
 
@@ -47,3 +53,43 @@ func TestOrgMetadataJSONParsing(t *testing.T) {
 		t.Fatalf("expected group name=default, got %s", metadata.Groups[0].GroupName)
 	}
 }
+
+// emptyRegistry answers every Registry call with zero values, i.e. "not found".
+type emptyRegistry struct{}
+
+func (emptyRegistry) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (emptyRegistry) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	parsed, err := RegistryMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsed.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	zeros := make([]any, len(method.Outputs))
+	for i, out := range method.Outputs {
+		zeros[i] = reflect.New(out.Type.GetType()).Elem().Interface()
+	}
+	return method.Outputs.Pack(zeros...)
+}
+
+func TestRegistryLookupsReturnNotFound(t *testing.T) {
+	caller, err := NewRegistryCaller(common.Address{}, emptyRegistry{})
+	if err != nil {
+		t.Fatalf("NewRegistryCaller: %v", err)
+	}
+	evm := &EVMClient{Registry: &Registry{RegistryCaller: *caller}}
+
+	if _, err := evm.NewOrgClientCtx(context.Background(), "typo-org", "default_group"); !errors.Is(err, ErrOrganizationNotFound) {
+		t.Fatalf("NewOrgClientCtx: err = %v; want ErrOrganizationNotFound", err)
+	}
+
+	org := &OrgClient{EVMClient: evm, OrganizationMetaData: &model.OrganizationMetaData{OrgID: "snet"}}
+	if _, err := org.NewServiceClientCtx(context.Background(), "typo-service", "default_group"); !errors.Is(err, ErrServiceNotFound) {
+		t.Fatalf("NewServiceClientCtx: err = %v; want ErrServiceNotFound", err)
+	}
+}
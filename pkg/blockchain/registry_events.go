@@ -0,0 +1,159 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// RegistryEventType names an event emitted by the Registry contract.
+type RegistryEventType string
+
+const (
+	OrganizationCreated     RegistryEventType = "OrganizationCreated"
+	OrganizationModified    RegistryEventType = "OrganizationModified"
+	OrganizationDeleted     RegistryEventType = "OrganizationDeleted"
+	ServiceCreated          RegistryEventType = "ServiceCreated"
+	ServiceMetadataModified RegistryEventType = "ServiceMetadataModified"
+	ServiceTagsModified     RegistryEventType = "ServiceTagsModified"
+	ServiceDeleted          RegistryEventType = "ServiceDeleted"
+)
+
+// RegistryEvent is a decoded Registry event. ServiceID is empty for
+// organization events, and MetadataURI is set only for ServiceCreated and
+// ServiceMetadataModified, the two events that carry it.
+type RegistryEvent struct {
+	Type        RegistryEventType
+	OrgID       string
+	ServiceID   string
+	MetadataURI string
+	Raw         types.Log
+}
+
+// IsServiceEvent reports whether the event concerns a service rather than an
+// organization.
+func (e RegistryEvent) IsServiceEvent() bool {
+	return e.ServiceID != ""
+}
+
+// WatchRegistryEvents is like WatchRegistryEventsCtx but subscribes with a
+// background context and from the latest block.
+func (evm *EVMClient) WatchRegistryEvents(sink chan<- RegistryEvent, orgIDs ...string) (event.Subscription, error) {
+	return evm.WatchRegistryEventsCtx(context.Background(), nil, sink, orgIDs...)
+}
+
+// WatchRegistryEventsCtx subscribes to every organization and service event
+// of the Registry contract and delivers them, decoded, to sink. Events from
+// block start on are delivered; a nil start means the latest block. With
+// orgIDs, only events of those organizations are delivered.
+//
+// Subscriptions need a WebSocket RPC endpoint. The subscription ends with an
+// error on its Err channel when any of the underlying subscriptions fails;
+// Unsubscribe must be called to release it.
+func (evm *EVMClient) WatchRegistryEventsCtx(ctx context.Context, start *uint64, sink chan<- RegistryEvent, orgIDs ...string) (event.Subscription, error) {
+	var orgs [][32]byte
+	for _, id := range orgIDs {
+		orgs = append(orgs, StringToBytes32(id))
+	}
+	watch := &bind.WatchOpts{Start: start, Context: ctx}
+	f := &evm.Registry.RegistryFilterer
+
+	var (
+		orgCreated  = make(chan *RegistryOrganizationCreated)
+		orgModified = make(chan *RegistryOrganizationModified)
+		orgDeleted  = make(chan *RegistryOrganizationDeleted)
+		srvCreated  = make(chan *RegistryServiceCreated)
+		srvMetadata = make(chan *RegistryServiceMetadataModified)
+		srvTags     = make(chan *RegistryServiceTagsModified)
+		srvDeleted  = make(chan *RegistryServiceDeleted)
+		subs        []event.Subscription
+		unsubscribe = func() {
+			for _, sub := range subs {
+				sub.Unsubscribe()
+			}
+		}
+	)
+	watchers := []struct {
+		name  RegistryEventType
+		watch func() (event.Subscription, error)
+	}{
+		{OrganizationCreated, func() (event.Subscription, error) { return f.WatchOrganizationCreated(watch, orgCreated, orgs) }},
+		{OrganizationModified, func() (event.Subscription, error) { return f.WatchOrganizationModified(watch, orgModified, orgs) }},
+		{OrganizationDeleted, func() (event.Subscription, error) { return f.WatchOrganizationDeleted(watch, orgDeleted, orgs) }},
+		{ServiceCreated, func() (event.Subscription, error) { return f.WatchServiceCreated(watch, srvCreated, orgs, nil) }},
+		{ServiceMetadataModified, func() (event.Subscription, error) {
+			return f.WatchServiceMetadataModified(watch, srvMetadata, orgs, nil)
+		}},
+		{ServiceTagsModified, func() (event.Subscription, error) { return f.WatchServiceTagsModified(watch, srvTags, orgs, nil) }},
+		{ServiceDeleted, func() (event.Subscription, error) { return f.WatchServiceDeleted(watch, srvDeleted, orgs, nil) }},
+	}
+	for _, w := range watchers {
+		sub, err := w.watch()
+		if err != nil {
+			unsubscribe()
+			return nil, fmt.Errorf("failed to watch %s events: %w", w.name, err)
+		}
+		subs = append(subs, sub)
+	}
+
+	// Fan the subscription errors in, so that a single failure ends the merged
+	// subscription.
+	errc := make(chan error, len(subs))
+	for _, sub := range subs {
+		go func(sub event.Subscription) {
+			if err := <-sub.Err(); err != nil {
+				errc <- err
+			}
+		}(sub)
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer unsubscribe()
+		for {
+			var ev RegistryEvent
+			select {
+			case e := <-orgCreated:
+				ev = RegistryEvent{Type: OrganizationCreated, OrgID: bytes32ToString(e.OrgId), Raw: e.Raw}
+			case e := <-orgModified:
+				ev = RegistryEvent{Type: OrganizationModified, OrgID: bytes32ToString(e.OrgId), Raw: e.Raw}
+			case e := <-orgDeleted:
+				ev = RegistryEvent{Type: OrganizationDeleted, OrgID: bytes32ToString(e.OrgId), Raw: e.Raw}
+			case e := <-srvCreated:
+				ev = serviceEvent(ServiceCreated, e.OrgId, e.ServiceId, e.Raw)
+				ev.MetadataURI = string(e.MetadataURI)
+			case e := <-srvMetadata:
+				ev = serviceEvent(ServiceMetadataModified, e.OrgId, e.ServiceId, e.Raw)
+				ev.MetadataURI = string(e.MetadataURI)
+			case e := <-srvTags:
+				ev = serviceEvent(ServiceTagsModified, e.OrgId, e.ServiceId, e.Raw)
+			case e := <-srvDeleted:
+				ev = serviceEvent(ServiceDeleted, e.OrgId, e.ServiceId, e.Raw)
+			case err := <-errc:
+				return err
+			case <-quit:
+				return nil
+			}
+			select {
+			case sink <- ev:
+			case err := <-errc:
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func serviceEvent(typ RegistryEventType, orgID, serviceID [32]byte, raw types.Log) RegistryEvent {
+	return RegistryEvent{Type: typ, OrgID: bytes32ToString(orgID), ServiceID: bytes32ToString(serviceID), Raw: raw}
+}
+
+// bytes32ToString converts a Registry ID back to a string, trimming the
+// zero padding added by StringToBytes32.
+func bytes32ToString(b [32]byte) string {
+	return string(bytes.TrimRight(b[:], "\x00"))
+}
@@ -0,0 +1,118 @@
+package blockchain
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// logFeed is a log filterer whose subscriptions are fed by hand, keyed by
+// the event ID of their query.
+type logFeed struct {
+	mu   sync.Mutex
+	subs map[common.Hash]chan<- types.Log
+}
+
+func (f *logFeed) FilterLogs(context.Context, ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+
+func (f *logFeed) SubscribeFilterLogs(_ context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subs[q.Topics[0][0]] = ch
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	}), nil
+}
+
+// emit sends a Registry event log with the given indexed and data arguments
+// to the subscription of the event. The subscriptions are buffered.
+func (f *logFeed) emit(t *testing.T, name string, indexed []any, data ...any) {
+	t.Helper()
+	parsed, err := RegistryMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := parsed.Events[name]
+	query := [][]any{{ev.ID}}
+	for _, arg := range indexed {
+		query = append(query, []any{arg})
+	}
+	topics, err := abi.MakeTopics(query...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := ev.Inputs.NonIndexed().Pack(data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := types.Log{BlockNumber: 42}
+	for _, topic := range topics {
+		log.Topics = append(log.Topics, topic[0])
+	}
+	log.Data = packed
+
+	f.mu.Lock()
+	ch := f.subs[ev.ID]
+	f.mu.Unlock()
+	ch <- log
+}
+
+func TestWatchRegistryEventsCtx(t *testing.T) {
+	feed := &logFeed{subs: make(map[common.Hash]chan<- types.Log)}
+	filterer, err := NewRegistryFilterer(common.Address{}, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evm := &EVMClient{Registry: &Registry{RegistryFilterer: *filterer}}
+
+	sink := make(chan RegistryEvent)
+	sub, err := evm.WatchRegistryEventsCtx(context.Background(), nil, sink, "snet")
+	if err != nil {
+		t.Fatalf("WatchRegistryEventsCtx: %v", err)
+	}
+	defer sub.Unsubscribe()
+	if len(feed.subs) != 7 {
+		t.Fatalf("%d subscriptions; want 7", len(feed.subs))
+	}
+
+	org, srv := StringToBytes32("snet"), StringToBytes32("calc")
+	tests := []struct {
+		name    string
+		indexed []any
+		data    []any
+		want    RegistryEvent
+	}{
+		{"OrganizationModified", []any{org}, nil, RegistryEvent{Type: OrganizationModified, OrgID: "snet"}},
+		{"ServiceCreated", []any{org, srv}, []any{[]byte("ipfs://Qm1")}, RegistryEvent{Type: ServiceCreated, OrgID: "snet", ServiceID: "calc", MetadataURI: "ipfs://Qm1"}},
+		{"ServiceMetadataModified", []any{org, srv}, []any{[]byte("ipfs://Qm2")}, RegistryEvent{Type: ServiceMetadataModified, OrgID: "snet", ServiceID: "calc", MetadataURI: "ipfs://Qm2"}},
+		{"ServiceTagsModified", []any{org, srv}, nil, RegistryEvent{Type: ServiceTagsModified, OrgID: "snet", ServiceID: "calc"}},
+		{"OrganizationDeleted", []any{org}, nil, RegistryEvent{Type: OrganizationDeleted, OrgID: "snet"}},
+	}
+	for _, tt := range tests {
+		feed.emit(t, tt.name, tt.indexed, tt.data...)
+		select {
+		case got := <-sink:
+			if got.Type != tt.want.Type || got.OrgID != tt.want.OrgID || got.ServiceID != tt.want.ServiceID || got.MetadataURI != tt.want.MetadataURI {
+				t.Errorf("%s: event = %+v; want %+v", tt.name, got, tt.want)
+			}
+			if got.Raw.BlockNumber != 42 {
+				t.Errorf("%s: raw log not kept", tt.name)
+			}
+			if got.IsServiceEvent() != (tt.want.ServiceID != "") {
+				t.Errorf("%s: IsServiceEvent = %v", tt.name, got.IsServiceEvent())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: no event", tt.name)
+		}
+	}
+}
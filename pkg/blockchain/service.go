@@ -2,13 +2,13 @@ package blockchain
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 	"github.com/shamank/snet-sdk-go/pkg/storage"
 	"go.uber.org/zap"
 )
@@ -28,30 +28,20 @@ type ServiceClient struct {
 func (orgClient *OrgClient) NewServiceClient(srvID, groupName string) (*ServiceClient, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
+	return orgClient.NewServiceClientCtx(ctx, srvID, groupName)
+}
 
-	hash := orgClient.getServiceHash(srvID)
-
-	rawMetadata, err := orgClient.ReadFile(ctx, hash)
+// NewServiceClientCtx is like NewServiceClient but reads the Registry, the
+// metadata and the proto archive with ctx instead of a fixed 120s timeout.
+func (orgClient *OrgClient) NewServiceClientCtx(ctx context.Context, srvID, groupName string) (*ServiceClient, error) {
+	hash, err := orgClient.getServiceHash(ctx, srvID)
 	if err != nil {
-		return nil, fmt.Errorf("can't read serviceMetadata file: %w", err)
-	}
-
-	var serviceMetadata model.ServiceMetadata
-	if err := json.Unmarshal(rawMetadata, &serviceMetadata); err != nil {
-		return nil, fmt.Errorf("can't parse serviceMetadata: %w", err)
+		return nil, err
 	}
 
-	var rawFile []byte
-
-	// Backward compatibility: older metadata may use ModelIpfsHash.
-	if serviceMetadata.ModelIpfsHash != "" {
-		rawFile, err = orgClient.ReadFile(ctx, serviceMetadata.ModelIpfsHash)
-	}
-	if serviceMetadata.ServiceApiSource != "" {
-		rawFile, err = orgClient.ReadFile(ctx, serviceMetadata.ServiceApiSource)
-	}
+	serviceMetadata, err := readServiceMetadata(ctx, orgClient.Storage, hash)
 	if err != nil {
-		return nil, fmt.Errorf("can't read api source (proto) files: %w", err)
+		return nil, err
 	}
 
 	var currentSrvGroup *model.ServiceGroup
@@ -62,82 +52,122 @@ func (orgClient *OrgClient) NewServiceClient(srvID, groupName string) (*ServiceC
 		}
 	}
 
-	serviceMetadata.ProtoFiles, err = storage.ParseProtoFiles(rawFile)
+	serviceMetadata.ProtoFiles, err = readServiceProtos(ctx, orgClient.Storage, serviceMetadata)
 	if err != nil {
-		return nil, fmt.Errorf("can't parse proto files: %w", err)
+		return nil, err
 	}
 
 	if currentSrvGroup == nil {
-		return nil, fmt.Errorf("group %s not found in service %s", groupName, srvID)
+		return nil, fmt.Errorf("%w: %q in service %q", ErrGroupNotFound, groupName, srvID)
 	}
 
-	// TODO: endpoint selection strategy (currently takes the first endpoint).
 	if len(currentSrvGroup.Endpoints) == 0 {
 		return nil, fmt.Errorf("no endpoints found in group %s", groupName)
 	}
 
-	return &ServiceClient{srvID, &serviceMetadata, currentSrvGroup, orgClient.OrganizationMetaData, orgClient.EVMClient}, nil
+	return &ServiceClient{srvID, serviceMetadata, currentSrvGroup, orgClient.OrganizationMetaData, orgClient.EVMClient}, nil
 }
 
-// updateMetadataUri updates the service metadata URI in the Registry contract.
-// This is a low-level method that submits a transaction without authentication.
-func (srvClient *ServiceClient) updateMetadataUri(uri string) common.Hash {
-	resp, err := srvClient.Registry.UpdateServiceRegistration(nil, StringToBytes32(srvClient.org.OrgID), StringToBytes32(srvClient.ServiceID), []byte(uri))
-	if err != nil || resp == nil {
-		zap.L().Panic("Error updateMetadataUri",
-			zap.String("OrganizationId", srvClient.org.OrgID),
-			zap.String("ServiceId", srvClient.ServiceID),
-			zap.String("uri", uri),
-			zap.Error(err),
-		)
+// ReadServiceMetadata reads and parses the service metadata file at uri. The
+// proto files it refers to are not read.
+func (evm *EVMClient) ReadServiceMetadata(uri string) (*model.ServiceMetadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+	return evm.ReadServiceMetadataCtx(ctx, uri)
+}
+
+// ReadServiceMetadataCtx is like ReadServiceMetadata but reads the file with ctx.
+func (evm *EVMClient) ReadServiceMetadataCtx(ctx context.Context, uri string) (*model.ServiceMetadata, error) {
+	return readServiceMetadata(ctx, evm.Storage, uri)
+}
+
+func readServiceMetadata(ctx context.Context, st storage.Storage, uri string) (*model.ServiceMetadata, error) {
+	rawMetadata, err := st.ReadFile(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("can't read serviceMetadata file: %w", err)
+	}
+
+	var serviceMetadata model.ServiceMetadata
+	if err := json.Unmarshal(rawMetadata, &serviceMetadata); err != nil {
+		return nil, fmt.Errorf("can't parse serviceMetadata: %w", err)
 	}
-	return resp.Hash()
+	return &serviceMetadata, nil
 }
 
-// Delete removes the service registration from the Registry contract.
-// This is a low-level method that submits a transaction without authentication.
-func (srvClient *ServiceClient) Delete() common.Hash {
-	resp, err := srvClient.Registry.DeleteServiceRegistration(nil, StringToBytes32(srvClient.org.OrgID), StringToBytes32(srvClient.ServiceID))
-	if err != nil || resp == nil {
-		zap.L().Panic("Error DeleteServiceRegistration",
-			zap.String("OrganizationId", srvClient.org.OrgID),
-			zap.String("ServiceId", srvClient.ServiceID),
-			zap.Error(err),
-		)
+// ReadServiceProtos reads and unpacks the proto archive the service metadata
+// points to, returning the files by name.
+func (evm *EVMClient) ReadServiceProtos(metadata *model.ServiceMetadata) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+	return evm.ReadServiceProtosCtx(ctx, metadata)
+}
+
+// ReadServiceProtosCtx is like ReadServiceProtos but reads the archive with ctx.
+func (evm *EVMClient) ReadServiceProtosCtx(ctx context.Context, metadata *model.ServiceMetadata) (map[string]string, error) {
+	return readServiceProtos(ctx, evm.Storage, metadata)
+}
+
+func readServiceProtos(ctx context.Context, st storage.Storage, metadata *model.ServiceMetadata) (map[string]string, error) {
+	var (
+		rawFile []byte
+		err     error
+	)
+	// Backward compatibility: older metadata may use ModelIpfsHash.
+	if metadata.ModelIpfsHash != "" {
+		rawFile, err = st.ReadFile(ctx, metadata.ModelIpfsHash)
+	}
+	if metadata.ServiceApiSource != "" {
+		rawFile, err = st.ReadFile(ctx, metadata.ServiceApiSource)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read api source (proto) files: %w", err)
+	}
+
+	protoFiles, err := storage.ParseProtoFiles(rawFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse proto files: %w", err)
 	}
-	return resp.Hash()
+	return protoFiles, nil
+}
+
+// Delete removes the service registration from the Registry contract.
+//
+// Deprecated: the Registry rejects unsigned transactions, so Delete always
+// fails. Use DeleteServiceWithAuth.
+func (srvClient *ServiceClient) Delete() (common.Hash, error) {
+	return common.Hash{}, fmt.Errorf("delete service %q: %w", srvClient.ServiceID, errUnsignedTransaction)
 }
 
 // Update updates the service registration in the Registry contract.
-func (srvClient *ServiceClient) Update() common.Hash {
-	resp, err := srvClient.Registry.DeleteServiceRegistration(nil, StringToBytes32(srvClient.org.OrgID), StringToBytes32(srvClient.ServiceID))
-	if err != nil || resp == nil {
-		zap.L().Panic("Error DeleteServiceRegistration",
-			zap.String("OrganizationId", srvClient.org.OrgID),
-			zap.String("ServiceId", srvClient.ServiceID),
-			zap.Error(err),
-		)
-	}
-	return resp.Hash()
+//
+// Deprecated: the Registry rejects unsigned transactions, so Update always
+// fails. Use UpdateServiceMetadata.
+func (srvClient *ServiceClient) Update() (common.Hash, error) {
+	return common.Hash{}, fmt.Errorf("update service %q: %w", srvClient.ServiceID, errUnsignedTransaction)
 }
 
 // CreateServiceRegistration creates a new service registration in the Registry contract.
-func (orgClient *OrgClient) CreateServiceRegistration(pk *ecdsa.PrivateKey, serviceID string, metadataURI []byte) (common.Hash, error) {
-	opts, err := orgClient.GetTransactOpts(pk)
+func (orgClient *OrgClient) CreateServiceRegistration(auth signer.Signer, serviceID string, metadataURI []byte) (common.Hash, error) {
+	return orgClient.CreateServiceRegistrationCtx(context.Background(), auth, serviceID, metadataURI)
+}
+
+// CreateServiceRegistrationCtx is like CreateServiceRegistration but submits the transaction with ctx.
+func (orgClient *OrgClient) CreateServiceRegistrationCtx(ctx context.Context, auth signer.Signer, serviceID string, metadataURI []byte) (common.Hash, error) {
+	opts, err := orgClient.GetTransactOptsCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create transact opts: %w", err)
 	}
 
 	tx, err := orgClient.Registry.CreateServiceRegistration(opts, StringToBytes32(orgClient.OrgID), StringToBytes32(serviceID), metadataURI)
 	if err != nil {
-		zap.L().Error("Failed to create service registration",
+		orgClient.logger().Error("Failed to create service registration",
 			zap.String("orgId", orgClient.OrgID),
 			zap.String("serviceId", serviceID),
 			zap.Error(err))
 		return common.Hash{}, fmt.Errorf("failed to create service registration: %w", err)
 	}
 
-	zap.L().Info("Service registration transaction sent",
+	orgClient.logger().Info("Service registration transaction sent",
 		zap.String("orgId", orgClient.OrgID),
 		zap.String("serviceId", serviceID),
 		zap.String("txHash", tx.Hash().Hex()))
@@ -145,22 +175,27 @@ func (orgClient *OrgClient) CreateServiceRegistration(pk *ecdsa.PrivateKey, serv
 }
 
 // UpdateServiceMetadata updates the metadata URI for a service.
-func (srvClient *ServiceClient) UpdateServiceMetadata(pk *ecdsa.PrivateKey, metadataURI []byte) (common.Hash, error) {
-	opts, err := srvClient.GetTransactOpts(pk)
+func (srvClient *ServiceClient) UpdateServiceMetadata(auth signer.Signer, metadataURI []byte) (common.Hash, error) {
+	return srvClient.UpdateServiceMetadataCtx(context.Background(), auth, metadataURI)
+}
+
+// UpdateServiceMetadataCtx is like UpdateServiceMetadata but submits the transaction with ctx.
+func (srvClient *ServiceClient) UpdateServiceMetadataCtx(ctx context.Context, auth signer.Signer, metadataURI []byte) (common.Hash, error) {
+	opts, err := srvClient.GetTransactOptsCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create transact opts: %w", err)
 	}
 
 	tx, err := srvClient.Registry.UpdateServiceRegistration(opts, StringToBytes32(srvClient.org.OrgID), StringToBytes32(srvClient.ServiceID), metadataURI)
 	if err != nil {
-		zap.L().Error("Failed to update service metadata",
+		srvClient.logger().Error("Failed to update service metadata",
 			zap.String("orgId", srvClient.org.OrgID),
 			zap.String("serviceId", srvClient.ServiceID),
 			zap.Error(err))
 		return common.Hash{}, fmt.Errorf("failed to update service metadata: %w", err)
 	}
 
-	zap.L().Info("Service metadata update transaction sent",
+	srvClient.logger().Info("Service metadata update transaction sent",
 		zap.String("orgId", srvClient.org.OrgID),
 		zap.String("serviceId", srvClient.ServiceID),
 		zap.String("txHash", tx.Hash().Hex()))
@@ -168,22 +203,27 @@ func (srvClient *ServiceClient) UpdateServiceMetadata(pk *ecdsa.PrivateKey, meta
 }
 
 // DeleteServiceWithAuth deletes a service registration with authentication.
-func (srvClient *ServiceClient) DeleteServiceWithAuth(pk *ecdsa.PrivateKey) (common.Hash, error) {
-	opts, err := srvClient.GetTransactOpts(pk)
+func (srvClient *ServiceClient) DeleteServiceWithAuth(auth signer.Signer) (common.Hash, error) {
+	return srvClient.DeleteServiceWithAuthCtx(context.Background(), auth)
+}
+
+// DeleteServiceWithAuthCtx is like DeleteServiceWithAuth but submits the transaction with ctx.
+func (srvClient *ServiceClient) DeleteServiceWithAuthCtx(ctx context.Context, auth signer.Signer) (common.Hash, error) {
+	opts, err := srvClient.GetTransactOptsCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create transact opts: %w", err)
 	}
 
 	tx, err := srvClient.Registry.DeleteServiceRegistration(opts, StringToBytes32(srvClient.org.OrgID), StringToBytes32(srvClient.ServiceID))
 	if err != nil {
-		zap.L().Error("Failed to delete service registration",
+		srvClient.logger().Error("Failed to delete service registration",
 			zap.String("orgId", srvClient.org.OrgID),
 			zap.String("serviceId", srvClient.ServiceID),
 			zap.Error(err))
 		return common.Hash{}, fmt.Errorf("failed to delete service registration: %w", err)
 	}
 
-	zap.L().Info("Service deletion transaction sent",
+	srvClient.logger().Info("Service deletion transaction sent",
 		zap.String("orgId", srvClient.org.OrgID),
 		zap.String("serviceId", srvClient.ServiceID),
 		zap.String("txHash", tx.Hash().Hex()))
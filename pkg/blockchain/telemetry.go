@@ -0,0 +1,118 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedBackend is the ethclient.Client of an EVMClient with a span around
+// every JSON-RPC request the SDK makes, named after the request method
+// (eth_call, eth_sendRawTransaction, ...). Contract bindings are bound to it,
+// so contract calls and transactions are traced too. Subscriptions and methods
+// it does not override go to the client untraced.
+type tracedBackend struct {
+	*ethclient.Client
+	tel *telemetry.Telemetry
+}
+
+func (b tracedBackend) start(ctx context.Context, method string) (context.Context, trace.Span) {
+	return b.tel.Start(ctx, method,
+		attribute.String("rpc.system", "jsonrpc"),
+		telemetry.MethodKey.String(method))
+}
+
+func (b tracedBackend) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (code []byte, err error) {
+	ctx, span := b.start(ctx, "eth_getCode")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.CodeAt(ctx, account, blockNumber)
+}
+
+func (b tracedBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (out []byte, err error) {
+	ctx, span := b.start(ctx, "eth_call")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.CallContract(ctx, msg, blockNumber)
+}
+
+func (b tracedBackend) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	ctx, span := b.start(ctx, "eth_getBlockByNumber")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.HeaderByNumber(ctx, number)
+}
+
+func (b tracedBackend) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	ctx, span := b.start(ctx, "eth_getCode")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.PendingCodeAt(ctx, account)
+}
+
+func (b tracedBackend) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	ctx, span := b.start(ctx, "eth_getTransactionCount")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.PendingNonceAt(ctx, account)
+}
+
+func (b tracedBackend) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	ctx, span := b.start(ctx, "eth_gasPrice")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.SuggestGasPrice(ctx)
+}
+
+func (b tracedBackend) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	ctx, span := b.start(ctx, "eth_maxPriorityFeePerGas")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.SuggestGasTipCap(ctx)
+}
+
+func (b tracedBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (gas uint64, err error) {
+	ctx, span := b.start(ctx, "eth_estimateGas")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.EstimateGas(ctx, msg)
+}
+
+func (b tracedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) (err error) {
+	ctx, span := b.start(ctx, "eth_sendRawTransaction")
+	span.SetAttributes(attribute.String("eth.tx_hash", tx.Hash().Hex()))
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.SendTransaction(ctx, tx)
+}
+
+func (b tracedBackend) FilterLogs(ctx context.Context, q ethereum.FilterQuery) (logs []types.Log, err error) {
+	ctx, span := b.start(ctx, "eth_getLogs")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.FilterLogs(ctx, q)
+}
+
+func (b tracedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	ctx, span := b.start(ctx, "eth_getTransactionReceipt")
+	defer func() {
+		// A receipt that is not available yet is an expected answer while
+		// waiting for a transaction.
+		if errors.Is(err, ethereum.NotFound) {
+			telemetry.End(span, nil)
+			return
+		}
+		telemetry.End(span, err)
+	}()
+	return b.Client.TransactionReceipt(ctx, txHash)
+}
+
+func (b tracedBackend) ChainID(ctx context.Context) (id *big.Int, err error) {
+	ctx, span := b.start(ctx, "eth_chainId")
+	defer func() { telemetry.End(span, err) }()
+	return b.Client.ChainID(ctx)
+}
+
+// traced returns the client of evm with its requests traced with the
+// Telemetry set by WithTelemetry, or telemetry.Global().
+func (evm *EVMClient) traced() tracedBackend {
+	return tracedBackend{Client: evm.Client, tel: evm.tel}
+}
@@ -3,10 +3,14 @@ package blockchain
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 	"go.uber.org/zap"
 )
 
@@ -21,18 +25,49 @@ func GetTransactOpts(chainID *big.Int, pk *ecdsa.PrivateKey) (*bind.TransactOpts
 	return opts, nil
 }
 
+// NewTransactOpts creates a transactor that signs with s for chainID. The
+// returned TransactOpts are bound to ctx, which is also passed to s when a
+// transaction is signed.
+func NewTransactOpts(ctx context.Context, s signer.Signer, chainID *big.Int) (*bind.TransactOpts, error) {
+	if s == nil {
+		return nil, errors.New("signer is required for transactions")
+	}
+	if chainID == nil {
+		return nil, errors.New("chain ID is required for transactions")
+	}
+	from := s.Address()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(ctx, tx, chainID)
+		},
+		Context: ctx,
+	}, nil
+}
+
 // GetTransactOpts creates a transactor from the EVM client context.
 // It automatically fetches the chain ID from the connected Ethereum client.
-func (evm *EVMClient) GetTransactOpts(pk *ecdsa.PrivateKey) (*bind.TransactOpts, error) {
-	if pk == nil {
-		return nil, fmt.Errorf("private key is required for transactions")
+// Prefer GetTransactOptsCtx if you need cancellation.
+func (evm *EVMClient) GetTransactOpts(s signer.Signer) (*bind.TransactOpts, error) {
+	return evm.GetTransactOptsCtx(context.Background(), s)
+}
+
+// GetTransactOptsCtx is like GetTransactOpts but fetches the chain ID with ctx
+// and binds ctx to the returned TransactOpts, so gas estimation, signing and
+// submission of the transaction honour its deadline and cancellation.
+func (evm *EVMClient) GetTransactOptsCtx(ctx context.Context, s signer.Signer) (*bind.TransactOpts, error) {
+	if s == nil {
+		return nil, fmt.Errorf("signer is required for transactions")
 	}
 
-	chainID, err := evm.Client.ChainID(context.Background())
+	chainID, err := evm.traced().ChainID(ctx)
 	if err != nil {
-		zap.L().Error("failed to get chain ID", zap.Error(err))
+		evm.logger().Error("failed to get chain ID", zap.Error(err))
 		return nil, err
 	}
 
-	return GetTransactOpts(chainID, pk)
+	return NewTransactOpts(ctx, s, chainID)
 }
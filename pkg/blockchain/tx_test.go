@@ -1,10 +1,14 @@
 package blockchain
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 )
 
 func TestGetTransactOpts(t *testing.T) {
@@ -86,19 +90,56 @@ func TestGetTransactOpts_DifferentChainIDs(t *testing.T) {
 	}
 }
 
-func TestEVMClient_GetTransactOpts_NilPrivateKey(t *testing.T) {
+func TestEVMClient_GetTransactOpts_NilSigner(t *testing.T) {
 	evm := &EVMClient{}
 
 	opts, err := evm.GetTransactOpts(nil)
 	if err == nil {
-		t.Fatal("expected error for nil private key")
+		t.Fatal("expected error for nil signer")
 	}
 	if opts != nil {
 		t.Fatal("expected nil opts")
 	}
 
-	expectedErr := "private key is required for transactions"
+	expectedErr := "signer is required for transactions"
 	if err.Error() != expectedErr {
 		t.Fatalf("unexpected error: got %q, want %q", err.Error(), expectedErr)
 	}
 }
+
+func TestNewTransactOpts_SignsWithSigner(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	s, err := signer.NewPrivateKeySigner(priv)
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner: %v", err)
+	}
+	chainID := big.NewInt(11155111)
+
+	opts, err := NewTransactOpts(context.Background(), s, chainID)
+	if err != nil {
+		t.Fatalf("NewTransactOpts: %v", err)
+	}
+	if opts.From != s.Address() {
+		t.Fatalf("From = %s, want %s", opts.From.Hex(), s.Address().Hex())
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, Gas: 21000, GasFeeCap: big.NewInt(2), GasTipCap: big.NewInt(1)})
+	signed, err := opts.Signer(opts.From, tx)
+	if err != nil {
+		t.Fatalf("Signer: %v", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		t.Fatalf("Sender: %v", err)
+	}
+	if sender != s.Address() {
+		t.Fatalf("signed by %s, want %s", sender.Hex(), s.Address().Hex())
+	}
+
+	if _, err := opts.Signer(common.Address{1}, tx); err == nil {
+		t.Fatal("expected an error when signing for another address")
+	}
+}
@@ -2,6 +2,7 @@ package blockchain
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"encoding/binary"
 	"errors"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
@@ -165,6 +167,16 @@ func GetSignature(message []byte, privateKeyECDSA *ecdsa.PrivateKey) []byte {
 	return signature
 }
 
+// SignMessage is like GetSignature but signs with s: it produces the
+// personal-sign signature of keccak256(message), which is what the daemon and
+// the MPE contract verify.
+func SignMessage(ctx context.Context, s signer.Signer, message []byte) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("signer is required")
+	}
+	return s.SignMessage(ctx, crypto.Keccak256(message))
+}
+
 // Bytes32ArrayToStrings converts an array of [32]byte values into a slice of strings,
 // trimming trailing NUL bytes on the right of each element.
 func Bytes32ArrayToStrings(arr [][32]byte) []string {
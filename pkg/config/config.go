@@ -4,13 +4,21 @@
 package config
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 // Config holds all SDK settings required to initialize blockchain and service clients.
@@ -28,57 +36,106 @@ type Config struct {
 	// PrivateKey is the hex-encoded ECDSA private key used for signed operations
 	// (optional if you only do free calls / read-only operations).
 	PrivateKey string `json:"private_key" yaml:"private_key"`
+	// Signer signs on behalf of the user. When set it takes precedence over
+	// PrivateKey, KeystorePath and ClefURL. See package signer.
+	Signer signer.Signer `json:"-" yaml:"-"`
+	// KeystorePath is the path of an encrypted go-ethereum keystore JSON file
+	// to sign with, decrypted with KeystorePassphrase. Used when Signer and
+	// PrivateKey are empty.
+	KeystorePath       string `json:"keystore_path" yaml:"keystore_path"`
+	KeystorePassphrase string `json:"keystore_passphrase" yaml:"keystore_passphrase"`
+	// ClefURL is the JSON-RPC endpoint of an external signer (Clef or
+	// compatible) that holds the key. Used when Signer, PrivateKey and
+	// KeystorePath are empty. SignerAddress selects the account; empty uses
+	// the first account the signer lists.
+	ClefURL       string `json:"clef_url" yaml:"clef_url"`
+	SignerAddress string `json:"signer_address" yaml:"signer_address"`
 	// LighthouseURL is the HTTP gateway used to fetch Filecoin-backed content.
 	// Default: https://gateway.lighthouse.storage/ipfs/
 	LighthouseURL string `json:"lighthouse_url" yaml:"lighthouse_url"`
 	// IpfsURL is the HTTP API endpoint of the IPFS node used to read files.
 	// Default: https://ipfs.singularitynet.io:443
 	IpfsURL string `json:"ipfs_url" yaml:"ipfs_url"`
-	// Debug enables verbose logging.
+	// Debug enables verbose logging: extra debug entries (signer address,
+	// health check details) are written to the logger, whose own level still
+	// applies.
 	Debug bool `json:"debug" yaml:"debug"`
+	// Logger receives the SDK's log output. See GetLogger.
+	Logger *zap.Logger `json:"-" yaml:"-"`
+	// LogHandler receives the SDK's log output through log/slog when Logger
+	// is nil.
+	LogHandler slog.Handler `json:"-" yaml:"-"`
+	// TracerProvider and MeterProvider receive the SDK's OpenTelemetry spans
+	// and metrics. Nil uses the global providers. See GetTelemetry.
+	TracerProvider trace.TracerProvider `json:"-" yaml:"-"`
+	MeterProvider  metric.MeterProvider `json:"-" yaml:"-"`
 	// Timeouts configures per-operation timeouts. See Timeouts.WithDefaults for defaults.
 	Timeouts Timeouts `json:"timeouts" yaml:"timeouts"`
+	// EndpointPolicy selects how calls are spread over the endpoints of a
+	// service group: "health-first" (default) or "round-robin".
+	EndpointPolicy string `json:"endpoint_policy" yaml:"endpoint_policy"`
+	// EndpointHealthcheckInterval enables background health probes of every
+	// endpoint of a service group. Zero disables background probing; endpoints
+	// are then only marked bad when a call fails with Unavailable.
+	EndpointHealthcheckInterval time.Duration `json:"endpoint_healthcheck_interval" yaml:"endpoint_healthcheck_interval"`
+	// ChannelStateFile is the path of a JSON file in which paid and prepaid
+	// strategies persist the nonce and signed amount of their payment channels,
+	// so a restart or an unreachable daemon does not lose track of what has
+	// been authorised. Empty keeps the state in process memory only.
+	ChannelStateFile string `json:"channel_state_file" yaml:"channel_state_file"`
+	// Cache enables the on-disk cache of metadata, proto files, compiled
+	// descriptors and Registry lookups. See CacheConfig.
+	Cache CacheConfig `json:"cache" yaml:"cache"`
 
 	// privateKeyECDSA is the parsed ECDSA private key (lazy-loaded on first access)
 	privateKeyECDSA *ecdsa.PrivateKey
-}
 
-// Network describes a blockchain network (chain ID and name). ChainID is used
-// for EIP-155 signing; Name is informational.
-type Network struct {
-	ChainID string `json:"chain_id"`
-	Name    string `json:"network_name"`
-}
+	// resolvedSigner is the signer built by GetSigner (lazy-loaded on first access)
+	resolvedSigner signer.Signer
 
-// Sepolia is a predefined Network for Ethereum Sepolia testnet.
-var Sepolia = Network{
-	ChainID: "11155111",
-	Name:    "sepolia",
+	// resolvedLogger is the logger built by GetLogger (lazy-loaded on first access)
+	resolvedLogger *zap.Logger
+
+	// resolvedTelemetry is the telemetry built by GetTelemetry (lazy-loaded on first access)
+	resolvedTelemetry *telemetry.Telemetry
 }
 
-// Main is a predefined Network for Ethereum mainnet.
-var Main = Network{
-	ChainID: "1",
-	Name:    "main",
+// CacheConfig configures the on-disk metadata cache. The cache is disabled
+// while Dir is empty.
+type CacheConfig struct {
+	// Dir is the directory the cache entries are kept in.
+	Dir string `json:"dir" yaml:"dir"`
+	// TTL is how long Registry lookups are used before the Registry is read
+	// again. Metadata files and protos are addressed by content and never
+	// expire. Default: 15m.
+	TTL time.Duration `json:"ttl" yaml:"ttl"`
+	// StaleWhileRevalidate serves expired Registry lookups at once and
+	// refreshes them in the background.
+	StaleWhileRevalidate bool `json:"stale_while_revalidate" yaml:"stale_while_revalidate"`
+	// Offline serves metadata and protos only from the cache, without
+	// reading the Registry or storage. Organization and service clients can
+	// then only be created for what was cached before.
+	Offline bool `json:"offline" yaml:"offline"`
 }
 
 // Timeouts controls SDK operation deadlines.
 // Zero values will be replaced by sane defaults in WithDefaults.
+// Configuration files give them as duration strings ("30s", "2m").
 type Timeouts struct {
-	Dial            time.Duration // gRPC/Web3 dial/connect
-	GRPCUnary       time.Duration // RPC
-	GRPCStream      time.Duration // RPC stream
-	ChainRead       time.Duration // eth_call, balance etc
-	ChainSubmit     time.Duration // send tx
-	ReceiptWait     time.Duration // wait tx
-	StrategyRefresh time.Duration // refresh strategy
-	PaymentEnsure   time.Duration // ensure payment channel
+	Dial            time.Duration `json:"dial" yaml:"dial"`                         // gRPC/Web3 dial/connect
+	GRPCUnary       time.Duration `json:"grpc_unary" yaml:"grpc_unary"`             // RPC
+	GRPCStream      time.Duration `json:"grpc_stream" yaml:"grpc_stream"`           // RPC stream
+	ChainRead       time.Duration `json:"chain_read" yaml:"chain_read"`             // eth_call, balance etc
+	ChainSubmit     time.Duration `json:"chain_submit" yaml:"chain_submit"`         // send tx
+	ReceiptWait     time.Duration `json:"receipt_wait" yaml:"receipt_wait"`         // wait tx
+	StrategyRefresh time.Duration `json:"strategy_refresh" yaml:"strategy_refresh"` // refresh strategy
+	PaymentEnsure   time.Duration `json:"payment_ensure" yaml:"payment_ensure"`     // ensure payment channel
 }
 
 // Validate normalizes the configuration by applying implicit defaults for
-// LighthouseURL, IpfsURL and Network (defaults to Sepolia) and verifies that
-// RPCAddr is provided.
-// Returns an error when RPCAddr is empty.
+// LighthouseURL, IpfsURL and Network (defaults to Sepolia) and checks every
+// field. All problems are reported at once as ValidationErrors, each naming
+// the field by its configuration file path (for example "timeouts.dial").
 func (c *Config) Validate() error {
 
 	if c.LighthouseURL == "" {
@@ -90,14 +147,113 @@ func (c *Config) Validate() error {
 	}
 
 	if c.Network.ChainID == "" {
-		c.Network = Sepolia
+		// Keep contract address overrides given without a chain.
+		c.Network.ChainID, c.Network.Name = Sepolia.ChainID, Sepolia.Name
 	}
 
+	var errs ValidationErrors
+
 	if c.RPCAddr == "" {
-		return errors.New("RPC address is required")
+		errs.add("rpc_addr", errors.New("RPC address is required"))
+	}
+
+	for _, e := range c.Network.validate() {
+		errs.add("network."+e.Field, e.Err)
+	}
+
+	if c.RegistryAddr != "" && !common.IsHexAddress(c.RegistryAddr) {
+		errs.add("registry_addr", fmt.Errorf("invalid address %q", c.RegistryAddr))
+	}
+
+	if c.SignerAddress != "" && !common.IsHexAddress(c.SignerAddress) {
+		errs.add("signer_address", fmt.Errorf("invalid address %q", c.SignerAddress))
+	}
+
+	switch c.EndpointPolicy {
+	case "", "health-first", "round-robin":
+	default:
+		errs.add("endpoint_policy", fmt.Errorf("unknown endpoint policy %q (want health-first or round-robin)", c.EndpointPolicy))
+	}
+
+	if c.EndpointHealthcheckInterval < 0 {
+		errs.add("endpoint_healthcheck_interval", errors.New("must not be negative"))
+	}
+
+	if c.Cache.TTL < 0 {
+		errs.add("cache.ttl", errors.New("must not be negative"))
+	}
+
+	if c.Cache.Dir == "" && (c.Cache.Offline || c.Cache.StaleWhileRevalidate || c.Cache.TTL != 0) {
+		errs.add("cache.dir", errors.New("required when the cache is configured"))
+	}
+
+	for _, d := range []struct {
+		field string
+		value time.Duration
+	}{
+		{"timeouts.dial", c.Timeouts.Dial},
+		{"timeouts.grpc_unary", c.Timeouts.GRPCUnary},
+		{"timeouts.grpc_stream", c.Timeouts.GRPCStream},
+		{"timeouts.chain_read", c.Timeouts.ChainRead},
+		{"timeouts.chain_submit", c.Timeouts.ChainSubmit},
+		{"timeouts.receipt_wait", c.Timeouts.ReceiptWait},
+		{"timeouts.strategy_refresh", c.Timeouts.StrategyRefresh},
+		{"timeouts.payment_ensure", c.Timeouts.PaymentEnsure},
+	} {
+		if d.value < 0 {
+			errs.add(d.field, errors.New("must not be negative"))
+		}
+	}
+
+	return errs.err()
+}
+
+// FieldError describes a problem with one configuration field. Field is the
+// path of the field in configuration files, for example "timeouts.dial".
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors lists every problem found in a configuration. Use
+// errors.As to inspect the individual FieldErrors.
+type ValidationErrors []*FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, len(v))
+	for i, e := range v {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the individual field errors.
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, e := range v {
+		errs[i] = e
 	}
+	return errs
+}
 
-	return nil
+func (v *ValidationErrors) add(field string, err error) {
+	*v = append(*v, &FieldError{Field: field, Err: err})
+}
+
+// err returns v as an error, or nil when it is empty.
+func (v ValidationErrors) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
 }
 
 // WithDefaults returns a copy of t with zero values replaced by defaults:
@@ -140,7 +296,8 @@ func (t Timeouts) WithDefaults() Timeouts {
 
 // GetPrivateKey returns the parsed ECDSA private key.
 // It parses the hex string on first call and caches the result.
-// Returns nil if PrivateKey is empty (read-only mode).
+// Returns nil if PrivateKey is empty (read-only mode). The SDK signs through
+// GetSigner, which also covers keystore files and external signers.
 func (c *Config) GetPrivateKey() *ecdsa.PrivateKey {
 	// If key is not set - this is normal for read-only operations
 	if c.PrivateKey == "" {
@@ -194,3 +351,64 @@ func (c *Config) RequirePrivateKey() (*ecdsa.PrivateKey, error) {
 	}
 	return c.GetPrivateKey(), nil
 }
+
+// HasSigner returns true if any way of signing is configured: Signer,
+// PrivateKey, KeystorePath or ClefURL.
+func (c *Config) HasSigner() bool {
+	return c.Signer != nil || c.PrivateKey != "" || c.KeystorePath != "" || c.ClefURL != ""
+}
+
+// GetSigner returns the signer for signed operations, built on first call
+// from Signer, PrivateKey, KeystorePath or ClefURL (in that order) and cached.
+// It returns nil and no error when none is configured (read-only mode).
+// Like GetPrivateKey, the first call must not race with others; sdk.NewSDK
+// makes it during initialization.
+func (c *Config) GetSigner() (signer.Signer, error) {
+	if c.Signer != nil {
+		return c.Signer, nil
+	}
+	if c.resolvedSigner != nil {
+		return c.resolvedSigner, nil
+	}
+
+	var (
+		s   signer.Signer
+		err error
+	)
+	switch {
+	case c.PrivateKey != "":
+		s, err = signer.ParsePrivateKey(c.PrivateKey)
+	case c.KeystorePath != "":
+		s, err = signer.NewKeystoreSigner(c.KeystorePath, c.KeystorePassphrase)
+	case c.ClefURL != "":
+		var address common.Address
+		if c.SignerAddress != "" {
+			if !common.IsHexAddress(c.SignerAddress) {
+				return nil, fmt.Errorf("invalid signer address %q", c.SignerAddress)
+			}
+			address = common.HexToAddress(c.SignerAddress)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), c.Timeouts.WithDefaults().Dial)
+		defer cancel()
+		s, err = signer.DialClef(ctx, c.ClefURL, address)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("configure signer: %w", err)
+	}
+	c.resolvedSigner = s
+	return s, nil
+}
+
+// RequireSigner returns the signer or an error if none is configured.
+func (c *Config) RequireSigner() (signer.Signer, error) {
+	s, err := c.GetSigner()
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, errors.New("signer not configured: set PrivateKey, KeystorePath, ClefURL or Signer")
+	}
+	return s, nil
+}
@@ -5,7 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 )
 
 func TestConfig_Validate_Success(t *testing.T) {
@@ -76,7 +78,7 @@ func TestConfig_Validate_Error(t *testing.T) {
 		t.Fatal("expected error when RPCAddr is empty")
 	}
 
-	expectedErr := "RPC address is required"
+	expectedErr := "rpc_addr: RPC address is required"
 	if err.Error() != expectedErr {
 		t.Fatalf("expected error %q, got %q", expectedErr, err.Error())
 	}
@@ -290,6 +292,67 @@ func TestConfig_RequirePrivateKey(t *testing.T) {
 	})
 }
 
+func TestConfig_GetSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	want := crypto.PubkeyToAddress(key.PublicKey)
+
+	t.Run("none configured", func(t *testing.T) {
+		config := &Config{}
+		if config.HasSigner() {
+			t.Fatal("HasSigner() = true, want false")
+		}
+		s, err := config.GetSigner()
+		if s != nil || err != nil {
+			t.Fatalf("GetSigner() = %v, %v; want nil, nil", s, err)
+		}
+		if _, err := config.RequireSigner(); err == nil || !strings.Contains(err.Error(), "signer not configured") {
+			t.Fatalf("RequireSigner() error = %v; want 'signer not configured'", err)
+		}
+	})
+
+	t.Run("private key", func(t *testing.T) {
+		config := &Config{PrivateKey: "0x" + common.Bytes2Hex(crypto.FromECDSA(key))}
+		s, err := config.RequireSigner()
+		if err != nil {
+			t.Fatalf("RequireSigner() error: %v", err)
+		}
+		if s.Address() != want {
+			t.Fatalf("signer address = %s, want %s", s.Address(), want)
+		}
+		if again, _ := config.GetSigner(); again != s {
+			t.Error("GetSigner() should return cached instance")
+		}
+	})
+
+	t.Run("explicit signer wins", func(t *testing.T) {
+		explicit, err := signer.NewPrivateKeySigner(key)
+		if err != nil {
+			t.Fatalf("NewPrivateKeySigner: %v", err)
+		}
+		config := &Config{Signer: explicit, PrivateKey: "invalid"}
+		if s, err := config.GetSigner(); err != nil || s != explicit {
+			t.Fatalf("GetSigner() = %v, %v; want the explicit signer", s, err)
+		}
+	})
+
+	t.Run("invalid private key", func(t *testing.T) {
+		config := &Config{PrivateKey: "invalid"}
+		if _, err := config.GetSigner(); err == nil || !strings.Contains(err.Error(), "configure signer") {
+			t.Fatalf("GetSigner() error = %v; want 'configure signer'", err)
+		}
+	})
+
+	t.Run("missing keystore", func(t *testing.T) {
+		config := &Config{KeystorePath: "/nonexistent/keystore.json"}
+		if _, err := config.GetSigner(); err == nil {
+			t.Fatal("GetSigner() should error for a missing keystore file")
+		}
+	})
+}
+
 func TestParsePrivateKey(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -36,6 +36,21 @@ func TestConfigValidate_RequiresRPC(t *testing.T) {
 	}
 }
 
+// TestConfigValidate_EndpointPolicy verifies that only known endpoint
+// policies are accepted.
+func TestConfigValidate_EndpointPolicy(t *testing.T) {
+	for _, policy := range []string{"", "health-first", "round-robin"} {
+		cfg := &Config{RPCAddr: "wss://example", EndpointPolicy: policy}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("policy %q: unexpected error: %v", policy, err)
+		}
+	}
+	cfg := &Config{RPCAddr: "wss://example", EndpointPolicy: "random"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for unknown endpoint policy")
+	}
+}
+
 // TestConfigValidate_AllowsAnyProtocol verifies that Validate accepts
 // any protocol for RPCAddr (WebSocket validation moved to strategy setters).
 func TestConfigValidate_AllowsAnyProtocol(t *testing.T) {
@@ -109,3 +124,30 @@ func TestTimeoutsWithDefaults(t *testing.T) {
 		t.Fatalf("PaymentEnsure default mismatch: %v", out.PaymentEnsure)
 	}
 }
+
+// TestConfigValidate_Cache verifies that the cache options require a
+// directory and a non-negative TTL.
+func TestConfigValidate_Cache(t *testing.T) {
+	valid := []CacheConfig{
+		{},
+		{Dir: "/tmp/snet", TTL: time.Minute, StaleWhileRevalidate: true},
+		{Dir: "/tmp/snet", Offline: true},
+	}
+	for _, cache := range valid {
+		cfg := &Config{RPCAddr: "wss://example", Cache: cache}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("%+v: unexpected error: %v", cache, err)
+		}
+	}
+	invalid := []CacheConfig{
+		{Offline: true},
+		{TTL: time.Minute},
+		{Dir: "/tmp/snet", TTL: -time.Second},
+	}
+	for _, cache := range invalid {
+		cfg := &Config{RPCAddr: "wss://example", Cache: cache}
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%+v: expected error", cache)
+		}
+	}
+}
@@ -19,13 +19,28 @@
 //	config.Sepolia - Ethereum Sepolia testnet (ChainID: 11155111)
 //	config.Main    - Ethereum mainnet (ChainID: 1)
 //
-// Custom networks can be defined:
-//
-//	customNet := config.Network{
-//		ChainID: "12345",
-//		Name:    "custom-network",
+// Their contract addresses come from the published SingularityNET
+// deployments. Other chains (a local devnet, a private deployment) need the
+// addresses set explicitly:
+//
+//	devnet := config.Network{
+//		ChainID:      "31337",
+//		Name:         "devnet",
+//		RegistryAddr: "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+//		MPEAddr:      "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512",
+//		TokenAddr:    "0x9fE46736679d2D9a65F0992a2728052dE3C7A5E2", // optional, read from the MPE otherwise
 //	}
 //
+// The same fields override individual addresses of Sepolia or Main. Register
+// a network to select it by name in files and the environment
+// ("network: devnet", SNET_NETWORK=devnet):
+//
+//	if err := config.RegisterNetwork(devnet); err != nil { ... }
+//
+// The SDK checks that every contract address has code on the chain when it
+// connects, so a wrong address or chain fails NewSDK instead of the first
+// call.
+//
 // # RPC Endpoints
 //
 // The RPC endpoint protocol depends on your payment strategy:
@@ -36,21 +51,25 @@
 //   - Paid/Prepaid calls: WebSocket (WSS/WS) required for event subscriptions
 //     Example: "wss://sepolia.infura.io/ws/v3/PROJECT_ID"
 //
-// # Private Key
+// # Signer
 //
-// Private key is required for:
-//   - Paid and prepaid payment operations
+// A signer is required for:
+//   - Free, paid and prepaid payment operations
 //   - Creating/managing organizations
 //   - Creating/managing services
 //   - Any blockchain write operations
 //
-// The key should be hex-encoded without the "0x" prefix:
-//
-//	cfg.PrivateKey = "abcdef1234567890..." // 64 hex characters
+// Configure one of the following (checked in this order, see GetSigner):
 //
-// Replace with your actual private key:
+//	cfg.Signer = mySigner                   // any signer.Signer
+//	cfg.PrivateKey = "YOUR_PRIVATE_KEY"     // 64 hex characters, "0x" optional
+//	cfg.KeystorePath = "UTC--2024-...--0x1234..."
+//	cfg.KeystorePassphrase = os.Getenv("KEYSTORE_PASSPHRASE")
+//	cfg.ClefURL = "http://localhost:8550"   // external signer, key stays out of process
+//	cfg.SignerAddress = "0x1234..."         // optional; defaults to the first Clef account
 //
-//	cfg.PrivateKey = "YOUR_PRIVATE_KEY"
+// Keystore files and external signers keep the raw key out of configuration
+// files and environment variables.
 //
 // # Storage Gateways
 //
@@ -64,6 +83,20 @@
 //	cfg.IpfsURL = "http://localhost:5001"
 //	cfg.LighthouseURL = "https://custom-gateway.example.com/ipfs/"
 //
+// # Metadata Cache
+//
+// Set Cache.Dir to keep organization and service metadata, proto files,
+// compiled descriptors and Registry lookups on disk across runs:
+//
+//	cfg.Cache = config.CacheConfig{
+//		Dir:                  "/var/cache/snet",
+//		TTL:                  10 * time.Minute, // Registry lookups; files never expire
+//		StaleWhileRevalidate: true,             // serve expired lookups, refresh in background
+//	}
+//
+// With Offline set, metadata is served only from the cache. The chain is
+// still dialed, so RPCAddr must be reachable.
+//
 // # Timeouts
 //
 // All operations have configurable timeouts. The Timeouts struct provides granular control:
@@ -93,6 +126,18 @@
 //   - gRPC invocations
 //   - Payment channel operations
 //
+// # Logging
+//
+// The SDK logs through zap. Pass your logger, or a log/slog handler:
+//
+//	cfg.Logger = zap.Must(zap.NewProduction())
+//	cfg.LogHandler = slog.NewJSONHandler(os.Stderr, nil) // used when Logger is nil
+//
+// Without either, the SDK logs to zap.L(), which discards everything unless
+// the application replaced the global logger. Entries of service clients
+// carry org, service and group fields; payment strategies add channel and
+// nonce.
+//
 // # Registry Address
 //
 // By default, the SDK uses the standard SingularityNET registry contract.
@@ -100,6 +145,31 @@
 //
 //	cfg.RegistryAddr = "0x1234567890abcdef1234567890abcdef12345678"
 //
+// RegistryAddr takes precedence over Network.RegistryAddr.
+//
+// # Loading from Files and Environment
+//
+// Load reads a YAML or JSON file, applies the selected profile and then
+// environment variable overrides; FromEnv reads environment variables alone:
+//
+//	cfg, err := config.Load("snet.yaml", config.WithProfile("sepolia-dev"))
+//	cfg, err := config.FromEnv("SNET") // SNET_RPC_ADDR, SNET_PRIVATE_KEY, ...
+//
+// A file holds the fields of Config under their yaml keys, durations as
+// strings, and named profiles that override them:
+//
+//	network: sepolia
+//	rpc_addr: wss://sepolia.infura.io/ws/v3/YOUR_PROJECT_ID
+//	timeouts:
+//	  grpc_unary: 30s
+//	profiles:
+//	  mainnet-prod:
+//	    network: main
+//	    rpc_addr: wss://mainnet.infura.io/ws/v3/YOUR_PROJECT_ID
+//
+// Without WithProfile the profile comes from SNET_PROFILE. Both functions
+// validate the result.
+//
 // # Configuration Validation
 //
 // Always call Validate() to apply defaults and check required fields:
@@ -112,7 +182,9 @@
 // Validate() will:
 //   - Set default storage URLs if not provided
 //   - Set default network to Sepolia if not provided
-//   - Return error if RPCAddr is empty
+//   - Return ValidationErrors listing every invalid field (empty RPCAddr,
+//     malformed addresses or chain ID, unknown endpoint policy, negative
+//     durations) by its file path, for example "timeouts.dial"
 //
 // # Complete Example
 //
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultEnvPrefix is the prefix of the environment variables read by Load.
+const DefaultEnvPrefix = "SNET"
+
+// fileConfig is the layout of a configuration file: the base configuration
+// plus named profiles that override parts of it.
+type fileConfig struct {
+	Config   `yaml:",inline"`
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+}
+
+type loadOptions struct {
+	profile   string
+	envPrefix string
+}
+
+// LoadOption configures Load.
+type LoadOption func(*loadOptions)
+
+// WithProfile selects the named profile of the configuration file. It takes
+// precedence over the <prefix>_PROFILE environment variable.
+func WithProfile(name string) LoadOption {
+	return func(o *loadOptions) {
+		o.profile = name
+	}
+}
+
+// WithEnvPrefix sets the prefix of the environment variables that override
+// the file (default DefaultEnvPrefix).
+func WithEnvPrefix(prefix string) LoadOption {
+	return func(o *loadOptions) {
+		o.envPrefix = prefix
+	}
+}
+
+// Load reads the YAML or JSON configuration file at path and returns the
+// validated configuration. Values are merged in this order, later ones
+// winning:
+//
+//  1. defaults (see Validate and Timeouts.WithDefaults)
+//  2. the top level of the file
+//  3. the selected profile under "profiles" (WithProfile or <prefix>_PROFILE)
+//  4. environment variables (see FromEnv)
+//
+// Unknown keys, unknown profiles, malformed environment variables and
+// invalid values are all reported in the returned error.
+func Load(path string, opts ...LoadOption) (*Config, error) {
+	o := loadOptions{envPrefix: DefaultEnvPrefix}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	var file fileConfig
+	if err := decodeStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	cfg := &file.Config
+
+	profile := o.profile
+	if profile == "" {
+		profile, _ = lookupEnv(envName(o.envPrefix, "profile"))
+	}
+	if profile != "" {
+		node, ok := file.Profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q in %s (available: %s)", profile, path, strings.Join(profileNames(file.Profiles), ", "))
+		}
+		// Re-encode the profile so unknown keys are rejected as in the base.
+		raw, err := yaml.Marshal(&node)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", profile, err)
+		}
+		if err := decodeStrict(raw, cfg); err != nil {
+			return nil, fmt.Errorf("parse profile %q in %s: %w", profile, path, err)
+		}
+	}
+
+	return finish(cfg, o.envPrefix)
+}
+
+// FromEnv builds a validated configuration from environment variables and
+// defaults alone. Every field is read from <prefix>_<FIELD PATH>, the upper
+// case of its configuration file path with dots replaced by underscores:
+//
+//	SNET_RPC_ADDR, SNET_PRIVATE_KEY, SNET_KEYSTORE_PATH, SNET_DEBUG,
+//	SNET_TIMEOUTS_GRPC_UNARY, SNET_ENDPOINT_HEALTHCHECK_INTERVAL, ...
+//
+// SNET_NETWORK takes a value accepted by ParseNetwork, whose fields
+// SNET_NETWORK_MPE_ADDR and so on then override. Booleans are parsed
+// with strconv.ParseBool and durations with time.ParseDuration. Empty
+// variables are ignored.
+func FromEnv(prefix string) (*Config, error) {
+	return finish(&Config{}, prefix)
+}
+
+// finish applies the environment on top of cfg and validates the result,
+// reporting environment and validation problems together.
+func finish(cfg *Config, prefix string) (*Config, error) {
+	errs := applyEnv(reflect.ValueOf(cfg).Elem(), "", prefix)
+	if err := cfg.Validate(); err != nil {
+		var verrs ValidationErrors
+		if !errors.As(err, &verrs) {
+			return nil, err
+		}
+		errs = append(errs, verrs...)
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decodeStrict decodes YAML (or JSON, which is valid YAML) into out and
+// rejects keys that match no field.
+func decodeStrict(data []byte, out any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+var (
+	networkType  = reflect.TypeFor[Network]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// applyEnv sets the fields of the struct v from the environment. path is the
+// configuration file path of v.
+func applyEnv(v reflect.Value, path, prefix string) ValidationErrors {
+	var errs ValidationErrors
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		field := name
+		if path != "" {
+			field = path + "." + name
+		}
+		fv := v.Field(i)
+
+		key := envName(prefix, field)
+		raw, ok := lookupEnv(key)
+		fail := func(err error) {
+			errs.add(field, fmt.Errorf("%s: %w", key, err))
+		}
+
+		if f.Type.Kind() == reflect.Struct {
+			// A network is selected as a whole (SNET_NETWORK=devnet) and
+			// its fields can then be overridden one by one.
+			if f.Type == networkType && ok {
+				n, err := ParseNetwork(raw)
+				if err != nil {
+					fail(err)
+				} else {
+					fv.Set(reflect.ValueOf(n))
+				}
+			}
+			errs = append(errs, applyEnv(fv, field, prefix)...)
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+		switch {
+		case f.Type == durationType:
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				fail(err)
+				continue
+			}
+			fv.SetInt(int64(d))
+		case f.Type.Kind() == reflect.Bool:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				fail(err)
+				continue
+			}
+			fv.SetBool(b)
+		case f.Type.Kind() == reflect.String:
+			fv.SetString(raw)
+		}
+	}
+	return errs
+}
+
+// envName returns the environment variable for the configuration file path
+// field, for example SNET_TIMEOUTS_DIAL for "timeouts.dial".
+func envName(prefix, field string) string {
+	name := strings.ToUpper(strings.ReplaceAll(field, ".", "_"))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// lookupEnv is like os.LookupEnv but treats empty variables as unset.
+func lookupEnv(key string) (string, bool) {
+	v := os.Getenv(key)
+	return v, v != ""
+}
+
+func profileNames(profiles map[string]yaml.Node) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// UnmarshalYAML accepts either a mapping of the Network fields or a single
+// value accepted by ParseNetwork.
+func (n *Network) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		parsed, err := ParseNetwork(value.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", value.Line, err)
+		}
+		*n = parsed
+		return nil
+	}
+	type plain Network
+	return value.Decode((*plain)(n))
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	return path
+}
+
+const profilesYAML = `
+rpc_addr: wss://sepolia.example/ws
+network: sepolia
+timeouts:
+  dial: 3s
+  grpc_unary: 20s
+profiles:
+  sepolia-dev:
+    debug: true
+    timeouts:
+      grpc_unary: 1m
+  mainnet-prod:
+    network: main
+    rpc_addr: wss://mainnet.example/ws
+    endpoint_policy: round-robin
+`
+
+func TestLoad_MergesFileProfileAndEnv(t *testing.T) {
+	path := writeConfigFile(t, "snet.yaml", profilesYAML)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.RPCAddr != "wss://sepolia.example/ws" || cfg.Network != Sepolia || cfg.Debug {
+		t.Fatalf("base config = %+v", cfg)
+	}
+	if cfg.Timeouts.Dial != 3*time.Second || cfg.Timeouts.GRPCUnary != 20*time.Second {
+		t.Fatalf("timeouts = %+v; want dial 3s, grpc_unary 20s", cfg.Timeouts)
+	}
+	if cfg.IpfsURL == "" {
+		t.Fatal("defaults not applied")
+	}
+
+	cfg, err = Load(path, WithProfile("sepolia-dev"))
+	if err != nil {
+		t.Fatalf("Load sepolia-dev: %v", err)
+	}
+	if !cfg.Debug || cfg.Timeouts.GRPCUnary != time.Minute || cfg.Timeouts.Dial != 3*time.Second {
+		t.Fatalf("sepolia-dev = debug %v, timeouts %+v; want profile values over the base", cfg.Debug, cfg.Timeouts)
+	}
+
+	t.Setenv("SNET_PROFILE", "mainnet-prod")
+	t.Setenv("SNET_RPC_ADDR", "wss://override.example/ws")
+	t.Setenv("SNET_TIMEOUTS_DIAL", "7s")
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load mainnet-prod: %v", err)
+	}
+	if cfg.Network != Main || cfg.EndpointPolicy != "round-robin" {
+		t.Fatalf("mainnet-prod = network %+v, policy %q", cfg.Network, cfg.EndpointPolicy)
+	}
+	if cfg.RPCAddr != "wss://override.example/ws" || cfg.Timeouts.Dial != 7*time.Second {
+		t.Fatalf("env overrides = rpc %q, dial %v", cfg.RPCAddr, cfg.Timeouts.Dial)
+	}
+
+	if _, err := Load(path, WithProfile("staging")); err == nil || !strings.Contains(err.Error(), "mainnet-prod, sepolia-dev") {
+		t.Fatalf("unknown profile error = %v; want the available profiles listed", err)
+	}
+}
+
+func TestLoad_JSON(t *testing.T) {
+	path := writeConfigFile(t, "snet.json", `{
+		"rpc_addr": "https://rpc.example",
+		"network": {"chain_id": "31337", "network_name": "devnet"},
+		"endpoint_healthcheck_interval": "30s",
+		"timeouts": {"payment_ensure": "5m"}
+	}`)
+
+	cfg, err := Load(path, WithEnvPrefix("SNET_TEST_UNUSED"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Network != (Network{ChainID: "31337", Name: "devnet"}) {
+		t.Fatalf("network = %+v", cfg.Network)
+	}
+	if cfg.EndpointHealthcheckInterval != 30*time.Second || cfg.Timeouts.PaymentEnsure != 5*time.Minute {
+		t.Fatalf("durations = %v, %v", cfg.EndpointHealthcheckInterval, cfg.Timeouts.PaymentEnsure)
+	}
+}
+
+func TestLoad_RejectsUnknownKeys(t *testing.T) {
+	path := writeConfigFile(t, "snet.yaml", "rpc_addr: wss://x\nrpc_adr: typo\n")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "rpc_adr") {
+		t.Fatalf("Load error = %v; want the unknown key reported", err)
+	}
+
+	path = writeConfigFile(t, "snet.yaml", "rpc_addr: wss://x\nprofiles:\n  dev:\n    debugg: true\n")
+	if _, err := Load(path, WithProfile("dev")); err == nil || !strings.Contains(err.Error(), "debugg") {
+		t.Fatalf("Load error = %v; want the unknown profile key reported", err)
+	}
+}
+
+func TestFromEnv_ReportsEveryProblem(t *testing.T) {
+	t.Setenv("APP_RPC_ADDR", "")
+	t.Setenv("APP_DEBUG", "maybe")
+	t.Setenv("APP_TIMEOUTS_GRPC_STREAM", "soon")
+	t.Setenv("APP_REGISTRY_ADDR", "0x123")
+	t.Setenv("APP_ENDPOINT_POLICY", "random")
+
+	_, err := FromEnv("APP")
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("FromEnv error = %v; want ValidationErrors", err)
+	}
+	var fields []string
+	for _, e := range verrs {
+		fields = append(fields, e.Field)
+	}
+	want := []string{"debug", "timeouts.grpc_stream", "rpc_addr", "registry_addr", "endpoint_policy"}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Fatalf("fields = %v; want %v", fields, want)
+	}
+	if !strings.Contains(err.Error(), "APP_DEBUG") {
+		t.Fatalf("error %q does not name the environment variable", err)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("SNET_RPC_ADDR", "wss://rpc.example")
+	t.Setenv("SNET_NETWORK", "1")
+	t.Setenv("SNET_PRIVATE_KEY", "0xabc")
+	t.Setenv("SNET_DEBUG", "true")
+	t.Setenv("SNET_TIMEOUTS_CHAIN_READ", "2s")
+
+	cfg, err := FromEnv(DefaultEnvPrefix)
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	if cfg.RPCAddr != "wss://rpc.example" || cfg.Network != Main || cfg.PrivateKey != "0xabc" || !cfg.Debug || cfg.Timeouts.ChainRead != 2*time.Second {
+		t.Fatalf("config = %+v", cfg)
+	}
+}
+
+func TestParseNetwork(t *testing.T) {
+	for in, want := range map[string]Network{
+		"sepolia":  Sepolia,
+		"11155111": Sepolia,
+		"Main":     Main,
+		"mainnet":  Main,
+		"31337":    {ChainID: "31337"},
+	} {
+		got, err := ParseNetwork(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseNetwork(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	if _, err := ParseNetwork("goerli"); err == nil {
+		t.Fatal("ParseNetwork(goerli) succeeded; want error")
+	}
+}
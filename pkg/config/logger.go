@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// GetLogger returns the logger the SDK writes to: Logger if set, otherwise a
+// logger writing to LogHandler, otherwise zap.L(). The global zap logger
+// discards everything unless the application installed one with
+// zap.ReplaceGlobals, so the SDK is silent by default. Like GetSigner, the
+// first call must not race with others; sdk.NewSDK makes it during
+// initialization.
+func (c *Config) GetLogger() *zap.Logger {
+	switch {
+	case c.Logger != nil:
+		return c.Logger
+	case c.resolvedLogger != nil:
+		return c.resolvedLogger
+	case c.LogHandler != nil:
+		c.resolvedLogger = zap.New(slogCore{handler: c.LogHandler})
+		return c.resolvedLogger
+	default:
+		return zap.L()
+	}
+}
+
+// slogCore is a zapcore.Core that hands entries to a slog.Handler, so the
+// SDK can keep logging through zap while applications use log/slog.
+type slogCore struct {
+	handler slog.Handler
+}
+
+func (c slogCore) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(context.Background(), slogLevel(level))
+}
+
+func (c slogCore) With(fields []zapcore.Field) zapcore.Core {
+	return slogCore{handler: c.handler.WithAttrs(slogAttrs(fields))}
+}
+
+func (c slogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c slogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	record := slog.NewRecord(entry.Time, slogLevel(entry.Level), entry.Message, 0)
+	if entry.LoggerName != "" {
+		record.AddAttrs(slog.String("logger", entry.LoggerName))
+	}
+	record.AddAttrs(slogAttrs(fields)...)
+	return c.handler.Handle(context.Background(), record)
+}
+
+func (slogCore) Sync() error {
+	return nil
+}
+
+// slogLevel maps zap levels to slog levels; DPanic, Panic and Fatal are
+// reported as errors.
+func slogLevel(level zapcore.Level) slog.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// slogAttrs converts zap fields to slog attributes in order. Errors stay
+// error values; other fields are converted by zap's map encoder.
+func slogAttrs(fields []zapcore.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType {
+			attrs = append(attrs, slog.Any(f.Key, err))
+			continue
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		for key, value := range enc.Fields {
+			attrs = append(attrs, slog.Any(key, value))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestConfig_GetLogger(t *testing.T) {
+	if got := (&Config{}).GetLogger(); got != zap.L() {
+		t.Fatal("GetLogger without a logger did not return zap.L()")
+	}
+
+	core, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(core)
+	cfg := &Config{Logger: logger, LogHandler: slog.NewTextHandler(&bytes.Buffer{}, nil)}
+	cfg.GetLogger().Info("hello")
+	if logs.Len() != 1 {
+		t.Fatalf("Logger received %d entries; want it to take precedence over LogHandler", logs.Len())
+	}
+}
+
+func TestConfig_GetLogger_SlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := &Config{LogHandler: slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})}
+	log := cfg.GetLogger()
+	if log != cfg.GetLogger() {
+		t.Fatal("GetLogger built a new logger on the second call")
+	}
+
+	log.Debug("dropped")
+	log.With(zap.String("org", "snet")).Warn("channel state",
+		zap.Uint64("nonce", 7),
+		zap.Error(errors.New("daemon unavailable")))
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("handler output %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"level": "WARN",
+		"msg":   "channel state",
+		"org":   "snet",
+		"nonce": float64(7),
+		"error": "daemon unavailable",
+	}
+	for k, v := range want {
+		if record[k] != v {
+			t.Fatalf("record[%q] = %v; want %v (record %v)", k, record[k], v, record)
+		}
+	}
+}
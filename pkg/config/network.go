@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Network describes a blockchain network (chain ID and name). ChainID is used
+// for EIP-155 signing; Name is informational.
+//
+// The contract addresses are optional. Empty ones are resolved from
+// snet-ecosystem-contracts by chain ID, and the token address from the
+// MultiPartyEscrow contract; chains that snet-ecosystem-contracts does not
+// know (private chains, local devnets) must set RegistryAddr and MPEAddr.
+// Config.RegistryAddr, when set, takes precedence over RegistryAddr.
+//
+// In configuration files a network is either a mapping of these fields or a
+// single value accepted by ParseNetwork ("sepolia", "main", the name of a
+// registered network or a chain ID).
+type Network struct {
+	ChainID      string `json:"chain_id" yaml:"chain_id"`
+	Name         string `json:"network_name" yaml:"network_name"`
+	RegistryAddr string `json:"registry_addr,omitempty" yaml:"registry_addr,omitempty"`
+	MPEAddr      string `json:"mpe_addr,omitempty" yaml:"mpe_addr,omitempty"`
+	TokenAddr    string `json:"token_addr,omitempty" yaml:"token_addr,omitempty"`
+}
+
+// Sepolia is a predefined Network for Ethereum Sepolia testnet.
+var Sepolia = Network{
+	ChainID: "11155111",
+	Name:    "sepolia",
+}
+
+// Main is a predefined Network for Ethereum mainnet.
+var Main = Network{
+	ChainID: "1",
+	Name:    "main",
+}
+
+var (
+	registeredMu sync.RWMutex
+	// registered holds the networks added with RegisterNetwork, keyed by
+	// lower-case name and by chain ID.
+	registered = map[string]Network{}
+)
+
+// RegisterNetwork makes n available to ParseNetwork, and so to configuration
+// files and the SNET_NETWORK environment variable, under its name and its
+// chain ID. Registered networks take precedence over the predefined ones, so
+// Sepolia or Main can be re-registered with other contract addresses.
+// Registering a name or chain ID again replaces the earlier network.
+func RegisterNetwork(n Network) error {
+	if n.Name == "" {
+		return errors.New("register network: name is required")
+	}
+	if errs := n.validate(); len(errs) > 0 {
+		return fmt.Errorf("register network %q: %w", n.Name, errs)
+	}
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registered[strings.ToLower(n.Name)] = n
+	registered[n.ChainID] = n
+	return nil
+}
+
+// Networks returns the predefined and registered networks, sorted by name.
+func Networks() []Network {
+	byName := map[string]Network{Sepolia.Name: Sepolia, Main.Name: Main}
+	registeredMu.RLock()
+	for _, n := range registered {
+		byName[strings.ToLower(n.Name)] = n
+	}
+	registeredMu.RUnlock()
+
+	list := make([]Network, 0, len(byName))
+	for _, n := range byName {
+		list = append(list, n)
+	}
+	slices.SortFunc(list, func(a, b Network) int { return strings.Compare(a.Name, b.Name) })
+	return list
+}
+
+// ParseNetwork returns the network named by s: a registered network (by name
+// or chain ID), "sepolia", "main" (or "mainnet"), or a decimal chain ID. Chain
+// IDs of known networks return those networks; other chain IDs return a
+// Network without a name or addresses.
+func ParseNetwork(s string) (Network, error) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	registeredMu.RLock()
+	n, ok := registered[key]
+	registeredMu.RUnlock()
+	if ok {
+		return n, nil
+	}
+	switch key {
+	case Sepolia.Name, Sepolia.ChainID:
+		return Sepolia, nil
+	case Main.Name, "mainnet", Main.ChainID:
+		return Main, nil
+	}
+	if _, ok := new(big.Int).SetString(key, 10); !ok {
+		return Network{}, fmt.Errorf("unknown network %q (want sepolia, main, a registered network or a chain ID)", s)
+	}
+	return Network{ChainID: key}, nil
+}
+
+// validate checks the chain ID and the contract addresses. Field paths are
+// relative to the network.
+func (n Network) validate() ValidationErrors {
+	var errs ValidationErrors
+	if _, ok := new(big.Int).SetString(n.ChainID, 10); !ok {
+		errs.add("chain_id", fmt.Errorf("invalid chain ID %q", n.ChainID))
+	}
+	for _, a := range []struct{ field, value string }{
+		{"registry_addr", n.RegistryAddr},
+		{"mpe_addr", n.MPEAddr},
+		{"token_addr", n.TokenAddr},
+	} {
+		if a.value != "" && !common.IsHexAddress(a.value) {
+			errs.add(a.field, fmt.Errorf("invalid address %q", a.value))
+		}
+	}
+	return errs
+}
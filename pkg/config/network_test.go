@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegisterNetwork(t *testing.T) {
+	devnet := Network{
+		ChainID:      "31337",
+		Name:         "test-devnet",
+		RegistryAddr: "0x00000000000000000000000000000000000000aa",
+		MPEAddr:      "0x00000000000000000000000000000000000000bb",
+	}
+	if err := RegisterNetwork(devnet); err != nil {
+		t.Fatalf("RegisterNetwork: %v", err)
+	}
+	for _, s := range []string{"test-devnet", "Test-Devnet", "31337"} {
+		if got, err := ParseNetwork(s); err != nil || got != devnet {
+			t.Fatalf("ParseNetwork(%q) = %+v, %v; want %+v", s, got, err, devnet)
+		}
+	}
+	found := false
+	for _, n := range Networks() {
+		found = found || n == devnet
+	}
+	if !found {
+		t.Fatalf("Networks() = %+v; want test-devnet listed", Networks())
+	}
+
+	if err := RegisterNetwork(Network{ChainID: "5"}); err == nil {
+		t.Fatal("RegisterNetwork without a name succeeded")
+	}
+	err := RegisterNetwork(Network{ChainID: "x", Name: "broken", MPEAddr: "0x12"})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 2 {
+		t.Fatalf("RegisterNetwork(broken) = %v; want chain ID and MPE address errors", err)
+	}
+}
+
+func TestLoad_CustomNetwork(t *testing.T) {
+	if err := RegisterNetwork(Network{ChainID: "1337", Name: "test-private", MPEAddr: "0x00000000000000000000000000000000000000bb"}); err != nil {
+		t.Fatalf("RegisterNetwork: %v", err)
+	}
+	path := writeConfigFile(t, "snet.yaml", `
+rpc_addr: http://localhost:8545
+network: test-private
+profiles:
+  inline:
+    network:
+      chain_id: "999"
+      registry_addr: "0x00000000000000000000000000000000000000aa"
+      mpe_addr: not-an-address
+`)
+
+	t.Setenv("SNET_NETWORK_TOKEN_ADDR", "0x00000000000000000000000000000000000000cc")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Network.Name != "test-private" || cfg.Network.MPEAddr == "" || cfg.Network.TokenAddr != "0x00000000000000000000000000000000000000cc" {
+		t.Fatalf("network = %+v; want the registered network with the env token override", cfg.Network)
+	}
+
+	_, err = Load(path, WithProfile("inline"))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "network.mpe_addr" {
+		t.Fatalf("Load inline = %v; want a network.mpe_addr error", err)
+	}
+}
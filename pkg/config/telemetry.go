@@ -0,0 +1,20 @@
+package config
+
+import "github.com/shamank/snet-sdk-go/pkg/telemetry"
+
+// GetTelemetry returns the telemetry the SDK traces and measures with, built
+// from TracerProvider and MeterProvider. Without either it returns
+// telemetry.Global(), which discards everything until the application installs
+// providers with otel.SetTracerProvider and otel.SetMeterProvider. Like
+// GetLogger, the first call must not race with others.
+func (c *Config) GetTelemetry() *telemetry.Telemetry {
+	switch {
+	case c.resolvedTelemetry != nil:
+		return c.resolvedTelemetry
+	case c.TracerProvider == nil && c.MeterProvider == nil:
+		return telemetry.Global()
+	default:
+		c.resolvedTelemetry = telemetry.New(c.TracerProvider, c.MeterProvider)
+		return c.resolvedTelemetry
+	}
+}
@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/bufbuild/protocompile/linker"
+	"github.com/shamank/snet-sdk-go/pkg/storage"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// WithDescriptorCache makes NewClient and NewPool keep the descriptors they
+// compile in c, keyed by the digest of the proto sources, and load them from
+// there instead of compiling the same sources again.
+func WithDescriptorCache(c *storage.Cache) ClientOption {
+	return func(o *clientOptions) {
+		o.cache = c
+	}
+}
+
+// compileProtos is like getProtoDescriptors but goes through cache when it
+// is not nil. Compiling needs no network, so it also works in offline mode;
+// the result is then not stored. Cache failures are logged to log.
+func compileProtos(protoFiles map[string]string, cache *storage.Cache, log *zap.Logger) (linker.Files, error) {
+	if cache == nil {
+		return getProtoDescriptors(protoFiles)
+	}
+
+	protoFiles["training.proto"] = TrainingProtoEmbedded
+	names := slices.Sorted(maps.Keys(protoFiles))
+	key := descriptorCacheKey(names, protoFiles)
+	if data, _, ok := cache.Load(key); ok {
+		files, err := decodeDescriptors(data, names)
+		if err == nil {
+			return files, nil
+		}
+		log.Warn("ignoring unreadable cached descriptors", zap.Error(err))
+	}
+
+	files, err := getProtoDescriptors(protoFiles)
+	if err != nil {
+		return nil, err
+	}
+	if !cache.Offline() {
+		data, err := encodeDescriptors(files)
+		if err == nil {
+			err = cache.Store(key, data)
+		}
+		if err != nil {
+			log.Warn("failed to cache compiled descriptors", zap.Error(err))
+		}
+	}
+	return files, nil
+}
+
+// descriptorCacheKey returns the cache key of the sources: a digest of every
+// file name and content, in name order.
+func descriptorCacheKey(names []string, protoFiles map[string]string) string {
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(name), name, len(protoFiles[name]), protoFiles[name])
+	}
+	return "descriptors/" + hex.EncodeToString(h.Sum(nil))
+}
+
+// encodeDescriptors serializes files and everything they import as a
+// FileDescriptorSet, dependencies first.
+func encodeDescriptors(files linker.Files) ([]byte, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, f := range files {
+		add(f)
+	}
+	return proto.Marshal(set)
+}
+
+// decodeDescriptors rebuilds the files named names from a FileDescriptorSet
+// written by encodeDescriptors.
+func decodeDescriptors(data []byte, names []string) (linker.Files, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	registry, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, err
+	}
+	files := make(linker.Files, 0, len(names))
+	for _, name := range names {
+		fd, err := registry.FindFileByPath(name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := linker.NewFileRecursive(fd)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/shamank/snet-sdk-go/pkg/storage"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCompileProtos_Cache(t *testing.T) {
+	sources := func() map[string]string {
+		return map[string]string{
+			"types.proto": `
+				syntax = "proto3";
+				package demo;
+				import "google/protobuf/timestamp.proto";
+				message HelloRequest { string name = 1; google.protobuf.Timestamp at = 2; }
+				message HelloReply { string message = 1; }
+			`,
+			"demo.proto": `
+				syntax = "proto3";
+				package demo;
+				import "types.proto";
+				service Greeter { rpc SayHello(HelloRequest) returns (HelloReply) {} }
+			`,
+		}
+	}
+
+	dir := t.TempDir()
+	cache, err := storage.NewCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := compileProtos(sources(), cache, zap.NewNop()); err != nil {
+		t.Fatalf("compileProtos returned error: %v", err)
+	}
+	protoFiles := sources()
+	protoFiles["training.proto"] = TrainingProtoEmbedded
+	names := []string{"demo.proto", "training.proto", "types.proto"}
+	data, _, ok := cache.Load(descriptorCacheKey(names, protoFiles))
+	if !ok {
+		t.Fatal("compiled descriptors not cached")
+	}
+	fds, err := decodeDescriptors(data, names)
+	if err != nil {
+		t.Fatalf("decodeDescriptors returned error: %v", err)
+	}
+	_, method, err := FindMethod(fds, "SayHello")
+	if err != nil {
+		t.Fatalf("FindMethod returned error: %v", err)
+	}
+	if got := method.Input().Fields().ByName("at").Message().FullName(); got != "google.protobuf.Timestamp" {
+		t.Fatalf("unexpected imported field type: %s", got)
+	}
+
+	// Compiling needs no network, so an offline cache still compiles new
+	// sources.
+	offline, err := storage.NewCache(dir, storage.WithOfflineMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := sources()
+	changed["demo.proto"] += "\n// changed\n"
+	if _, err := compileProtos(changed, offline, zap.NewNop()); err != nil {
+		t.Fatalf("offline compileProtos of new sources returned error: %v", err)
+	}
+
+	// Unreadable cached descriptors are compiled again and reported to the
+	// given logger.
+	key := descriptorCacheKey(names, protoFiles)
+	if err := cache.Store(key, []byte("not descriptors")); err != nil {
+		t.Fatal(err)
+	}
+	core, logs := observer.New(zap.WarnLevel)
+	if _, err := compileProtos(sources(), cache, zap.New(core)); err != nil {
+		t.Fatalf("compileProtos over unreadable cache returned error: %v", err)
+	}
+	if logs.FilterMessage("ignoring unreadable cached descriptors").Len() != 1 {
+		t.Fatalf("logged %v; want the unreadable cache warning", logs.All())
+	}
+}
@@ -43,6 +43,56 @@
 //	request := &MyRequest{Field: "value"}
 //	response, err := client.CallWithProto(ctx, "MethodName", request)
 //
+// # Streaming Calls
+//
+// Methods declared with "stream" are invoked through a Stream. Server-streaming
+// calls can send their single request up front and iterate over responses:
+//
+//	stream, err := client.StreamWithJSON(ctx, "Watch", []byte(`{"id": 1}`))
+//	if err != nil {
+//		return err
+//	}
+//	for msg, err := range stream.JSON() {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(string(msg))
+//	}
+//
+// Client-streaming and bidirectional calls use NewStream with Send*/Recv*:
+//
+//	stream, err := client.NewStream(ctx, "Upload", grpc.WithStreamTimeout(time.Minute))
+//	defer stream.Close()
+//	_ = stream.SendMap(map[string]any{"chunk": "..."})
+//	_ = stream.CloseSend()
+//	resp, err := stream.RecvJSON()
+//
+// # Multiple Endpoints
+//
+// A Pool keeps one Client per endpoint of a service group, compiles the proto
+// files once and fails over when an endpoint returns codes.Unavailable:
+//
+//	pool, err := grpc.NewPool(group.Endpoints, protoFiles, grpc.PolicyRoundRobin)
+//	defer pool.Close()
+//	err = pool.Do(ctx, func(c *grpc.Client) error {
+//		out, err = c.CallWithJSON(ctx, "Process", input)
+//		return err
+//	})
+//
+// Endpoints that fail are skipped until MarkHealthy or a successful Probe
+// restores them. Generated stubs fail over the same way when they are built
+// on pool.Client().Conn(), or on the pool itself, which implements
+// grpc.ClientConnInterface. Pass WithLogger to choose where endpoint health
+// changes and descriptor cache failures are logged.
+//
+// # Tracing
+//
+// Every RPC on a connection made by NewClient or NewPool, including calls of
+// generated stubs on Client.GRPC, gets an OpenTelemetry client span named
+// "<package>.<Service>/<Method>". Pass WithTelemetry to choose the providers:
+//
+//	client := grpc.NewClient(endpoint, protoFiles, grpc.WithTelemetry(telemetry.New(tp, mp)))
+//
 // # Proto File Management
 //
 // Access compiled proto descriptors:
@@ -56,6 +106,11 @@
 //   - Used for runtime method resolution
 //   - Support for imports and dependencies
 //
+// Compile and ListMethods work on proto sources without a connection.
+// PublishProtos (or PublishProtoDir for a directory) compiles protos, packs
+// them into a tar.gz archive, uploads it through a storage.Uploader and
+// returns service metadata whose ServiceApiSource points at the archive.
+//
 // # Transport Security
 //
 // Transport is determined by endpoint scheme:
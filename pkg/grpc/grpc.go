@@ -12,15 +12,29 @@ import (
 	"time"
 
 	"github.com/bufbuild/protocompile/linker"
-	"go.uber.org/zap"
 	oggrpc "google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/dynamicpb"
 )
 
+// jsonUnmarshalOptions decodes JSON request bodies into dynamic input messages,
+// discarding unknown fields and allowing partial messages.
+var jsonUnmarshalOptions = protojson.UnmarshalOptions{
+	AllowPartial:   true,
+	DiscardUnknown: true,
+}
+
+// jsonMarshalOptions encodes dynamic output messages to JSON with proto field
+// names and unpopulated fields emitted.
+var jsonMarshalOptions = protojson.MarshalOptions{
+	EmitUnpopulated: true,
+	UseProtoNames:   true,
+}
+
 // Client is a dynamic gRPC client that holds a connected gRPC ClientConn and a
 // set of compiled file descriptors used to locate services/methods at runtime.
 type Client struct {
@@ -28,6 +42,9 @@ type Client struct {
 	GRPC *oggrpc.ClientConn `json:"-"`
 	// ProtoFiles are the compiled descriptors of the provided .proto sources.
 	ProtoFiles linker.Files `json:"-"`
+
+	// conn overrides GRPC in Conn; set for clients handed out by a Pool.
+	conn oggrpc.ClientConnInterface
 }
 
 // NewClient creates a dynamic gRPC client for the given endpoint and set of
@@ -39,27 +56,48 @@ type Client struct {
 //
 // The provided proto files are compiled at runtime; if compilation fails the
 // connection is closed and nil is returned. The returned client proactively
-// starts connecting (ClientConn.Connect()).
-func NewClient(endpoint string, protoFiles map[string]string) *Client {
-	addr, creds := grpcCredsFromEndpoint(endpoint)
-	conn, err := oggrpc.NewClient(addr, creds)
+// starts connecting (ClientConn.Connect()). Every RPC on the connection is
+// traced with OpenTelemetry (see WithTelemetry).
+func NewClient(endpoint string, protoFiles map[string]string, opts ...ClientOption) *Client {
+	o := resolveClientOptions(opts)
+	descriptors, err := compileProtos(protoFiles, o.cache, o.log)
 	if err != nil {
-		zap.L().Error(err.Error())
 		return nil
 	}
 
-	descriptors, err := getProtoDescriptors(protoFiles)
+	client, err := newClient(endpoint, descriptors, o)
 	if err != nil {
-		_ = conn.Close()
+		o.log.Error(err.Error())
 		return nil
 	}
+	return client
+}
+
+// newClient connects to endpoint and binds the already compiled descriptors,
+// so several connections can share one compilation.
+func newClient(endpoint string, descriptors linker.Files, o clientOptions) (*Client, error) {
+	addr, creds := grpcCredsFromEndpoint(endpoint)
+	conn, err := oggrpc.NewClient(addr, append(telemetryDialOptions(o.tel, addr), creds)...)
+	if err != nil {
+		return nil, err
+	}
 
 	conn.Connect()
 
 	return &Client{
 		GRPC:       conn,
 		ProtoFiles: descriptors,
+	}, nil
+}
+
+// Conn returns the connection generated stubs should be built on. For a
+// client returned by Pool.Client every RPC made through it fails over between
+// the endpoints of the pool like Pool.Do; otherwise it is GRPC.
+func (c *Client) Conn() oggrpc.ClientConnInterface {
+	if c.conn != nil {
+		return c.conn
 	}
+	return c.GRPC
 }
 
 // Close shuts down the underlying gRPC connection.
@@ -104,7 +142,7 @@ func (c *Client) CallWithProto(ctx context.Context, method string, req proto.Mes
 		return nil, err
 	}
 	out := dynamicpb.NewMessage(methodDesc.Output())
-	err = c.GRPC.Invoke(ctx, "/"+string(fd.Package())+"."+string(methodDesc.Parent().Name())+"/"+method, req, out)
+	err = c.GRPC.Invoke(ctx, fullMethodName(fd, methodDesc), req, out)
 	if err != nil {
 		return nil, err
 	}
@@ -125,24 +163,17 @@ func (c *Client) CallWithJSON(ctx context.Context, method string, body []byte) (
 	in := dynamicpb.NewMessage(methodDesc.Input())
 	out := dynamicpb.NewMessage(methodDesc.Output())
 
-	err = protojson.UnmarshalOptions{
-		AllowPartial:   true,
-		DiscardUnknown: true,
-	}.Unmarshal(body, in)
+	err = jsonUnmarshalOptions.Unmarshal(body, in)
 	if err != nil {
 		return nil, err
 	}
 
-	fullMethod := "/" + string(fd.Package()) + "." + string(methodDesc.Parent().Name()) + "/" + method
-	err = c.GRPC.Invoke(ctx, fullMethod, in, out)
+	err = c.GRPC.Invoke(ctx, fullMethodName(fd, methodDesc), in, out)
 	if err != nil {
 		return nil, err
 	}
 
-	jsonBytes, err := protojson.MarshalOptions{
-		EmitUnpopulated: true,
-		UseProtoNames:   true,
-	}.Marshal(out)
+	jsonBytes, err := jsonMarshalOptions.Marshal(out)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +181,23 @@ func (c *Client) CallWithJSON(ctx context.Context, method string, body []byte) (
 	return jsonBytes, nil
 }
 
+// MethodPath resolves a simple method name to its fully-qualified gRPC path
+// "/<package>.<Service>/<Method>", as used in the service metadata's
+// dynamic pricing mapping and per-method pricing.
+func (c *Client) MethodPath(method string) (string, error) {
+	fd, methodDesc, err := FindMethod(c.ProtoFiles, method)
+	if err != nil {
+		return "", err
+	}
+	return fullMethodName(fd, methodDesc), nil
+}
+
+// fullMethodName builds the fully-qualified gRPC method path
+// "/<package>.<Service>/<Method>" for the given descriptors.
+func fullMethodName(fd protoreflect.FileDescriptor, methodDesc protoreflect.MethodDescriptor) string {
+	return "/" + string(fd.Package()) + "." + string(methodDesc.Parent().Name()) + "/" + string(methodDesc.Name())
+}
+
 // grpcCredsFromEndpoint derives a dial address and dial option from an endpoint URL.
 // "https://" enables TLS; "http://" and bare addresses use insecure credentials.
 func grpcCredsFromEndpoint(endpoint string) (string, oggrpc.DialOption) {
@@ -0,0 +1,277 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	oggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Policy selects which endpoint of a Pool serves the next call.
+type Policy int
+
+const (
+	// PolicyHealthFirst sends every call to the first healthy endpoint in the
+	// published order and moves on only when that endpoint is marked bad.
+	PolicyHealthFirst Policy = iota
+	// PolicyRoundRobin rotates calls across all healthy endpoints.
+	PolicyRoundRobin
+)
+
+// ParsePolicy converts a policy name ("health-first", "round-robin") to a
+// Policy. An empty name selects PolicyHealthFirst.
+func ParsePolicy(name string) (Policy, error) {
+	switch name {
+	case "", "health-first":
+		return PolicyHealthFirst, nil
+	case "round-robin":
+		return PolicyRoundRobin, nil
+	default:
+		return 0, fmt.Errorf("unknown endpoint policy %q (want health-first or round-robin)", name)
+	}
+}
+
+// String returns the policy name accepted by ParsePolicy.
+func (p Policy) String() string {
+	if p == PolicyRoundRobin {
+		return "round-robin"
+	}
+	return "health-first"
+}
+
+// EndpointStatus is a point-in-time view of one endpoint of a Pool.
+type EndpointStatus struct {
+	URL         string    // Endpoint as published in the service group
+	Healthy     bool      // Whether the endpoint is currently eligible for calls
+	LastError   error     // Error that marked the endpoint bad, if any
+	LastChecked time.Time // Time of the last probe or state change
+}
+
+// poolEndpoint is a single connection of a Pool together with its health.
+type poolEndpoint struct {
+	url     string
+	client  *Client
+	healthy bool
+	lastErr error
+	checked time.Time
+}
+
+// Pool holds one dynamic Client per endpoint of a service group and spreads
+// calls over them according to a Policy. Endpoints that fail with
+// codes.Unavailable (or that a probe reports as down) are skipped until they
+// are marked healthy again; when every endpoint is bad, all of them are tried
+// as a last resort. A Pool is safe for concurrent use.
+type Pool struct {
+	mu        sync.Mutex
+	endpoints []*poolEndpoint
+	policy    Policy
+	next      int
+	log       *zap.Logger
+}
+
+// NewPool compiles protoFiles once and connects to every endpoint. Endpoint
+// URLs follow the same scheme rules as NewClient and opts apply to every
+// connection. All endpoints start healthy.
+func NewPool(endpoints []string, protoFiles map[string]string, policy Policy, opts ...ClientOption) (*Pool, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("at least one endpoint is required")
+	}
+
+	o := resolveClientOptions(opts)
+	descriptors, err := compileProtos(protoFiles, o.cache, o.log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile proto files: %w", err)
+	}
+
+	p := &Pool{policy: policy, log: o.log}
+	for _, url := range endpoints {
+		client, err := newClient(url, descriptors, o)
+		if err != nil {
+			_ = p.Close()
+			return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+		}
+		p.endpoints = append(p.endpoints, &poolEndpoint{url: url, client: client, healthy: true, checked: time.Now()})
+	}
+	return p, nil
+}
+
+// Client returns a client for long-lived consumers such as payment
+// strategies. Its GRPC is the connection of the endpoint that would serve the
+// next call (the round-robin cursor is not advanced), while RPCs made through
+// Client.Conn go through the pool and fail over like Do.
+func (p *Pool) Client() *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c := *p.orderLocked(false)[0].client
+	c.conn = p
+	return &c
+}
+
+// Invoke implements grpc.ClientConnInterface: the unary RPC is sent to the
+// selected endpoint and retried on the next one as described for Do.
+func (p *Pool) Invoke(ctx context.Context, method string, args, reply any, opts ...oggrpc.CallOption) error {
+	return p.Do(ctx, func(c *Client) error {
+		return c.GRPC.Invoke(ctx, method, args, reply, opts...)
+	})
+}
+
+// NewStream implements grpc.ClientConnInterface. Only opening the stream
+// fails over; errors on an open stream are returned to the caller.
+func (p *Pool) NewStream(ctx context.Context, desc *oggrpc.StreamDesc, method string, opts ...oggrpc.CallOption) (oggrpc.ClientStream, error) {
+	var stream oggrpc.ClientStream
+	err := p.Do(ctx, func(c *Client) error {
+		var err error
+		stream, err = c.GRPC.NewStream(ctx, desc, method, opts...)
+		return err
+	})
+	return stream, err
+}
+
+// Do runs call against the selected endpoint. If the call fails with
+// codes.Unavailable, the endpoint is marked bad and call is retried on the
+// next candidate until one succeeds, a different error is returned, every
+// endpoint has been tried or ctx is done. call may be invoked more than once
+// and must therefore not consume one-shot state.
+func (p *Pool) Do(ctx context.Context, call func(*Client) error) error {
+	p.mu.Lock()
+	candidates := p.orderLocked(true)
+	p.mu.Unlock()
+
+	var err error
+	for _, ep := range candidates {
+		err = call(ep.client)
+		if status.Code(err) != codes.Unavailable {
+			if err == nil && !p.isHealthy(ep) {
+				// A last-resort endpoint answered; it is back.
+				p.MarkHealthy(ep.url)
+			}
+			return err
+		}
+		p.MarkUnhealthy(ep.url, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return err
+}
+
+// isHealthy reports the current health of ep.
+func (p *Pool) isHealthy(ep *poolEndpoint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return ep.healthy
+}
+
+// MarkUnhealthy excludes url from selection until MarkHealthy is called for it.
+func (p *Pool) MarkUnhealthy(url string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ep := range p.endpoints {
+		if ep.url == url {
+			if ep.healthy {
+				p.log.Warn("endpoint marked unhealthy", zap.String("endpoint", url), zap.Error(err))
+			}
+			ep.healthy = false
+			ep.lastErr = err
+			ep.checked = time.Now()
+		}
+	}
+}
+
+// MarkHealthy makes url eligible for selection again.
+func (p *Pool) MarkHealthy(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ep := range p.endpoints {
+		if ep.url == url {
+			if !ep.healthy {
+				p.log.Info("endpoint restored", zap.String("endpoint", url))
+			}
+			ep.healthy = true
+			ep.lastErr = nil
+			ep.checked = time.Now()
+		}
+	}
+}
+
+// Probe runs probe concurrently against every endpoint and marks each one
+// healthy or unhealthy depending on the result.
+func (p *Pool) Probe(ctx context.Context, probe func(ctx context.Context, url string, client *Client) error) {
+	p.mu.Lock()
+	endpoints := append([]*poolEndpoint(nil), p.endpoints...)
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, ep := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := probe(ctx, ep.url, ep.client); err != nil {
+				p.MarkUnhealthy(ep.url, err)
+				return
+			}
+			p.MarkHealthy(ep.url)
+		}()
+	}
+	wg.Wait()
+}
+
+// Status returns a snapshot of every endpoint in published order.
+func (p *Pool) Status() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		out = append(out, EndpointStatus{
+			URL:         ep.url,
+			Healthy:     ep.healthy,
+			LastError:   ep.lastErr,
+			LastChecked: ep.checked,
+		})
+	}
+	return out
+}
+
+// Close shuts down every connection of the pool.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for _, ep := range p.endpoints {
+		if err := ep.client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// orderLocked returns the endpoints in the order they should be tried:
+// healthy ones first (rotated for round-robin), then unhealthy ones as a last
+// resort. advance moves the round-robin cursor. p.mu must be held.
+func (p *Pool) orderLocked(advance bool) []*poolEndpoint {
+	var healthy, unhealthy []*poolEndpoint
+	for _, ep := range p.endpoints {
+		if ep.healthy {
+			healthy = append(healthy, ep)
+		} else {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+
+	if p.policy == PolicyRoundRobin && len(healthy) > 1 {
+		start := p.next % len(healthy)
+		healthy = slices.Concat(healthy[start:], healthy[:start])
+		if advance {
+			p.next++
+		}
+	}
+
+	return append(healthy, unhealthy...)
+}
@@ -0,0 +1,200 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// startGetServer serves test.Counter/Get from counterProto and answers every
+// request with {"value": tag}, so tests can tell endpoints apart.
+func startGetServer(t *testing.T, tag int32) string {
+	t.Helper()
+	files, err := getProtoDescriptors(map[string]string{"counter.proto": counterProto})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	_, get, err := FindMethod(files, "Get")
+	if err != nil {
+		t.Fatalf("find method: %v", err)
+	}
+	numDesc := get.Input()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		if strings.Contains(err.Error(), "operation not permitted") {
+			t.Skip("network operations not permitted in sandbox")
+		}
+		t.Fatalf("listen: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Counter",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Get",
+			Handler: func(_ any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				in := dynamicpb.NewMessage(numDesc)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				out := dynamicpb.NewMessage(numDesc)
+				out.Set(numDesc.Fields().ByName("value"), protoreflect.ValueOfInt32(tag))
+				return out, nil
+			},
+		}},
+	}, struct{}{})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() {
+		srv.Stop()
+		_ = lis.Close()
+	})
+	return lis.Addr().String()
+}
+
+// deadEndpoint returns an address nothing listens on.
+func deadEndpoint(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		if strings.Contains(err.Error(), "operation not permitted") {
+			t.Skip("network operations not permitted in sandbox")
+		}
+		t.Fatalf("listen: %v", err)
+	}
+	addr := lis.Addr().String()
+	_ = lis.Close()
+	return addr
+}
+
+// callGet performs one Get through the pool and returns the responding tag.
+func callGet(t *testing.T, p *Pool) float64 {
+	t.Helper()
+	var resp map[string]any
+	err := p.Do(context.Background(), func(c *Client) error {
+		var err error
+		resp, err = c.CallWithMap(context.Background(), "Get", map[string]any{"value": 0})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	return resp["value"].(float64)
+}
+
+func TestPoolFailsOverOnUnavailable(t *testing.T) {
+	dead := deadEndpoint(t)
+	live := startGetServer(t, 7)
+
+	p, err := NewPool([]string{dead, live}, map[string]string{"counter.proto": counterProto}, PolicyHealthFirst)
+	if err != nil {
+		t.Fatalf("NewPool error: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	if got := callGet(t, p); got != 7 {
+		t.Fatalf("expected live endpoint to answer, got %v", got)
+	}
+	status := p.Status()
+	if status[0].Healthy || status[0].LastError == nil {
+		t.Fatalf("expected dead endpoint to be marked unhealthy, got %+v", status[0])
+	}
+	if p.Client().GRPC != p.endpoints[1].client.GRPC {
+		t.Fatal("expected Client to prefer the healthy endpoint")
+	}
+}
+
+func TestPoolClientConnFailsOver(t *testing.T) {
+	dead := deadEndpoint(t)
+	live := startGetServer(t, 7)
+
+	p, err := NewPool([]string{dead, live}, map[string]string{"counter.proto": counterProto}, PolicyHealthFirst)
+	if err != nil {
+		t.Fatalf("NewPool error: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	// Taken while the dead endpoint is still preferred, as a payment
+	// strategy would when it is created.
+	client := p.Client()
+	if client.GRPC != p.endpoints[0].client.GRPC {
+		t.Fatal("expected Client to start on the first endpoint")
+	}
+
+	_, get, err := FindMethod(client.ProtoFiles, "Get")
+	if err != nil {
+		t.Fatalf("find method: %v", err)
+	}
+	out := dynamicpb.NewMessage(get.Output())
+	if err := client.Conn().Invoke(context.Background(), "/test.Counter/Get", dynamicpb.NewMessage(get.Input()), out); err != nil {
+		t.Fatalf("Invoke through Conn: %v", err)
+	}
+	if got := out.Get(get.Output().Fields().ByName("value")).Int(); got != 7 {
+		t.Fatalf("expected live endpoint to answer, got %v", got)
+	}
+	if p.Status()[0].Healthy {
+		t.Fatal("expected dead endpoint to be marked unhealthy")
+	}
+}
+
+func TestPoolRoundRobin(t *testing.T) {
+	a := startGetServer(t, 1)
+	b := startGetServer(t, 2)
+
+	p, err := NewPool([]string{a, b}, map[string]string{"counter.proto": counterProto}, PolicyRoundRobin)
+	if err != nil {
+		t.Fatalf("NewPool error: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	var got []float64
+	for range 4 {
+		got = append(got, callGet(t, p))
+	}
+	if got[0] == got[1] || got[0] != got[2] || got[1] != got[3] {
+		t.Fatalf("expected calls to alternate between endpoints, got %v", got)
+	}
+}
+
+func TestPoolProbeRestoresEndpoints(t *testing.T) {
+	p, err := NewPool([]string{"127.0.0.1:1", "127.0.0.1:2"}, map[string]string{"counter.proto": counterProto}, PolicyHealthFirst)
+	if err != nil {
+		t.Fatalf("NewPool error: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	p.MarkUnhealthy("127.0.0.1:1", errors.New("down"))
+	p.Probe(context.Background(), func(_ context.Context, url string, _ *Client) error {
+		if url == "127.0.0.1:2" {
+			return errors.New("probe failed")
+		}
+		return nil
+	})
+
+	status := p.Status()
+	if !status[0].Healthy || status[1].Healthy {
+		t.Fatalf("unexpected health after probe: %+v", status)
+	}
+	if p.Client().GRPC != p.endpoints[0].client.GRPC {
+		t.Fatal("expected restored first endpoint to be preferred")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for name, want := range map[string]Policy{"": PolicyHealthFirst, "health-first": PolicyHealthFirst, "round-robin": PolicyRoundRobin} {
+		got, err := ParsePolicy(name)
+		if err != nil || got != want {
+			t.Fatalf("ParsePolicy(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := ParsePolicy("random"); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+}
@@ -9,9 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/bufbuild/protocompile"
 	"github.com/bufbuild/protocompile/linker"
+	"github.com/bufbuild/protocompile/parser"
+	"github.com/bufbuild/protocompile/reporter"
 	"github.com/shamank/snet-sdk-go/pkg/model"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -140,6 +143,47 @@ func FindMethod(files linker.Files, methodName string) (protoreflect.FileDescrip
 	return nil, nil, fmt.Errorf("method %s not found in provided proto files", methodName)
 }
 
+// ListMethods returns the full paths ("/package.Service/Method") of the
+// methods declared in the given proto sources, sorted. The files are only
+// parsed, not linked, so sources with imports that cannot be resolved are
+// still listed.
+func ListMethods(protoFiles map[string]string) ([]string, error) {
+	var methods []string
+	for name, src := range protoFiles {
+		if !strings.HasSuffix(name, ".proto") {
+			continue
+		}
+		handler := reporter.NewHandler(nil)
+		file, err := parser.Parse(name, strings.NewReader(src), handler)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		result, err := parser.ResultFromAST(file, false, handler)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		fd := result.FileDescriptorProto()
+		prefix := fd.GetPackage()
+		if prefix != "" {
+			prefix += "."
+		}
+		for _, service := range fd.GetService() {
+			for _, method := range service.GetMethod() {
+				methods = append(methods, "/"+prefix+service.GetName()+"/"+method.GetName())
+			}
+		}
+	}
+	slices.Sort(methods)
+	return methods, nil
+}
+
+// Compile compiles proto sources the way NewClient does, without
+// connecting anywhere, and returns the linked files. protoFiles is not
+// modified.
+func Compile(protoFiles map[string]string) (linker.Files, error) {
+	return getProtoDescriptors(maps.Clone(protoFiles))
+}
+
 // TrainingProtoEmbedded contains the embedded text content of training.proto,
 // which is compiled alongside user-provided proto sources at runtime.
 //
@@ -1,6 +1,7 @@
 package grpc
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -34,6 +35,14 @@ func TestGetProtoDescriptorsAndFindMethod(t *testing.T) {
 	if string(method.Parent().Name()) != "Greeter" {
 		t.Fatalf("unexpected service name: %s", method.Parent().Name())
 	}
+
+	path, err := (&Client{ProtoFiles: fds}).MethodPath("SayHello")
+	if err != nil {
+		t.Fatalf("MethodPath returned error: %v", err)
+	}
+	if path != "/demo.Greeter/SayHello" {
+		t.Fatalf("unexpected method path: %s", path)
+	}
 }
 
 func TestFindMethod_NotFound(t *testing.T) {
@@ -60,3 +69,35 @@ func TestGetProtoDescriptors_InvalidSource(t *testing.T) {
 		t.Fatal("expected compilation error for invalid proto")
 	}
 }
+
+func TestListMethods(t *testing.T) {
+	files := map[string]string{
+		"demo.proto": `
+			syntax = "proto3";
+			package demo.v1;
+			import "missing.proto";
+			service Greeter {
+				rpc SayHello(missing.Req) returns (missing.Resp) {}
+				rpc SayBye(missing.Req) returns (missing.Resp) {}
+			}
+		`,
+		"plain.proto": `
+			syntax = "proto3";
+			service Echo { rpc Echo(Msg) returns (Msg) {} }
+			message Msg {}
+		`,
+		"README.md": "not a proto",
+	}
+	methods, err := ListMethods(files)
+	if err != nil {
+		t.Fatalf("ListMethods returned error: %v", err)
+	}
+	want := []string{"/Echo/Echo", "/demo.v1.Greeter/SayBye", "/demo.v1.Greeter/SayHello"}
+	if fmt.Sprint(methods) != fmt.Sprint(want) {
+		t.Fatalf("ListMethods = %v; want %v", methods, want)
+	}
+
+	if _, err := ListMethods(map[string]string{"bad.proto": "service {"}); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
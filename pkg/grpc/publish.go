@@ -0,0 +1,83 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/storage"
+)
+
+// ReadProtoDir reads the .proto files under dir, keyed by their slash-separated
+// path relative to dir, the way they are named in a proto archive.
+func ReadProtoDir(dir string) (map[string]string, error) {
+	protoFiles := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".proto") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		protoFiles[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(protoFiles) == 0 {
+		return nil, fmt.Errorf("no .proto files in %s", dir)
+	}
+	return protoFiles, nil
+}
+
+// PublishProtos compiles protoFiles to make sure they are valid, packs them
+// into the tar.gz archive storage.ParseProtoFiles reads, uploads it through
+// st and returns a copy of metadata whose ServiceApiSource points at the
+// archive and whose ProtoFiles are protoFiles. st must implement
+// storage.Uploader. metadata is not modified.
+func PublishProtos(ctx context.Context, st storage.Storage, metadata *model.ServiceMetadata, protoFiles map[string]string) (*model.ServiceMetadata, error) {
+	if len(protoFiles) == 0 {
+		return nil, errors.New("no proto files to publish")
+	}
+	if _, err := Compile(protoFiles); err != nil {
+		return nil, fmt.Errorf("failed to compile protos: %w", err)
+	}
+	archive, err := storage.PackProtoFiles(protoFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack protos: %w", err)
+	}
+	uploader, ok := st.(storage.Uploader)
+	if !ok {
+		return nil, storage.ErrUploadNotSupported
+	}
+	uri, err := uploader.Upload(ctx, archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload protos: %w", err)
+	}
+
+	published := *metadata
+	published.ServiceApiSource = uri
+	published.ProtoFiles = maps.Clone(protoFiles)
+	return &published, nil
+}
+
+// PublishProtoDir is like PublishProtos with the .proto files under dir.
+func PublishProtoDir(ctx context.Context, st storage.Storage, metadata *model.ServiceMetadata, dir string) (*model.ServiceMetadata, error) {
+	protoFiles, err := ReadProtoDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return PublishProtos(ctx, st, metadata, protoFiles)
+}
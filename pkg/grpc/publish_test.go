@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/storage"
+)
+
+// uploadStorage keeps the last uploaded file.
+type uploadStorage struct {
+	uploaded []byte
+}
+
+func (s *uploadStorage) ReadFile(context.Context, string) ([]byte, error) {
+	return s.uploaded, nil
+}
+
+func (s *uploadStorage) UploadJSON(context.Context, interface{}) (string, error) {
+	return "", errors.New("unexpected JSON upload")
+}
+
+func (s *uploadStorage) Upload(_ context.Context, data []byte) (string, error) {
+	s.uploaded = data
+	return "ipfs://QmProtos", nil
+}
+
+// readOnlyStorage is a storage.Storage without Upload.
+type readOnlyStorage struct{ storage.Storage }
+
+func TestPublishProtoDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"calc.proto":       "syntax = \"proto3\";\npackage calc;\nimport \"common/num.proto\";\nservice Calc { rpc Add(common.Num) returns (common.Num); }\n",
+		"common/num.proto": "syntax = \"proto3\";\npackage common;\nmessage Num { int32 value = 1; }\n",
+		"common/README.md": "not a proto",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	delete(files, "common/README.md")
+
+	st := &uploadStorage{}
+	metadata := &model.ServiceMetadata{DisplayName: "Calc"}
+	published, err := PublishProtoDir(context.Background(), st, metadata, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published.ServiceApiSource != "ipfs://QmProtos" || metadata.ServiceApiSource != "" {
+		t.Fatalf("ServiceApiSource = %q, original %q", published.ServiceApiSource, metadata.ServiceApiSource)
+	}
+	if !maps.Equal(published.ProtoFiles, files) {
+		t.Fatalf("ProtoFiles = %v", published.ProtoFiles)
+	}
+	unpacked, err := storage.ParseProtoFiles(st.uploaded)
+	if err != nil || !maps.Equal(unpacked, files) {
+		t.Fatalf("uploaded archive = %v, %v", unpacked, err)
+	}
+
+	if _, err := PublishProtos(context.Background(), readOnlyStorage{st}, metadata, files); !errors.Is(err, storage.ErrUploadNotSupported) {
+		t.Fatalf("read-only storage: err = %v; want %v", err, storage.ErrUploadNotSupported)
+	}
+	st.uploaded = nil
+	broken := map[string]string{"calc.proto": "syntax = \"proto3\";\nservice Calc {"}
+	if _, err := PublishProtos(context.Background(), st, metadata, broken); err == nil || st.uploaded != nil {
+		t.Fatalf("invalid protos: err = %v, uploaded %d bytes", err, len(st.uploaded))
+	}
+}
@@ -0,0 +1,255 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"time"
+
+	oggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// StreamOption configures a streaming call opened with Client.NewStream.
+type StreamOption func(*streamConfig)
+
+// streamConfig holds the resolved options for a streaming call.
+type streamConfig struct {
+	timeout time.Duration // Upper bound for the whole stream lifetime
+}
+
+// WithStreamTimeout bounds the lifetime of the stream. It is applied only when
+// the caller's context has no deadline of its own; d <= 0 disables it.
+func WithStreamTimeout(d time.Duration) StreamOption {
+	return func(cfg *streamConfig) {
+		cfg.timeout = d
+	}
+}
+
+// Stream is a dynamic client-, server- or bidirectional-streaming call. Request
+// and response messages are built from the method descriptors found with
+// FindMethod, so no generated stubs are required.
+//
+// A Stream follows the usual gRPC rules: Send* and Recv* may be used from two
+// different goroutines, but neither may be called concurrently with itself.
+type Stream struct {
+	cs     oggrpc.ClientStream
+	method protoreflect.MethodDescriptor
+	cancel context.CancelFunc
+}
+
+// NewStream opens a streaming RPC by method name. The method must be declared
+// with the "stream" keyword on its request and/or response in the .proto.
+// Outgoing metadata (for example payment headers) must already be attached to
+// ctx; it is sent once, when the stream is opened.
+//
+// The returned Stream owns a derived context. It is released automatically
+// when Recv* reports the end of the stream or an error; call Close to abandon
+// the stream earlier.
+func (c *Client) NewStream(ctx context.Context, method string, opts ...StreamOption) (*Stream, error) {
+	fd, methodDesc, err := FindMethod(c.ProtoFiles, method)
+	if err != nil {
+		return nil, err
+	}
+	if !methodDesc.IsStreamingClient() && !methodDesc.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s is unary; use CallWithJSON, CallWithMap or CallWithProto", method)
+	}
+
+	var cfg streamConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var cancel context.CancelFunc
+	if _, ok := ctx.Deadline(); !ok && cfg.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	desc := &oggrpc.StreamDesc{
+		StreamName:    string(methodDesc.Name()),
+		ServerStreams: methodDesc.IsStreamingServer(),
+		ClientStreams: methodDesc.IsStreamingClient(),
+	}
+	cs, err := c.GRPC.NewStream(ctx, desc, fullMethodName(fd, methodDesc))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &Stream{cs: cs, method: methodDesc, cancel: cancel}, nil
+}
+
+// StreamWithProto opens a server-streaming RPC, sends req as the only request
+// message and half-closes the send direction. Responses are read from the
+// returned Stream.
+func (c *Client) StreamWithProto(ctx context.Context, method string, req proto.Message, opts ...StreamOption) (*Stream, error) {
+	s, err := c.NewStream(ctx, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.SendProto(req); err != nil {
+		s.Close()
+		return nil, err
+	}
+	if err = s.CloseSend(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// StreamWithJSON is like StreamWithProto but takes the request as JSON.
+func (c *Client) StreamWithJSON(ctx context.Context, method string, body []byte, opts ...StreamOption) (*Stream, error) {
+	_, methodDesc, err := FindMethod(c.ProtoFiles, method)
+	if err != nil {
+		return nil, err
+	}
+	in := dynamicpb.NewMessage(methodDesc.Input())
+	if err = jsonUnmarshalOptions.Unmarshal(body, in); err != nil {
+		return nil, err
+	}
+	return c.StreamWithProto(ctx, method, in, opts...)
+}
+
+// StreamWithMap is like StreamWithProto but takes the request as a JSON-like map.
+func (c *Client) StreamWithMap(ctx context.Context, method string, params map[string]any, opts ...StreamOption) (*Stream, error) {
+	jsonData, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return c.StreamWithJSON(ctx, method, jsonData, opts...)
+}
+
+// Method returns the descriptor of the method this stream was opened for.
+func (s *Stream) Method() protoreflect.MethodDescriptor {
+	return s.method
+}
+
+// Context returns the context of the underlying client stream.
+func (s *Stream) Context() context.Context {
+	return s.cs.Context()
+}
+
+// Header returns the header metadata sent by the server. It blocks until the
+// headers are available.
+func (s *Stream) Header() (metadata.MD, error) {
+	return s.cs.Header()
+}
+
+// Trailer returns the trailer metadata sent by the server. It is only
+// populated after Recv* has returned io.EOF or an error.
+func (s *Stream) Trailer() metadata.MD {
+	return s.cs.Trailer()
+}
+
+// SendProto sends a request message on the stream.
+func (s *Stream) SendProto(msg proto.Message) error {
+	return s.cs.SendMsg(msg)
+}
+
+// SendJSON unmarshals body into the method's input message and sends it.
+func (s *Stream) SendJSON(body []byte) error {
+	in := dynamicpb.NewMessage(s.method.Input())
+	if err := jsonUnmarshalOptions.Unmarshal(body, in); err != nil {
+		return err
+	}
+	return s.SendProto(in)
+}
+
+// SendMap encodes params as JSON and sends it as the method's input message.
+func (s *Stream) SendMap(params map[string]any) error {
+	jsonData, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.SendJSON(jsonData)
+}
+
+// CloseSend half-closes the stream: no more requests will be sent, while
+// responses can still be received.
+func (s *Stream) CloseSend() error {
+	return s.cs.CloseSend()
+}
+
+// Close abandons the stream and releases its context. It is safe to call more
+// than once and after the stream has finished.
+func (s *Stream) Close() {
+	s.cancel()
+}
+
+// RecvProto receives the next response message. It returns io.EOF once the
+// server has finished the stream successfully.
+func (s *Stream) RecvProto() (proto.Message, error) {
+	out := dynamicpb.NewMessage(s.method.Output())
+	if err := s.cs.RecvMsg(out); err != nil {
+		// The RPC is over either way; release the derived context.
+		s.cancel()
+		return nil, err
+	}
+	return out, nil
+}
+
+// RecvJSON receives the next response message as JSON. It returns io.EOF once
+// the server has finished the stream successfully.
+func (s *Stream) RecvJSON() ([]byte, error) {
+	out, err := s.RecvProto()
+	if err != nil {
+		return nil, err
+	}
+	return jsonMarshalOptions.Marshal(out)
+}
+
+// RecvMap receives the next response message as a JSON-like map. It returns
+// io.EOF once the server has finished the stream successfully.
+func (s *Stream) RecvMap() (map[string]any, error) {
+	jsonData, err := s.RecvJSON()
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err := json.Unmarshal(jsonData, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Protos yields response messages until the stream ends. A clean end of the
+// stream stops the iteration without an error; any other failure is yielded
+// once as the final element. Call Close when breaking out of the loop early.
+func (s *Stream) Protos() iter.Seq2[proto.Message, error] {
+	return recvSeq(s.RecvProto)
+}
+
+// JSON yields response messages as JSON until the stream ends. See Protos.
+func (s *Stream) JSON() iter.Seq2[[]byte, error] {
+	return recvSeq(s.RecvJSON)
+}
+
+// Maps yields response messages as JSON-like maps until the stream ends. See Protos.
+func (s *Stream) Maps() iter.Seq2[map[string]any, error] {
+	return recvSeq(s.RecvMap)
+}
+
+// recvSeq adapts a Recv* method to an iterator that stops on io.EOF and
+// yields any other error as its last element.
+func recvSeq[T any](recv func() (T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			msg, err := recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(msg, err) || err != nil {
+				return
+			}
+		}
+	}
+}
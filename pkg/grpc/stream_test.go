@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// counterProto declares one method of each streaming kind used by the tests.
+const counterProto = `
+syntax = "proto3";
+package test;
+message Num { int32 value = 1; }
+service Counter {
+  rpc Count(Num) returns (stream Num);
+  rpc Sum(stream Num) returns (Num);
+  rpc Echo(stream Num) returns (stream Num);
+  rpc Get(Num) returns (Num);
+}
+`
+
+// startCounterServer serves the Counter service using dynamic messages built
+// from counterProto. It records the metadata seen on the last opened stream.
+func startCounterServer(t *testing.T) (string, *metadata.MD, func()) {
+	t.Helper()
+	files, err := getProtoDescriptors(map[string]string{"counter.proto": counterProto})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	_, count, err := FindMethod(files, "Count")
+	if err != nil {
+		t.Fatalf("find method: %v", err)
+	}
+	numDesc := count.Input()
+	valueField := numDesc.Fields().ByName("value")
+
+	newNum := func(v int32) *dynamicpb.Message {
+		m := dynamicpb.NewMessage(numDesc)
+		m.Set(valueField, protoreflect.ValueOfInt32(v))
+		return m
+	}
+	value := func(m *dynamicpb.Message) int32 {
+		return int32(m.Get(valueField).Int())
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		if strings.Contains(err.Error(), "operation not permitted") {
+			t.Skip("network operations not permitted in sandbox")
+		}
+		t.Fatalf("listen: %v", err)
+	}
+
+	var lastMD metadata.MD
+	srv := grpc.NewServer(grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		lastMD, _ = metadata.FromIncomingContext(ss.Context())
+		return handler(srv, ss)
+	}))
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Counter",
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    "Count",
+				ServerStreams: true,
+				Handler: func(_ any, ss grpc.ServerStream) error {
+					in := dynamicpb.NewMessage(numDesc)
+					if err := ss.RecvMsg(in); err != nil {
+						return err
+					}
+					for i := int32(1); i <= value(in); i++ {
+						if err := ss.SendMsg(newNum(i)); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			{
+				StreamName:    "Sum",
+				ClientStreams: true,
+				Handler: func(_ any, ss grpc.ServerStream) error {
+					var total int32
+					for {
+						in := dynamicpb.NewMessage(numDesc)
+						err := ss.RecvMsg(in)
+						if errors.Is(err, io.EOF) {
+							return ss.SendMsg(newNum(total))
+						}
+						if err != nil {
+							return err
+						}
+						total += value(in)
+					}
+				},
+			},
+			{
+				StreamName:    "Echo",
+				ServerStreams: true,
+				ClientStreams: true,
+				Handler: func(_ any, ss grpc.ServerStream) error {
+					for {
+						in := dynamicpb.NewMessage(numDesc)
+						err := ss.RecvMsg(in)
+						if errors.Is(err, io.EOF) {
+							return nil
+						}
+						if err != nil {
+							return err
+						}
+						if err := ss.SendMsg(newNum(value(in) * 2)); err != nil {
+							return err
+						}
+					}
+				},
+			},
+		},
+	}, struct{}{})
+	go func() { _ = srv.Serve(lis) }()
+
+	return lis.Addr().String(), &lastMD, func() {
+		srv.Stop()
+		_ = lis.Close()
+	}
+}
+
+func TestStreamVariants(t *testing.T) {
+	addr, lastMD, cleanup := startCounterServer(t)
+	defer cleanup()
+
+	client := NewClient(addr, map[string]string{"counter.proto": counterProto})
+	if client == nil {
+		t.Fatal("client should not be nil")
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx := context.Background()
+
+	t.Run("ServerStreamJSON", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(ctx, "snet-payment-type", "escrow")
+		stream, err := client.StreamWithJSON(ctx, "Count", []byte(`{"value":3}`))
+		if err != nil {
+			t.Fatalf("StreamWithJSON error: %v", err)
+		}
+		var got []string
+		for msg, err := range stream.JSON() {
+			if err != nil {
+				t.Fatalf("recv error: %v", err)
+			}
+			got = append(got, string(msg))
+		}
+		if len(got) != 3 || !strings.Contains(got[2], `"value":3`) {
+			t.Fatalf("unexpected responses: %v", got)
+		}
+		if v := (*lastMD).Get("snet-payment-type"); len(v) != 1 || v[0] != "escrow" {
+			t.Fatalf("expected metadata to be sent once with the stream, got %v", v)
+		}
+	})
+
+	t.Run("ServerStreamMap", func(t *testing.T) {
+		stream, err := client.StreamWithMap(ctx, "Count", map[string]any{"value": 2})
+		if err != nil {
+			t.Fatalf("StreamWithMap error: %v", err)
+		}
+		var n int
+		for msg, err := range stream.Maps() {
+			if err != nil {
+				t.Fatalf("recv error: %v", err)
+			}
+			n++
+			if msg["value"] != float64(n) {
+				t.Fatalf("unexpected message %d: %v", n, msg)
+			}
+		}
+		if n != 2 {
+			t.Fatalf("expected 2 messages, got %d", n)
+		}
+	})
+
+	t.Run("ClientStream", func(t *testing.T) {
+		stream, err := client.NewStream(ctx, "Sum")
+		if err != nil {
+			t.Fatalf("NewStream error: %v", err)
+		}
+		for _, v := range []int{1, 2, 3} {
+			if err := stream.SendMap(map[string]any{"value": v}); err != nil {
+				t.Fatalf("send error: %v", err)
+			}
+		}
+		if err := stream.CloseSend(); err != nil {
+			t.Fatalf("CloseSend error: %v", err)
+		}
+		resp, err := stream.RecvJSON()
+		if err != nil {
+			t.Fatalf("recv error: %v", err)
+		}
+		var m map[string]any
+		if err := json.Unmarshal(resp, &m); err != nil {
+			t.Fatalf("unmarshal response: %v", err)
+		}
+		if m["value"] != float64(6) {
+			t.Fatalf("unexpected sum: %v", m)
+		}
+	})
+
+	t.Run("BidiStream", func(t *testing.T) {
+		stream, err := client.NewStream(ctx, "Echo")
+		if err != nil {
+			t.Fatalf("NewStream error: %v", err)
+		}
+		defer stream.Close()
+		for _, v := range []int{5, 7} {
+			if err := stream.SendJSON([]byte(fmt.Sprintf(`{"value":%d}`, v))); err != nil {
+				t.Fatalf("send error: %v", err)
+			}
+			msg, err := stream.RecvMap()
+			if err != nil {
+				t.Fatalf("recv error: %v", err)
+			}
+			if msg["value"] != float64(v*2) {
+				t.Fatalf("unexpected echo for %d: %v", v, msg)
+			}
+		}
+		if err := stream.CloseSend(); err != nil {
+			t.Fatalf("CloseSend error: %v", err)
+		}
+		if _, err := stream.RecvProto(); !errors.Is(err, io.EOF) {
+			t.Fatalf("expected io.EOF after CloseSend, got %v", err)
+		}
+	})
+
+	t.Run("UnaryRejected", func(t *testing.T) {
+		if _, err := client.NewStream(ctx, "Get"); err == nil {
+			t.Fatal("expected error opening a stream for a unary method")
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/shamank/snet-sdk-go/pkg/storage"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	oggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// ClientOption configures NewClient and NewPool.
+type ClientOption func(*clientOptions)
+
+// clientOptions holds the resolved ClientOptions.
+type clientOptions struct {
+	tel   *telemetry.Telemetry
+	cache *storage.Cache
+	log   *zap.Logger
+}
+
+// WithTelemetry traces every RPC made on the connection, including those of
+// generated stubs sharing Client.GRPC, with t instead of telemetry.Global().
+// A Telemetry carried by the context of an RPC (telemetry.NewContext) takes
+// precedence.
+func WithTelemetry(t *telemetry.Telemetry) ClientOption {
+	return func(o *clientOptions) {
+		o.tel = t
+	}
+}
+
+// WithLogger makes the client, the pool and the descriptor cache log to l
+// instead of zap.L().
+func WithLogger(l *zap.Logger) ClientOption {
+	return func(o *clientOptions) {
+		o.log = l
+	}
+}
+
+func resolveClientOptions(opts []ClientOption) clientOptions {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.log == nil {
+		o.log = zap.L()
+	}
+	return o
+}
+
+// telemetryDialOptions returns the interceptors that trace unary and streaming
+// RPCs with the Telemetry of their context, or t. The span of a stream ends
+// with its first receive error (io.EOF included) or when its context is done.
+func telemetryDialOptions(t *telemetry.Telemetry, target string) []oggrpc.DialOption {
+	start := func(ctx context.Context, method string) (context.Context, trace.Span) {
+		tel := telemetry.FromContext(ctx)
+		if tel == nil {
+			tel = t
+		}
+		return tel.Start(ctx, spanName(method), rpcAttributes(method, target)...)
+	}
+	unary := func(ctx context.Context, method string, req, reply any, cc *oggrpc.ClientConn, invoker oggrpc.UnaryInvoker, opts ...oggrpc.CallOption) error {
+		ctx, span := start(ctx, method)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		endRPC(span, err)
+		return err
+	}
+	stream := func(ctx context.Context, desc *oggrpc.StreamDesc, cc *oggrpc.ClientConn, method string, streamer oggrpc.Streamer, opts ...oggrpc.CallOption) (oggrpc.ClientStream, error) {
+		ctx, span := start(ctx, method)
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			endRPC(span, err)
+			return nil, err
+		}
+		ts := &tracedStream{ClientStream: cs, span: span}
+		context.AfterFunc(cs.Context(), func() { ts.end(cs.Context().Err()) })
+		return ts, nil
+	}
+	return []oggrpc.DialOption{
+		oggrpc.WithChainUnaryInterceptor(unary),
+		oggrpc.WithChainStreamInterceptor(stream),
+	}
+}
+
+// tracedStream ends the span of a stream when the stream finishes.
+type tracedStream struct {
+	oggrpc.ClientStream
+	once sync.Once
+	span trace.Span
+}
+
+func (s *tracedStream) end(err error) {
+	s.once.Do(func() { endRPC(s.span, err) })
+}
+
+func (s *tracedStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			s.end(nil)
+		} else {
+			s.end(err)
+		}
+	}
+	return err
+}
+
+// endRPC ends span with the gRPC status code of err.
+func endRPC(span trace.Span, err error) {
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(status.Code(err))))
+	telemetry.End(span, err)
+}
+
+// spanName follows the OpenTelemetry convention "<package>.<Service>/<Method>".
+func spanName(method string) string {
+	return strings.TrimPrefix(method, "/")
+}
+
+// rpcAttributes returns the semantic-convention attributes of an RPC.
+func rpcAttributes(method, target string) []attribute.KeyValue {
+	service, name, _ := strings.Cut(spanName(method), "/")
+	return []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		telemetry.MethodKey.String(name),
+		attribute.String("server.address", target),
+	}
+}
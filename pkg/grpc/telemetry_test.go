@@ -0,0 +1,52 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClientTracesCalls(t *testing.T) {
+	addr, cleanup := startEchoServer(t)
+	defer cleanup()
+
+	spans := tracetest.NewSpanRecorder()
+	tel := telemetry.New(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)), nil)
+	client := NewClient(addr, map[string]string{"echo.proto": echoProto}, WithTelemetry(tel))
+	if client == nil {
+		t.Fatal("client should not be nil")
+	}
+	defer func() { _ = client.Close() }()
+
+	if _, err := client.CallWithJSON(context.Background(), "Ping", []byte(`{}`)); err != nil {
+		t.Fatalf("CallWithJSON: %v", err)
+	}
+	// A Telemetry in the context of the call takes precedence.
+	scoped := tel.With(telemetry.ServiceKey.String("echo"))
+	if _, err := client.CallWithJSON(telemetry.NewContext(context.Background(), scoped), "Ping", []byte(`{}`)); err != nil {
+		t.Fatalf("CallWithJSON: %v", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("ended %d spans; want 2", len(ended))
+	}
+	for i, span := range ended {
+		if span.Name() != "test.Echo/Ping" {
+			t.Fatalf("span name = %q; want test.Echo/Ping", span.Name())
+		}
+		attrs := make(map[string]string)
+		for _, kv := range span.Attributes() {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		if attrs["rpc.system"] != "grpc" || attrs["rpc.method"] != "Ping" || attrs["rpc.grpc.status_code"] != "0" {
+			t.Fatalf("span attributes = %v", attrs)
+		}
+		if scopedCall := i == 1; (attrs[string(telemetry.ServiceKey)] == "echo") != scopedCall {
+			t.Fatalf("span %d attributes = %v; want the service only on the scoped call", i, attrs)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package model
+
+import "math/big"
+
+// ServiceMetadataBuilder assembles ServiceMetadata step by step:
+//
+//	metadata, err := model.NewServiceMetadataBuilder("Calculator").
+//		MPEAddress(mpe).
+//		APISource("ipfs://Qm...").
+//		Group("default_group", []string{"https://calc.example.com:7000"},
+//			model.FixedPrice(big.NewInt(10)),
+//			model.FreeCalls(5, signerAddress)).
+//		Tags("math").
+//		Build()
+//
+// Build validates the result with ServiceMetadata.Validate.
+type ServiceMetadataBuilder struct {
+	metadata ServiceMetadata
+}
+
+// GroupOption configures a group added with ServiceMetadataBuilder.Group.
+type GroupOption func(*ServiceGroup)
+
+// NewServiceMetadataBuilder starts metadata of version 1 for a gRPC service
+// with proto encoding.
+func NewServiceMetadataBuilder(displayName string) *ServiceMetadataBuilder {
+	return &ServiceMetadataBuilder{metadata: ServiceMetadata{
+		Version:     1,
+		DisplayName: displayName,
+		Encoding:    "proto",
+		ServiceType: "grpc",
+	}}
+}
+
+// MPEAddress sets the address of the MultiPartyEscrow contract the service
+// is paid through.
+func (b *ServiceMetadataBuilder) MPEAddress(addr string) *ServiceMetadataBuilder {
+	b.metadata.MPEAddress = addr
+	return b
+}
+
+// Encoding sets the message encoding ("proto" by default).
+func (b *ServiceMetadataBuilder) Encoding(encoding string) *ServiceMetadataBuilder {
+	b.metadata.Encoding = encoding
+	return b
+}
+
+// ServiceType sets the service type ("grpc" by default).
+func (b *ServiceMetadataBuilder) ServiceType(serviceType string) *ServiceMetadataBuilder {
+	b.metadata.ServiceType = serviceType
+	return b
+}
+
+// APISource sets the storage URI of an already uploaded proto archive.
+func (b *ServiceMetadataBuilder) APISource(uri string) *ServiceMetadataBuilder {
+	b.metadata.ServiceApiSource = uri
+	return b
+}
+
+// ProtoFiles sets the proto sources of the service by file name, so that
+// they are validated without reading the archive from storage. Without an
+// APISource, the sdk publishes them when the service is created or updated
+// and sets service_api_source to the uploaded archive.
+func (b *ServiceMetadataBuilder) ProtoFiles(files map[string]string) *ServiceMetadataBuilder {
+	b.metadata.ProtoFiles = files
+	return b
+}
+
+// Group adds a group serving the service on endpoints. The name must be a
+// group of the organization.
+func (b *ServiceMetadataBuilder) Group(name string, endpoints []string, opts ...GroupOption) *ServiceMetadataBuilder {
+	g := &ServiceGroup{GroupName: name, Endpoints: endpoints}
+	for _, opt := range opts {
+		opt(g)
+	}
+	b.metadata.Groups = append(b.metadata.Groups, g)
+	return b
+}
+
+// Tags adds search keywords.
+func (b *ServiceMetadataBuilder) Tags(tags ...string) *ServiceMetadataBuilder {
+	b.metadata.Tags = append(b.metadata.Tags, tags...)
+	return b
+}
+
+// DynamicPricing makes the price of the method at path be asked from the
+// pricing method at priceMethod before each call. Both are full paths
+// ("/package.Service/Method").
+func (b *ServiceMetadataBuilder) DynamicPricing(path, priceMethod string) *ServiceMetadataBuilder {
+	if b.metadata.DynamicPriceMethodMapping == nil {
+		b.metadata.DynamicPriceMethodMapping = make(map[string]string)
+	}
+	b.metadata.DynamicPriceMethodMapping[path] = priceMethod
+	return b
+}
+
+// TrainingMethods adds the methods that support model training.
+func (b *ServiceMetadataBuilder) TrainingMethods(methods ...string) *ServiceMetadataBuilder {
+	b.metadata.TrainingMethods = append(b.metadata.TrainingMethods, methods...)
+	return b
+}
+
+// Build returns the metadata, or the ValidationErrors of
+// ServiceMetadata.Validate.
+func (b *ServiceMetadataBuilder) Build() (*ServiceMetadata, error) {
+	metadata := b.metadata
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// FixedPrice charges cogs for every call to the group. It is the default
+// price of the group.
+func FixedPrice(cogs *big.Int) GroupOption {
+	return func(g *ServiceGroup) {
+		g.Pricing = append(g.Pricing, Pricing{PriceModel: PriceModelFixed, PriceInCogs: cogs, Default: true})
+	}
+}
+
+// PricePerMethod charges cogs for calls to one method of the group, given
+// by its proto package, service and method names. Other methods cost the
+// FixedPrice of the group.
+func PricePerMethod(pkg, service, method string, cogs *big.Int) GroupOption {
+	return func(g *ServiceGroup) {
+		mp := MethodPricing{MethodName: method, PriceInCogs: cogs}
+		for i := range g.Pricing {
+			p := &g.Pricing[i]
+			if p.PriceModel != PriceModelFixedPerMethod || p.PackageName != pkg {
+				continue
+			}
+			for j := range p.PricingDetails {
+				if p.PricingDetails[j].ServiceName == service {
+					p.PricingDetails[j].MethodPricing = append(p.PricingDetails[j].MethodPricing, mp)
+					return
+				}
+			}
+			p.PricingDetails = append(p.PricingDetails, PricingDetails{ServiceName: service, MethodPricing: []MethodPricing{mp}})
+			return
+		}
+		g.Pricing = append(g.Pricing, Pricing{
+			PriceModel:     PriceModelFixedPerMethod,
+			PackageName:    pkg,
+			PricingDetails: []PricingDetails{{ServiceName: service, MethodPricing: []MethodPricing{mp}}},
+		})
+	}
+}
+
+// FreeCalls offers n free calls per user, whose tokens are signed by
+// signerAddress.
+func FreeCalls(n int, signerAddress string) GroupOption {
+	return func(g *ServiceGroup) {
+		g.FreeCalls = n
+		g.FreeCallSigner = signerAddress
+	}
+}
@@ -93,9 +93,34 @@
 //
 // Common price models:
 //   - fixed_price: Pay per call with PriceInCogs
+//   - fixed_price_per_method: Per-method prices in PricingDetails
 //   - subscription: Recurring payment for unlimited calls
 //   - dynamic: Price varies based on input/output
 //
+// ServiceGroup.MethodPrice resolves the price of a method path
+// ("/package.Service/Method"), falling back to DefaultPrice; MaxPrice returns
+// the most expensive fixed price of the group.
+//
+// # Building and Validating Service Metadata
+//
+// ServiceMetadataBuilder assembles metadata for publishing; GroupOptions such
+// as FixedPrice, PricePerMethod and FreeCalls configure each group:
+//
+//	metadata, err := model.NewServiceMetadataBuilder("Calculator").
+//		MPEAddress(mpe).
+//		APISource("ipfs://Qm...").
+//		Group("default_group", []string{"https://calc.example.com:7000"},
+//			model.FixedPrice(big.NewInt(10))).
+//		Build()
+//
+// Build runs ServiceMetadata.Validate, which checks the metadata on its own
+// and returns every problem as ValidationErrors, a list of FieldErrors keyed
+// by the JSON path of the field ("groups[0].pricing[1].price_in_cogs").
+// Checks against the organization, the network and the protos are done by
+// the sdk package before publishing. Metadata may carry ProtoFiles instead
+// of a ServiceApiSource; the sdk uploads them (see grpc.PublishProtos) when
+// the service is created or updated.
+//
 // # Payment Configuration
 //
 // Payment defines how payments are collected and managed:
@@ -39,6 +39,7 @@ type ServiceMetadata struct {
 	MPEAddress                string                        `json:"mpe_address"`
 	DynamicPriceMethodMapping map[string]string             `json:"dynamicpricing"`
 	TrainingMethods           []string                      `json:"training_methods"`
+	Tags                      []string                      `json:"tags,omitempty"`
 	ProtoDescriptors          []protoreflect.FileDescriptor `json:"-"`
 	ProtoFiles                map[string]string             `json:"-"`
 }
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -41,3 +42,53 @@ func TestServiceMetadata_GetMpeAddr(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceGroup_MethodPrice(t *testing.T) {
+	group := &ServiceGroup{
+		Pricing: []Pricing{
+			{PriceModel: PriceModelFixed, PriceInCogs: big.NewInt(5), Default: true},
+			{
+				PriceModel:  PriceModelFixedPerMethod,
+				PackageName: "example_service",
+				PricingDetails: []PricingDetails{{
+					ServiceName: "Calculator",
+					MethodPricing: []MethodPricing{
+						{MethodName: "add", PriceInCogs: big.NewInt(2)},
+						{MethodName: "mul", PriceInCogs: big.NewInt(9)},
+					},
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		method string
+		want   int64
+	}{
+		{"/example_service.Calculator/add", 2},
+		{"/example_service.Calculator/mul", 9},
+		{"mul", 9},
+		{"/example_service.Calculator/div", 5},
+		{"/other.Calculator/add", 5},
+		{"/example_service.Other/add", 5},
+	}
+	for _, tt := range tests {
+		if got := group.MethodPrice(tt.method); got.Int64() != tt.want {
+			t.Fatalf("MethodPrice(%q) = %v, want %d", tt.method, got, tt.want)
+		}
+	}
+
+	if got := group.MaxPrice(); got.Int64() != 9 {
+		t.Fatalf("MaxPrice() = %v, want 9", got)
+	}
+}
+
+func TestServiceGroup_DefaultPriceFallbacks(t *testing.T) {
+	if got := (&ServiceGroup{}).DefaultPrice(); got.Sign() != 0 {
+		t.Fatalf("DefaultPrice() of empty group = %v, want 0", got)
+	}
+	group := &ServiceGroup{Pricing: []Pricing{{PriceModel: PriceModelFixed, PriceInCogs: big.NewInt(7)}}}
+	if got := group.DefaultPrice(); got.Int64() != 7 {
+		t.Fatalf("DefaultPrice() = %v, want 7", got)
+	}
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Price models understood by ServiceGroup.MethodPrice.
+const (
+	// PriceModelFixed charges PriceInCogs for every call.
+	PriceModelFixed = "fixed_price"
+	// PriceModelFixedPerMethod charges the MethodPricing entry of the called
+	// method, listed in PricingDetails.
+	PriceModelFixedPerMethod = "fixed_price_per_method"
+)
+
+// DefaultPrice returns the price applied to methods without a per-method
+// entry: the PriceInCogs of the pricing marked Default, otherwise of the first
+// fixed_price entry, otherwise of the first entry that has one. It returns
+// zero when the group has no price at all.
+func (g *ServiceGroup) DefaultPrice() *big.Int {
+	var fixed, first *big.Int
+	for _, p := range g.Pricing {
+		if p.PriceInCogs == nil {
+			continue
+		}
+		if p.Default {
+			return p.PriceInCogs
+		}
+		if fixed == nil && p.PriceModel == PriceModelFixed {
+			fixed = p.PriceInCogs
+		}
+		if first == nil {
+			first = p.PriceInCogs
+		}
+	}
+	switch {
+	case fixed != nil:
+		return fixed
+	case first != nil:
+		return first
+	default:
+		return big.NewInt(0)
+	}
+}
+
+// MethodPrice returns the price of method, given either as a fully-qualified
+// gRPC path ("/package.Service/Method") or as a bare method name. A matching
+// fixed_price_per_method entry wins; PackageName and ServiceName are only
+// compared when both sides carry them. Otherwise DefaultPrice is returned.
+func (g *ServiceGroup) MethodPrice(method string) *big.Int {
+	pkg, service, name := SplitMethodPath(method)
+	for _, p := range g.Pricing {
+		if p.PriceModel != PriceModelFixedPerMethod {
+			continue
+		}
+		if pkg != "" && p.PackageName != "" && p.PackageName != pkg {
+			continue
+		}
+		for _, details := range p.PricingDetails {
+			if service != "" && details.ServiceName != "" && details.ServiceName != service {
+				continue
+			}
+			for _, mp := range details.MethodPricing {
+				if mp.MethodName == name && mp.PriceInCogs != nil {
+					return mp.PriceInCogs
+				}
+			}
+		}
+	}
+	return g.DefaultPrice()
+}
+
+// MaxPrice returns the highest price any single call to the group can cost
+// under fixed pricing. Strategies use it to make sure a payment channel can
+// cover the next call whatever method it targets.
+func (g *ServiceGroup) MaxPrice() *big.Int {
+	highest := g.DefaultPrice()
+	for _, p := range g.Pricing {
+		for _, details := range p.PricingDetails {
+			for _, mp := range details.MethodPricing {
+				if mp.PriceInCogs != nil && mp.PriceInCogs.Cmp(highest) > 0 {
+					highest = mp.PriceInCogs
+				}
+			}
+		}
+	}
+	return highest
+}
+
+// SplitMethodPath splits a gRPC method path "/package.Service/Method" into its
+// package, service and method names. A bare method name is returned as is,
+// with empty package and service.
+func SplitMethodPath(method string) (pkg, service, name string) {
+	path := strings.TrimPrefix(method, "/")
+	qualified, name, ok := strings.Cut(path, "/")
+	if !ok {
+		return "", "", path
+	}
+	if i := strings.LastIndex(qualified, "."); i >= 0 {
+		return qualified[:i], qualified[i+1:], name
+	}
+	return "", qualified, name
+}
@@ -0,0 +1,209 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Encodings and service types accepted by ServiceMetadata.Validate.
+var (
+	Encodings    = []string{"proto", "json"}
+	ServiceTypes = []string{"grpc", "jsonrpc", "process", "http"}
+)
+
+// FieldError describes a problem with one metadata field. Field is the path
+// of the field in the metadata JSON, for example "groups[0].endpoints[1]".
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors lists every problem found in metadata. Use errors.As to
+// inspect the individual FieldErrors.
+type ValidationErrors []*FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, len(v))
+	for i, e := range v {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the individual field errors.
+func (v ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, e := range v {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Add records a problem with field.
+func (v *ValidationErrors) Add(field string, err error) {
+	*v = append(*v, &FieldError{Field: field, Err: err})
+}
+
+// Err returns v as an error, or nil when it is empty.
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Validate checks the metadata on its own, without the organization, the
+// chain or the protos: required fields, addresses, groups, endpoints and
+// pricing. It reports every problem at once as ValidationErrors.
+func (s *ServiceMetadata) Validate() error {
+	var errs ValidationErrors
+
+	if s.Version < 1 {
+		errs.Add("version", errors.New("must be at least 1"))
+	}
+	if strings.TrimSpace(s.DisplayName) == "" {
+		errs.Add("display_name", errors.New("is required"))
+	}
+	if !slices.Contains(Encodings, s.Encoding) {
+		errs.Add("encoding", fmt.Errorf("unknown encoding %q (want one of %s)", s.Encoding, strings.Join(Encodings, ", ")))
+	}
+	if !slices.Contains(ServiceTypes, s.ServiceType) {
+		errs.Add("service_type", fmt.Errorf("unknown service type %q (want one of %s)", s.ServiceType, strings.Join(ServiceTypes, ", ")))
+	}
+	if err := checkAddress(s.MPEAddress); err != nil {
+		errs.Add("mpe_address", err)
+	}
+	if s.ServiceApiSource == "" && s.ModelIpfsHash == "" && len(s.ProtoFiles) == 0 {
+		errs.Add("service_api_source", errors.New("is required unless ProtoFiles are set to be published"))
+	}
+
+	if len(s.Groups) == 0 {
+		errs.Add("groups", errors.New("at least one group is required"))
+	}
+	seen := make(map[string]bool)
+	for i, g := range s.Groups {
+		field := fmt.Sprintf("groups[%d]", i)
+		if g == nil {
+			errs.Add(field, errors.New("is null"))
+			continue
+		}
+		switch {
+		case g.GroupName == "":
+			errs.Add(field+".group_name", errors.New("is required"))
+		case seen[g.GroupName]:
+			errs.Add(field+".group_name", fmt.Errorf("duplicate group %q", g.GroupName))
+		}
+		seen[g.GroupName] = true
+		g.validate(field, &errs)
+	}
+
+	for _, method := range slices.Sorted(maps.Keys(s.DynamicPriceMethodMapping)) {
+		for _, m := range []string{method, s.DynamicPriceMethodMapping[method]} {
+			if _, service, _ := SplitMethodPath(m); service == "" {
+				errs.Add("dynamicpricing", fmt.Errorf("%q is not a method path (/package.Service/Method)", m))
+			}
+		}
+	}
+
+	return errs.Err()
+}
+
+// validate checks the endpoints, free calls and pricing of the group.
+func (g *ServiceGroup) validate(field string, errs *ValidationErrors) {
+	if len(g.Endpoints) == 0 {
+		errs.Add(field+".endpoints", errors.New("at least one endpoint is required"))
+	}
+	for i, endpoint := range g.Endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.Add(fmt.Sprintf("%s.endpoints[%d]", field, i), fmt.Errorf("%q is not an http:// or https:// URL", endpoint))
+		}
+	}
+
+	if g.FreeCalls < 0 {
+		errs.Add(field+".free_calls", errors.New("must not be negative"))
+	}
+	if g.FreeCalls > 0 {
+		if err := checkAddress(g.FreeCallSigner); err != nil {
+			errs.Add(field+".free_call_signer_address", fmt.Errorf("required with free calls: %w", err))
+		}
+	}
+
+	if len(g.Pricing) == 0 {
+		errs.Add(field+".pricing", errors.New("at least one price is required"))
+	}
+	defaults := 0
+	for i, p := range g.Pricing {
+		pfield := fmt.Sprintf("%s.pricing[%d]", field, i)
+		if p.Default {
+			defaults++
+		}
+		switch p.PriceModel {
+		case PriceModelFixed:
+			if err := checkPrice(p.PriceInCogs); err != nil {
+				errs.Add(pfield+".price_in_cogs", err)
+			}
+		case PriceModelFixedPerMethod:
+			if len(p.PricingDetails) == 0 {
+				errs.Add(pfield+".details", errors.New("at least one service is required"))
+			}
+			for j, details := range p.PricingDetails {
+				dfield := fmt.Sprintf("%s.details[%d]", pfield, j)
+				if len(details.MethodPricing) == 0 {
+					errs.Add(dfield+".method_pricing", errors.New("at least one method is required"))
+				}
+				for k, mp := range details.MethodPricing {
+					mfield := fmt.Sprintf("%s.method_pricing[%d]", dfield, k)
+					if mp.MethodName == "" {
+						errs.Add(mfield+".method_name", errors.New("is required"))
+					}
+					if err := checkPrice(mp.PriceInCogs); err != nil {
+						errs.Add(mfield+".price_in_cogs", err)
+					}
+				}
+			}
+		default:
+			errs.Add(pfield+".price_model", fmt.Errorf("unknown price model %q (want %s or %s)", p.PriceModel, PriceModelFixed, PriceModelFixedPerMethod))
+		}
+	}
+	if defaults > 1 {
+		errs.Add(field+".pricing", fmt.Errorf("%d prices are marked default; at most one may be", defaults))
+	}
+}
+
+func checkAddress(addr string) error {
+	switch {
+	case addr == "":
+		return errors.New("is required")
+	case !common.IsHexAddress(addr):
+		return fmt.Errorf("invalid address %q", addr)
+	case common.HexToAddress(addr) == (common.Address{}):
+		return errors.New("must not be the zero address")
+	}
+	return nil
+}
+
+func checkPrice(price *big.Int) error {
+	switch {
+	case price == nil:
+		return errors.New("is required")
+	case price.Sign() < 0:
+		return errors.New("must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+const (
+	testMPE    = "0x5e592F9b1d303183d963635f895f0f0C48284f4e"
+	testSigner = "0x7DF35C98f41F3Af0df1dc4c7F7D4C19a71Dd059F"
+)
+
+func TestServiceMetadataBuilder(t *testing.T) {
+	metadata, err := NewServiceMetadataBuilder("Calculator").
+		MPEAddress(testMPE).
+		APISource("ipfs://QmProtos").
+		Group("default_group", []string{"https://calc.example.com:7000"},
+			FixedPrice(big.NewInt(10)),
+			PricePerMethod("example", "Calculator", "add", big.NewInt(2)),
+			PricePerMethod("example", "Calculator", "mul", big.NewInt(3)),
+			FreeCalls(5, testSigner)).
+		Tags("math").
+		DynamicPricing("/example.Calculator/div", "/example.Calculator/div_price").
+		Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	g := metadata.Groups[0]
+	if metadata.Version != 1 || metadata.Encoding != "proto" || metadata.ServiceType != "grpc" {
+		t.Fatalf("defaults not applied: %+v", metadata)
+	}
+	if len(g.Pricing) != 2 || len(g.Pricing[1].PricingDetails) != 1 || len(g.Pricing[1].PricingDetails[0].MethodPricing) != 2 {
+		t.Fatalf("per-method prices not merged: %+v", g.Pricing)
+	}
+	if got := g.MethodPrice("/example.Calculator/mul"); got.Int64() != 3 {
+		t.Fatalf("MethodPrice(mul) = %v; want 3", got)
+	}
+	if got := g.MethodPrice("/example.Calculator/sub"); got.Int64() != 10 {
+		t.Fatalf("MethodPrice(sub) = %v; want the fixed price 10", got)
+	}
+}
+
+func TestServiceMetadataValidate(t *testing.T) {
+	metadata := &ServiceMetadata{
+		Version:     1,
+		DisplayName: " ",
+		Encoding:    "xml",
+		ServiceType: "grpc",
+		MPEAddress:  "0x0000000000000000000000000000000000000000",
+		Groups: []*ServiceGroup{
+			{
+				GroupName: "default_group",
+				Endpoints: []string{"https://ok.example:7000", "calc.example:7000"},
+				FreeCalls: 5,
+				Pricing: []Pricing{
+					{PriceModel: PriceModelFixed, Default: true},
+					{PriceModel: PriceModelFixedPerMethod, PackageName: "example", Default: true, PricingDetails: []PricingDetails{
+						{ServiceName: "Calculator", MethodPricing: []MethodPricing{{MethodName: "add", PriceInCogs: big.NewInt(-1)}}},
+					}},
+				},
+			},
+			{GroupName: "default_group", Endpoints: []string{"http://b.example"}, Pricing: []Pricing{{PriceModel: "auction"}}},
+			nil,
+		},
+		DynamicPriceMethodMapping: map[string]string{"/example.Calculator/div": "div_price"},
+	}
+
+	err := metadata.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Validate = %v; want ValidationErrors", err)
+	}
+	want := []string{
+		"display_name",
+		"encoding",
+		"mpe_address",
+		"service_api_source",
+		"groups[0].endpoints[1]",
+		"groups[0].free_call_signer_address",
+		"groups[0].pricing[0].price_in_cogs",
+		"groups[0].pricing[1].details[0].method_pricing[0].price_in_cogs",
+		"groups[0].pricing",
+		"groups[1].group_name",
+		"groups[1].pricing[0].price_model",
+		"groups[2]",
+		"dynamicpricing",
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Field)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("fields = %v\nwant %v\nerror: %v", got, want, err)
+	}
+
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "display_name" {
+		t.Fatalf("errors.As(*FieldError) = %v", fe)
+	}
+}
@@ -0,0 +1,315 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// ErrChannelStateNotFound is returned by a ChannelStateStore when it holds no
+// state for the requested channel.
+var ErrChannelStateNotFound = errors.New("channel state not found")
+
+// ChannelState is the locally known state of one payment channel: the nonce
+// and the highest amount this client has signed for it, including amounts of
+// calls that may still be in flight.
+type ChannelState struct {
+	MPEAddress   common.Address `json:"mpe_address"`
+	ChannelID    *big.Int       `json:"channel_id"`
+	Nonce        *big.Int       `json:"nonce"`
+	SignedAmount *big.Int       `json:"signed_amount"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// ChannelStateStore persists ChannelState across restarts so that the paid
+// and prepaid strategies never lose track of how much they have authorised.
+// Strategies save to the store in the background every time the signed amount
+// changes and reconcile it with the daemon on Refresh. Implementations must be safe for
+// concurrent use.
+type ChannelStateStore interface {
+	// Load returns the state of the channel, or ErrChannelStateNotFound.
+	Load(ctx context.Context, mpe common.Address, channelID *big.Int) (*ChannelState, error)
+	// Save stores state, replacing any previous state of the same channel.
+	Save(ctx context.Context, state *ChannelState) error
+}
+
+// channelKey identifies a channel inside a store.
+func channelKey(mpe common.Address, channelID *big.Int) string {
+	return mpe.Hex() + "/" + channelID.String()
+}
+
+// copyChannelState returns a deep copy of s so that callers never share
+// big.Int values with a store.
+func copyChannelState(s *ChannelState) *ChannelState {
+	c := *s
+	if s.ChannelID != nil {
+		c.ChannelID = new(big.Int).Set(s.ChannelID)
+	}
+	if s.Nonce != nil {
+		c.Nonce = new(big.Int).Set(s.Nonce)
+	}
+	if s.SignedAmount != nil {
+		c.SignedAmount = new(big.Int).Set(s.SignedAmount)
+	}
+	return &c
+}
+
+// MemoryChannelStateStore keeps channel state in memory. It survives strategy
+// re-creation within a process but not a restart.
+type MemoryChannelStateStore struct {
+	mu       sync.RWMutex
+	channels map[string]*ChannelState
+}
+
+// NewMemoryChannelStateStore returns an empty in-memory store.
+func NewMemoryChannelStateStore() *MemoryChannelStateStore {
+	return &MemoryChannelStateStore{channels: make(map[string]*ChannelState)}
+}
+
+// Load implements ChannelStateStore.
+func (m *MemoryChannelStateStore) Load(_ context.Context, mpe common.Address, channelID *big.Int) (*ChannelState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.channels[channelKey(mpe, channelID)]
+	if !ok {
+		return nil, ErrChannelStateNotFound
+	}
+	return copyChannelState(s), nil
+}
+
+// Save implements ChannelStateStore.
+func (m *MemoryChannelStateStore) Save(_ context.Context, state *ChannelState) error {
+	if state == nil || state.ChannelID == nil {
+		return errors.New("channel state without channel id")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.channels == nil {
+		m.channels = make(map[string]*ChannelState)
+	}
+	m.channels[channelKey(state.MPEAddress, state.ChannelID)] = copyChannelState(state)
+	return nil
+}
+
+// FileChannelStateStore keeps channel state in a JSON file. Every Save
+// rewrites the file atomically (write to a temporary file, then rename), so a
+// crash leaves either the previous or the new state on disk.
+//
+// Writes are serialized within one store; share a single store between all
+// clients of a process that use the same file.
+type FileChannelStateStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileChannelStateStore returns a store backed by the JSON file at path.
+// The file and its directory are created on the first Save.
+func NewFileChannelStateStore(path string) *FileChannelStateStore {
+	return &FileChannelStateStore{path: path}
+}
+
+// Path returns the location of the backing file.
+func (f *FileChannelStateStore) Path() string {
+	return f.path
+}
+
+// Load implements ChannelStateStore.
+func (f *FileChannelStateStore) Load(_ context.Context, mpe common.Address, channelID *big.Int) (*ChannelState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	channels, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	s, ok := channels[channelKey(mpe, channelID)]
+	if !ok {
+		return nil, ErrChannelStateNotFound
+	}
+	return s, nil
+}
+
+// Save implements ChannelStateStore.
+func (f *FileChannelStateStore) Save(_ context.Context, state *ChannelState) error {
+	if state == nil || state.ChannelID == nil {
+		return errors.New("channel state without channel id")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	channels, err := f.read()
+	if err != nil {
+		return err
+	}
+	channels[channelKey(state.MPEAddress, state.ChannelID)] = copyChannelState(state)
+	return f.write(channels)
+}
+
+// read loads every channel from the file. A missing file is an empty store.
+// f.mu must be held.
+func (f *FileChannelStateStore) read() (map[string]*ChannelState, error) {
+	channels := make(map[string]*ChannelState)
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return channels, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read channel state file: %w", err)
+	}
+	if len(data) == 0 {
+		return channels, nil
+	}
+	if err := json.Unmarshal(data, &channels); err != nil {
+		return nil, fmt.Errorf("decode channel state file %s: %w", f.path, err)
+	}
+	return channels, nil
+}
+
+// write replaces the file with channels. f.mu must be held.
+func (f *FileChannelStateStore) write(channels map[string]*ChannelState) error {
+	data, err := json.MarshalIndent(channels, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode channel state: %w", err)
+	}
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create channel state directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create channel state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write channel state file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync channel state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close channel state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("replace channel state file: %w", err)
+	}
+	return nil
+}
+
+// loadChannelState returns the stored state of the channel, or nil when there
+// is no store, no state, or the store cannot be read.
+func loadChannelState(ctx context.Context, log *zap.Logger, store ChannelStateStore, mpe common.Address, channelID *big.Int) *ChannelState {
+	if store == nil || channelID == nil {
+		return nil
+	}
+	s, err := store.Load(ctx, mpe, channelID)
+	if err != nil {
+		if !errors.Is(err, ErrChannelStateNotFound) {
+			log.Warn("load channel state", zap.Stringer("channel", channelID), zap.Error(err))
+		}
+		return nil
+	}
+	return s
+}
+
+// saveChannelState writes the channel state through store; a nil store is a no-op.
+func saveChannelState(ctx context.Context, store ChannelStateStore, mpe common.Address, channelID, nonce, signedAmount *big.Int) error {
+	if store == nil || channelID == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return store.Save(ctx, &ChannelState{
+		MPEAddress:   mpe,
+		ChannelID:    channelID,
+		Nonce:        nonce,
+		SignedAmount: signedAmount,
+		UpdatedAt:    time.Now().UTC(),
+	})
+}
+
+// stateWriter saves channel state through a store in the background, so that
+// strategies never wait for the disk while holding their lock. Only the newest
+// state of each channel is kept until it is written: a burst of changes costs
+// one write per channel. The zero value is ready to use.
+type stateWriter struct {
+	mu      sync.Mutex
+	queued  map[string]*ChannelState
+	running bool
+	done    sync.WaitGroup
+}
+
+// save queues state to be written through store, replacing a queued state of
+// the same channel, and starts the flusher unless it is running. Failed writes
+// are logged to log.
+func (w *stateWriter) save(store ChannelStateStore, log *zap.Logger, state *ChannelState) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.queued == nil {
+		w.queued = make(map[string]*ChannelState)
+	}
+	w.queued[channelKey(state.MPEAddress, state.ChannelID)] = state
+	if w.running {
+		return
+	}
+	w.running = true
+	w.done.Add(1)
+	go w.flush(store, log)
+}
+
+// flush writes queued states until none is left.
+func (w *stateWriter) flush(store ChannelStateStore, log *zap.Logger) {
+	defer w.done.Done()
+	for {
+		w.mu.Lock()
+		queued := w.queued
+		w.queued = nil
+		if len(queued) == 0 {
+			w.running = false
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+
+		for _, state := range queued {
+			if err := store.Save(context.Background(), state); err != nil {
+				log.Warn("persist channel state",
+					zap.Stringer("channel", state.ChannelID),
+					zap.Stringer("nonce", state.Nonce),
+					zap.Error(err))
+			}
+		}
+	}
+}
+
+// wait blocks until every queued state has been written.
+func (w *stateWriter) wait() {
+	w.done.Wait()
+}
+
+// reconcileSignedAmount merges the daemon's signed amount for a channel at
+// nonce with the stored state and returns the amount to continue from. A
+// stored amount for the same nonce wins when it is higher: it was signed by
+// this client and may still reach the daemon, so it must not be signed again.
+// State stored for another nonce belongs to a claimed channel and is ignored.
+func reconcileSignedAmount(nonce, signed *big.Int, stored *ChannelState) *big.Int {
+	if signed == nil {
+		signed = big.NewInt(0)
+	}
+	if stored == nil || stored.Nonce == nil || stored.SignedAmount == nil || nonce == nil {
+		return signed
+	}
+	if stored.Nonce.Cmp(nonce) == 0 && stored.SignedAmount.Cmp(signed) > 0 {
+		return new(big.Int).Set(stored.SignedAmount)
+	}
+	return signed
+}
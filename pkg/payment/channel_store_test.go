@@ -0,0 +1,226 @@
+package payment
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	sggrpc "github.com/shamank/snet-sdk-go/pkg/grpc"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"go.uber.org/zap"
+	ogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testMPE = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+func TestChannelStateStores_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "channels.json")
+	stores := map[string]ChannelStateStore{
+		"memory": NewMemoryChannelStateStore(),
+		"file":   NewFileChannelStateStore(path),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if _, err := store.Load(ctx, testMPE, big.NewInt(1)); !errors.Is(err, ErrChannelStateNotFound) {
+				t.Fatalf("Load of empty store: err=%v; want ErrChannelStateNotFound", err)
+			}
+			if err := saveChannelState(ctx, store, testMPE, big.NewInt(1), big.NewInt(2), big.NewInt(30)); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			if err := saveChannelState(ctx, store, testMPE, big.NewInt(7), big.NewInt(0), big.NewInt(5)); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			if err := saveChannelState(ctx, store, testMPE, big.NewInt(1), big.NewInt(2), big.NewInt(40)); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+
+			got, err := store.Load(ctx, testMPE, big.NewInt(1))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if got.Nonce.Cmp(big.NewInt(2)) != 0 || got.SignedAmount.Cmp(big.NewInt(40)) != 0 {
+				t.Fatalf("state = nonce %s amount %s; want nonce 2 amount 40", got.Nonce, got.SignedAmount)
+			}
+			got.SignedAmount.SetInt64(0)
+			again, _ := store.Load(ctx, testMPE, big.NewInt(1))
+			if again.SignedAmount.Cmp(big.NewInt(40)) != 0 {
+				t.Fatal("mutating a loaded state changed the store")
+			}
+			other, err := store.Load(ctx, testMPE, big.NewInt(7))
+			if err != nil || other.SignedAmount.Cmp(big.NewInt(5)) != 0 {
+				t.Fatalf("channel 7 = %+v, %v; want amount 5", other, err)
+			}
+		})
+	}
+
+	// A new store on the same file sees the persisted state.
+	reopened := NewFileChannelStateStore(path)
+	got, err := reopened.Load(context.Background(), testMPE, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("Load after reopen: %v", err)
+	}
+	if got.SignedAmount.Cmp(big.NewInt(40)) != 0 {
+		t.Fatalf("amount after reopen = %s; want 40", got.SignedAmount)
+	}
+}
+
+func TestReconcileSignedAmount(t *testing.T) {
+	stored := func(nonce, amount int64) *ChannelState {
+		return &ChannelState{Nonce: big.NewInt(nonce), SignedAmount: big.NewInt(amount)}
+	}
+	tests := []struct {
+		name   string
+		stored *ChannelState
+		want   int64
+	}{
+		{"no stored state", nil, 100},
+		{"stored higher, same nonce", stored(3, 130), 130},
+		{"stored lower, same nonce", stored(3, 90), 100},
+		{"stored for an old nonce", stored(2, 500), 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reconcileSignedAmount(big.NewInt(3), big.NewInt(100), tt.stored)
+			if got.Cmp(big.NewInt(tt.want)) != 0 {
+				t.Fatalf("got %s; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaidStrategy_WritesThroughChannelStateStore(t *testing.T) {
+	store := NewMemoryChannelStateStore()
+	ps := &PaidStrategy{
+		serviceMetadata: &model.ServiceMetadata{MPEAddress: testMPE.Hex()},
+		channelID:       big.NewInt(1),
+		nonce:           big.NewInt(0),
+		signedAmount:    big.NewInt(100),
+		priceInCogs:     big.NewInt(10),
+		signer:          mustSigner(t, mustKey(t)),
+		store:           store,
+	}
+	amount := func() int64 {
+		t.Helper()
+		ps.states.wait()
+		s, err := store.Load(context.Background(), testMPE, big.NewInt(1))
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return s.SignedAmount.Int64()
+	}
+
+	_, first, _ := ps.Reserve(context.Background())
+	if got := amount(); got != 110 {
+		t.Fatalf("stored amount after reserve = %d; want 110", got)
+	}
+	_, second, _ := ps.Reserve(context.Background())
+	if got := amount(); got != 120 {
+		t.Fatalf("stored amount after second reserve = %d; want 120", got)
+	}
+	second.Rollback()
+	if got := amount(); got != 110 {
+		t.Fatalf("stored amount after rollback = %d; want 110", got)
+	}
+	first.Commit()
+	if got := amount(); got != 110 {
+		t.Fatalf("stored amount after commit = %d; want 110", got)
+	}
+}
+
+// slowStore counts saves and holds the first one until release is closed.
+type slowStore struct {
+	*MemoryChannelStateStore
+	saves   atomic.Int32
+	release chan struct{}
+}
+
+func (s *slowStore) Save(ctx context.Context, state *ChannelState) error {
+	if s.saves.Add(1) == 1 {
+		<-s.release
+	}
+	return s.MemoryChannelStateStore.Save(ctx, state)
+}
+
+func TestStateWriter_KeepsNewestStatePerChannel(t *testing.T) {
+	store := &slowStore{MemoryChannelStateStore: NewMemoryChannelStateStore(), release: make(chan struct{})}
+	var w stateWriter
+	for amount := range int64(10) {
+		w.save(store, zap.NewNop(), &ChannelState{MPEAddress: testMPE, ChannelID: big.NewInt(1), Nonce: big.NewInt(0), SignedAmount: big.NewInt(amount)})
+	}
+	close(store.release)
+	w.wait()
+
+	if n := store.saves.Load(); n > 2 {
+		t.Fatalf("%d saves for one channel; want the queued states merged", n)
+	}
+	got, err := store.Load(context.Background(), testMPE, big.NewInt(1))
+	if err != nil || got.SignedAmount.Int64() != 9 {
+		t.Fatalf("stored state = %+v, %v; want amount 9", got, err)
+	}
+}
+
+func TestNewPaidStrategy_UsesStoredStateWhenDaemonUnavailable(t *testing.T) {
+	priv := mustKey(t)
+	store := NewMemoryChannelStateStore()
+	if err := saveChannelState(context.Background(), store, testMPE, big.NewInt(111), big.NewInt(4), big.NewInt(250)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	chainStub := &stubChainOps{
+		currentBlock: big.NewInt(100),
+		networkID:    big.NewInt(1),
+		filterResult: &blockchain.MultiPartyEscrowChannelOpen{ChannelId: big.NewInt(111)},
+		ensureResult: big.NewInt(111),
+	}
+	down := failingChannelState{err: status.Error(codes.Unavailable, "connection refused")}
+
+	serviceMeta := &model.ServiceMetadata{MPEAddress: testMPE.Hex()}
+	serviceMeta.Groups = []*model.ServiceGroup{{
+		GroupName: "default",
+		Pricing:   []model.Pricing{{PriceModel: "fixed", PriceInCogs: big.NewInt(10)}},
+	}}
+	groupID := make([]byte, 32)
+	orgGroup := &model.OrganizationGroup{
+		ID: base64.StdEncoding.EncodeToString(groupID),
+		PaymentDetails: model.Payment{
+			PaymentAddress:             "0x00000000000000000000000000000000000000bb",
+			PaymentExpirationThreshold: big.NewInt(50),
+		},
+	}
+
+	build := func(opts ...PaidStrategyOption) (Strategy, error) {
+		opts = append(opts, WithPaidStrategyDependencies(PaidStrategyDependencies{Chain: chainStub, ChannelState: down}))
+		return NewPaidStrategy(context.Background(), &blockchain.EVMClient{}, &sggrpc.Client{}, serviceMeta, mustSigner(t, priv), serviceMeta.Groups[0], orgGroup, opts...)
+	}
+
+	if _, err := build(); err == nil {
+		t.Fatal("expected an error without a channel state store")
+	}
+
+	s, err := build(WithChannelStateStore(store))
+	if err != nil {
+		t.Fatalf("NewPaidStrategy: %v", err)
+	}
+	ps := s.(*PaidStrategy)
+	if ps.nonce.Cmp(big.NewInt(4)) != 0 || ps.signedAmount.Cmp(big.NewInt(250)) != 0 {
+		t.Fatalf("state = nonce %s amount %s; want nonce 4 amount 250", ps.nonce, ps.signedAmount)
+	}
+}
+
+type failingChannelState struct {
+	err error
+}
+
+func (f failingChannelState) ChannelState(ogrpc.ClientConnInterface, context.Context, common.Address, *big.Int, *big.Int, signer.Signer) (*ChannelStateReply, error) {
+	return nil, f.err
+}
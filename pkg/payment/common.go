@@ -6,13 +6,15 @@ package payment
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -29,20 +31,20 @@ const PrefixGetChannelState = "__get_channel_state"
 //	concat("__get_channel_state", MPEAddress, channelID, currentBlockNumber)
 //
 // hashes/signs it with an Ethereum personal-sign style signature (see
-// blockchain.GetSignature) using privateKeyECDSA, and sends a ChannelStateRequest
+// blockchain.SignMessage) using s, and sends a ChannelStateRequest
 // via the PaymentChannelStateService. The daemon verifies the signature and
 // returns the current signed amount/nonce for the channel.
 //
 // Parameters:
-//   - grpcConn: connected gRPC ClientConn to the daemon.
+//   - grpcConn: connection to the daemon (a ClientConn or Client.Conn).
 //   - ctx:      call context (cancellation/deadline).
 //   - MPEAddress: address of the MPE contract.
 //   - channelID:  channel identifier.
 //   - currentBlockNumber: chain tip (or recent) block number used for freshness.
-//   - privateKeyECDSA: signer key corresponding to the user address.
+//   - s: signer of the user address.
 //
 // Returns a non-nil ChannelStateReply on success or an error.
-func GetChannelStateFromDaemon(grpcConn *grpc.ClientConn, ctx context.Context, MPEAddress common.Address, channelID, currentBlockNumber *big.Int, privateKeyECDSA *ecdsa.PrivateKey) (*ChannelStateReply, error) {
+func GetChannelStateFromDaemon(grpcConn grpc.ClientConnInterface, ctx context.Context, MPEAddress common.Address, channelID, currentBlockNumber *big.Int, s signer.Signer) (*ChannelStateReply, error) {
 
 	client := NewPaymentChannelStateServiceClient(grpcConn)
 
@@ -53,23 +55,43 @@ func GetChannelStateFromDaemon(grpcConn *grpc.ClientConn, ctx context.Context, M
 		math.U256Bytes(currentBlockNumber),
 	}, nil)
 
+	signature, err := blockchain.SignMessage(ctx, s, message)
+	if err != nil {
+		return nil, fmt.Errorf("sign channel state request: %w", err)
+	}
 	request := &ChannelStateRequest{
 		ChannelId:    channelID.Bytes(),
-		Signature:    blockchain.GetSignature(message, privateKeyECDSA),
+		Signature:    signature,
 		CurrentBlock: currentBlockNumber.Uint64(),
 	}
 
 	reply, err := client.GetChannelState(ctx, request)
 	if err != nil {
-		return nil, err
+		return nil, ClassifyDaemonError(err)
 	}
 	if reply == nil {
 		return nil, errors.New("channel state reply is nil")
 	}
-	zap.L().Debug("Channel state reply", zap.Any("reply", reply))
 	return reply, nil
 }
 
+// sign signs message of the payment headers with s. Signing is traced with
+// the Telemetry of ctx, as it may involve an external signer.
+func sign(ctx context.Context, s signer.Signer, message []byte) ([]byte, error) {
+	ctx, span := telemetry.FromContext(ctx).Start(ctx, "payment.Sign")
+	signature, err := blockchain.SignMessage(ctx, s, message)
+	telemetry.End(span, err)
+	return signature, err
+}
+
+// loggerOrGlobal returns l, or zap.L() when no logger was given.
+func loggerOrGlobal(l *zap.Logger) *zap.Logger {
+	if l == nil {
+		return zap.L()
+	}
+	return l
+}
+
 // bigIntToBytes encodes a big.Int as a 32-byte big-endian slice, matching
 // Ethereum's common.BigToHash formatting.
 func bigIntToBytes(value *big.Int) []byte {
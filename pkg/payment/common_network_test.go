@@ -62,7 +62,7 @@ func TestGetChannelStateFromDaemon(t *testing.T) {
 	defer conn.Close()
 
 	ctx := context.Background()
-	resp, err := GetChannelStateFromDaemon(conn, ctx, mpeAddr, channelID, block, priv)
+	resp, err := GetChannelStateFromDaemon(conn, ctx, mpeAddr, channelID, block, mustSigner(t, priv))
 	if err != nil {
 		t.Fatalf("GetChannelStateFromDaemon error: %v", err)
 	}
@@ -41,6 +41,23 @@
 //
 // Free call tokens are automatically refreshed when needed.
 //
+// # Falling Back From Free Calls
+//
+// FallbackStrategy combines a free-call strategy with a paid or prepaid one. It
+// checks GetFreeCallsAvailable, uses free calls while any are left and then
+// pays through the fallback, which is built only when first needed. While
+// paying it asks for a new free-call token every recheck interval (see
+// WithFreeCallRecheck) and switches back once free calls are available again:
+//
+//	fallback := payment.NewFallbackStrategy(free, func(ctx context.Context) (payment.Strategy, error) {
+//		return payment.NewPaidStrategy(ctx, evm, grpcClient, metadata, key, serviceGroup, orgGroup)
+//	})
+//
+// If the daemon rejects a free call with ErrFreeCallsExhausted before the
+// cached count runs out, FreeCallsExhausted switches to the fallback; the sdk
+// package does this and retries the call. Services with free calls use this
+// strategy by default.
+//
 // # Paid Call Strategy
 //
 // Pay-per-call using MPE escrow channels. Each call:
@@ -85,6 +102,34 @@
 //	// Calls deduct from pre-funded channel
 //	response, _ := service.CallWithJSON("method", input)
 //
+// PrepaidStrategy tracks the token it got from the daemon: the planned and
+// used amounts of the TokenReply, the price of every call made since, and the
+// token expiration (the "exp" claim of the daemon's JWT). Usage reports the
+// remaining balance:
+//
+//	usage := prepaid.Usage()
+//	log.Printf("%d prepaid calls left until %v", usage.RemainingCalls, usage.ExpiresAt)
+//
+// When no more than the renewal threshold of calls is left (a tenth of the
+// batch by default, see WithPrepaidRenewThreshold), the next call signs
+// another batch of callCount calls and renews the token first; a token about
+// to expire is renewed as well (see WithPrepaidRenewBefore). Renew tops up
+// explicitly. The channel must hold the new signed amount, or the daemon
+// answers with ErrInsufficientChannelFunds.
+//
+// # Pricing
+//
+// Paid and prepaid strategies price each call from the service group:
+//   - fixed_price: the default PriceInCogs for every method
+//   - fixed_price_per_method: the MethodPricing entry of the called method,
+//     named in the context with WithMethod
+//   - dynamic pricing: the price returned by the method mapped in
+//     ServiceMetadata.DynamicPriceMethodMapping, attached with WithDynamicPrice
+//     and sent to the daemon in the snet-derived-dynamic-price-cost header
+//
+// The SDK's Service calls set both values automatically. Channels are funded
+// for the most expensive fixed price of the group.
+//
 // # Strategy Comparison
 //
 //	┌──────────────┬─────────────┬────────────┬──────────────┐
@@ -122,6 +167,28 @@
 //
 // Channel state is tracked on-chain via MPE contract events.
 //
+// # Channel State Persistence
+//
+// By default the nonce and signed amount of a channel live only in memory and
+// are rebuilt from the daemon on startup. A ChannelStateStore keeps them across
+// restarts: whenever the signed amount changes the strategies queue the new
+// state, and a background writer saves the newest state of each channel, so
+// calls never wait for the disk. On construction and Refresh the stored state
+// is reconciled with the daemon's channel state. For the same nonce the
+// higher of the two amounts wins, so an amount that may already have been sent
+// is never signed twice; when the daemon cannot be reached, the stored state is
+// used instead. Two implementations are provided:
+//
+//	store := payment.NewFileChannelStateStore("channels.json") // JSON file, atomic rewrites
+//	store := payment.NewMemoryChannelStateStore()              // in-process only
+//
+//	paid, err := payment.NewPaidStrategy(ctx, evm, grpc, meta, key, srvGroup, orgGroup,
+//		payment.WithChannelStateStore(store))
+//	prepaid, err := payment.NewPrePaidStrategy(ctx, evm, grpc, mpe, srvGroup, orgGroup, key, 100,
+//		payment.WithPrepaidChannelStateStore(store))
+//
+// The sdk package selects the store from config.Config.ChannelStateFile.
+//
 // # Error Handling
 //
 // Common payment errors:
@@ -133,6 +200,16 @@
 //   - Free call limit reached: Service quota exceeded
 //   - Invalid signature: Payment authorization rejected
 //
+// Failures reported by the daemon are classified by ClassifyDaemonError into a
+// *DaemonError carrying the gRPC code and message. Its Kind is one of the
+// sentinels ErrChannelNotFound, ErrInsufficientChannelFunds, ErrChannelExpired,
+// ErrFreeCallsExhausted, ErrPrepaidTokenExpired, ErrSignatureRejected or
+// ErrDaemonUnavailable, so callers can use errors.Is:
+//
+//	if errors.Is(err, payment.ErrChannelExpired) {
+//		// extend the channel and refresh the strategy
+//	}
+//
 // Example error handling:
 //
 //	err := service.SetPaidPaymentStrategy()
@@ -161,11 +238,37 @@
 //   - snet-payment-channel-amount: Cumulative amount
 //   - snet-payment-channel-signature-bin: Payment signature
 //
+// # Logging
+//
+// Strategies log recoverable problems (an unreachable daemon, a failed
+// renewal or state write) to zap.L() unless given a logger with
+// WithPaidLogger, WithPrepaidLogger, WithFreeLogger or WithFallbackLogger.
+// Entries about a channel carry channel and nonce fields.
+//
 // # Thread Safety
 //
 // Strategy instances are safe for concurrent use. Internal state (tokens, channel
 // nonces) is protected with appropriate synchronization.
 //
+// PaidStrategy also implements Reserver. Reserve signs the next amount and keeps
+// it pending until the caller commits it (the call reached the daemon) or rolls
+// it back (the call failed before that), so concurrent calls never sign the same
+// amount and failed calls do not leave gaps in the signed total. PrepaidStrategy
+// implements it to count calls against the prepaid balance in the same way.
+// When the claim cannot be signed, Reserve returns an error, nothing is
+// reserved and the call must not be made:
+//
+//	ctx, r, err := strategy.Reserve(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	_, err = client.CallWithJSON(ctx, "method", input)
+//	if status.Code(err) == codes.Unavailable {
+//		r.Rollback()
+//	} else {
+//		r.Commit()
+//	}
+//
 // # Best Practices
 //
 // 1. Use free calls for development and testing
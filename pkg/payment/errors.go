@@ -0,0 +1,120 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Payment failures reported by the daemon. Errors returned by the strategies
+// and by GetChannelStateFromDaemon wrap one of them when the daemon's status
+// code and message identify the cause; test with errors.Is.
+var (
+	// ErrChannelNotFound means the daemon has no state for the payment channel,
+	// typically because it was opened after the daemon's last sync.
+	ErrChannelNotFound = errors.New("payment channel not found by daemon")
+	// ErrInsufficientChannelFunds means the signed amount exceeds the value of
+	// the channel; add funds to the channel.
+	ErrInsufficientChannelFunds = errors.New("insufficient funds in payment channel")
+	// ErrChannelExpired means the channel has expired or is too close to its
+	// expiration for the daemon to accept payments; extend the channel.
+	ErrChannelExpired = errors.New("payment channel expired")
+	// ErrFreeCallsExhausted means the user has no free calls left.
+	ErrFreeCallsExhausted = errors.New("free calls exhausted")
+	// ErrPrepaidTokenExpired means the prepaid (or free-call) auth token is no
+	// longer valid; refresh the strategy to obtain a new one.
+	ErrPrepaidTokenExpired = errors.New("prepaid token expired")
+	// ErrSignatureRejected means the daemon could not verify a payment
+	// signature, or the signed nonce or amount did not match its state.
+	ErrSignatureRejected = errors.New("payment signature rejected")
+	// ErrDaemonUnavailable means the daemon could not be reached.
+	ErrDaemonUnavailable = errors.New("daemon unavailable")
+)
+
+// DaemonError is a gRPC failure returned by a service daemon. Kind is the
+// sentinel matching Code and Message, or nil when the failure is not a known
+// payment error. DaemonError keeps the gRPC status, so status.Code and
+// status.FromError still see the original code.
+type DaemonError struct {
+	Code    codes.Code
+	Message string
+	Kind    error
+}
+
+// Error implements error.
+func (e *DaemonError) Error() string {
+	if e.Kind != nil {
+		return fmt.Sprintf("%v: daemon returned %s: %s", e.Kind, e.Code, e.Message)
+	}
+	return fmt.Sprintf("daemon returned %s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns Kind, so errors.Is(err, ErrChannelExpired) and friends work
+// through the DaemonError.
+func (e *DaemonError) Unwrap() error {
+	return e.Kind
+}
+
+// GRPCStatus returns the daemon's status.
+func (e *DaemonError) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Message)
+}
+
+// ClassifyDaemonError converts a gRPC status error from a daemon into a
+// *DaemonError whose Kind is derived from the status code and message. Errors
+// that carry no gRPC status (local failures, context errors) and errors that
+// are already classified are returned unchanged.
+func ClassifyDaemonError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var de *DaemonError
+	if errors.As(err, &de) {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return &DaemonError{
+		Code:    st.Code(),
+		Message: st.Message(),
+		Kind:    daemonErrorKind(st.Code(), st.Message()),
+	}
+}
+
+// daemonErrorKind maps a daemon status to one of the sentinel errors. The
+// daemon reports most payment failures as Unauthenticated or Unknown with a
+// descriptive message, so the message decides when the code does not.
+func daemonErrorKind(code codes.Code, message string) error {
+	if code == codes.Unavailable {
+		return ErrDaemonUnavailable
+	}
+
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "channel is not found"),
+		strings.Contains(msg, "channel not found"):
+		return ErrChannelNotFound
+	case strings.Contains(msg, "free call") &&
+		(strings.Contains(msg, "exceeded") || strings.Contains(msg, "exhausted") || strings.Contains(msg, "no free calls")):
+		return ErrFreeCallsExhausted
+	case strings.Contains(msg, "token") && strings.Contains(msg, "expired"):
+		return ErrPrepaidTokenExpired
+	case strings.Contains(msg, "expired"):
+		return ErrChannelExpired
+	case strings.Contains(msg, "not enough tokens"),
+		strings.Contains(msg, "insufficient"),
+		strings.Contains(msg, "usage exceeded"),
+		strings.Contains(msg, "exceeds channel value"):
+		return ErrInsufficientChannelFunds
+	case strings.Contains(msg, "signature"),
+		strings.Contains(msg, "incorrect nonce"),
+		strings.Contains(msg, "does not equal to price"):
+		return ErrSignatureRejected
+	}
+	return nil
+}
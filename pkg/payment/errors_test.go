@@ -0,0 +1,60 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifyDaemonError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), ErrDaemonUnavailable},
+		{"channel not found", status.Error(codes.NotFound, "channel is not found, channelId: 5"), ErrChannelNotFound},
+		{"not enough tokens", status.Error(codes.Unauthenticated, "not enough tokens on payment channel, channel amount: 10, payment amount: 20"), ErrInsufficientChannelFunds},
+		{"channel expired", status.Error(codes.Unauthenticated, "payment channel \"5\" is near to be expired, expiration time: 100, current block: 90"), ErrChannelExpired},
+		{"free calls exhausted", status.Error(codes.Unauthenticated, "free call limit has been exceeded, calls made = 5, total free calls eligible = 5"), ErrFreeCallsExhausted},
+		{"token expired", status.Error(codes.Unauthenticated, "token has expired"), ErrPrepaidTokenExpired},
+		{"bad signature", status.Error(codes.Unauthenticated, "incorrect payment signature"), ErrSignatureRejected},
+		{"unrecognised", status.Error(codes.Internal, "boom"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ClassifyDaemonError(fmt.Errorf("wrapped: %w", tt.err))
+
+			var de *DaemonError
+			if !errors.As(err, &de) {
+				t.Fatalf("ClassifyDaemonError returned %T, want *DaemonError", err)
+			}
+			if de.Kind != tt.want {
+				t.Fatalf("Kind = %v, want %v", de.Kind, tt.want)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+			if status.Code(err) != status.Code(tt.err) {
+				t.Fatalf("status code = %s, want %s", status.Code(err), status.Code(tt.err))
+			}
+		})
+	}
+}
+
+func TestClassifyDaemonErrorKeepsLocalErrors(t *testing.T) {
+	if ClassifyDaemonError(nil) != nil {
+		t.Fatal("nil error was classified")
+	}
+	if err := ClassifyDaemonError(context.Canceled); err != context.Canceled {
+		t.Fatalf("local error changed to %v", err)
+	}
+	classified := ClassifyDaemonError(status.Error(codes.Unavailable, "down"))
+	if again := ClassifyDaemonError(classified); again != classified {
+		t.Fatalf("classified error changed to %v", again)
+	}
+}
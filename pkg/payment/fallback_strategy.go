@@ -0,0 +1,371 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultFreeCallRecheck is how often FallbackStrategy asks the daemon for new
+// free calls while it pays through the fallback strategy.
+const defaultFreeCallRecheck = 10 * time.Minute
+
+// FreeCallCounter is a strategy that can report the free calls left for the
+// signer. FreeStrategy implements it.
+type FreeCallCounter interface {
+	Strategy
+	GetFreeCallsAvailable(ctx context.Context) (uint64, error)
+}
+
+// FallbackStrategy uses free calls while the daemon reports some left and
+// switches to a paid or prepaid fallback once they run out. The fallback is
+// built lazily on the first call that needs it, so users who stay within the
+// free quota never open a payment channel. While paying, it periodically
+// requests a new free-call token and switches back when free calls are
+// available again.
+//
+// FallbackStrategy implements Reserver: a free call reserves one of the
+// remaining free calls, and a paid call forwards to the fallback's Reserve
+// when it has one. It is safe for concurrent use. Asking the daemon for free
+// calls and building the fallback happen outside the state lock, one call at
+// a time; calls that need neither are not held up by them.
+type FallbackStrategy struct {
+	mu          sync.Mutex
+	switching   sync.Mutex // Held while free calls are counted or the fallback is built; taken before mu
+	free        FreeCallCounter
+	newFallback func(ctx context.Context) (Strategy, error)
+	fallback    Strategy
+	usingFree   bool
+	remaining   uint64
+	recheck     time.Duration
+	checkedAt   time.Time
+	now         func() time.Time
+	log         *zap.Logger
+}
+
+// FallbackOption customizes a FallbackStrategy.
+type FallbackOption func(*FallbackStrategy)
+
+// WithFreeCallRecheck sets how often the strategy checks for new free calls
+// while it uses the fallback. A non-positive interval disables switching back.
+func WithFreeCallRecheck(interval time.Duration) FallbackOption {
+	return func(f *FallbackStrategy) {
+		f.recheck = interval
+	}
+}
+
+// WithFallbackLogger makes the strategy log switches between free calls and
+// the fallback to l instead of zap.L().
+func WithFallbackLogger(l *zap.Logger) FallbackOption {
+	return func(f *FallbackStrategy) {
+		f.log = l
+	}
+}
+
+// NewFallbackStrategy returns a strategy that pays with free, and with the
+// strategy returned by newFallback once the free calls are exhausted.
+// newFallback must return a ready-to-use strategy (refreshed, if needed); it is
+// called at most once unless it fails.
+func NewFallbackStrategy(free FreeCallCounter, newFallback func(ctx context.Context) (Strategy, error), options ...FallbackOption) *FallbackStrategy {
+	f := &FallbackStrategy{
+		free:        free,
+		newFallback: newFallback,
+		usingFree:   true,
+		recheck:     defaultFreeCallRecheck,
+		now:         time.Now,
+	}
+	for _, opt := range options {
+		opt(f)
+	}
+	return f
+}
+
+// logger returns the logger set with WithFallbackLogger, or zap.L().
+func (f *FallbackStrategy) logger() *zap.Logger {
+	return loggerOrGlobal(f.log)
+}
+
+// Refresh requests a new free-call token and the number of free calls left,
+// switching to the fallback (and refreshing it) when none are left.
+func (f *FallbackStrategy) Refresh(ctx context.Context) error {
+	f.switching.Lock()
+	defer f.switching.Unlock()
+
+	err := f.free.Refresh(ctx)
+	if err == nil {
+		_, err = f.countFree(ctx)
+	}
+	if err != nil && !errors.Is(err, ErrFreeCallsExhausted) {
+		return err
+	}
+
+	f.mu.Lock()
+	if f.remaining > 0 {
+		f.usingFree = true
+		f.mu.Unlock()
+		return nil
+	}
+	fallback := f.fallback
+	if fallback != nil {
+		f.usingFree = false
+	}
+	f.mu.Unlock()
+
+	if fallback != nil {
+		return fallback.Refresh(ctx)
+	}
+	_, err = f.useFallback(ctx)
+	return err
+}
+
+// GRPCMetadata decorates ctx with the headers of the active strategy and
+// treats the call as committed. If the headers cannot be built, the failure
+// is logged and ctx is returned without them.
+func (f *FallbackStrategy) GRPCMetadata(ctx context.Context) context.Context {
+	reserved, r, err := f.Reserve(ctx)
+	if err != nil {
+		f.logger().Warn("payment headers", zap.Error(err))
+		return ctx
+	}
+	r.Commit()
+	return reserved
+}
+
+// Reserve picks the strategy for one call and reserves its payment: one free
+// call while any are left, the fallback's reservation otherwise. When the
+// chosen strategy is a Reserver and fails, the free call is returned and the
+// error is passed on.
+func (f *FallbackStrategy) Reserve(ctx context.Context) (context.Context, Reservation, error) {
+	free, active := f.choose(ctx)
+	if free {
+		reservation := &freeCallReservation{strategy: f}
+		r, ok := f.free.(Reserver)
+		if !ok {
+			return f.free.GRPCMetadata(ctx), reservation, nil
+		}
+		ctx, inner, err := r.Reserve(ctx)
+		if err != nil {
+			reservation.Rollback()
+			return nil, nil, err
+		}
+		reservation.inner = inner
+		return ctx, reservation, nil
+	}
+	if r, ok := active.(Reserver); ok {
+		return r.Reserve(ctx)
+	}
+	return active.GRPCMetadata(ctx), noopReservation{}, nil
+}
+
+// GetFreeCallsAvailable asks the daemon for the free calls left.
+func (f *FallbackStrategy) GetFreeCallsAvailable(ctx context.Context) (uint64, error) {
+	return f.free.GetFreeCallsAvailable(ctx)
+}
+
+// UsingFree reports whether the next call is expected to be a free call.
+func (f *FallbackStrategy) UsingFree() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.usingFree
+}
+
+// Fallback returns the fallback strategy, or nil if it has not been built yet.
+func (f *FallbackStrategy) Fallback() Strategy {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fallback
+}
+
+// FreeCallsExhausted records that the daemon rejected a free call because the
+// quota ran out, so the next call uses the fallback. It reports whether the
+// strategy was using free calls, i.e. whether retrying the call can help.
+func (f *FallbackStrategy) FreeCallsExhausted() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.usingFree {
+		return false
+	}
+	f.remaining = 0
+	f.usingFree = false
+	f.checkedAt = f.now()
+	return true
+}
+
+// choose picks the strategy for the next call: a free call (free is true and
+// one of the remaining free calls is taken) or active, the fallback. It
+// switches between free calls and the fallback as needed, with f.switching
+// held, so that only one call asks the daemon or builds the fallback. While a
+// due recheck for new free calls is running, other calls keep paying with the
+// fallback. If the free calls left cannot be counted or the fallback cannot be
+// built, it keeps using free calls so that the daemon reports the actual
+// state.
+func (f *FallbackStrategy) choose(ctx context.Context) (free bool, active Strategy) {
+	f.mu.Lock()
+	free, active, ok := f.chooseLocked()
+	recheck, fallback := f.recheckDueLocked(), f.fallback
+	f.mu.Unlock()
+	if ok {
+		return free, active
+	}
+
+	if recheck {
+		if !f.switching.TryLock() {
+			return false, fallback
+		}
+	} else {
+		f.switching.Lock()
+	}
+	defer f.switching.Unlock()
+
+	// Another call may have switched while this one waited.
+	f.mu.Lock()
+	free, active, ok = f.chooseLocked()
+	check := f.usingFree && f.remaining == 0
+	recheck = f.recheckDueLocked()
+	f.mu.Unlock()
+	if ok {
+		return free, active
+	}
+
+	switch {
+	case check:
+		// The count may be stale (for example after a new token was issued).
+		if _, err := f.countFree(ctx); err != nil && !errors.Is(err, ErrFreeCallsExhausted) {
+			// The count is unknown: keep using free calls rather than pay,
+			// so that the daemon reports the actual state.
+			f.logger().Warn("check free calls", zap.Error(err))
+			return true, nil
+		}
+		f.mu.Lock()
+		f.usingFree = f.remaining > 0
+		f.mu.Unlock()
+	case recheck:
+		if err := f.free.Refresh(ctx); err == nil {
+			if available, err := f.countFree(ctx); err == nil && available > 0 {
+				f.logger().Info("free calls available again, leaving the fallback", zap.Uint64("free_calls", available))
+				f.mu.Lock()
+				f.usingFree = true
+				f.mu.Unlock()
+			}
+		}
+		f.mu.Lock()
+		f.checkedAt = f.now()
+		f.mu.Unlock()
+	}
+
+	f.mu.Lock()
+	free, active, ok = f.chooseLocked()
+	f.mu.Unlock()
+	if ok {
+		return free, active
+	}
+
+	active, err := f.useFallback(ctx)
+	if err != nil {
+		f.logger().Warn("free calls exhausted and the fallback is unavailable", zap.Error(err))
+		return true, nil
+	}
+	return false, active
+}
+
+// chooseLocked picks the strategy for the next call when that needs no call
+// to the daemon: a remaining free call, which it takes, or the fallback when
+// no recheck for new free calls is due. ok is false otherwise. f.mu must be
+// held.
+func (f *FallbackStrategy) chooseLocked() (free bool, active Strategy, ok bool) {
+	switch {
+	case f.usingFree && f.remaining > 0:
+		f.remaining--
+		return true, nil, true
+	case !f.usingFree && f.fallback != nil && !f.recheckDueLocked():
+		return false, f.fallback, true
+	}
+	return false, nil, false
+}
+
+// recheckDueLocked reports whether the strategy pays with the fallback and it
+// is time to ask the daemon for new free calls. f.mu must be held.
+func (f *FallbackStrategy) recheckDueLocked() bool {
+	return !f.usingFree && f.fallback != nil && f.recheck > 0 && f.now().Sub(f.checkedAt) >= f.recheck
+}
+
+// countFree asks the daemon for the free calls left and records the answer.
+// Only ErrFreeCallsExhausted counts as none left; after other failures the
+// count is kept. f.switching must be held.
+func (f *FallbackStrategy) countFree(ctx context.Context) (uint64, error) {
+	available, err := f.free.GetFreeCallsAvailable(ctx)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.checkedAt = f.now()
+	if err != nil {
+		if errors.Is(err, ErrFreeCallsExhausted) {
+			f.remaining = 0
+		}
+		return 0, err
+	}
+	f.remaining = available
+	return available, nil
+}
+
+// useFallback builds the fallback strategy on first use and makes it active.
+// f.switching must be held.
+func (f *FallbackStrategy) useFallback(ctx context.Context) (Strategy, error) {
+	f.mu.Lock()
+	fallback := f.fallback
+	f.mu.Unlock()
+
+	if fallback == nil {
+		if f.newFallback == nil {
+			return nil, errors.New("no fallback strategy configured")
+		}
+		var err error
+		if fallback, err = f.newFallback(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	f.mu.Lock()
+	f.fallback = fallback
+	f.usingFree = false
+	f.mu.Unlock()
+	return fallback, nil
+}
+
+// freeCallReservation returns a reserved free call to the pool on rollback.
+// inner is the reservation of the free strategy, if it is a Reserver.
+type freeCallReservation struct {
+	once     sync.Once
+	strategy *FallbackStrategy
+	inner    Reservation
+}
+
+func (r *freeCallReservation) Commit() {
+	r.once.Do(func() {
+		if r.inner != nil {
+			r.inner.Commit()
+		}
+	})
+}
+
+func (r *freeCallReservation) Rollback() {
+	r.once.Do(func() {
+		if r.inner != nil {
+			r.inner.Rollback()
+		}
+		r.strategy.mu.Lock()
+		if r.strategy.usingFree {
+			r.strategy.remaining++
+		}
+		r.strategy.mu.Unlock()
+	})
+}
+
+// noopReservation is the reservation of strategies that do not implement
+// Reserver.
+type noopReservation struct{}
+
+func (noopReservation) Commit()   {}
+func (noopReservation) Rollback() {}
@@ -0,0 +1,264 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/metadata"
+)
+
+// countingFree is a FreeCallCounter whose daemon reports available free calls.
+type countingFree struct {
+	available uint64
+	refreshes int
+}
+
+func (c *countingFree) GRPCMetadata(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, PaymentTypeHeader, "free-call")
+}
+
+func (c *countingFree) Refresh(context.Context) error {
+	c.refreshes++
+	return nil
+}
+
+func (c *countingFree) GetFreeCallsAvailable(context.Context) (uint64, error) {
+	return c.available, nil
+}
+
+// unsignedFree is a countingFree whose headers cannot be signed.
+type unsignedFree struct {
+	countingFree
+}
+
+func (u *unsignedFree) Reserve(context.Context) (context.Context, Reservation, error) {
+	return nil, nil, errors.New("signer is required")
+}
+
+type escrowStub struct{}
+
+func (escrowStub) GRPCMetadata(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, PaymentTypeHeader, "escrow")
+}
+
+func (escrowStub) Refresh(context.Context) error { return nil }
+
+func paymentType(t *testing.T, ctx context.Context) string {
+	t.Helper()
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if v := md.Get(PaymentTypeHeader); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func TestFallbackStrategy_SwitchesToFallbackWhenFreeCallsRunOut(t *testing.T) {
+	free := &countingFree{available: 2}
+	built := 0
+	f := NewFallbackStrategy(free, func(context.Context) (Strategy, error) {
+		built++
+		return escrowStub{}, nil
+	})
+	ctx := context.Background()
+	if err := f.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	first, r, _ := f.Reserve(ctx)
+	if got := paymentType(t, first); got != "free-call" {
+		t.Fatalf("first call paid with %q; want free-call", got)
+	}
+	r.Rollback() // the call never reached the daemon, so the free call is returned
+
+	for i := 0; i < 2; i++ {
+		ctx, r, _ := f.Reserve(context.Background())
+		if got := paymentType(t, ctx); got != "free-call" {
+			t.Fatalf("call %d paid with %q; want free-call", i, got)
+		}
+		r.Commit()
+	}
+	if built != 0 {
+		t.Fatal("fallback built while free calls were left")
+	}
+
+	free.available = 0
+	for i := 0; i < 2; i++ {
+		ctx, r, _ := f.Reserve(context.Background())
+		if got := paymentType(t, ctx); got != "escrow" {
+			t.Fatalf("call after quota paid with %q; want escrow", got)
+		}
+		r.Commit()
+	}
+	if built != 1 {
+		t.Fatalf("fallback built %d times; want 1", built)
+	}
+}
+
+func TestFallbackStrategy_ReturnsFreeCallWhenHeadersFail(t *testing.T) {
+	free := &unsignedFree{countingFree{available: 1}}
+	f := NewFallbackStrategy(free, func(context.Context) (Strategy, error) {
+		return escrowStub{}, nil
+	})
+	if err := f.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	if _, _, err := f.Reserve(context.Background()); err == nil {
+		t.Fatal("Reserve succeeded without a signature")
+	}
+	if !f.UsingFree() || f.remaining != 1 {
+		t.Fatalf("usingFree=%v remaining=%d; want the free call returned", f.UsingFree(), f.remaining)
+	}
+}
+
+func TestFallbackStrategy_KeepsFreeCallsWhenBlockNumberFails(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockErr := errors.New("rpc unavailable")
+	var failing bool
+	free := &FreeStrategy{
+		signer:        mustSigner(t, priv),
+		signerAddress: crypto.PubkeyToAddress(priv.PublicKey),
+		stateClient:   &mockFreeCallClient{token: &FreeCallToken{Token: []byte("token")}, available: 1},
+		blockNumber: func(context.Context) (*big.Int, error) {
+			if failing {
+				return nil, blockErr
+			}
+			return big.NewInt(55), nil
+		},
+	}
+	built := 0
+	f := NewFallbackStrategy(free, func(context.Context) (Strategy, error) {
+		built++
+		return escrowStub{}, nil
+	})
+	if err := f.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	_, r, err := f.Reserve(context.Background())
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	r.Commit()
+
+	failing = true
+	if err := f.Refresh(context.Background()); !errors.Is(err, blockErr) {
+		t.Fatalf("Refresh = %v; want %v", err, blockErr)
+	}
+	if _, _, err := f.Reserve(context.Background()); !errors.Is(err, blockErr) {
+		t.Fatalf("Reserve = %v; want %v", err, blockErr)
+	}
+	if built != 0 || !f.UsingFree() {
+		t.Fatalf("fallback built %d times, UsingFree = %v; want free calls kept", built, f.UsingFree())
+	}
+}
+
+func TestFallbackStrategy_SwitchesBackWhenFreeCallsReturn(t *testing.T) {
+	free := &countingFree{available: 5}
+	now := time.Unix(1000, 0)
+	core, logs := observer.New(zap.InfoLevel)
+	f := NewFallbackStrategy(free, func(context.Context) (Strategy, error) {
+		return escrowStub{}, nil
+	}, WithFreeCallRecheck(time.Minute), WithFallbackLogger(zap.New(core)))
+	f.now = func() time.Time { return now }
+	if err := f.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	// The daemon rejected a free call: the next one is paid even though the
+	// cached count says otherwise.
+	free.available = 0
+	if !f.FreeCallsExhausted() {
+		t.Fatal("FreeCallsExhausted = false while using free calls")
+	}
+	if f.FreeCallsExhausted() {
+		t.Fatal("FreeCallsExhausted = true while already paying")
+	}
+	if ctx, _, _ := f.Reserve(context.Background()); paymentType(t, ctx) != "escrow" {
+		t.Fatal("call after exhaustion was not paid")
+	}
+
+	free.available = 3
+	now = now.Add(30 * time.Second)
+	if ctx, _, _ := f.Reserve(context.Background()); paymentType(t, ctx) != "escrow" {
+		t.Fatal("switched back to free calls before the recheck interval")
+	}
+
+	refreshes := free.refreshes
+	now = now.Add(time.Minute)
+	if ctx, _, _ := f.Reserve(context.Background()); paymentType(t, ctx) != "free-call" {
+		t.Fatal("did not switch back to free calls after the recheck interval")
+	}
+	if free.refreshes != refreshes+1 {
+		t.Fatal("free-call token not refreshed before switching back")
+	}
+	if !f.UsingFree() {
+		t.Fatal("UsingFree = false after switching back")
+	}
+	entries := logs.FilterMessageSnippet("free calls available again").AllUntimed()
+	if len(entries) != 1 || entries[0].ContextMap()["free_calls"] != uint64(3) {
+		t.Fatalf("log entries = %+v; want one switch back with free_calls=3", logs.AllUntimed())
+	}
+}
+
+// gatedFree is a FreeCallCounter whose count blocks once gate is set, until
+// gate is closed.
+type gatedFree struct {
+	countingFree
+	entered chan struct{}
+	gate    chan struct{}
+}
+
+func (g *gatedFree) GetFreeCallsAvailable(ctx context.Context) (uint64, error) {
+	if g.gate != nil {
+		close(g.entered)
+		<-g.gate
+	}
+	return g.available, nil
+}
+
+func TestFallbackStrategy_PaysWhileRecheckRuns(t *testing.T) {
+	free := &gatedFree{}
+	now := time.Unix(1000, 0)
+	built := 0
+	f := NewFallbackStrategy(free, func(context.Context) (Strategy, error) {
+		built++
+		return escrowStub{}, nil
+	}, WithFreeCallRecheck(time.Minute))
+	f.now = func() time.Time { return now }
+	if err := f.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	free.available = 1
+	free.entered, free.gate = make(chan struct{}), make(chan struct{})
+	rechecked := make(chan string)
+	go func() {
+		ctx, _, _ := f.Reserve(context.Background())
+		rechecked <- paymentType(t, ctx)
+	}()
+	<-free.entered
+
+	if ctx, _, _ := f.Reserve(context.Background()); paymentType(t, ctx) != "escrow" {
+		t.Fatal("call during the recheck was not paid with the fallback")
+	}
+	if f.UsingFree() {
+		t.Fatal("UsingFree = true before the recheck finished")
+	}
+	close(free.gate)
+	if got := <-rechecked; got != "free-call" {
+		t.Fatalf("rechecking call paid with %q; want free-call", got)
+	}
+	if built != 1 {
+		t.Fatalf("fallback built %d times; want 1", built)
+	}
+}
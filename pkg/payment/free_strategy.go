@@ -3,16 +3,18 @@ package payment
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"errors"
-	"log"
+	"fmt"
 	"math/big"
 	"strconv"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shamank/snet-sdk-go/pkg/blockchain"
 	"github.com/shamank/snet-sdk-go/pkg/grpc"
 	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -20,44 +22,67 @@ import (
 // It obtains a short-lived free-call token from the daemon's FreeCallState service,
 // and attaches the required gRPC metadata (token, user address, signed message,
 // current block) to each request.
+//
+// FreeStrategy is safe for concurrent use: Refresh replaces the token while
+// GRPCMetadata and GetFreeCallsAvailable read it under a lock.
 type FreeStrategy struct {
-	Token            []byte
-	orgID            string
-	groupID          string
-	serviceID        string
-	evmClient        *blockchain.EVMClient
-	grpcClient       *grpc.Client
-	serviceMetadata  *model.ServiceMetadata
-	signerPrivateKey *ecdsa.PrivateKey
-	signerAddress    common.Address
-	stateClient      FreeCallStateServiceClient
-	tokenLifetime    *uint64
-	blockNumber      func(context.Context) (*big.Int, error)
+	mu              sync.RWMutex
+	Token           []byte
+	orgID           string
+	groupID         string
+	serviceID       string
+	evmClient       *blockchain.EVMClient
+	grpcClient      *grpc.Client
+	serviceMetadata *model.ServiceMetadata
+	signer          signer.Signer
+	signerAddress   common.Address
+	stateClient     FreeCallStateServiceClient
+	tokenLifetime   *uint64
+	blockNumber     func(context.Context) (*big.Int, error)
+	log             *zap.Logger
+}
+
+// FreeStrategyOption configures construction of a FreeStrategy.
+type FreeStrategyOption func(*FreeStrategy)
+
+// WithFreeLogger makes the strategy log to l instead of zap.L().
+func WithFreeLogger(l *zap.Logger) FreeStrategyOption {
+	return func(f *FreeStrategy) {
+		f.log = l
+	}
 }
 
 // NewFreeStrategy constructs a FreeStrategy for the given org/service/group.
-// The private key must be valid and corresponds to the user (caller) address.
+// The signer signs for the user (caller) address.
 // tokenLifetime controls the requested token lifetime in blocks (nil = daemon default).
-func NewFreeStrategy(evm *blockchain.EVMClient, grpc *grpc.Client, orgID, serviceID, groupID string, privateKey *ecdsa.PrivateKey, tokenLifetime *uint64) (Strategy, error) {
-	addr := blockchain.GetAddressFromPrivateKeyECDSA(privateKey)
-	if addr == nil {
-		return nil, errors.New("invalid private key")
-	}
-
-	return &FreeStrategy{
-		evmClient:        evm,
-		grpcClient:       grpc,
-		serviceID:        serviceID,
-		orgID:            orgID,
-		groupID:          groupID,
-		signerPrivateKey: privateKey,
-		signerAddress:    *blockchain.GetAddressFromPrivateKeyECDSA(privateKey),
-		stateClient:      NewFreeCallStateServiceClient(grpc.GRPC),
-		tokenLifetime:    tokenLifetime,
+func NewFreeStrategy(evm *blockchain.EVMClient, grpc *grpc.Client, orgID, serviceID, groupID string, s signer.Signer, tokenLifetime *uint64, opts ...FreeStrategyOption) (Strategy, error) {
+	if s == nil {
+		return nil, errors.New("signer is required for free calls")
+	}
+
+	f := &FreeStrategy{
+		evmClient:     evm,
+		grpcClient:    grpc,
+		serviceID:     serviceID,
+		orgID:         orgID,
+		groupID:       groupID,
+		signer:        s,
+		signerAddress: s.Address(),
+		stateClient:   NewFreeCallStateServiceClient(grpc.Conn()),
+		tokenLifetime: tokenLifetime,
 		blockNumber: func(ctx context.Context) (*big.Int, error) {
 			return evm.GetCurrentBlockNumberCtx(ctx)
 		},
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f, nil
+}
+
+// logger returns the logger set with WithFreeLogger, or zap.L().
+func (f *FreeStrategy) logger() *zap.Logger {
+	return loggerOrGlobal(f.log)
 }
 
 // Refresh requests (or refreshes) a free-call token from the daemon.
@@ -72,7 +97,10 @@ func (f *FreeStrategy) Refresh(ctx context.Context) error {
 		return err
 	}
 	msg := f.msgForNewFreeCallToken(number.Uint64())
-	signedMsg := blockchain.GetSignature(msg, f.signerPrivateKey)
+	signedMsg, err := blockchain.SignMessage(ctx, f.signer, msg)
+	if err != nil {
+		return fmt.Errorf("sign free-call token request: %w", err)
+	}
 	token, err := f.stateClient.GetFreeCallToken(ctx, &GetFreeCallTokenRequest{
 		Address:               f.signerAddress.Hex(),
 		Signature:             signedMsg,
@@ -80,36 +108,55 @@ func (f *FreeStrategy) Refresh(ctx context.Context) error {
 		TokenLifetimeInBlocks: f.tokenLifetime,
 	})
 	if err != nil {
-		log.Println(err)
+		err = ClassifyDaemonError(err)
+		f.logger().Debug("free-call token request failed", zap.Error(err))
 		return err
 	}
+	f.mu.Lock()
 	f.Token = token.Token
+	f.mu.Unlock()
 	return nil
 }
 
 // GRPCMetadata injects the "free-call" authentication headers into the outgoing
 // context, including the token, user address, signature and current block.
-// Returns a derived context; if the current block cannot be read, it returns nil.
+// Returns a derived context; if the current block cannot be read or the
+// headers cannot be signed, it returns nil.
 func (f *FreeStrategy) GRPCMetadata(ctx context.Context) context.Context {
-	if f.blockNumber == nil {
+	ctx, _, err := f.Reserve(ctx)
+	if err != nil {
+		f.logger().Warn("free-call headers", zap.Error(err))
 		return nil
 	}
+	return ctx
+}
+
+// Reserve is like GRPCMetadata but reports why the headers could not be
+// built. A free call holds no state to commit, so the Reservation is a no-op.
+func (f *FreeStrategy) Reserve(ctx context.Context) (context.Context, Reservation, error) {
+	if f.blockNumber == nil {
+		return nil, nil, errors.New("block number provider not configured")
+	}
 	number, err := f.blockNumber(ctx)
 	if err != nil {
-		return nil
+		return nil, nil, err
 	}
 
-	msg := f.msgForFreeCall(number.Uint64())
-	signedMsg := blockchain.GetSignature(msg, f.signerPrivateKey)
+	token := f.token()
+	msg := f.msgForFreeCallWithToken(number.Uint64(), token)
+	signedMsg, err := sign(ctx, f.signer, msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sign free call: %w", err)
+	}
 
 	md := metadata.Pairs(
 		PaymentTypeHeader, "free-call",
-		FreeCallAuthTokenHeader, string(f.Token),
+		FreeCallAuthTokenHeader, string(token),
 		FreeCallUserAddressHeader, f.signerAddress.Hex(),
 		PaymentChannelSignatureHeader, string(signedMsg),
 		CurrentBlockNumberHeader, strconv.FormatInt(int64(number.Uint64()), 10),
 	)
-	return metadata.NewOutgoingContext(ctx, md)
+	return metadata.NewOutgoingContext(ctx, md), noopReservation{}, nil
 }
 
 // GetFreeCallsAvailable queries the daemon for the remaining number of free
@@ -121,17 +168,22 @@ func (f *FreeStrategy) GetFreeCallsAvailable(ctx context.Context) (uint64, error
 	}
 	number, err := f.blockNumber(ctx)
 	if err != nil {
-		return 0, nil
+		return 0, fmt.Errorf("get block number: %w", err)
+	}
+	token := f.token()
+	msg := f.msgForFreeCallWithToken(number.Uint64(), token)
+	signature, err := blockchain.SignMessage(ctx, f.signer, msg)
+	if err != nil {
+		return 0, fmt.Errorf("sign free calls request: %w", err)
 	}
-	msg := f.msgForFreeCall(number.Uint64())
 	resp, err := f.stateClient.GetFreeCallsAvailable(ctx, &FreeCallStateRequest{
 		Address:       f.signerAddress.Hex(),
-		FreeCallToken: f.Token,
-		Signature:     blockchain.GetSignature(msg, f.signerPrivateKey),
+		FreeCallToken: token,
+		Signature:     signature,
 		CurrentBlock:  number.Uint64(),
 	})
 	if err != nil {
-		return 0, err
+		return 0, ClassifyDaemonError(err)
 	}
 	return resp.FreeCallsAvailable, nil
 }
@@ -151,9 +203,22 @@ func (f *FreeStrategy) msgForNewFreeCallToken(currentBlockNumber uint64) []byte
 	}, nil)
 }
 
+// token returns the current free-call token.
+func (f *FreeStrategy) token() []byte {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.Token
+}
+
 // msgForFreeCall builds the message that authorizes a free-call with the current
 // token and freshness block.
 func (f *FreeStrategy) msgForFreeCall(currentBlockNumber uint64) []byte {
+	return f.msgForFreeCallWithToken(currentBlockNumber, f.token())
+}
+
+// msgForFreeCallWithToken is like msgForFreeCall but signs over the given
+// token, so the header and the signature always agree during a Refresh.
+func (f *FreeStrategy) msgForFreeCallWithToken(currentBlockNumber uint64, token []byte) []byte {
 	return bytes.Join([][]byte{
 		[]byte(FreeCallPrefixSignature), // prefix
 		[]byte(f.signerAddress.Hex()),   // user address
@@ -161,6 +226,6 @@ func (f *FreeStrategy) msgForFreeCall(currentBlockNumber uint64) []byte {
 		[]byte(f.serviceID),
 		[]byte(f.groupID),
 		bigIntToBytes(big.NewInt(int64(currentBlockNumber))),
-		f.Token,
+		token,
 	}, nil)
 }
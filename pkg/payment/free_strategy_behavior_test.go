@@ -38,13 +38,13 @@ func TestFreeStrategyRefreshAndMetadata(t *testing.T) {
 	}
 
 	strategy := &FreeStrategy{
-		Token:            nil,
-		orgID:            "org",
-		groupID:          "group",
-		serviceID:        "service",
-		signerPrivateKey: priv,
-		signerAddress:    crypto.PubkeyToAddress(priv.PublicKey),
-		stateClient:      mock,
+		Token:         nil,
+		orgID:         "org",
+		groupID:       "group",
+		serviceID:     "service",
+		signer:        mustSigner(t, priv),
+		signerAddress: crypto.PubkeyToAddress(priv.PublicKey),
+		stateClient:   mock,
 		blockNumber: func(ctx context.Context) (*big.Int, error) {
 			return big.NewInt(55), nil
 		},
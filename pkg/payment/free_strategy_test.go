@@ -13,12 +13,12 @@ func TestFreeStrategy_msgForFreeCall(t *testing.T) {
 	addr := gethcrypto.PubkeyToAddress(priv.PublicKey)
 
 	f := &FreeStrategy{
-		Token:            []byte("tok"),
-		orgID:            "orgX",
-		groupID:          "grpY",
-		serviceID:        "svcZ",
-		signerPrivateKey: priv,
-		signerAddress:    addr,
+		Token:         []byte("tok"),
+		orgID:         "orgX",
+		groupID:       "grpY",
+		serviceID:     "svcZ",
+		signer:        mustSigner(t, priv),
+		signerAddress: addr,
 	}
 
 	block := uint64(12345)
@@ -37,7 +37,7 @@ func Test_PaidStrategy_Metadata_Propagates(t *testing.T) {
 		nonce:           big.NewInt(9),
 		signedAmount:    big.NewInt(777),
 		priceInCogs:     big.NewInt(1),
-		privateKeyECDSA: priv,
+		signer:          mustSigner(t, priv),
 	}
 	ctx = ps.GRPCMetadata(ctx)
 
@@ -3,36 +3,98 @@ package payment
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"errors"
 	"fmt"
-	"log"
 	"math/big"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shamank/snet-sdk-go/pkg/blockchain"
 	"github.com/shamank/snet-sdk-go/pkg/grpc"
 	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 	grpcconn "google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/status"
 )
 
 // PaidStrategy implements the "escrow" payment flow backed by the
 // MultiPartyEscrow (MPE) contract. It maintains the channel ID, current
 // nonce, and a running signed amount to authorize server withdrawals.
+//
+// PaidStrategy is safe for concurrent use. Every call reserves the next
+// amount under a lock, so concurrent calls never sign the same amount twice;
+// signing happens after the lock is released and the channel state store is
+// written in the background, so neither a slow signer nor the disk holds up
+// other calls.
 type PaidStrategy struct {
+	mu              sync.Mutex
 	evmClient       *blockchain.EVMClient
 	grpcClient      *grpc.Client
 	serviceMetadata *model.ServiceMetadata
+	serviceGroup    *model.ServiceGroup // Source of per-method prices; nil means priceInCogs for every call
 	channelID       *big.Int
 	nonce           *big.Int
-	signedAmount    *big.Int
-	priceInCogs     *big.Int
-	privateKeyECDSA *ecdsa.PrivateKey
+	signedAmount    *big.Int // Highest amount signed so far, including pending reservations
+	committed       *big.Int // Highest amount known to have reached the daemon; nil until the first reservation
+	pending         map[*paidReservation]struct{}
+	gen             uint64   // Incremented when the nonce changes; reservations of an older generation are ignored
+	priceInCogs     *big.Int // Price of calls without a per-method or dynamic price
+	signer          signer.Signer
+	store           ChannelStateStore // Written in the background on every signed amount change; nil keeps state in memory only
+	states          stateWriter       // Writes to store outside mu
+	log             *zap.Logger
+}
+
+// paidReservation is a signed amount held by one in-flight escrow call.
+type paidReservation struct {
+	strategy *PaidStrategy
+	gen      uint64 // Generation of the strategy the amount was reserved in
+	amount   *big.Int
+	price    *big.Int             // Part of amount added for this call
+	tel      *telemetry.Telemetry // Records price as signed on Commit
+}
+
+// Commit records that the call carrying the reserved amount reached the daemon.
+func (r *paidReservation) Commit() {
+	p := r.strategy
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r.gen != p.gen {
+		return
+	}
+	if _, ok := p.pending[r]; !ok {
+		return
+	}
+	delete(p.pending, r)
+	if p.committed == nil || r.amount.Cmp(p.committed) > 0 {
+		p.committed = r.amount
+	}
+	r.tel.RecordCogs(context.Background(), r.price, telemetry.PaymentTypeKey.String("escrow"))
+}
+
+// Rollback releases the reserved amount. When no later reservation has reached
+// the daemon, the running total goes back to the highest amount still in use,
+// so the next call signs the released amount again.
+func (r *paidReservation) Rollback() {
+	p := r.strategy
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r.gen != p.gen {
+		return
+	}
+	if _, ok := p.pending[r]; !ok {
+		return
+	}
+	delete(p.pending, r)
+	top := p.topLocked()
+	if top.Cmp(p.signedAmount) != 0 {
+		p.signedAmount = top
+		p.persistLocked()
+	}
 }
 
 // ChainOperations captures blockchain interactions required by the paid
@@ -40,14 +102,14 @@ type PaidStrategy struct {
 type ChainOperations interface {
 	CurrentBlock(ctx context.Context) (*big.Int, error)
 	NetworkID(ctx context.Context) (*big.Int, error)
-	BuildBindOpts(from common.Address, currentBlockNumber, chainID *big.Int, key *ecdsa.PrivateKey, ctx context.Context) (*blockchain.BindOpts, error)
+	BuildBindOpts(from common.Address, currentBlockNumber, chainID *big.Int, s signer.Signer, ctx context.Context) (*blockchain.BindOpts, error)
 	FilterChannels(senders, recipients []common.Address, groupIDs [][32]byte, opts *blockchain.BindOpts) (*blockchain.MultiPartyEscrowChannelOpen, error)
 	EnsurePaymentChannel(mpe common.Address, filtered *blockchain.MultiPartyEscrowChannelOpen, currentSigned, price, desiredExpiration *big.Int, opts *blockchain.BindOpts, chans *blockchain.ChansToWatch, senders, recipients []common.Address, groupIDs [][32]byte) (*big.Int, error)
 }
 
 // ChannelStateClient reads the current daemon channel state.
 type ChannelStateClient interface {
-	ChannelState(conn *grpcconn.ClientConn, ctx context.Context, mpe common.Address, channelID, currentBlock *big.Int, key *ecdsa.PrivateKey) (*ChannelStateReply, error)
+	ChannelState(conn grpcconn.ClientConnInterface, ctx context.Context, mpe common.Address, channelID, currentBlock *big.Int, s signer.Signer) (*ChannelStateReply, error)
 }
 
 // PaidStrategyDependencies groups optional overrides for blockchain and daemon access.
@@ -64,6 +126,8 @@ type PaidStrategyOption func(*paidStrategyConfig)
 type paidStrategyConfig struct {
 	chain        ChainOperations    // Blockchain operations implementation
 	channelState ChannelStateClient // Channel state client implementation
+	store        ChannelStateStore  // Persistent channel state; nil disables persistence
+	log          *zap.Logger        // Logger; nil logs to zap.L()
 }
 
 // WithPaidStrategyDependencies overrides default dependencies used by NewPaidStrategy.
@@ -88,6 +152,30 @@ func WithPaidStrategyDependencies(deps PaidStrategyDependencies) PaidStrategyOpt
 	}
 }
 
+// WithChannelStateStore makes the strategy write its nonce and signed amount
+// through store and reconcile them with the daemon on construction and Refresh.
+// When the daemon cannot be reached during construction, the stored state of
+// the channel is used instead.
+//
+// Example:
+//
+//	store := NewFileChannelStateStore("/var/lib/myapp/channels.json")
+//	strategy, err := NewPaidStrategy(ctx, evm, grpc, meta, key, srvGroup, orgGroup,
+//		WithChannelStateStore(store))
+func WithChannelStateStore(store ChannelStateStore) PaidStrategyOption {
+	return func(cfg *paidStrategyConfig) {
+		cfg.store = store
+	}
+}
+
+// WithPaidLogger makes the strategy log channel selection and persistence
+// failures to l instead of zap.L().
+func WithPaidLogger(l *zap.Logger) PaidStrategyOption {
+	return func(cfg *paidStrategyConfig) {
+		cfg.log = l
+	}
+}
+
 // newPaidStrategyConfig creates a configuration with default dependencies.
 // It applies the provided options to customize the configuration.
 //
@@ -121,8 +209,8 @@ func (d defaultChainOperations) NetworkID(ctx context.Context) (*big.Int, error)
 	return d.evm.Client.NetworkID(ctx)
 }
 
-func (d defaultChainOperations) BuildBindOpts(from common.Address, currentBlockNumber, chainID *big.Int, key *ecdsa.PrivateKey, ctx context.Context) (*blockchain.BindOpts, error) {
-	transactOpts, err := blockchain.GetTransactOpts(chainID, key)
+func (d defaultChainOperations) BuildBindOpts(from common.Address, currentBlockNumber, chainID *big.Int, s signer.Signer, ctx context.Context) (*blockchain.BindOpts, error) {
+	transactOpts, err := blockchain.NewTransactOpts(ctx, s, chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -146,8 +234,8 @@ func (d defaultChainOperations) EnsurePaymentChannel(mpe common.Address, filtere
 // This is the production implementation used when no custom dependencies are provided.
 type defaultChannelStateClient struct{}
 
-func (defaultChannelStateClient) ChannelState(conn *grpcconn.ClientConn, ctx context.Context, mpe common.Address, channelID, currentBlock *big.Int, key *ecdsa.PrivateKey) (*ChannelStateReply, error) {
-	return GetChannelStateFromDaemon(conn, ctx, mpe, channelID, currentBlock, key)
+func (defaultChannelStateClient) ChannelState(conn grpcconn.ClientConnInterface, ctx context.Context, mpe common.Address, channelID, currentBlock *big.Int, s signer.Signer) (*ChannelStateReply, error) {
+	return GetChannelStateFromDaemon(conn, ctx, mpe, channelID, currentBlock, s)
 }
 
 // NewPaidStrategy builds a PaidStrategy, ensuring there is a usable payment
@@ -157,16 +245,19 @@ func (defaultChannelStateClient) ChannelState(conn *grpcconn.ClientConn, ctx con
 //  1. Resolve payment group ID and recipient address from metadata.
 //  2. Read current block, chain ID and prepare bind opts.
 //  3. Look up an existing channel (sender, recipient, groupID).
-//  4. If found, query the daemon for current nonce/signed amount.
-//  5. Ensure a valid channel (open/extend/add-funds as needed).
-//  6. Initialize signedAmount = currentSigned + single-call price.
+//  4. If found, query the daemon for current nonce/signed amount and
+//     reconcile it with the channel state store (see WithChannelStateStore).
+//  5. Ensure a valid channel (open/extend/add-funds as needed) that can cover
+//     the most expensive method of the group.
+//  6. Initialize signedAmount = currentSigned; each call then adds its own
+//     price (see Reserve).
 //
 // Parameters:
 //   - ctx: context for on-chain lookups and tx submission.
 //   - evm: initialized EVM client.
 //   - grpcCli: connected daemon gRPC client.
 //   - serviceMetadata: service metadata (for MPE address).
-//   - privateKeyECDSA: caller's signing key.
+//   - s: caller's signer.
 //   - serviceGroup: selected service group (for price & endpoints).
 //   - orgGroup: selected org group (for payment settings).
 //
@@ -178,7 +269,7 @@ func NewPaidStrategy(
 	evm *blockchain.EVMClient,
 	grpcCli *grpc.Client,
 	serviceMetadata *model.ServiceMetadata,
-	privateKeyECDSA *ecdsa.PrivateKey,
+	s signer.Signer,
 	serviceGroup *model.ServiceGroup,
 	orgGroup *model.OrganizationGroup,
 	opts ...PaidStrategyOption,
@@ -187,11 +278,15 @@ func NewPaidStrategy(
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if s == nil {
+		return nil, errors.New("signer is required for paid calls")
+	}
 
 	cfg := newPaidStrategyConfig(evm, opts)
+	log := loggerOrGlobal(cfg.log)
 
 	mpeAddress := common.HexToAddress(serviceMetadata.MPEAddress)
-	priceInCogs := serviceGroup.Pricing[0].PriceInCogs
+	priceInCogs := serviceGroup.DefaultPrice()
 
 	groupID, err := blockchain.DecodePaymentGroupID(orgGroup.ID)
 	if err != nil {
@@ -199,7 +294,7 @@ func NewPaidStrategy(
 	}
 
 	recipient := common.HexToAddress(orgGroup.PaymentDetails.PaymentAddress)
-	fromAddress := blockchain.GetAddressFromPrivateKeyECDSA(privateKeyECDSA)
+	fromAddress := s.Address()
 
 	currentBlockNumber, err := cfg.chain.CurrentBlock(ctx)
 	if err != nil {
@@ -211,7 +306,7 @@ func NewPaidStrategy(
 		return nil, err
 	}
 
-	bindOpts, err := cfg.chain.BuildBindOpts(*fromAddress, currentBlockNumber, chainID, privateKeyECDSA, ctx)
+	bindOpts, err := cfg.chain.BuildBindOpts(fromAddress, currentBlockNumber, chainID, s, ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +319,7 @@ func NewPaidStrategy(
 		Err:             make(chan error),
 	}
 
-	senders := []common.Address{*fromAddress}
+	senders := []common.Address{fromAddress}
 	recipients := []common.Address{recipient}
 	groupIDs := [][32]byte{groupID}
 
@@ -239,19 +334,25 @@ func NewPaidStrategy(
 	currentNonce := big.NewInt(0)
 
 	if filteredChannel != nil {
-		state, err := cfg.channelState.ChannelState(grpcCli.GRPC, ctx, mpeAddress, filteredChannel.ChannelId, currentBlockNumber, privateKeyECDSA)
+		state, err := cfg.channelState.ChannelState(grpcCli.Conn(), ctx, mpeAddress, filteredChannel.ChannelId, currentBlockNumber, s)
 		if err != nil {
-			st, ok := status.FromError(err)
+			err = ClassifyDaemonError(err)
 			switch {
-			case ok && (st.Code() == codes.NotFound || st.Code() == codes.Unknown) && strings.Contains(st.Message(), "channel is not found"):
-				log.Printf("paid strategy: channel %s not found in daemon, will create a new one", filteredChannel.ChannelId.String())
-				filteredChannel = nil
-			case strings.Contains(err.Error(), "channel is not found"):
-				log.Printf("paid strategy: channel %s not found in daemon, will create a new one", filteredChannel.ChannelId.String())
+			case errors.Is(err, ErrChannelNotFound):
+				log.Info("channel not found in daemon, opening a new one", zap.Stringer("channel", filteredChannel.ChannelId))
 				filteredChannel = nil
 			default:
-				log.Println("daemon err")
-				return nil, err
+				stored := loadChannelState(ctx, log, cfg.store, mpeAddress, filteredChannel.ChannelId)
+				if stored == nil || stored.Nonce == nil || stored.SignedAmount == nil {
+					return nil, err
+				}
+				log.Warn("daemon unavailable, using stored channel state",
+					zap.Stringer("channel", filteredChannel.ChannelId),
+					zap.Stringer("nonce", stored.Nonce),
+					zap.Error(err))
+				currentNonce = new(big.Int).Set(stored.Nonce)
+				currentSignedAmount = new(big.Int).Set(stored.SignedAmount)
+				state = nil
 			}
 		}
 		if filteredChannel != nil && state != nil {
@@ -264,31 +365,49 @@ func NewPaidStrategy(
 					return nil, errors.New("error while getting current nonce")
 				}
 			}
+			stored := loadChannelState(ctx, log, cfg.store, mpeAddress, filteredChannel.ChannelId)
+			currentSignedAmount = reconcileSignedAmount(currentNonce, currentSignedAmount, stored)
 		}
 	}
 
-	channelID, err := cfg.chain.EnsurePaymentChannel(mpeAddress, filteredChannel, currentSignedAmount, priceInCogs, newExpiration, bindOpts, chans, senders, recipients, groupIDs)
+	channelID, err := cfg.chain.EnsurePaymentChannel(mpeAddress, filteredChannel, currentSignedAmount, serviceGroup.MaxPrice(), newExpiration, bindOpts, chans, senders, recipients, groupIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Println("channel id EnsurePaymentChannel", channelID)
+	log.Debug("payment channel ready",
+		zap.Stringer("channel", channelID),
+		zap.Stringer("nonce", currentNonce),
+		zap.Stringer("signed_amount", currentSignedAmount))
+
+	if err := saveChannelState(ctx, cfg.store, mpeAddress, channelID, currentNonce, currentSignedAmount); err != nil {
+		return nil, fmt.Errorf("failed to persist channel state: %w", err)
+	}
 
 	return &PaidStrategy{
 		evmClient:       evm,
 		grpcClient:      grpcCli,
 		serviceMetadata: serviceMetadata,
-		privateKeyECDSA: privateKeyECDSA,
+		serviceGroup:    serviceGroup,
+		signer:          s,
 		signedAmount:    currentSignedAmount,
+		committed:       currentSignedAmount,
 		priceInCogs:     priceInCogs,
 		channelID:       channelID,
 		nonce:           currentNonce,
+		store:           cfg.store,
+		log:             cfg.log,
 	}, nil
 }
 
 // Refresh updates internal state (nonce, signedAmount) from daemon
 // to reflect concurrent usage of the channel by other clients.
 // After refresh, signedAmount reflects the current used amount from daemon.
+// Reservations that are still pending keep their amounts unless the daemon
+// reports a new nonce, in which case they are dropped. A higher amount found in
+// the channel state store for the same nonce (signed by an earlier run or
+// another process sharing the store) wins over the daemon's, and the result is
+// written back to the store.
 func (p *PaidStrategy) Refresh(ctx context.Context) error {
 	if ctx == nil {
 		var cancel context.CancelFunc
@@ -301,69 +420,186 @@ func (p *PaidStrategy) Refresh(ctx context.Context) error {
 		return fmt.Errorf("failed to get current block number: %w", err)
 	}
 
+	p.mu.Lock()
+	channelID := p.channelID
+	p.mu.Unlock()
+
 	mpeAddress := common.HexToAddress(p.serviceMetadata.MPEAddress)
 	channelState, err := GetChannelStateFromDaemon(
-		p.grpcClient.GRPC,
+		p.grpcClient.Conn(),
 		ctx,
 		mpeAddress,
-		p.channelID,
+		channelID,
 		currentBlock,
-		p.privateKeyECDSA,
+		p.signer,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to get channel state from daemon: %w", err)
 	}
+	p.logger().Debug("channel state reply", zap.Any("reply", channelState))
+
+	stored := loadChannelState(ctx, p.logger(), p.store, mpeAddress, channelID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
 	if b := channelState.GetCurrentNonce(); len(b) > 0 {
-		p.nonce = new(big.Int).SetBytes(b)
+		nonce := new(big.Int).SetBytes(b)
+		if p.nonce == nil || nonce.Cmp(p.nonce) != 0 {
+			p.pending = nil
+			p.gen++
+		}
+		p.nonce = nonce
 	}
 
 	if b := channelState.GetCurrentSignedAmount(); len(b) > 0 {
-		p.signedAmount = new(big.Int).SetBytes(b)
+		p.committed = new(big.Int).SetBytes(b)
+	}
+
+	top := p.topLocked()
+	if amount := reconcileSignedAmount(p.nonce, top, stored); amount.Cmp(top) > 0 {
+		p.committed = amount
 	}
+	p.signedAmount = p.topLocked()
+	p.persistLocked()
 
 	return nil
 }
 
 // GRPCMetadata returns a child context carrying escrow payment headers required
 // by the daemon (channel ID, nonce, total signed amount, and the claim signature).
-// It automatically increments signedAmount by priceInCogs for each call.
+// It automatically increments signedAmount by the price of the call (see
+// Reserve) and treats the call as committed; use Reserve to be able to roll it
+// back. If the claim cannot be signed, the failure is logged and ctx is
+// returned without payment headers.
 func (p *PaidStrategy) GRPCMetadata(ctx context.Context) context.Context {
-	p.signedAmount = new(big.Int).Add(p.signedAmount, p.priceInCogs)
+	paid, r, err := p.Reserve(ctx)
+	if err != nil {
+		p.logger().Warn("escrow headers", zap.Error(err))
+		return ctx
+	}
+	r.Commit()
+	return paid
+}
+
+// Reserve signs the next amount (signedAmount plus the price of the call) and
+// returns a context carrying the escrow headers together with the pending
+// Reservation. The price is the dynamic price from WithDynamicPrice, the
+// per-method price of the method from WithMethod, or priceInCogs. Commit the
+// reservation once the call has reached the daemon and roll it back otherwise.
+//
+// Only the amount is reserved under the strategy lock; the claim is signed
+// after the lock is released. If signing fails, the reservation is rolled
+// back and the error is returned.
+func (p *PaidStrategy) Reserve(ctx context.Context) (context.Context, Reservation, error) {
+	price, dynamic := callPrice(ctx, p.serviceGroup, p.priceInCogs)
+
+	p.mu.Lock()
+	if p.signedAmount == nil {
+		p.signedAmount = big.NewInt(0)
+	}
+	if p.committed == nil {
+		p.committed = p.signedAmount
+	}
+	if p.pending == nil {
+		p.pending = make(map[*paidReservation]struct{})
+	}
+
+	p.signedAmount = new(big.Int).Add(p.signedAmount, price)
+	r := &paidReservation{strategy: p, gen: p.gen, amount: p.signedAmount, price: price, tel: telemetry.FromContext(ctx)}
+	p.pending[r] = struct{}{}
+	p.persistLocked()
+	channelID, nonce := p.channelID, p.nonce
+	p.mu.Unlock()
+
+	signature, err := p.signMessage(ctx, channelID, nonce, r.amount)
+	if err != nil {
+		r.Rollback()
+		return nil, nil, fmt.Errorf("sign payment claim: %w", err)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		telemetry.Cogs(price),
+		telemetry.ChannelKey.String(channelID.String()))
 
 	md := metadata.Pairs(
 		PaymentTypeHeader, "escrow",
-		PaymentChannelIDHeader, p.channelID.String(),
-		PaymentChannelNonceHeader, p.nonce.String(),
-		PaymentChannelAmountHeader, p.signedAmount.String(),
-		PaymentChannelSignatureHeader, string(p.signMessage()),
+		PaymentChannelIDHeader, channelID.String(),
+		PaymentChannelNonceHeader, nonce.String(),
+		PaymentChannelAmountHeader, r.amount.String(),
+		PaymentChannelSignatureHeader, string(signature),
 	)
-	return metadata.NewOutgoingContext(ctx, md)
+	if dynamic {
+		md.Set(DynamicPriceDerived, price.String())
+	}
+	return metadata.NewOutgoingContext(ctx, md), r, nil
+}
+
+// logger returns the logger set with WithPaidLogger, or zap.L().
+func (p *PaidStrategy) logger() *zap.Logger {
+	return loggerOrGlobal(p.log)
+}
+
+// persistLocked queues the nonce and signed amount to be written through the
+// channel state store in the background. A failed write is logged rather than
+// failing the call; the amount is then only tracked in memory until the next
+// successful write. p.mu must be held.
+func (p *PaidStrategy) persistLocked() {
+	if p.store == nil || p.channelID == nil {
+		return
+	}
+	p.states.save(p.store, p.logger(), &ChannelState{
+		MPEAddress:   common.HexToAddress(p.serviceMetadata.MPEAddress),
+		ChannelID:    p.channelID,
+		Nonce:        p.nonce,
+		SignedAmount: p.signedAmount,
+		UpdatedAt:    time.Now().UTC(),
+	})
+}
+
+// topLocked returns the highest amount that is committed or still reserved.
+// p.mu must be held.
+func (p *PaidStrategy) topLocked() *big.Int {
+	top := p.committed
+	if top == nil {
+		top = big.NewInt(0)
+	}
+	for r := range p.pending {
+		if r.amount.Cmp(top) > 0 {
+			top = r.amount
+		}
+	}
+	return top
 }
 
 // signMessage builds and signs the MPE claim message used to authorize server
-// withdrawal up to p.signedAmount on (channelID, nonce). The message layout is:
+// withdrawal up to amount on (channelID, nonce). The message layout is:
 //
 //	concat(PrefixInSignature, MPEAddress, ChannelID, Nonce, SignedAmount)
 //
 // The resulting hash is signed using an Ethereum personal-sign style signature.
-func (p *PaidStrategy) signMessage() []byte {
+// p.mu must not be held: the signer may be slow (Clef waits for a person).
+func (p *PaidStrategy) signMessage(ctx context.Context, channelID, nonce, amount *big.Int) ([]byte, error) {
 	message := bytes.Join([][]byte{
 		[]byte(PrefixInSignature),
 		common.HexToAddress(p.serviceMetadata.MPEAddress).Bytes(),
-		bigIntToBytes(p.channelID),
-		bigIntToBytes(p.nonce),
-		bigIntToBytes(p.signedAmount),
+		bigIntToBytes(channelID),
+		bigIntToBytes(nonce),
+		bigIntToBytes(amount),
 	}, nil)
-	return blockchain.GetSignature(message, p.privateKeyECDSA)
+	return sign(ctx, p.signer, message)
 }
 
-// NextSignedAmount increments the locally tracked signed amount by price and
-// returns the new total. Use this after a successful call priced at 'price'.
+// NextSignedAmount increments the locally tracked signed amount by price,
+// records it as committed and returns a copy of the new total. Use this after
+// a successful call priced at 'price'.
 func (p *PaidStrategy) NextSignedAmount(price *big.Int) *big.Int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	if p.signedAmount == nil {
 		p.signedAmount = big.NewInt(0)
 	}
 	p.signedAmount = new(big.Int).Add(p.signedAmount, price)
-	return p.signedAmount
+	p.committed = new(big.Int).Set(p.signedAmount)
+	p.persistLocked()
+	return new(big.Int).Set(p.signedAmount)
 }
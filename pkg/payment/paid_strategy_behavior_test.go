@@ -2,7 +2,6 @@ package payment
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/base64"
 	"math/big"
 	"testing"
@@ -13,6 +12,7 @@ import (
 	"github.com/shamank/snet-sdk-go/pkg/blockchain"
 	sggrpc "github.com/shamank/snet-sdk-go/pkg/grpc"
 	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 	ogrpc "google.golang.org/grpc"
 )
 
@@ -76,7 +76,7 @@ func TestNewPaidStrategySuccess(t *testing.T) {
 		&blockchain.EVMClient{},
 		&sggrpc.Client{},
 		serviceMeta,
-		priv,
+		mustSigner(t, priv),
 		serviceMeta.Groups[0],
 		orgGroup,
 		WithPaidStrategyDependencies(PaidStrategyDependencies{
@@ -127,7 +127,7 @@ func (s *stubChainOps) NetworkID(context.Context) (*big.Int, error) {
 	return s.networkID, nil
 }
 
-func (s *stubChainOps) BuildBindOpts(common.Address, *big.Int, *big.Int, *ecdsa.PrivateKey, context.Context) (*blockchain.BindOpts, error) {
+func (s *stubChainOps) BuildBindOpts(common.Address, *big.Int, *big.Int, signer.Signer, context.Context) (*blockchain.BindOpts, error) {
 	return &blockchain.BindOpts{
 		Call:     &bind.CallOpts{},
 		Transact: &bind.TransactOpts{},
@@ -149,6 +149,6 @@ type stubChannelState struct {
 	reply *ChannelStateReply
 }
 
-func (s stubChannelState) ChannelState(*ogrpc.ClientConn, context.Context, common.Address, *big.Int, *big.Int, *ecdsa.PrivateKey) (*ChannelStateReply, error) {
+func (s stubChannelState) ChannelState(ogrpc.ClientConnInterface, context.Context, common.Address, *big.Int, *big.Int, signer.Signer) (*ChannelStateReply, error) {
 	return s.reply, nil
 }
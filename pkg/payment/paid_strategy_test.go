@@ -3,10 +3,15 @@ package payment
 import (
 	"context"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	gmd "google.golang.org/grpc/metadata"
 )
 
@@ -25,7 +30,7 @@ func TestPaidStrategy_GRPCMetadata_HeadersAndSignature(t *testing.T) {
 		nonce:           nonce,
 		signedAmount:    amount,
 		priceInCogs:     big.NewInt(1),
-		privateKeyECDSA: priv,
+		signer:          mustSigner(t, priv),
 	}
 
 	ctx := ps.GRPCMetadata(context.Background())
@@ -51,3 +56,257 @@ func TestPaidStrategy_GRPCMetadata_HeadersAndSignature(t *testing.T) {
 	sig := mustOne(t, md, PaymentChannelSignatureHeader)
 	_ = mustSignature65(t, sig) // Validates that the signature decodes to 65 bytes.
 }
+
+// TestPaidStrategy_ConcurrentReservationsAreUnique ensures concurrent calls never sign the same amount.
+func TestPaidStrategy_ConcurrentReservationsAreUnique(t *testing.T) {
+	ps := &PaidStrategy{
+		serviceMetadata: &model.ServiceMetadata{MPEAddress: "0x00000000000000000000000000000000000000Ab"},
+		channelID:       big.NewInt(1),
+		nonce:           big.NewInt(0),
+		signedAmount:    big.NewInt(100),
+		priceInCogs:     big.NewInt(10),
+		signer:          mustSigner(t, mustKey(t)),
+	}
+
+	const calls = 50
+	amounts := make(chan string, calls)
+	var wg sync.WaitGroup
+	for range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			md, _ := gmd.FromOutgoingContext(ps.GRPCMetadata(context.Background()))
+			amounts <- md.Get(PaymentChannelAmountHeader)[0]
+		}()
+	}
+	wg.Wait()
+	close(amounts)
+
+	seen := make(map[string]bool)
+	for a := range amounts {
+		if seen[a] {
+			t.Fatalf("amount %s signed twice", a)
+		}
+		seen[a] = true
+	}
+	if got := ps.signedAmount.String(); got != "600" {
+		t.Fatalf("signedAmount=%s; want 600", got)
+	}
+}
+
+// TestPaidStrategy_RollbackLeavesNoGap ensures rolled back reservations are signed again.
+func TestPaidStrategy_RollbackLeavesNoGap(t *testing.T) {
+	ps := &PaidStrategy{
+		serviceMetadata: &model.ServiceMetadata{MPEAddress: "0x00000000000000000000000000000000000000Ab"},
+		channelID:       big.NewInt(1),
+		nonce:           big.NewInt(0),
+		signedAmount:    big.NewInt(100),
+		priceInCogs:     big.NewInt(10),
+		signer:          mustSigner(t, mustKey(t)),
+	}
+
+	_, first, _ := ps.Reserve(context.Background())
+	_, second, _ := ps.Reserve(context.Background())
+	second.Rollback()
+	first.Rollback()
+	first.Commit() // no-op after rollback
+
+	ctx, r, _ := ps.Reserve(context.Background())
+	md, _ := gmd.FromOutgoingContext(ctx)
+	if got := mustOne(t, md, PaymentChannelAmountHeader); got != "110" {
+		t.Fatalf("%s=%q; want 110 after rollbacks", PaymentChannelAmountHeader, got)
+	}
+	r.Commit()
+
+	_, next, _ := ps.Reserve(context.Background())
+	next.Rollback()
+	if got := ps.signedAmount.String(); got != "110" {
+		t.Fatalf("signedAmount=%s; want committed 110", got)
+	}
+}
+
+// TestPaidStrategy_SigningFailureRollsBack ensures a claim that cannot be
+// signed fails the reservation and leaves no gap.
+func TestPaidStrategy_SigningFailureRollsBack(t *testing.T) {
+	ps := &PaidStrategy{
+		serviceMetadata: &model.ServiceMetadata{MPEAddress: "0x00000000000000000000000000000000000000Ab"},
+		channelID:       big.NewInt(1),
+		nonce:           big.NewInt(0),
+		signedAmount:    big.NewInt(100),
+		priceInCogs:     big.NewInt(10),
+	}
+
+	if ctx, r, err := ps.Reserve(context.Background()); err == nil || ctx != nil || r != nil {
+		t.Fatalf("Reserve without a signer = (%v, %v, %v); want an error", ctx, r, err)
+	}
+	if got := ps.signedAmount.String(); got != "100" || len(ps.pending) != 0 {
+		t.Fatalf("signedAmount=%s with %d pending; want 100 and none after the failure", got, len(ps.pending))
+	}
+
+	ps.signer = mustSigner(t, mustKey(t))
+	ctx, r, err := ps.Reserve(context.Background())
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	md, _ := gmd.FromOutgoingContext(ctx)
+	if got := mustOne(t, md, PaymentChannelAmountHeader); got != "110" {
+		t.Fatalf("%s=%q; want 110 after the failed reservation", PaymentChannelAmountHeader, got)
+	}
+	r.Commit()
+}
+
+// TestPaidStrategy_NextSignedAmountReturnsACopy ensures callers cannot change
+// the signed or committed amount through the returned total.
+func TestPaidStrategy_NextSignedAmountReturnsACopy(t *testing.T) {
+	ps := &PaidStrategy{signedAmount: big.NewInt(100)}
+	total := ps.NextSignedAmount(big.NewInt(10))
+	total.SetInt64(0)
+	if ps.signedAmount.Int64() != 110 || ps.committed == nil || ps.committed.Int64() != 110 {
+		t.Fatalf("signedAmount=%v committed=%v; want both 110", ps.signedAmount, ps.committed)
+	}
+}
+
+// blockingSigner holds its first signature until release is closed.
+type blockingSigner struct {
+	signer.Signer
+	once    sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSigner) SignMessage(ctx context.Context, message []byte) ([]byte, error) {
+	first := false
+	s.once.Do(func() { first = true })
+	if first {
+		close(s.entered)
+		<-s.release
+	}
+	return s.Signer.SignMessage(ctx, message)
+}
+
+// TestPaidStrategy_SignsOutsideTheLock ensures a slow signature does not hold
+// up other calls and that reservations of a previous nonce are ignored.
+func TestPaidStrategy_SignsOutsideTheLock(t *testing.T) {
+	bs := &blockingSigner{Signer: mustSigner(t, mustKey(t)), entered: make(chan struct{}), release: make(chan struct{})}
+	ps := &PaidStrategy{
+		serviceMetadata: &model.ServiceMetadata{MPEAddress: "0x00000000000000000000000000000000000000Ab"},
+		channelID:       big.NewInt(1),
+		nonce:           big.NewInt(0),
+		signedAmount:    big.NewInt(100),
+		priceInCogs:     big.NewInt(10),
+		signer:          bs,
+	}
+
+	slow := make(chan Reservation)
+	go func() {
+		_, r, _ := ps.Reserve(context.Background())
+		slow <- r
+	}()
+	<-bs.entered
+
+	ctx, fast, _ := ps.Reserve(context.Background())
+	md, _ := gmd.FromOutgoingContext(ctx)
+	if got := mustOne(t, md, PaymentChannelAmountHeader); got != "120" {
+		t.Fatalf("%s=%q; want 120 while the first call is signing", PaymentChannelAmountHeader, got)
+	}
+	close(bs.release)
+	first := <-slow
+
+	// The channel was claimed: Refresh starts a new generation.
+	ps.mu.Lock()
+	ps.nonce, ps.committed, ps.signedAmount, ps.pending = big.NewInt(1), big.NewInt(0), big.NewInt(0), nil
+	ps.gen++
+	ps.mu.Unlock()
+
+	first.Commit()
+	fast.Rollback()
+	if ps.committed.Sign() != 0 || ps.signedAmount.Sign() != 0 {
+		t.Fatalf("stale reservations changed the new nonce: committed=%s signed=%s", ps.committed, ps.signedAmount)
+	}
+}
+
+// TestPaidStrategy_PerMethodAndDynamicPrice ensures calls are signed at the price of the called method.
+func TestPaidStrategy_PerMethodAndDynamicPrice(t *testing.T) {
+	ps := &PaidStrategy{
+		serviceMetadata: &model.ServiceMetadata{MPEAddress: "0x00000000000000000000000000000000000000Ab"},
+		serviceGroup: &model.ServiceGroup{Pricing: []model.Pricing{
+			{PriceModel: model.PriceModelFixed, PriceInCogs: big.NewInt(10), Default: true},
+			{
+				PriceModel:  model.PriceModelFixedPerMethod,
+				PackageName: "calc",
+				PricingDetails: []model.PricingDetails{{
+					ServiceName:   "Calculator",
+					MethodPricing: []model.MethodPricing{{MethodName: "mul", PriceInCogs: big.NewInt(3)}},
+				}},
+			},
+		}},
+		channelID:    big.NewInt(1),
+		nonce:        big.NewInt(0),
+		signedAmount: big.NewInt(100),
+		priceInCogs:  big.NewInt(10),
+		signer:       mustSigner(t, mustKey(t)),
+	}
+
+	ctx := ps.GRPCMetadata(WithMethod(context.Background(), "/calc.Calculator/mul"))
+	md, _ := gmd.FromOutgoingContext(ctx)
+	if got := mustOne(t, md, PaymentChannelAmountHeader); got != "103" {
+		t.Fatalf("%s=%q; want 103 (per-method price)", PaymentChannelAmountHeader, got)
+	}
+	if len(md.Get(DynamicPriceDerived)) != 0 {
+		t.Fatalf("unexpected %s header for fixed price", DynamicPriceDerived)
+	}
+
+	ctx = ps.GRPCMetadata(WithMethod(context.Background(), "/calc.Calculator/add"))
+	md, _ = gmd.FromOutgoingContext(ctx)
+	if got := mustOne(t, md, PaymentChannelAmountHeader); got != "113" {
+		t.Fatalf("%s=%q; want 113 (default price)", PaymentChannelAmountHeader, got)
+	}
+
+	ctx = WithDynamicPrice(WithMethod(context.Background(), "/calc.Calculator/mul"), big.NewInt(42))
+	md, _ = gmd.FromOutgoingContext(ps.GRPCMetadata(ctx))
+	if got := mustOne(t, md, PaymentChannelAmountHeader); got != "155" {
+		t.Fatalf("%s=%q; want 155 (dynamic price)", PaymentChannelAmountHeader, got)
+	}
+	if got := mustOne(t, md, DynamicPriceDerived); got != "42" {
+		t.Fatalf("%s=%q; want 42", DynamicPriceDerived, got)
+	}
+}
+
+// TestPaidStrategy_RecordsCommittedCogs ensures only committed reservations count as signed cogs.
+func TestPaidStrategy_RecordsCommittedCogs(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	tel := telemetry.New(nil, sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	ctx := telemetry.NewContext(context.Background(), tel)
+
+	ps := &PaidStrategy{
+		serviceMetadata: &model.ServiceMetadata{MPEAddress: "0x00000000000000000000000000000000000000Ab"},
+		channelID:       big.NewInt(1),
+		nonce:           big.NewInt(0),
+		signedAmount:    big.NewInt(100),
+		priceInCogs:     big.NewInt(10),
+		signer:          mustSigner(t, mustKey(t)),
+	}
+	_, committed, _ := ps.Reserve(ctx)
+	_, rolledBack, _ := ps.Reserve(ctx)
+	rolledBack.Rollback()
+	committed.Commit()
+	committed.Commit() // counted once
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	var signed float64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[float64]); ok && m.Name == telemetry.CogsSignedMetric {
+				for _, p := range sum.DataPoints {
+					signed += p.Value
+				}
+			}
+		}
+	}
+	if signed != 10 {
+		t.Fatalf("%s = %v; want 10", telemetry.CogsSignedMetric, signed)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	gethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 	gmd "google.golang.org/grpc/metadata"
 )
 
@@ -21,6 +22,16 @@ func mustKey(t *testing.T) *ecdsa.PrivateKey {
 	return k
 }
 
+// mustSigner wraps k in a signer.PrivateKeySigner.
+func mustSigner(t *testing.T, k *ecdsa.PrivateKey) signer.Signer {
+	t.Helper()
+	s, err := signer.NewPrivateKeySigner(k)
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	return s
+}
+
 // mustOne extracts exactly one value for the given metadata key.
 func mustOne(t *testing.T, md gmd.MD, key string) string {
 	t.Helper()
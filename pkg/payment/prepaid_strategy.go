@@ -3,16 +3,19 @@ package payment
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"errors"
+	"fmt"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/shamank/snet-sdk-go/pkg/blockchain"
 	"github.com/shamank/snet-sdk-go/pkg/grpc"
 	"github.com/shamank/snet-sdk-go/pkg/model"
-	"google.golang.org/grpc/metadata"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"go.uber.org/zap"
 )
 
 // PrepaidStrategy implements the "prepaid-call" flow. The client signs a claim
@@ -20,7 +23,16 @@ import (
 // daemon. Subsequent RPCs present this token (plus channel identifiers) as
 // metadata; the daemon validates freshness (by block number) and the claim
 // signature before serving the request.
+//
+// The strategy keeps track of the token: the planned and used amounts reported
+// by the daemon, the calls made since, and the token expiration. Before the
+// prepaid balance runs out it signs another batch of calls and renews the
+// token, and before the token expires it renews it (see Reserve and Usage).
+//
+// PrepaidStrategy is safe for concurrent use: Refresh replaces the token while
+// GRPCMetadata and Reserve read it under a lock.
 type PrepaidStrategy struct {
+	mu sync.RWMutex
 	// Token is the opaque auth token returned by the daemon.
 	Token string
 	// tokenClient is a gRPC client for the token issuance service.
@@ -37,74 +49,234 @@ type PrepaidStrategy struct {
 	nonce *big.Int
 	// signedAmount is the total authorized amount (in cogs) for the current claim.
 	signedAmount *big.Int
-	// privateKeyECDSA is the caller’s signing key.
-	privateKeyECDSA *ecdsa.PrivateKey
+	// signer signs the claims and token requests of the caller.
+	signer signer.Signer
+	// increment is the amount authorised on top of the daemon's signed amount
+	// when the channel nonce changes (price * callCount).
+	increment *big.Int
+	// store persists (nonce, signedAmount); nil keeps state in memory only.
+	store ChannelStateStore
+	// states writes to store in the background, outside mu.
+	states stateWriter
+	// serviceGroup prices calls per method; nil means priceInCogs for every call.
+	serviceGroup *model.ServiceGroup
+	// priceInCogs is the price used to count the remaining calls.
+	priceInCogs *big.Int
+
+	// plannedAmount and usedAmount are the amounts reported with the token;
+	// nil until the first successful Refresh.
+	plannedAmount *big.Int
+	usedAmount    *big.Int
+	// spent is the amount of the calls made (or in flight) since the token
+	// was issued.
+	spent *big.Int
+	// generation is bumped by every token, so reservations made with an older
+	// token do not touch spent.
+	generation uint64
+	// expiresAt is the token expiration; zero when the token carries none.
+	expiresAt time.Time
+
+	// renewMu serialises renewals.
+	renewMu sync.Mutex
+	// renewBelow is the number of remaining calls at which a new batch is signed.
+	renewBelow uint64
+	// renewBefore is how long before expiresAt the token is renewed.
+	renewBefore time.Duration
+	// renewAttempt is the time of the last failed automatic renewal.
+	renewAttempt time.Time
+	now          func() time.Time
+
+	// log receives renewal and persistence failures; nil logs to zap.L().
+	log *zap.Logger
+}
+
+// PrepaidStrategyOption configures construction of a PrepaidStrategy.
+type PrepaidStrategyOption func(*PrepaidStrategy)
+
+// WithPrepaidChannelStateStore makes the prepaid strategy write its nonce and
+// signed amount through store before every token request and reconcile them
+// with the daemon on construction and Refresh. When the daemon cannot be
+// reached during construction, the stored state of the channel is used.
+func WithPrepaidChannelStateStore(store ChannelStateStore) PrepaidStrategyOption {
+	return func(p *PrepaidStrategy) {
+		p.store = store
+	}
+}
+
+// WithPrepaidRenewThreshold makes the strategy sign another batch of calls and
+// renew the token once no more than calls prepaid calls are left. The default
+// is a tenth of the batch, but at least one call.
+func WithPrepaidRenewThreshold(calls uint64) PrepaidStrategyOption {
+	return func(p *PrepaidStrategy) {
+		p.renewBelow = calls
+	}
+}
+
+// WithPrepaidRenewBefore sets how long before its expiration the token is
+// renewed (one minute by default). A non-positive duration renews it only
+// after the daemon rejects it.
+func WithPrepaidRenewBefore(d time.Duration) PrepaidStrategyOption {
+	return func(p *PrepaidStrategy) {
+		p.renewBefore = d
+	}
+}
+
+// WithPrepaidLogger makes the strategy log token renewal and persistence
+// failures to l instead of zap.L().
+func WithPrepaidLogger(l *zap.Logger) PrepaidStrategyOption {
+	return func(p *PrepaidStrategy) {
+		p.log = l
+	}
+}
+
+// logger returns the logger set with WithPrepaidLogger, or zap.L().
+func (p *PrepaidStrategy) logger() *zap.Logger {
+	return loggerOrGlobal(p.log)
 }
 
 // getSignature signs the provided MPE claim signature together with the current
 // block number, producing a freshness-bound signature required by the daemon.
 // The block number is encoded as 32-byte big-endian (math.U256Bytes).
-func (p *PrepaidStrategy) getSignature(mpeSignature []byte, currentBlockNumber *big.Int) []byte {
+func (p *PrepaidStrategy) getSignature(ctx context.Context, mpeSignature []byte, currentBlockNumber *big.Int) ([]byte, error) {
 	// always 32 bytes big‑endian
 	blockBytes := math.U256Bytes(currentBlockNumber)
 	message := bytes.Join([][]byte{mpeSignature, blockBytes}, nil)
-	return blockchain.GetSignature(message, p.privateKeyECDSA)
+	return blockchain.SignMessage(ctx, p.signer, message)
 }
 
 // getClaimSignature builds and signs the canonical MPE claim message:
 // concat(PrefixInSignature, MPEAddress, ChannelID, Nonce, SignedAmount).
 // The resulting signature is later wrapped with the current block (see getSignature).
-func (p *PrepaidStrategy) getClaimSignature() []byte {
+// p.mu must not be held: the signer may be slow (Clef waits for a person).
+func (p *PrepaidStrategy) getClaimSignature(ctx context.Context, channelID, nonce, signedAmount *big.Int) ([]byte, error) {
 	message := bytes.Join([][]byte{
 		[]byte(PrefixInSignature),
 		p.mpeAddr.Bytes(),
-		bigIntToBytes(p.channelID),
-		bigIntToBytes(p.nonce),
-		bigIntToBytes(p.signedAmount),
+		bigIntToBytes(channelID),
+		bigIntToBytes(nonce),
+		bigIntToBytes(signedAmount),
 	}, nil)
-	return blockchain.GetSignature(message, p.privateKeyECDSA)
+	return blockchain.SignMessage(ctx, p.signer, message)
 }
 
 // GRPCMetadata returns a child context augmented with prepaid-call headers:
-// payment type, channel ID, nonce, and the daemon-issued auth token.
-// The token MUST be kept up-to-date by calling Refresh.
+// payment type, channel ID, nonce, and the daemon-issued auth token. When ctx
+// carries a price from WithDynamicPrice it is sent in the DynamicPriceDerived
+// header so the daemon charges the derived price against the token.
+// The call is counted as made; use Reserve to be able to take it back.
+// The token MUST be obtained first by calling Refresh.
 func (p *PrepaidStrategy) GRPCMetadata(ctx context.Context) context.Context {
-	md := metadata.Pairs(
-		PaymentTypeHeader, "prepaid-call",
-		PaymentChannelIDHeader, p.channelID.String(),
-		PaymentChannelNonceHeader, p.nonce.String(),
-		PrePaidAuthTokenHeader, p.Token,
-	)
-	return metadata.NewOutgoingContext(ctx, md)
+	ctx, r, _ := p.Reserve(ctx)
+	r.Commit()
+	return ctx
 }
 
-// Refresh obtains or renews the prepaid auth token from the daemon. It signs the
-// MPE claim (channelID, nonce, signedAmount) and then signs again with the
-// current block number to prove freshness. On success, the token is stored in
-// p.Token.
+// Refresh obtains or renews the prepaid auth token from the daemon. It first
+// reconciles (nonce, signedAmount) with the daemon's channel state and the
+// channel state store and writes the result through the store, then signs the
+// MPE claim (channelID, nonce, signedAmount) and signs again with the current
+// block number to prove freshness. On success, the token is stored in p.Token
+// and the planned and used amounts and the expiration that come with it
+// replace the usage of the previous token.
 func (p *PrepaidStrategy) Refresh(ctx context.Context) error {
 	currentBlockNumber, err := p.evmClient.GetCurrentBlockNumberCtx(ctx)
 	if err != nil {
 		return err
 	}
 
-	claimSignature := p.getClaimSignature()
-	signature := p.getSignature(claimSignature, currentBlockNumber)
-	request := TokenRequest{
-		ChannelId:      p.channelID.Uint64(),
-		CurrentNonce:   p.nonce.Uint64(),
-		SignedAmount:   p.signedAmount.Uint64(), // usedAmount + (priceInCogs * callCount)
+	p.reconcile(ctx, currentBlockNumber)
+
+	request, err := p.tokenRequest(ctx, currentBlockNumber)
+	if err != nil {
+		return err
+	}
+
+	tokenReply, err := p.tokenClient.GetToken(ctx, request)
+	if err != nil {
+		return ClassifyDaemonError(err)
+	}
+
+	p.mu.Lock()
+	p.setTokenLocked(tokenReply)
+	p.mu.Unlock()
+	return nil
+}
+
+// tokenRequest signs the claim of the current (channelID, nonce,
+// signedAmount) and the request for a token at currentBlockNumber. Only the
+// state is read under p.mu; it is signed after the lock is released.
+func (p *PrepaidStrategy) tokenRequest(ctx context.Context, currentBlockNumber *big.Int) (*TokenRequest, error) {
+	p.mu.RLock()
+	channelID, nonce, signedAmount := p.channelID, p.nonce, p.signedAmount
+	p.mu.RUnlock()
+
+	claimSignature, err := p.getClaimSignature(ctx, channelID, nonce, signedAmount)
+	if err != nil {
+		return nil, fmt.Errorf("sign prepaid claim: %w", err)
+	}
+	signature, err := p.getSignature(ctx, claimSignature, currentBlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("sign token request: %w", err)
+	}
+	return &TokenRequest{
+		ChannelId:      channelID.Uint64(),
+		CurrentNonce:   nonce.Uint64(),
+		SignedAmount:   signedAmount.Uint64(), // usedAmount + (priceInCogs * callCount)
 		Signature:      signature,
 		CurrentBlock:   currentBlockNumber.Uint64(),
 		ClaimSignature: claimSignature,
+	}, nil
+}
+
+// reconcile brings (nonce, signedAmount) in line with the daemon and the
+// channel state store and queues the result to be persisted. When the daemon reports a new
+// nonce (the channel was claimed) a fresh batch of increment is authorised on
+// top of its signed amount; otherwise the signed amount never goes down. A
+// daemon error is logged and the known state is kept, so that GetToken reports
+// the actual failure.
+func (p *PrepaidStrategy) reconcile(ctx context.Context, currentBlockNumber *big.Int) {
+	p.mu.RLock()
+	channelID := p.channelID
+	p.mu.RUnlock()
+
+	var daemonNonce, daemonSigned *big.Int
+	if p.grpcClient != nil {
+		state, err := GetChannelStateFromDaemon(p.grpcClient.Conn(), ctx, p.mpeAddr, channelID, currentBlockNumber, p.signer)
+		if err != nil {
+			p.logger().Warn("read channel state from daemon", zap.Stringer("channel", channelID), zap.Error(err))
+		} else {
+			p.logger().Debug("channel state reply", zap.Any("reply", state))
+			daemonNonce = new(big.Int).SetBytes(state.GetCurrentNonce())
+			daemonSigned = new(big.Int).SetBytes(state.GetCurrentSignedAmount())
+		}
 	}
+	stored := loadChannelState(ctx, p.logger(), p.store, p.mpeAddr, channelID)
 
-	tokenReply, err := p.tokenClient.GetToken(ctx, &request)
-	if err != nil {
-		return err
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if daemonNonce != nil && (p.nonce == nil || daemonNonce.Cmp(p.nonce) != 0) {
+		p.nonce = daemonNonce
+		increment := p.increment
+		if increment == nil {
+			increment = big.NewInt(0)
+		}
+		p.signedAmount = new(big.Int).Add(daemonSigned, increment)
+	}
+	if daemonSigned != nil && daemonNonce.Cmp(p.nonce) == 0 && daemonSigned.Cmp(p.signedAmount) > 0 {
+		p.signedAmount = daemonSigned
+	}
+	p.signedAmount = reconcileSignedAmount(p.nonce, p.signedAmount, stored)
+
+	if p.store != nil && p.channelID != nil {
+		p.states.save(p.store, p.logger(), &ChannelState{
+			MPEAddress:   p.mpeAddr,
+			ChannelID:    p.channelID,
+			Nonce:        p.nonce,
+			SignedAmount: p.signedAmount,
+			UpdatedAt:    time.Now().UTC(),
+		})
 	}
-	p.Token = tokenReply.GetToken()
-	return nil
 }
 
 // NewPrePaidStrategy constructs a PrepaidStrategy for an existing MPE channel,
@@ -112,19 +284,33 @@ func (p *PrepaidStrategy) Refresh(ctx context.Context) error {
 // signed amount used to request a token.
 //
 // Flow:
-//  1. Resolve groupID/recipient; parse signer private key.
+//  1. Resolve groupID/recipient and the signer address.
 //  2. Read chain tip/chainID; build bind opts (call/watch/filter/transact).
 //  3. Locate the sender’s channel for (recipient, groupID).
-//  4. Query daemon for current (nonce, signedAmount).
+//  4. Query daemon for current (nonce, signedAmount), falling back to the
+//     channel state store when the daemon is unreachable.
 //  5. Ensure/extend/add-funds on the channel as needed.
-//  6. Compute signedAmount = currentSigned + priceInCogs * callCount.
+//  6. Compute signedAmount = currentSigned + price * callCount, where price
+//     is the most expensive fixed price of the group (see
+//     model.ServiceGroup.MaxPrice), so callCount calls of any method fit.
+//     The daemon's signed amount is first reconciled with the channel state
+//     store (see WithPrepaidChannelStateStore).
 //  7. Create strategy with token client; caller should invoke Refresh(ctx)
-//     before issuing RPC calls to obtain the token.
+//     before issuing RPC calls to obtain the token. Later batches of
+//     callCount calls are signed automatically (see Renew).
 //
 // Note: ctx is used for on-chain and daemon calls. Caller should provide
 // a context with appropriate timeout (e.g., 30-60 seconds for daemon calls).
-func NewPrePaidStrategy(ctx context.Context, evm *blockchain.EVMClient, grpc *grpc.Client, mpeAddress common.Address, srvGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup, privateKey string, callCount uint64) (Strategy, error) {
-	priceInCogs := srvGroup.Pricing[0].PriceInCogs
+func NewPrePaidStrategy(ctx context.Context, evm *blockchain.EVMClient, grpc *grpc.Client, mpeAddress common.Address, srvGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup, s signer.Signer, callCount uint64, options ...PrepaidStrategyOption) (Strategy, error) {
+	if s == nil {
+		return nil, errors.New("signer is required for prepaid calls")
+	}
+	strategy := &PrepaidStrategy{renewBefore: defaultPrepaidRenewBefore}
+	for _, opt := range options {
+		opt(strategy)
+	}
+
+	priceInCogs := srvGroup.MaxPrice()
 
 	groupID, err := blockchain.DecodePaymentGroupID(orgGroup.ID)
 	if err != nil {
@@ -133,10 +319,7 @@ func NewPrePaidStrategy(ctx context.Context, evm *blockchain.EVMClient, grpc *gr
 
 	recipient := common.HexToAddress(orgGroup.PaymentDetails.PaymentAddress)
 
-	fromAddress, privateKeyECDSA, err := blockchain.ParsePrivateKeyECDSA(privateKey)
-	if err != nil {
-		return nil, err
-	}
+	fromAddress := s.Address()
 
 	currentBlockNumber, err := evm.GetCurrentBlockNumberCtx(ctx)
 	if err != nil {
@@ -148,7 +331,7 @@ func NewPrePaidStrategy(ctx context.Context, evm *blockchain.EVMClient, grpc *gr
 		return nil, err
 	}
 
-	transactOpts, err := blockchain.GetTransactOpts(chainID, privateKeyECDSA)
+	transactOpts, err := blockchain.NewTransactOpts(ctx, s, chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -176,13 +359,26 @@ func NewPrePaidStrategy(ctx context.Context, evm *blockchain.EVMClient, grpc *gr
 		return nil, err
 	}
 
-	filteredChannelState, err := GetChannelStateFromDaemon(grpc.GRPC, ctx, mpeAddress, filteredChannel.ChannelId, currentBlockNumber, privateKeyECDSA)
-	if err != nil {
-		return nil, err
+	var currentSignedAmount *big.Int
+	filteredChannelState, err := GetChannelStateFromDaemon(grpc.Conn(), ctx, mpeAddress, filteredChannel.ChannelId, currentBlockNumber, s)
+	switch {
+	case errors.Is(err, ErrChannelNotFound):
+		// The daemon has not seen the channel yet, so nothing was signed on it.
+		currentSignedAmount = big.NewInt(0)
+	case err != nil:
+		stored := loadChannelState(ctx, strategy.logger(), strategy.store, mpeAddress, filteredChannel.ChannelId)
+		if stored == nil || stored.SignedAmount == nil {
+			return nil, err
+		}
+		strategy.logger().Warn("daemon unavailable, using stored channel state",
+			zap.Stringer("channel", filteredChannel.ChannelId),
+			zap.Stringer("nonce", stored.Nonce),
+			zap.Error(err))
+		currentSignedAmount = new(big.Int).Set(stored.SignedAmount)
+	default:
+		currentSignedAmount = new(big.Int).SetBytes(filteredChannelState.GetCurrentSignedAmount())
 	}
 
-	currentSignedAmount := new(big.Int).SetBytes(filteredChannelState.GetCurrentSignedAmount())
-
 	newExpiration := blockchain.GetNewExpiration(currentBlockNumber, orgGroup.PaymentDetails.PaymentExpirationThreshold) // TODO move this to selectPaymentChannel func
 
 	channelID, err := evm.EnsurePaymentChannel(mpeAddress, filteredChannel, currentSignedAmount, priceInCogs, newExpiration, opts, chans, senders, recipients, groupIDs)
@@ -190,32 +386,48 @@ func NewPrePaidStrategy(ctx context.Context, evm *blockchain.EVMClient, grpc *gr
 		return nil, err
 	}
 
-	channelState, err := GetChannelStateFromDaemon(grpc.GRPC, ctx, mpeAddress, channelID, currentBlockNumber, privateKeyECDSA)
-	if err != nil {
-		return nil, err
-	}
+	stored := loadChannelState(ctx, strategy.logger(), strategy.store, mpeAddress, channelID)
 
-	nonce := new(big.Int).SetBytes(channelState.GetCurrentNonce())
-	if nonce == nil {
-		return nil, errors.New("error while getting current nonce")
-	}
+	var nonce *big.Int
+	channelState, err := GetChannelStateFromDaemon(grpc.Conn(), ctx, mpeAddress, channelID, currentBlockNumber, s)
+	switch {
+	case errors.Is(err, ErrChannelNotFound):
+		nonce = big.NewInt(0)
+	case err != nil:
+		if stored == nil || stored.Nonce == nil || stored.SignedAmount == nil {
+			return nil, err
+		}
+		nonce = new(big.Int).Set(stored.Nonce)
+		currentSignedAmount = new(big.Int).Set(stored.SignedAmount)
+	default:
+		nonce = new(big.Int).SetBytes(channelState.GetCurrentNonce())
+		if nonce == nil {
+			return nil, errors.New("error while getting current nonce")
+		}
 
-	currentSignedAmount = new(big.Int).SetBytes(channelState.GetCurrentSignedAmount())
-	if currentSignedAmount == nil {
-		return nil, errors.New("error while getting signed amount")
+		currentSignedAmount = new(big.Int).SetBytes(channelState.GetCurrentSignedAmount())
+		if currentSignedAmount == nil {
+			return nil, errors.New("error while getting signed amount")
+		}
 	}
+	currentSignedAmount = reconcileSignedAmount(nonce, currentSignedAmount, stored)
 
 	increment := new(big.Int).Mul(priceInCogs, big.NewInt(int64(callCount)))
 	signedAmount := new(big.Int).Add(currentSignedAmount, increment)
 
-	return &PrepaidStrategy{
-		tokenClient:     NewTokenServiceClient(grpc.GRPC),
-		evmClient:       evm,
-		grpcClient:      grpc,
-		mpeAddr:         mpeAddress,
-		privateKeyECDSA: privateKeyECDSA,
-		signedAmount:    signedAmount,
-		channelID:       channelID,
-		nonce:           nonce,
-	}, nil
+	strategy.tokenClient = NewTokenServiceClient(grpc.Conn())
+	strategy.evmClient = evm
+	strategy.grpcClient = grpc
+	strategy.mpeAddr = mpeAddress
+	strategy.signer = s
+	strategy.signedAmount = signedAmount
+	strategy.channelID = channelID
+	strategy.nonce = nonce
+	strategy.increment = increment
+	strategy.serviceGroup = srvGroup
+	strategy.priceInCogs = priceInCogs
+	if strategy.renewBelow == 0 {
+		strategy.renewBelow = max(callCount/10, 1)
+	}
+	return strategy, nil
 }
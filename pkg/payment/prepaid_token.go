@@ -0,0 +1,273 @@
+package payment
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// defaultPrepaidRenewBefore is how long before its expiration a prepaid
+	// token is renewed.
+	defaultPrepaidRenewBefore = time.Minute
+	// prepaidRenewRetry is how long PrepaidStrategy waits after a failed
+	// automatic renewal before trying again.
+	prepaidRenewRetry = 30 * time.Second
+)
+
+// PrepaidUsage is the state of the prepaid token of a PrepaidStrategy. All
+// amounts are in cogs.
+type PrepaidUsage struct {
+	// Planned is the amount the daemon allows calls to spend with the token.
+	Planned *big.Int
+	// Used is the amount the daemon reported as used when it issued the
+	// token, plus the price of the calls made since.
+	Used *big.Int
+	// Remaining is Planned minus Used, and never negative.
+	Remaining *big.Int
+	// RemainingCalls is the number of calls Remaining pays for at the group's
+	// fixed price; it is zero when that price is zero.
+	RemainingCalls uint64
+	// ExpiresAt is when the token expires; zero if the token does not say.
+	ExpiresAt time.Time
+}
+
+// Usage returns the prepaid balance of the current token. Before the first
+// Refresh all amounts are zero.
+func (p *PrepaidStrategy) Usage() PrepaidUsage {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	usage := PrepaidUsage{
+		Planned:   big.NewInt(0),
+		Used:      big.NewInt(0),
+		Remaining: big.NewInt(0),
+		ExpiresAt: p.expiresAt,
+	}
+	if p.plannedAmount == nil {
+		return usage
+	}
+	usage.Planned.Set(p.plannedAmount)
+	usage.Used.Add(p.usedAmount, p.spentLocked())
+	usage.Remaining = p.remainingLocked()
+	if p.priceInCogs != nil && p.priceInCogs.Sign() > 0 {
+		calls := new(big.Int).Quo(usage.Remaining, p.priceInCogs)
+		if calls.IsUint64() {
+			usage.RemainingCalls = calls.Uint64()
+		}
+	}
+	return usage
+}
+
+// Reserve counts the price of one call against the prepaid balance and
+// returns a context carrying the prepaid-call headers (see GRPCMetadata).
+// When the call would leave no more than the renewal threshold of calls (see
+// WithPrepaidRenewThreshold), or the token is about to expire, the token is
+// renewed first; a failed renewal is logged and the current token is sent, so
+// that the daemon reports the actual failure. Roll the reservation back when
+// the call did not reach the daemon.
+func (p *PrepaidStrategy) Reserve(ctx context.Context) (context.Context, Reservation, error) {
+	price, dynamic := callPrice(ctx, p.serviceGroup, p.priceInCogs)
+	p.renewIfDue(ctx, price)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.spent = new(big.Int).Add(p.spentLocked(), price)
+	r := &prepaidReservation{strategy: p, amount: price, generation: p.generation, tel: telemetry.FromContext(ctx)}
+	trace.SpanFromContext(ctx).SetAttributes(
+		telemetry.Cogs(price),
+		telemetry.ChannelKey.String(p.channelID.String()))
+
+	md := metadata.Pairs(
+		PaymentTypeHeader, "prepaid-call",
+		PaymentChannelIDHeader, p.channelID.String(),
+		PaymentChannelNonceHeader, p.nonce.String(),
+		PrePaidAuthTokenHeader, p.Token,
+	)
+	if dynamic {
+		md.Set(DynamicPriceDerived, price.String())
+	}
+	return metadata.NewOutgoingContext(ctx, md), r, nil
+}
+
+// Renew signs another batch of calls (price * callCount) on top of the amount
+// planned for the current token and requests a new token for it. Renewing
+// again before the daemon accepted the batch does not sign another one. The
+// channel must hold the new signed amount; otherwise the daemon rejects it
+// with ErrInsufficientChannelFunds (see blockchain.ChannelManager to add
+// funds).
+func (p *PrepaidStrategy) Renew(ctx context.Context) error {
+	p.renewMu.Lock()
+	defer p.renewMu.Unlock()
+	return p.renew(ctx, true)
+}
+
+// renewIfDue renews the token before a call of price when the balance runs
+// low or the token is about to expire.
+func (p *PrepaidStrategy) renewIfDue(ctx context.Context, price *big.Int) {
+	p.mu.RLock()
+	_, due := p.renewalDueLocked(price)
+	p.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	p.renewMu.Lock()
+	defer p.renewMu.Unlock()
+
+	// Another call may have renewed the token meanwhile.
+	p.mu.RLock()
+	topUp, due := p.renewalDueLocked(price)
+	p.mu.RUnlock()
+	if !due {
+		return
+	}
+	if err := p.renew(ctx, topUp); err != nil {
+		p.mu.Lock()
+		p.renewAttempt = p.clock()
+		p.mu.Unlock()
+		p.logger().Warn("renew prepaid token", zap.Stringer("channel", p.channelID), zap.Error(err))
+	}
+}
+
+// renew requests a new token, first raising the signed amount to a batch
+// above the planned amount when topUp is set. p.renewMu must be held.
+func (p *PrepaidStrategy) renew(ctx context.Context, topUp bool) error {
+	if topUp {
+		p.mu.Lock()
+		if p.plannedAmount != nil && p.increment != nil {
+			target := new(big.Int).Add(p.plannedAmount, p.increment)
+			if p.signedAmount == nil || target.Cmp(p.signedAmount) > 0 {
+				p.signedAmount = target
+			}
+		}
+		p.mu.Unlock()
+	}
+	return p.Refresh(ctx)
+}
+
+// renewalDueLocked reports whether the token should be renewed before a call
+// of price, and whether another batch should be signed for it. Nothing is due
+// before the first token or shortly after a failed renewal. p.mu must be held.
+func (p *PrepaidStrategy) renewalDueLocked(price *big.Int) (topUp, due bool) {
+	if p.plannedAmount == nil {
+		return false, false
+	}
+	now := p.clock()
+	if !p.renewAttempt.IsZero() && now.Sub(p.renewAttempt) < prepaidRenewRetry {
+		return false, false
+	}
+
+	remaining := p.remainingLocked()
+	threshold := big.NewInt(0)
+	if p.priceInCogs != nil {
+		threshold.Mul(p.priceInCogs, new(big.Int).SetUint64(p.renewBelow))
+	}
+	topUp = remaining.Cmp(price) < 0 || (threshold.Sign() > 0 && remaining.Cmp(threshold) <= 0)
+
+	expiring := !p.expiresAt.IsZero() && p.renewBefore > 0 && !now.Add(p.renewBefore).Before(p.expiresAt)
+	return topUp, topUp || expiring
+}
+
+// setTokenLocked records a token issued by the daemon and starts counting the
+// calls made with it. p.mu must be held.
+func (p *PrepaidStrategy) setTokenLocked(reply *TokenReply) {
+	p.Token = reply.GetToken()
+	p.plannedAmount = new(big.Int).SetUint64(reply.GetPlannedAmount())
+	p.usedAmount = new(big.Int).SetUint64(reply.GetUsedAmount())
+	p.spent = big.NewInt(0)
+	p.generation++
+	p.expiresAt = tokenExpiry(p.Token)
+	p.renewAttempt = time.Time{}
+}
+
+// remainingLocked returns the planned amount minus the used amount and the
+// calls made since, floored at zero. p.mu must be held.
+func (p *PrepaidStrategy) remainingLocked() *big.Int {
+	if p.plannedAmount == nil {
+		return big.NewInt(0)
+	}
+	remaining := new(big.Int).Sub(p.plannedAmount, p.usedAmount)
+	remaining.Sub(remaining, p.spentLocked())
+	if remaining.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return remaining
+}
+
+// spentLocked returns p.spent, treating nil as zero. p.mu must be held.
+func (p *PrepaidStrategy) spentLocked() *big.Int {
+	if p.spent == nil {
+		return big.NewInt(0)
+	}
+	return p.spent
+}
+
+func (p *PrepaidStrategy) clock() time.Time {
+	if p.now == nil {
+		return time.Now()
+	}
+	return p.now()
+}
+
+// tokenExpiry returns the expiration ("exp" claim) of a daemon token, which
+// is a JWT, without verifying it. It returns the zero time for tokens in any
+// other format.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(claims.Exp), 0)
+}
+
+// prepaidReservation is the price of one prepaid call counted against the
+// token it was made with.
+type prepaidReservation struct {
+	once       sync.Once
+	strategy   *PrepaidStrategy
+	amount     *big.Int
+	generation uint64
+	tel        *telemetry.Telemetry // Records amount as signed on Commit
+}
+
+func (r *prepaidReservation) Commit() {
+	r.once.Do(func() {
+		r.tel.RecordCogs(context.Background(), r.amount, telemetry.PaymentTypeKey.String("prepaid-call"))
+	})
+}
+
+func (r *prepaidReservation) Rollback() {
+	r.once.Do(func() {
+		p := r.strategy
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if r.generation != p.generation {
+			return
+		}
+		p.spent = new(big.Int).Sub(p.spentLocked(), r.amount)
+		if p.spent.Sign() < 0 {
+			p.spent = big.NewInt(0)
+		}
+	})
+}
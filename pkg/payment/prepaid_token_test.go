@@ -0,0 +1,144 @@
+package payment
+
+import (
+	"context"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTokenStrategy returns a prepaid strategy holding a token for planned
+// cogs of which used are already spent, priced at 10 cogs per call.
+func newTokenStrategy(planned, used uint64, token string) *PrepaidStrategy {
+	p := &PrepaidStrategy{
+		channelID:   big.NewInt(1),
+		nonce:       big.NewInt(0),
+		priceInCogs: big.NewInt(10),
+		increment:   big.NewInt(100),
+		renewBelow:  2,
+		renewBefore: time.Minute,
+	}
+	p.setTokenLocked(&TokenReply{Token: token, PlannedAmount: planned, UsedAmount: used})
+	return p
+}
+
+func TestPrepaidStrategy_UsageCountsCalls(t *testing.T) {
+	p := newTokenStrategy(100, 20, "opaque")
+
+	_, first, _ := p.Reserve(context.Background())
+	first.Commit()
+	_, second, _ := p.Reserve(context.Background())
+	second.Rollback()
+	second.Commit() // no-op after Rollback
+
+	usage := p.Usage()
+	if usage.Planned.Int64() != 100 || usage.Used.Int64() != 30 || usage.Remaining.Int64() != 70 {
+		t.Fatalf("usage = planned %s, used %s, remaining %s; want 100, 30, 70", usage.Planned, usage.Used, usage.Remaining)
+	}
+	if usage.RemainingCalls != 7 {
+		t.Fatalf("RemainingCalls = %d; want 7", usage.RemainingCalls)
+	}
+	if !usage.ExpiresAt.IsZero() {
+		t.Fatalf("ExpiresAt = %v; want zero for a non-JWT token", usage.ExpiresAt)
+	}
+
+	// A new token resets the count; reservations made with the old one no
+	// longer affect it.
+	_, stale, _ := p.Reserve(context.Background())
+	p.setTokenLocked(&TokenReply{Token: "next", PlannedAmount: 200, UsedAmount: 40})
+	stale.Rollback()
+	if got := p.Usage().Used.Int64(); got != 40 {
+		t.Fatalf("Used after new token = %d; want 40", got)
+	}
+}
+
+func TestPrepaidStrategy_RenewalDue(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	ten := big.NewInt(10)
+
+	p := newTokenStrategy(100, 50, "opaque")
+	p.now = func() time.Time { return now }
+	if topUp, due := p.renewalDueLocked(ten); topUp || due {
+		t.Fatalf("5 calls left: topUp=%v due=%v; want neither", topUp, due)
+	}
+
+	p.spent = big.NewInt(30) // 2 calls left, the threshold
+	if topUp, due := p.renewalDueLocked(ten); !topUp || !due {
+		t.Fatalf("2 calls left: topUp=%v due=%v; want both", topUp, due)
+	}
+
+	// A failed renewal is not retried right away.
+	p.renewAttempt = now.Add(-time.Second)
+	if _, due := p.renewalDueLocked(ten); due {
+		t.Fatal("renewal due right after a failed attempt")
+	}
+	p.renewAttempt = now.Add(-prepaidRenewRetry)
+	if _, due := p.renewalDueLocked(ten); !due {
+		t.Fatal("renewal not due after the retry interval")
+	}
+
+	// A token close to its expiration is renewed without signing more.
+	p = newTokenStrategy(100, 0, "opaque")
+	p.now = func() time.Time { return now }
+	p.expiresAt = now.Add(30 * time.Second)
+	if topUp, due := p.renewalDueLocked(ten); topUp || !due {
+		t.Fatalf("expiring token: topUp=%v due=%v; want due only", topUp, due)
+	}
+	p.renewBefore = 0
+	if _, due := p.renewalDueLocked(ten); due {
+		t.Fatal("renewal due with renewBefore disabled")
+	}
+
+	// Nothing is known before the first token.
+	if _, due := (&PrepaidStrategy{}).renewalDueLocked(ten); due {
+		t.Fatal("renewal due before the first token")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	enc := base64.RawURLEncoding.EncodeToString
+	jwt := enc([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc([]byte(`{"authorized":true,"exp":1700000000}`)) + ".sig"
+
+	if got := tokenExpiry(jwt); !got.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("tokenExpiry(jwt) = %v; want %v", got, time.Unix(1700000000, 0))
+	}
+	for _, token := range []string{"", "opaque", "a.b.c", "a." + enc([]byte(`{"sub":"x"}`)) + ".c"} {
+		if got := tokenExpiry(token); !got.IsZero() {
+			t.Fatalf("tokenExpiry(%q) = %v; want zero", token, got)
+		}
+	}
+}
+
+// TestPrepaidStrategy_SignsTokenRequestOutsideTheLock ensures a slow signature
+// of a token request does not hold up calls.
+func TestPrepaidStrategy_SignsTokenRequestOutsideTheLock(t *testing.T) {
+	bs := &blockingSigner{Signer: mustSigner(t, mustKey(t)), entered: make(chan struct{}), release: make(chan struct{})}
+	p := newTokenStrategy(100, 20, "opaque")
+	p.signedAmount = big.NewInt(100)
+	p.signer = bs
+
+	requested := make(chan *TokenRequest)
+	go func() {
+		request, err := p.tokenRequest(context.Background(), big.NewInt(55))
+		if err != nil {
+			t.Errorf("tokenRequest: %v", err)
+		}
+		requested <- request
+	}()
+	<-bs.entered
+
+	_, r, err := p.Reserve(context.Background())
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	r.Commit()
+	if got := p.Usage().Used.Int64(); got != 30 {
+		t.Fatalf("Used while signing = %d; want 30", got)
+	}
+
+	close(bs.release)
+	if request := <-requested; request == nil || request.SignedAmount != 100 || request.CurrentBlock != 55 {
+		t.Fatalf("token request = %+v; want signed amount 100 at block 55", request)
+	}
+}
@@ -0,0 +1,58 @@
+package payment
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/shamank/snet-sdk-go/pkg/model"
+)
+
+// methodKey and dynamicPriceKey are the context keys used by WithMethod and
+// WithDynamicPrice.
+type (
+	methodKey       struct{}
+	dynamicPriceKey struct{}
+)
+
+// WithMethod returns a child context naming the gRPC method about to be
+// called ("/package.Service/Method"). Strategies use it to price the call from
+// the group's fixed_price_per_method entries.
+func WithMethod(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, methodKey{}, method)
+}
+
+// MethodFromContext returns the method set by WithMethod.
+func MethodFromContext(ctx context.Context) (string, bool) {
+	method, ok := ctx.Value(methodKey{}).(string)
+	return method, ok && method != ""
+}
+
+// WithDynamicPrice returns a child context carrying the price returned by the
+// service's dynamic pricing method for the upcoming call. Strategies sign that
+// price instead of the fixed one and send it in the DynamicPriceDerived header.
+func WithDynamicPrice(ctx context.Context, price *big.Int) context.Context {
+	return context.WithValue(ctx, dynamicPriceKey{}, price)
+}
+
+// DynamicPriceFromContext returns the price set by WithDynamicPrice.
+func DynamicPriceFromContext(ctx context.Context) (*big.Int, bool) {
+	price, ok := ctx.Value(dynamicPriceKey{}).(*big.Int)
+	return price, ok && price != nil
+}
+
+// callPrice works out the price of the call described by ctx: the dynamic
+// price if one was derived, otherwise the group's price for the method named
+// by WithMethod, otherwise fallback. dynamic reports whether the dynamic price
+// was used.
+func callPrice(ctx context.Context, group *model.ServiceGroup, fallback *big.Int) (price *big.Int, dynamic bool) {
+	if price, ok := DynamicPriceFromContext(ctx); ok {
+		return price, true
+	}
+	if method, ok := MethodFromContext(ctx); ok && group != nil {
+		return group.MethodPrice(method), false
+	}
+	if fallback == nil {
+		return big.NewInt(0), false
+	}
+	return fallback, false
+}
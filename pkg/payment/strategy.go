@@ -27,3 +27,26 @@ type Strategy interface {
 	// should be idempotent and cheap when no refresh is required.
 	Refresh(ctx context.Context) error
 }
+
+// Reservation is the payment state claimed by a single in-flight call through
+// Reserver.Reserve. Exactly one of Commit or Rollback must be called once the
+// outcome of the call is known; later calls are no-ops.
+type Reservation interface {
+	// Commit records that the call reached the daemon, so the reserved
+	// amount is final.
+	Commit()
+	// Rollback releases the reservation of a call that never reached the
+	// daemon, so the reserved amount can be signed again by the next call.
+	Rollback()
+}
+
+// Reserver is implemented by strategies whose metadata consumes state on every
+// call (for example the running signed amount of PaidStrategy). Reserve is
+// like Strategy.GRPCMetadata but leaves the claimed state pending until the
+// returned Reservation is committed or rolled back, which keeps the signed
+// total free of gaps when calls fail before reaching the daemon. When Reserve
+// returns an error (for example because the claim could not be signed),
+// nothing is reserved and the call must not be made.
+type Reserver interface {
+	Reserve(ctx context.Context) (context.Context, Reservation, error)
+}
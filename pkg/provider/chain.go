@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+)
+
+// ChannelReader reads the payment channels of the MultiPartyEscrow contract
+// for a Verifier.
+type ChannelReader interface {
+	// Channel returns the channel with the given ID. A channel that does not
+	// exist has a zero Sender.
+	Channel(ctx context.Context, id *big.Int) (blockchain.MultiPartyEscrowChannel, error)
+	// BlockNumber returns the number of the latest block.
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// NewChannelReader returns a ChannelReader backed by the MPE contract of
+// evm, for example the EVM client of sdk.Core.
+func NewChannelReader(evm *blockchain.EVMClient) ChannelReader {
+	return evmChannelReader{evm: evm}
+}
+
+type evmChannelReader struct {
+	evm *blockchain.EVMClient
+}
+
+func (r evmChannelReader) Channel(ctx context.Context, id *big.Int) (blockchain.MultiPartyEscrowChannel, error) {
+	if r.evm == nil || r.evm.MPE == nil {
+		return blockchain.MultiPartyEscrowChannel{}, errors.New("EVM client has no MPE contract")
+	}
+	ch, err := r.evm.MPE.Channels(&bind.CallOpts{Context: ctx}, id)
+	if err != nil {
+		return blockchain.MultiPartyEscrowChannel{}, err
+	}
+	return blockchain.MultiPartyEscrowChannel{
+		Sender:     ch.Sender,
+		Recipient:  ch.Recipient,
+		GroupID:    ch.GroupId,
+		Value:      ch.Value,
+		Nonce:      ch.Nonce,
+		Expiration: ch.Expiration,
+		Signer:     ch.Signer,
+	}, nil
+}
+
+func (r evmChannelReader) BlockNumber(ctx context.Context) (uint64, error) {
+	if r.evm == nil {
+		return 0, errors.New("EVM client is required")
+	}
+	block, err := r.evm.GetCurrentBlockNumberCtx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return block.Uint64(), nil
+}
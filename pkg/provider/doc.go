@@ -0,0 +1,45 @@
+// Package provider lets a Go gRPC server take SingularityNET payments
+// itself, without a daemon in front of it.
+//
+// A Verifier checks the payment headers SDK clients attach to their calls,
+// the way the daemon does:
+//   - escrow calls: the signature of the channel signer is recovered, the
+//     channel is read from the MultiPartyEscrow contract and must be opened
+//     to the group, unexpired and at the signed nonce, and the newly signed
+//     amount must exceed the last one by the price of the method;
+//   - free calls: the user must sign the call with a free-call token issued
+//     by the Verifier and have free calls left;
+//   - prepaid calls: the prepaid token must be one issued by the Verifier and
+//     the amount planned with it must still cover the price.
+//
+// The latest signed amount and signature of every channel, the amounts used
+// and the free calls made are kept in a Store (MemoryStore by default);
+// implement Store to persist them and claim the channels later.
+//
+// # Usage
+//
+//	org, _ := core.NewOrganizationClient("my-org", "default_group")
+//	svc, _ := core.NewServiceClient("my-org", "my-service", "default_group")
+//	group, err := provider.GroupFromMetadata(org.GetOrgMetadata(), "my-service", svc.GetServiceMetadata(), "default_group")
+//	if err != nil {
+//		return err
+//	}
+//	v, err := provider.NewVerifier(provider.NewChannelReader(core.GetEvm()), group)
+//	if err != nil {
+//		return err
+//	}
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(v.UnaryServerInterceptor()),
+//		grpc.ChainStreamInterceptor(v.StreamServerInterceptor()),
+//	)
+//	v.Register(server) // channel state, free-call and token services
+//	pb.RegisterCalculatorServer(server, calculator{})
+//
+// Handlers find the accepted payment with PaymentFromContext. Calls to the
+// payment, health and reflection services are never charged; WithUnpaidMethods
+// exempts more methods.
+//
+// Errors are gRPC status errors worded like those of the daemon, so
+// payment.ClassifyDaemonError maps them to the same sentinel errors on the
+// client.
+package provider
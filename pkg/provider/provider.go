@@ -0,0 +1,385 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Payment types, as sent in payment.PaymentTypeHeader.
+const (
+	PaymentTypeEscrow  = "escrow"
+	PaymentTypeFree    = "free-call"
+	PaymentTypePrepaid = "prepaid-call"
+)
+
+const (
+	// defaultFreeCallTokenLifetime is the lifetime of free-call tokens in
+	// blocks when the client does not ask for one.
+	defaultFreeCallTokenLifetime = 172800
+	// defaultBlockTolerance is how many blocks a signed block number may lag
+	// behind the chain.
+	defaultBlockTolerance = 5
+	// defaultPrepaidTokenLifetime is how long prepaid tokens stay valid.
+	defaultPrepaidTokenLifetime = 24 * time.Hour
+)
+
+// unpaidPrefixes are the method prefixes the interceptors never charge: the
+// payment services themselves, health checks and server reflection.
+var unpaidPrefixes = []string{
+	"/escrow.",
+	"/grpc.health.v1.",
+	"/grpc.reflection.",
+}
+
+// Group describes the service group a Verifier accepts payments for.
+type Group struct {
+	OrgID     string
+	ServiceID string
+	// GroupID is the decoded payment group ID.
+	GroupID [32]byte
+	// PaymentAddress is the address channels must be opened to.
+	PaymentAddress common.Address
+	// MPEAddress is the MultiPartyEscrow contract the channels live in.
+	MPEAddress common.Address
+	// Price returns the price in cogs of a call of the full gRPC method name.
+	Price func(method string) *big.Int
+	// FreeCalls is the number of free calls each user may make.
+	FreeCalls uint64
+	// ExpirationThreshold rejects channels that expire within this many
+	// blocks of the current block, leaving the provider time to claim them.
+	ExpirationThreshold uint64
+}
+
+// FixedPrice returns a Group.Price that charges cogs for every method.
+func FixedPrice(cogs *big.Int) func(string) *big.Int {
+	return func(string) *big.Int { return cogs }
+}
+
+// GroupFromMetadata builds the Group of service serviceID named groupName from
+// the published organization and service metadata, pricing methods as
+// model.ServiceGroup.MethodPrice does.
+func GroupFromMetadata(org *model.OrganizationMetaData, serviceID string, svc *model.ServiceMetadata, groupName string) (Group, error) {
+	if org == nil || svc == nil {
+		return Group{}, errors.New("organization and service metadata are required")
+	}
+	var orgGroup *model.OrganizationGroup
+	for _, g := range org.Groups {
+		if g != nil && g.GroupName == groupName {
+			orgGroup = g
+			break
+		}
+	}
+	var svcGroup *model.ServiceGroup
+	for _, g := range svc.Groups {
+		if g != nil && g.GroupName == groupName {
+			svcGroup = g
+			break
+		}
+	}
+	if orgGroup == nil || svcGroup == nil {
+		return Group{}, fmt.Errorf("group %q not found in organization and service metadata", groupName)
+	}
+	groupID, err := blockchain.DecodePaymentGroupID(orgGroup.ID)
+	if err != nil {
+		return Group{}, fmt.Errorf("decode group ID %q: %w", orgGroup.ID, err)
+	}
+	if !common.IsHexAddress(orgGroup.PaymentDetails.PaymentAddress) {
+		return Group{}, fmt.Errorf("group %q has no valid payment address", groupName)
+	}
+	var threshold uint64
+	if t := orgGroup.PaymentDetails.PaymentExpirationThreshold; t != nil && t.IsUint64() {
+		threshold = t.Uint64()
+	}
+	return Group{
+		OrgID:               org.OrgID,
+		ServiceID:           serviceID,
+		GroupID:             groupID,
+		PaymentAddress:      common.HexToAddress(orgGroup.PaymentDetails.PaymentAddress),
+		MPEAddress:          svc.GetMpeAddr(),
+		Price:               svcGroup.MethodPrice,
+		FreeCalls:           uint64(max(svcGroup.FreeCalls, 0)),
+		ExpirationThreshold: threshold,
+	}, nil
+}
+
+// Payment is a call accepted by a Verifier and what was paid for it.
+type Payment struct {
+	// Method is the full gRPC method name of the call.
+	Method string
+	// Type is PaymentTypeEscrow, PaymentTypeFree or PaymentTypePrepaid.
+	Type string
+	// User is the channel signer of paid calls and the user of free calls.
+	User common.Address
+	// ChannelID and Nonce identify the channel a paid call was charged to;
+	// nil for free calls.
+	ChannelID *big.Int
+	Nonce     *big.Int
+	// Price is the amount charged for the call in cogs; zero for free calls.
+	Price *big.Int
+}
+
+type paymentKey struct{}
+
+// PaymentFromContext returns the payment of the call whose server context is
+// ctx, as accepted by the interceptors of a Verifier.
+func PaymentFromContext(ctx context.Context) (*Payment, bool) {
+	p, ok := ctx.Value(paymentKey{}).(*Payment)
+	return p, ok
+}
+
+// Verifier checks the payments of calls to one service group the way the
+// SingularityNET daemon does, so a Go gRPC server can serve SDK clients
+// without a daemon in front of it. Use its interceptors to charge the calls
+// and Register to serve the channel-state, free-call and token APIs the
+// clients call before paying.
+//
+// An escrow call must carry the current nonce of a channel opened to the
+// group and a signature of the channel signer for the price on top of the
+// amount signed so far. A free call must carry a token issued to the signing
+// user while the user has free calls left. A prepaid call must carry a token
+// whose planned amount still covers the price.
+type Verifier struct {
+	chain ChannelReader
+	group Group
+	store Store
+
+	tokenKey             []byte
+	blockTolerance       uint64
+	freeCallLifetime     uint64
+	prepaidTokenLifetime time.Duration
+	unpaid               map[string]bool
+	log                  *zap.Logger
+	now                  func() time.Time
+
+	// mu serialises every read-modify-write of the store.
+	mu sync.Mutex
+}
+
+// Option configures a Verifier.
+type Option func(*Verifier)
+
+// WithStore keeps the payment state in s instead of a MemoryStore.
+func WithStore(s Store) Option {
+	return func(v *Verifier) {
+		v.store = s
+	}
+}
+
+// WithTokenKey sets the secret that authenticates the free-call and prepaid
+// tokens issued by the Verifier. By default a random key is generated, so
+// tokens do not survive a restart; set the same key on every replica of a
+// service that shares a store.
+func WithTokenKey(key []byte) Option {
+	return func(v *Verifier) {
+		v.tokenKey = key
+	}
+}
+
+// WithBlockTolerance sets how many blocks the block number signed by a
+// client may lag behind the chain (default 5).
+func WithBlockTolerance(blocks uint64) Option {
+	return func(v *Verifier) {
+		v.blockTolerance = blocks
+	}
+}
+
+// WithFreeCallTokenLifetime sets the lifetime in blocks of free-call tokens
+// (default 172800). Clients may ask for a shorter lifetime, never for a
+// longer one.
+func WithFreeCallTokenLifetime(blocks uint64) Option {
+	return func(v *Verifier) {
+		v.freeCallLifetime = blocks
+	}
+}
+
+// WithPrepaidTokenLifetime sets how long prepaid tokens stay valid (default
+// 24 hours).
+func WithPrepaidTokenLifetime(d time.Duration) Option {
+	return func(v *Verifier) {
+		v.prepaidTokenLifetime = d
+	}
+}
+
+// WithUnpaidMethods lets the interceptors pass calls of the given full gRPC
+// method names without payment, in addition to the payment, health and
+// reflection services.
+func WithUnpaidMethods(methods ...string) Option {
+	return func(v *Verifier) {
+		for _, m := range methods {
+			v.unpaid[m] = true
+		}
+	}
+}
+
+// WithLogger makes the Verifier log to l instead of zap.L().
+func WithLogger(l *zap.Logger) Option {
+	return func(v *Verifier) {
+		v.log = l
+	}
+}
+
+// NewVerifier returns a Verifier for the payments of group, reading channels
+// through chain.
+func NewVerifier(chain ChannelReader, group Group, opts ...Option) (*Verifier, error) {
+	if chain == nil {
+		return nil, errors.New("channel reader is required")
+	}
+	if group.Price == nil {
+		return nil, errors.New("group price is required")
+	}
+	if group.PaymentAddress == (common.Address{}) || group.MPEAddress == (common.Address{}) {
+		return nil, errors.New("group payment and MPE addresses are required")
+	}
+	v := &Verifier{
+		chain:                chain,
+		group:                group,
+		blockTolerance:       defaultBlockTolerance,
+		freeCallLifetime:     defaultFreeCallTokenLifetime,
+		prepaidTokenLifetime: defaultPrepaidTokenLifetime,
+		unpaid:               make(map[string]bool),
+		now:                  time.Now,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.store == nil {
+		v.store = NewMemoryStore()
+	}
+	if len(v.tokenKey) == 0 {
+		v.tokenKey = make([]byte, 32)
+		if _, err := rand.Read(v.tokenKey); err != nil {
+			return nil, fmt.Errorf("generate token key: %w", err)
+		}
+	}
+	return v, nil
+}
+
+// Group returns the group the Verifier accepts payments for.
+func (v *Verifier) Group() Group {
+	return v.group
+}
+
+// Store returns the store holding the payment state.
+func (v *Verifier) Store() Store {
+	return v.store
+}
+
+// logger returns the logger set with WithLogger, or zap.L().
+func (v *Verifier) logger() *zap.Logger {
+	if v.log != nil {
+		return v.log
+	}
+	return zap.L()
+}
+
+// paid reports whether calls of method must be paid for.
+func (v *Verifier) paid(method string) bool {
+	if v.unpaid[method] {
+		return false
+	}
+	for _, prefix := range unpaidPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Verify checks and charges the payment carried by the incoming metadata of
+// ctx for a call of method. Errors are gRPC status errors worded like those
+// of the daemon, so payment.ClassifyDaemonError recognises them on the
+// client.
+func (v *Verifier) Verify(ctx context.Context, method string) (*Payment, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := func(key string) string {
+		if vals := md.Get(key); len(vals) > 0 {
+			return vals[0]
+		}
+		return ""
+	}
+
+	var (
+		p   *Payment
+		err error
+	)
+	switch typ := header(payment.PaymentTypeHeader); typ {
+	case PaymentTypeEscrow:
+		p, err = v.verifyEscrow(ctx, method, header)
+	case PaymentTypeFree:
+		p, err = v.verifyFreeCall(ctx, method, header)
+	case PaymentTypePrepaid:
+		p, err = v.verifyPrepaid(ctx, method, header)
+	case "":
+		err = status.Error(codes.Unauthenticated, "missing payment type")
+	default:
+		err = status.Errorf(codes.InvalidArgument, "unknown payment type %q", typ)
+	}
+	if err != nil {
+		v.logger().Debug("payment rejected", zap.String("method", method), zap.Error(err))
+		return nil, err
+	}
+	v.logger().Debug("payment accepted",
+		zap.String("method", method),
+		zap.String("type", p.Type),
+		zap.String("user", p.User.Hex()),
+		zap.Stringer("price", p.Price))
+	return p, nil
+}
+
+// UnaryServerInterceptor returns an interceptor that verifies the payment of
+// every paid unary call before the handler runs. The handler finds the
+// payment with PaymentFromContext.
+func (v *Verifier) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !v.paid(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		p, err := v.Verify(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, paymentKey{}, p), req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that verifies the payment
+// of every paid stream once, when the stream opens. The handler finds the
+// payment with PaymentFromContext on the stream context.
+func (v *Verifier) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !v.paid(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		p, err := v.Verify(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &paidStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), paymentKey{}, p)})
+	}
+}
+
+// paidStream is a server stream whose context carries its payment.
+type paidStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *paidStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,277 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	testBlock  = 100
+	testMethod = "/example_service.Calculator/add"
+)
+
+var (
+	testMPE       = common.HexToAddress("0x5e592F9b1d303183d963635f895f0f0C48284f4e")
+	testRecipient = common.HexToAddress("0x3bb9b2499c283cec176e7C707Ecb495B7a961ebf")
+	testGroupID   = [32]byte{1, 2, 3}
+)
+
+// testChain is a chain at block holding channels.
+type testChain struct {
+	block    uint64
+	channels map[int64]blockchain.MultiPartyEscrowChannel
+}
+
+func (c *testChain) Channel(_ context.Context, id *big.Int) (blockchain.MultiPartyEscrowChannel, error) {
+	return c.channels[id.Int64()], nil
+}
+
+func (c *testChain) BlockNumber(context.Context) (uint64, error) {
+	return c.block, nil
+}
+
+// open adds channel id, signed by key, to the chain.
+func (c *testChain) open(id int64, key *ecdsa.PrivateKey, value int64) {
+	c.channels[id] = blockchain.MultiPartyEscrowChannel{
+		Sender:     crypto.PubkeyToAddress(key.PublicKey),
+		Recipient:  testRecipient,
+		GroupID:    testGroupID,
+		Value:      big.NewInt(value),
+		Nonce:      big.NewInt(0),
+		Expiration: big.NewInt(testBlock + 1000),
+		Signer:     crypto.PubkeyToAddress(key.PublicKey),
+	}
+}
+
+func newTestVerifier(t *testing.T, freeCalls uint64, opts ...Option) (*Verifier, *testChain) {
+	t.Helper()
+	chain := &testChain{block: testBlock, channels: make(map[int64]blockchain.MultiPartyEscrowChannel)}
+	v, err := NewVerifier(chain, Group{
+		OrgID:          "org",
+		ServiceID:      "calc",
+		GroupID:        testGroupID,
+		PaymentAddress: testRecipient,
+		MPEAddress:     testMPE,
+		Price:          FixedPrice(big.NewInt(10)),
+		FreeCalls:      freeCalls,
+	}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v, chain
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, message []byte) []byte {
+	t.Helper()
+	s, err := signer.NewPrivateKeySigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := blockchain.SignMessage(context.Background(), s, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func escrowHeaders(t *testing.T, key *ecdsa.PrivateKey, id, amount int64) []string {
+	t.Helper()
+	sig := sign(t, key, claimMessage(testMPE, big.NewInt(id), big.NewInt(0), big.NewInt(amount)))
+	return []string{
+		payment.PaymentTypeHeader, PaymentTypeEscrow,
+		payment.PaymentChannelIDHeader, strconv.FormatInt(id, 10),
+		payment.PaymentChannelNonceHeader, "0",
+		payment.PaymentChannelAmountHeader, strconv.FormatInt(amount, 10),
+		payment.PaymentChannelSignatureHeader, string(sig),
+	}
+}
+
+// call runs a unary call of method with the given metadata through the
+// interceptor of v and returns the payment the handler saw.
+func call(v *Verifier, method string, kv ...string) (*Payment, error) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+	var got *Payment
+	_, err := v.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, _ any) (any, error) {
+		got, _ = PaymentFromContext(ctx)
+		return nil, nil
+	})
+	return got, err
+}
+
+func TestVerifier_Escrow(t *testing.T) {
+	v, chain := newTestVerifier(t, 0)
+	user, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	chain.open(7, user, 25)
+
+	p, err := call(v, testMethod, escrowHeaders(t, user, 7, 10)...)
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	if p == nil || p.Type != PaymentTypeEscrow || p.Price.Int64() != 10 || p.User != crypto.PubkeyToAddress(user.PublicKey) {
+		t.Fatalf("payment = %+v; want an escrow payment of 10 by the user", p)
+	}
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    error
+	}{
+		{"no payment", nil, nil},
+		{"same amount again", escrowHeaders(t, user, 7, 10), payment.ErrSignatureRejected},
+		{"signed by another key", escrowHeaders(t, other, 7, 20), payment.ErrSignatureRejected},
+		{"more than the channel value", escrowHeaders(t, user, 7, 30), payment.ErrInsufficientChannelFunds},
+		{"unknown channel", escrowHeaders(t, user, 8, 10), payment.ErrChannelNotFound},
+	}
+	for _, tt := range tests {
+		_, err := call(v, testMethod, tt.headers...)
+		if err == nil || (tt.want != nil && !errors.Is(payment.ClassifyDaemonError(err), tt.want)) {
+			t.Errorf("%s: err = %v; want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := call(v, testMethod, escrowHeaders(t, user, 7, 20)...); err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+	st, err := v.Store().LoadChannel(context.Background(), testMPE, big.NewInt(7))
+	if err != nil || st.SignedAmount.Int64() != 20 || st.UsedAmount.Int64() != 20 || len(st.Signature) == 0 {
+		t.Fatalf("stored state = %+v, %v; want signed and used amounts of 20", st, err)
+	}
+
+	// After a claim the channel is at the next nonce, and payments signed
+	// for the old one are rejected.
+	ch := chain.channels[7]
+	ch.Nonce = big.NewInt(1)
+	chain.channels[7] = ch
+	if _, err := call(v, testMethod, escrowHeaders(t, user, 7, 30)...); !errors.Is(payment.ClassifyDaemonError(err), payment.ErrSignatureRejected) {
+		t.Fatalf("call at the old nonce: err = %v; want %v", err, payment.ErrSignatureRejected)
+	}
+}
+
+func TestVerifier_ExpiredChannel(t *testing.T) {
+	v, chain := newTestVerifier(t, 0)
+	user, _ := crypto.GenerateKey()
+	chain.open(1, user, 100)
+	chain.block = testBlock + 1000
+
+	_, err := call(v, testMethod, escrowHeaders(t, user, 1, 10)...)
+	if !errors.Is(payment.ClassifyDaemonError(err), payment.ErrChannelExpired) {
+		t.Fatalf("err = %v; want %v", err, payment.ErrChannelExpired)
+	}
+}
+
+func TestVerifier_UnpaidMethods(t *testing.T) {
+	v, _ := newTestVerifier(t, 0, WithUnpaidMethods("/example_service.Calculator/version"))
+	for _, method := range []string{
+		"/grpc.health.v1.Health/Check",
+		"/escrow.PaymentChannelStateService/GetChannelState",
+		"/example_service.Calculator/version",
+	} {
+		if p, err := call(v, method); err != nil || p != nil {
+			t.Errorf("%s: payment = %+v, err = %v; want an unpaid call", method, p, err)
+		}
+	}
+	if _, err := call(v, testMethod); err == nil {
+		t.Error("paid method without payment succeeded")
+	}
+}
+
+func TestVerifier_StreamInterceptor(t *testing.T) {
+	v, chain := newTestVerifier(t, 0)
+	user, _ := crypto.GenerateKey()
+	chain.open(2, user, 100)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(escrowHeaders(t, user, 2, 10)...))
+	var got *Payment
+	err := v.StreamServerInterceptor()(nil, testStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: testMethod}, func(_ any, ss grpc.ServerStream) error {
+		got, _ = PaymentFromContext(ss.Context())
+		return nil
+	})
+	if err != nil || got == nil || got.ChannelID.Int64() != 2 {
+		t.Fatalf("payment = %+v, err = %v; want a payment from channel 2", got, err)
+	}
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testStream) Context() context.Context { return s.ctx }
+
+func TestVerifier_PrepaidTokenExpires(t *testing.T) {
+	v, chain := newTestVerifier(t, 0, WithPrepaidTokenLifetime(time.Minute))
+	user, _ := crypto.GenerateKey()
+	chain.open(3, user, 100)
+
+	token, err := v.prepaidToken(big.NewInt(3), big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.parsePrepaidToken(token); err != nil {
+		t.Fatalf("parse fresh token: %v", err)
+	}
+	if _, err := v.parsePrepaidToken(token + "x"); err == nil {
+		t.Fatal("tampered token accepted")
+	}
+	v.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err = call(v, testMethod,
+		payment.PaymentTypeHeader, PaymentTypePrepaid,
+		payment.PaymentChannelIDHeader, "3",
+		payment.PaymentChannelNonceHeader, "0",
+		payment.PrePaidAuthTokenHeader, token,
+	)
+	if !errors.Is(payment.ClassifyDaemonError(err), payment.ErrPrepaidTokenExpired) {
+		t.Fatalf("err = %v; want %v", err, payment.ErrPrepaidTokenExpired)
+	}
+}
+
+func TestGroupFromMetadata(t *testing.T) {
+	org := &model.OrganizationMetaData{
+		OrgID: "org",
+		Groups: []*model.OrganizationGroup{{
+			ID:        "AQID",
+			GroupName: "default_group",
+			PaymentDetails: model.Payment{
+				PaymentAddress:             testRecipient.Hex(),
+				PaymentExpirationThreshold: big.NewInt(40320),
+			},
+		}},
+	}
+	svc := &model.ServiceMetadata{
+		MPEAddress: testMPE.Hex(),
+		Groups: []*model.ServiceGroup{{
+			GroupName: "default_group",
+			FreeCalls: 3,
+			Pricing:   []model.Pricing{{PriceModel: model.PriceModelFixed, PriceInCogs: big.NewInt(7), Default: true}},
+		}},
+	}
+
+	g, err := GroupFromMetadata(org, "calc", svc, "default_group")
+	if err != nil {
+		t.Fatalf("GroupFromMetadata: %v", err)
+	}
+	if g.GroupID != testGroupID || g.PaymentAddress != testRecipient || g.MPEAddress != testMPE || g.FreeCalls != 3 || g.ExpirationThreshold != 40320 {
+		t.Fatalf("group = %+v", g)
+	}
+	if price := g.Price(testMethod); price.Int64() != 7 {
+		t.Fatalf("Price = %v; want 7", price)
+	}
+	if _, err := GroupFromMetadata(org, "calc", svc, "other"); err == nil {
+		t.Fatal("unknown group accepted")
+	}
+}
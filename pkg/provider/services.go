@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Register serves the PaymentChannelStateService, FreeCallStateService and
+// TokenService of the Verifier on s. SDK clients call them to learn the
+// signed amount of their channels and to obtain free-call and prepaid tokens.
+func (v *Verifier) Register(s grpc.ServiceRegistrar) {
+	payment.RegisterPaymentChannelStateServiceServer(s, stateService{v: v})
+	payment.RegisterFreeCallStateServiceServer(s, freeCallService{v: v})
+	payment.RegisterTokenServiceServer(s, tokenService{v: v})
+}
+
+// stateService implements PaymentChannelStateService.
+type stateService struct {
+	payment.UnimplementedPaymentChannelStateServiceServer
+	v *Verifier
+}
+
+func (s stateService) GetChannelState(ctx context.Context, req *payment.ChannelStateRequest) (*payment.ChannelStateReply, error) {
+	v := s.v
+	id := new(big.Int).SetBytes(req.GetChannelId())
+	ch, err := v.chain.Channel(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "read payment channel %s: %v", id, err)
+	}
+	if ch.Sender == (common.Address{}) {
+		return nil, status.Errorf(codes.NotFound, "channel is not found, channelId: %s", id)
+	}
+	if _, err := v.checkBlock(ctx, req.GetCurrentBlock()); err != nil {
+		return nil, err
+	}
+	message := bytes.Join([][]byte{
+		[]byte(payment.PrefixGetChannelState),
+		v.group.MPEAddress.Bytes(),
+		common.BigToHash(id).Bytes(),
+		math.U256Bytes(new(big.Int).SetUint64(req.GetCurrentBlock())),
+	}, nil)
+	signer, err := RecoverSigner(message, req.GetSignature())
+	if err != nil || (signer != ch.Signer && signer != ch.Sender) {
+		return nil, status.Error(codes.Unauthenticated, "only the channel signer or sender may read the channel state: incorrect signature")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	st, err := v.loadChannel(ctx, id, ch.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &payment.ChannelStateReply{
+		CurrentNonce:        ch.Nonce.Bytes(),
+		CurrentSignedAmount: st.SignedAmount.Bytes(),
+		CurrentSignature:    st.Signature,
+		PlannedAmount:       st.PlannedAmount.Uint64(),
+		UsedAmount:          st.UsedAmount.Uint64(),
+	}, nil
+}
+
+// freeCallService implements FreeCallStateService.
+type freeCallService struct {
+	payment.UnimplementedFreeCallStateServiceServer
+	v *Verifier
+}
+
+func (s freeCallService) GetFreeCallToken(ctx context.Context, req *payment.GetFreeCallTokenRequest) (*payment.FreeCallToken, error) {
+	v := s.v
+	if !common.IsHexAddress(req.GetAddress()) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid address %q", req.GetAddress())
+	}
+	user := common.HexToAddress(req.GetAddress())
+	if _, err := v.checkBlock(ctx, req.GetCurrentBlock()); err != nil {
+		return nil, err
+	}
+	if err := checkSigner(v.freeCallMessage(req.GetAddress(), req.GetCurrentBlock(), nil), req.GetSignature(), user); err != nil {
+		return nil, err
+	}
+	// Clients may ask for a shorter lifetime, never for a longer one.
+	lifetime := v.freeCallLifetime
+	if req.TokenLifetimeInBlocks != nil {
+		lifetime = min(req.GetTokenLifetimeInBlocks(), lifetime)
+	}
+	expiration := req.GetCurrentBlock() + lifetime
+	if expiration < lifetime {
+		return nil, status.Errorf(codes.InvalidArgument, "token lifetime of %d blocks from block %d overflows", lifetime, req.GetCurrentBlock())
+	}
+	token := v.freeCallToken(user, expiration)
+	return &payment.FreeCallToken{
+		Token:                token,
+		TokenHex:             hex.EncodeToString(token),
+		TokenExpirationBlock: expiration,
+	}, nil
+}
+
+func (s freeCallService) GetFreeCallsAvailable(ctx context.Context, req *payment.FreeCallStateRequest) (*payment.FreeCallStateReply, error) {
+	v := s.v
+	if !common.IsHexAddress(req.GetAddress()) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid address %q", req.GetAddress())
+	}
+	user := common.HexToAddress(req.GetAddress())
+	current, err := v.checkBlock(ctx, req.GetCurrentBlock())
+	if err != nil {
+		return nil, err
+	}
+	message := v.freeCallMessage(req.GetAddress(), req.GetCurrentBlock(), req.GetFreeCallToken())
+	if err := checkSigner(message, req.GetSignature(), user); err != nil {
+		return nil, err
+	}
+	if err := v.checkFreeCallToken(req.GetFreeCallToken(), user, current); err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	used, err := v.store.FreeCallsUsed(ctx, v.group.GroupID, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "load free calls of %s: %v", user.Hex(), err)
+	}
+	var available uint64
+	if used < v.group.FreeCalls {
+		available = v.group.FreeCalls - used
+	}
+	return &payment.FreeCallStateReply{FreeCallsAvailable: available}, nil
+}
+
+// tokenService implements TokenService.
+type tokenService struct {
+	payment.UnimplementedTokenServiceServer
+	v *Verifier
+}
+
+func (s tokenService) GetToken(ctx context.Context, req *payment.TokenRequest) (*payment.TokenReply, error) {
+	v := s.v
+	id := new(big.Int).SetUint64(req.GetChannelId())
+	nonce := new(big.Int).SetUint64(req.GetCurrentNonce())
+	signed := new(big.Int).SetUint64(req.GetSignedAmount())
+
+	ch, err := v.readChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if nonce.Cmp(ch.Nonce) != 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "incorrect nonce %s: channel nonce is %s", nonce, ch.Nonce)
+	}
+	if _, err := v.checkBlock(ctx, req.GetCurrentBlock()); err != nil {
+		return nil, err
+	}
+	if err := checkSigner(claimMessage(v.group.MPEAddress, id, nonce, signed), req.GetClaimSignature(), ch.Signer); err != nil {
+		return nil, err
+	}
+	block := math.U256Bytes(new(big.Int).SetUint64(req.GetCurrentBlock()))
+	if err := checkSigner(bytes.Join([][]byte{req.GetClaimSignature(), block}, nil), req.GetSignature(), ch.Signer); err != nil {
+		return nil, err
+	}
+	if signed.Cmp(ch.Value) > 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "not enough tokens on payment channel %s: signed amount %s exceeds channel value %s", id, signed, ch.Value)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	st, err := v.loadChannel(ctx, id, ch.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if signed.Cmp(st.SignedAmount) < 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "signed amount %s is less than the last signed amount %s", signed, st.SignedAmount)
+	}
+	token, err := v.prepaidToken(id, nonce)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "issue prepaid token: %v", err)
+	}
+	st.SignedAmount, st.Signature = signed, req.GetClaimSignature()
+	st.PlannedAmount = new(big.Int).Set(signed)
+	if err := v.saveChannel(ctx, st); err != nil {
+		return nil, err
+	}
+	return &payment.TokenReply{
+		ChannelId:     req.GetChannelId(),
+		Token:         token,
+		PlannedAmount: st.PlannedAmount.Uint64(),
+		UsedAmount:    st.UsedAmount.Uint64(),
+	}, nil
+}
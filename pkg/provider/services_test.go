@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFreeCallService(t *testing.T) {
+	v, _ := newTestVerifier(t, 1)
+	user, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(user.PublicKey).Hex()
+	service := freeCallService{v: v}
+	ctx := context.Background()
+
+	token, err := service.GetFreeCallToken(ctx, &payment.GetFreeCallTokenRequest{
+		Address:      addr,
+		Signature:    sign(t, user, v.freeCallMessage(addr, testBlock, nil)),
+		CurrentBlock: testBlock,
+	})
+	if err != nil {
+		t.Fatalf("GetFreeCallToken: %v", err)
+	}
+	if token.TokenExpirationBlock != testBlock+defaultFreeCallTokenLifetime {
+		t.Fatalf("TokenExpirationBlock = %d", token.TokenExpirationBlock)
+	}
+	for requested, want := range map[uint64]uint64{
+		10:                               testBlock + 10,
+		2 * defaultFreeCallTokenLifetime: testBlock + defaultFreeCallTokenLifetime,
+	} {
+		long, err := service.GetFreeCallToken(ctx, &payment.GetFreeCallTokenRequest{
+			Address:               addr,
+			Signature:             sign(t, user, v.freeCallMessage(addr, testBlock, nil)),
+			CurrentBlock:          testBlock,
+			TokenLifetimeInBlocks: &requested,
+		})
+		if err != nil || long.TokenExpirationBlock != want {
+			t.Fatalf("GetFreeCallToken for %d blocks = %v, %v; want expiration %d", requested, long, err, want)
+		}
+	}
+	if _, err := service.GetFreeCallToken(ctx, &payment.GetFreeCallTokenRequest{
+		Address:      addr,
+		Signature:    sign(t, user, v.freeCallMessage(addr, testBlock-50, nil)),
+		CurrentBlock: testBlock - 50,
+	}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("GetFreeCallToken for an old block: err = %v; want Unauthenticated", err)
+	}
+
+	headers := []string{
+		payment.PaymentTypeHeader, PaymentTypeFree,
+		payment.FreeCallAuthTokenHeader, string(token.Token),
+		payment.FreeCallUserAddressHeader, addr,
+		payment.PaymentChannelSignatureHeader, string(sign(t, user, v.freeCallMessage(addr, testBlock, token.Token))),
+		payment.CurrentBlockNumberHeader, strconv.Itoa(testBlock),
+	}
+	if p, err := call(v, testMethod, headers...); err != nil || p.Type != PaymentTypeFree || p.Price.Sign() != 0 {
+		t.Fatalf("free call: payment = %+v, err = %v", p, err)
+	}
+	if _, err := call(v, testMethod, headers...); !errors.Is(payment.ClassifyDaemonError(err), payment.ErrFreeCallsExhausted) {
+		t.Fatalf("second free call: err = %v; want %v", err, payment.ErrFreeCallsExhausted)
+	}
+
+	reply, err := service.GetFreeCallsAvailable(ctx, &payment.FreeCallStateRequest{
+		Address:       addr,
+		FreeCallToken: token.Token,
+		Signature:     sign(t, user, v.freeCallMessage(addr, testBlock, token.Token)),
+		CurrentBlock:  testBlock,
+	})
+	if err != nil || reply.FreeCallsAvailable != 0 {
+		t.Fatalf("GetFreeCallsAvailable = %v, %v; want 0", reply, err)
+	}
+
+	// A token of one user is of no use to another.
+	other, _ := crypto.GenerateKey()
+	otherAddr := crypto.PubkeyToAddress(other.PublicKey).Hex()
+	if _, err := call(v, testMethod,
+		payment.PaymentTypeHeader, PaymentTypeFree,
+		payment.FreeCallAuthTokenHeader, string(token.Token),
+		payment.FreeCallUserAddressHeader, otherAddr,
+		payment.PaymentChannelSignatureHeader, string(sign(t, other, v.freeCallMessage(otherAddr, testBlock, token.Token))),
+		payment.CurrentBlockNumberHeader, strconv.Itoa(testBlock),
+	); err == nil {
+		t.Fatal("free call with the token of another user succeeded")
+	}
+
+	v.freeCallLifetime = ^uint64(0)
+	if _, err := service.GetFreeCallToken(ctx, &payment.GetFreeCallTokenRequest{
+		Address:      addr,
+		Signature:    sign(t, user, v.freeCallMessage(addr, testBlock, nil)),
+		CurrentBlock: testBlock,
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("GetFreeCallToken past the last block: err = %v; want InvalidArgument", err)
+	}
+}
+
+func TestTokenService_PrepaidCalls(t *testing.T) {
+	v, chain := newTestVerifier(t, 0)
+	user, _ := crypto.GenerateKey()
+	chain.open(3, user, 100)
+
+	claim := sign(t, user, claimMessage(testMPE, big.NewInt(3), big.NewInt(0), big.NewInt(20)))
+	block := math.U256Bytes(big.NewInt(testBlock))
+	reply, err := tokenService{v: v}.GetToken(context.Background(), &payment.TokenRequest{
+		ChannelId:      3,
+		CurrentNonce:   0,
+		SignedAmount:   20,
+		Signature:      sign(t, user, append(bytes.Clone(claim), block...)),
+		CurrentBlock:   testBlock,
+		ClaimSignature: claim,
+	})
+	if err != nil {
+		t.Fatalf("GetToken: %v", err)
+	}
+	if reply.PlannedAmount != 20 || reply.UsedAmount != 0 {
+		t.Fatalf("GetToken = %v; want planned 20 and used 0", reply)
+	}
+
+	headers := []string{
+		payment.PaymentTypeHeader, PaymentTypePrepaid,
+		payment.PaymentChannelIDHeader, "3",
+		payment.PaymentChannelNonceHeader, "0",
+		payment.PrePaidAuthTokenHeader, reply.Token,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := call(v, testMethod, headers...); err != nil {
+			t.Fatalf("prepaid call %d: %v", i+1, err)
+		}
+	}
+	if _, err := call(v, testMethod, headers...); !errors.Is(payment.ClassifyDaemonError(err), payment.ErrInsufficientChannelFunds) {
+		t.Fatalf("third prepaid call: err = %v; want %v", err, payment.ErrInsufficientChannelFunds)
+	}
+
+	headers[3] = "4"
+	if _, err := call(v, testMethod, headers...); err == nil {
+		t.Fatal("prepaid call with the token of another channel succeeded")
+	}
+}
+
+func TestStateService_GetChannelState(t *testing.T) {
+	v, chain := newTestVerifier(t, 0)
+	user, _ := crypto.GenerateKey()
+	stranger, _ := crypto.GenerateKey()
+	chain.open(7, user, 100)
+	if _, err := call(v, testMethod, escrowHeaders(t, user, 7, 10)...); err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(key *ecdsa.PrivateKey) *payment.ChannelStateRequest {
+		return &payment.ChannelStateRequest{
+			ChannelId: big.NewInt(7).Bytes(),
+			Signature: sign(t, key, bytes.Join([][]byte{
+				[]byte(payment.PrefixGetChannelState),
+				testMPE.Bytes(),
+				common.BigToHash(big.NewInt(7)).Bytes(),
+				math.U256Bytes(big.NewInt(testBlock)),
+			}, nil)),
+			CurrentBlock: testBlock,
+		}
+	}
+	service := stateService{v: v}
+	state, err := service.GetChannelState(context.Background(), request(user))
+	if err != nil {
+		t.Fatalf("GetChannelState: %v", err)
+	}
+	if new(big.Int).SetBytes(state.CurrentSignedAmount).Int64() != 10 || state.UsedAmount != 10 || len(state.CurrentSignature) == 0 {
+		t.Fatalf("GetChannelState = %v; want signed and used amounts of 10", state)
+	}
+	if _, err := service.GetChannelState(context.Background(), request(stranger)); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("GetChannelState by a stranger: err = %v; want Unauthenticated", err)
+	}
+}
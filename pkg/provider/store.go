@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrChannelStateNotFound is returned by a Store when it holds no state for
+// the requested channel.
+var ErrChannelStateNotFound = errors.New("channel state not found")
+
+// ChannelState is what the provider knows of one payment channel: the latest
+// amount the client signed at the current nonce, with its signature so the
+// provider can claim it, and the amounts planned for and used by calls.
+type ChannelState struct {
+	MPEAddress common.Address `json:"mpe_address"`
+	ChannelID  *big.Int       `json:"channel_id"`
+	Nonce      *big.Int       `json:"nonce"`
+	// SignedAmount is the total amount the client authorised at Nonce, and
+	// Signature the client's signature of the claim for it.
+	SignedAmount *big.Int `json:"signed_amount"`
+	Signature    []byte   `json:"signature"`
+	// PlannedAmount is the amount prepaid tokens of the channel may spend,
+	// and UsedAmount the amount spent by calls of any payment type.
+	PlannedAmount *big.Int  `json:"planned_amount"`
+	UsedAmount    *big.Int  `json:"used_amount"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Store persists payment state for a Verifier. The Verifier serialises its
+// own reads and writes, so implementations only need to be safe for
+// concurrent use; share a store between Verifiers of different processes only
+// if it offers stronger guarantees.
+type Store interface {
+	// LoadChannel returns the state of the channel, or
+	// ErrChannelStateNotFound.
+	LoadChannel(ctx context.Context, mpe common.Address, channelID *big.Int) (*ChannelState, error)
+	// SaveChannel stores state, replacing any previous state of the same
+	// channel.
+	SaveChannel(ctx context.Context, state *ChannelState) error
+	// FreeCallsUsed returns the number of free calls user made to the
+	// payment group; zero when there are none.
+	FreeCallsUsed(ctx context.Context, groupID [32]byte, user common.Address) (uint64, error)
+	// SaveFreeCallsUsed stores the number of free calls user made to the
+	// payment group.
+	SaveFreeCallsUsed(ctx context.Context, groupID [32]byte, user common.Address, used uint64) error
+}
+
+// channelKey identifies a channel inside a store.
+func channelKey(mpe common.Address, channelID *big.Int) string {
+	return mpe.Hex() + "/" + channelID.String()
+}
+
+// freeCallKey identifies a user of a payment group inside a store.
+func freeCallKey(groupID [32]byte, user common.Address) string {
+	return common.Hash(groupID).Hex() + "/" + user.Hex()
+}
+
+// copyChannelState returns a deep copy of s so that callers never share
+// big.Int values with a store.
+func copyChannelState(s *ChannelState) *ChannelState {
+	c := *s
+	for _, v := range []**big.Int{&c.ChannelID, &c.Nonce, &c.SignedAmount, &c.PlannedAmount, &c.UsedAmount} {
+		if *v != nil {
+			*v = new(big.Int).Set(*v)
+		}
+	}
+	c.Signature = append([]byte(nil), s.Signature...)
+	return &c
+}
+
+// MemoryStore keeps payment state in memory. State is lost when the process
+// exits, so channels can then only be claimed up to the amount the daemon of
+// the group reports.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	channels  map[string]*ChannelState
+	freeCalls map[string]uint64
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		channels:  make(map[string]*ChannelState),
+		freeCalls: make(map[string]uint64),
+	}
+}
+
+// LoadChannel implements Store.
+func (m *MemoryStore) LoadChannel(_ context.Context, mpe common.Address, channelID *big.Int) (*ChannelState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.channels[channelKey(mpe, channelID)]
+	if !ok {
+		return nil, ErrChannelStateNotFound
+	}
+	return copyChannelState(s), nil
+}
+
+// SaveChannel implements Store.
+func (m *MemoryStore) SaveChannel(_ context.Context, state *ChannelState) error {
+	if state == nil || state.ChannelID == nil {
+		return errors.New("channel state without channel id")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.channels[channelKey(state.MPEAddress, state.ChannelID)] = copyChannelState(state)
+	return nil
+}
+
+// Channels returns the state of every channel in the store, for example to
+// claim the signed amounts.
+func (m *MemoryStore) Channels() []*ChannelState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	channels := make([]*ChannelState, 0, len(m.channels))
+	for _, s := range m.channels {
+		channels = append(channels, copyChannelState(s))
+	}
+	return channels
+}
+
+// FreeCallsUsed implements Store.
+func (m *MemoryStore) FreeCallsUsed(_ context.Context, groupID [32]byte, user common.Address) (uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.freeCalls[freeCallKey(groupID, user)], nil
+}
+
+// SaveFreeCallsUsed implements Store.
+func (m *MemoryStore) SaveFreeCallsUsed(_ context.Context, groupID [32]byte, user common.Address, used uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.freeCalls[freeCallKey(groupID, user)] = used
+	return nil
+}
@@ -0,0 +1,369 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (v *Verifier) verifyEscrow(ctx context.Context, method string, header func(string) string) (*Payment, error) {
+	id, okID := new(big.Int).SetString(header(payment.PaymentChannelIDHeader), 10)
+	nonce, okNonce := new(big.Int).SetString(header(payment.PaymentChannelNonceHeader), 10)
+	amount, okAmount := new(big.Int).SetString(header(payment.PaymentChannelAmountHeader), 10)
+	if !okID || !okNonce || !okAmount {
+		return nil, status.Error(codes.InvalidArgument, "incorrect payment channel headers")
+	}
+	signature := []byte(header(payment.PaymentChannelSignatureHeader))
+
+	ch, err := v.readChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if nonce.Cmp(ch.Nonce) != 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "incorrect nonce %s: channel nonce is %s", nonce, ch.Nonce)
+	}
+	if err := checkSigner(claimMessage(v.group.MPEAddress, id, nonce, amount), signature, ch.Signer); err != nil {
+		return nil, err
+	}
+	if amount.Cmp(ch.Value) > 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "not enough tokens on payment channel %s: signed amount %s exceeds channel value %s", id, amount, ch.Value)
+	}
+	price := v.price(method)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	st, err := v.loadChannel(ctx, id, ch.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	income := new(big.Int).Sub(amount, st.SignedAmount)
+	if income.Cmp(price) != 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "income %s does not equal to price %s", income, price)
+	}
+	st.SignedAmount, st.Signature = amount, signature
+	st.UsedAmount.Add(st.UsedAmount, income)
+	if err := v.saveChannel(ctx, st); err != nil {
+		return nil, err
+	}
+	return &Payment{Method: method, Type: PaymentTypeEscrow, User: ch.Signer, ChannelID: id, Nonce: nonce, Price: price}, nil
+}
+
+func (v *Verifier) verifyFreeCall(ctx context.Context, method string, header func(string) string) (*Payment, error) {
+	user := header(payment.FreeCallUserAddressHeader)
+	block, err := strconv.ParseUint(header(payment.CurrentBlockNumberHeader), 10, 64)
+	if err != nil || !common.IsHexAddress(user) {
+		return nil, status.Error(codes.InvalidArgument, "incorrect free call headers")
+	}
+	addr := common.HexToAddress(user)
+	token := []byte(header(payment.FreeCallAuthTokenHeader))
+	signature := []byte(header(payment.PaymentChannelSignatureHeader))
+
+	current, err := v.checkBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSigner(v.freeCallMessage(user, block, token), signature, addr); err != nil {
+		return nil, err
+	}
+	if err := v.checkFreeCallToken(token, addr, current); err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	used, err := v.store.FreeCallsUsed(ctx, v.group.GroupID, addr)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "load free calls of %s: %v", addr.Hex(), err)
+	}
+	if used >= v.group.FreeCalls {
+		return nil, status.Errorf(codes.Unauthenticated, "free call limit exceeded for %s", addr.Hex())
+	}
+	if err := v.store.SaveFreeCallsUsed(ctx, v.group.GroupID, addr, used+1); err != nil {
+		return nil, status.Errorf(codes.Internal, "save free calls of %s: %v", addr.Hex(), err)
+	}
+	return &Payment{Method: method, Type: PaymentTypeFree, User: addr, Price: new(big.Int)}, nil
+}
+
+func (v *Verifier) verifyPrepaid(ctx context.Context, method string, header func(string) string) (*Payment, error) {
+	id, okID := new(big.Int).SetString(header(payment.PaymentChannelIDHeader), 10)
+	nonce, okNonce := new(big.Int).SetString(header(payment.PaymentChannelNonceHeader), 10)
+	if !okID || !okNonce {
+		return nil, status.Error(codes.InvalidArgument, "incorrect payment channel headers")
+	}
+	claims, err := v.parsePrepaidToken(header(payment.PrePaidAuthTokenHeader))
+	if err != nil {
+		return nil, err
+	}
+	if claims.ChannelID != id.String() || claims.Nonce != nonce.String() {
+		return nil, status.Error(codes.Unauthenticated, "prepaid token is not valid for this payment channel")
+	}
+
+	ch, err := v.readChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if nonce.Cmp(ch.Nonce) != 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "incorrect nonce %s: channel nonce is %s", nonce, ch.Nonce)
+	}
+	price := v.price(method)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	st, err := v.loadChannel(ctx, id, ch.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	used := new(big.Int).Add(st.UsedAmount, price)
+	if used.Cmp(st.PlannedAmount) > 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "usage exceeded: planned amount %s, used amount %s, price %s", st.PlannedAmount, st.UsedAmount, price)
+	}
+	st.UsedAmount = used
+	if err := v.saveChannel(ctx, st); err != nil {
+		return nil, err
+	}
+	return &Payment{Method: method, Type: PaymentTypePrepaid, User: ch.Signer, ChannelID: id, Nonce: nonce, Price: price}, nil
+}
+
+// price returns the price of a call of method, never nil.
+func (v *Verifier) price(method string) *big.Int {
+	if p := v.group.Price(method); p != nil {
+		return new(big.Int).Set(p)
+	}
+	return new(big.Int)
+}
+
+// readChannel reads channel id from the MPE contract and checks that it is
+// opened to the group and does not expire within the expiration threshold.
+func (v *Verifier) readChannel(ctx context.Context, id *big.Int) (blockchain.MultiPartyEscrowChannel, error) {
+	ch, err := v.chain.Channel(ctx, id)
+	if err != nil {
+		return ch, status.Errorf(codes.Internal, "read payment channel %s: %v", id, err)
+	}
+	if ch.Sender == (common.Address{}) {
+		return ch, status.Errorf(codes.Unauthenticated, "channel is not found, channelId: %s", id)
+	}
+	if ch.Recipient != v.group.PaymentAddress || ch.GroupID != v.group.GroupID {
+		return ch, status.Errorf(codes.Unauthenticated, "payment channel %s is not opened to this group", id)
+	}
+	block, err := v.chain.BlockNumber(ctx)
+	if err != nil {
+		return ch, status.Errorf(codes.Internal, "read block number: %v", err)
+	}
+	limit := new(big.Int).SetUint64(block)
+	limit.Add(limit, new(big.Int).SetUint64(v.group.ExpirationThreshold))
+	if ch.Expiration == nil || ch.Expiration.Cmp(limit) <= 0 {
+		return ch, status.Errorf(codes.Unauthenticated, "payment channel %s is expired or near to be expired: expiration block %s, current block %d, threshold %d", id, ch.Expiration, block, v.group.ExpirationThreshold)
+	}
+	return ch, nil
+}
+
+// checkBlock checks that a block number signed by a client is within the
+// block tolerance of the chain, and returns the current block number.
+func (v *Verifier) checkBlock(ctx context.Context, signed uint64) (uint64, error) {
+	current, err := v.chain.BlockNumber(ctx)
+	if err != nil {
+		return 0, status.Errorf(codes.Internal, "read block number: %v", err)
+	}
+	if signed+v.blockTolerance < current || signed > current+v.blockTolerance {
+		return 0, status.Errorf(codes.Unauthenticated, "signature is outdated: signed block %d, current block %d", signed, current)
+	}
+	return current, nil
+}
+
+// loadChannel returns the stored state of channel id at nonce, or a zero
+// state when the store has none or holds the state of an earlier nonce.
+// v.mu must be held.
+func (v *Verifier) loadChannel(ctx context.Context, id, nonce *big.Int) (*ChannelState, error) {
+	st, err := v.store.LoadChannel(ctx, v.group.MPEAddress, id)
+	switch {
+	case errors.Is(err, ErrChannelStateNotFound):
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "load state of payment channel %s: %v", id, err)
+	case st.Nonce != nil && st.Nonce.Cmp(nonce) == 0:
+		for _, amount := range []**big.Int{&st.SignedAmount, &st.PlannedAmount, &st.UsedAmount} {
+			if *amount == nil {
+				*amount = new(big.Int)
+			}
+		}
+		return st, nil
+	}
+	return &ChannelState{
+		MPEAddress:    v.group.MPEAddress,
+		ChannelID:     new(big.Int).Set(id),
+		Nonce:         new(big.Int).Set(nonce),
+		SignedAmount:  new(big.Int),
+		PlannedAmount: new(big.Int),
+		UsedAmount:    new(big.Int),
+	}, nil
+}
+
+// saveChannel stores st. v.mu must be held.
+func (v *Verifier) saveChannel(ctx context.Context, st *ChannelState) error {
+	st.UpdatedAt = v.now()
+	if err := v.store.SaveChannel(ctx, st); err != nil {
+		return status.Errorf(codes.Internal, "save state of payment channel %s: %v", st.ChannelID, err)
+	}
+	return nil
+}
+
+// freeCallMessage is the message signed for free-call tokens and free calls;
+// token is nil when requesting a token.
+func (v *Verifier) freeCallMessage(user string, block uint64, token []byte) []byte {
+	return bytes.Join([][]byte{
+		[]byte(payment.FreeCallPrefixSignature),
+		[]byte(user),
+		[]byte(v.group.OrgID),
+		[]byte(v.group.ServiceID),
+		[]byte(base64.StdEncoding.EncodeToString(v.group.GroupID[:])),
+		common.BigToHash(new(big.Int).SetUint64(block)).Bytes(),
+		token,
+	}, nil)
+}
+
+// claimMessage is the MPE claim message signed by escrow and prepaid clients.
+func claimMessage(mpe common.Address, id, nonce, amount *big.Int) []byte {
+	return bytes.Join([][]byte{
+		[]byte(payment.PrefixInSignature),
+		mpe.Bytes(),
+		common.BigToHash(id).Bytes(),
+		common.BigToHash(nonce).Bytes(),
+		common.BigToHash(amount).Bytes(),
+	}, nil)
+}
+
+// checkSigner returns an Unauthenticated error unless signature is a
+// signature of message by want.
+func checkSigner(message, signature []byte, want common.Address) error {
+	got, err := RecoverSigner(message, signature)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "incorrect signature: %v", err)
+	}
+	if got != want {
+		return status.Errorf(codes.Unauthenticated, "signature of %s does not match %s", got.Hex(), want.Hex())
+	}
+	return nil
+}
+
+// RecoverSigner returns the address that signed message with
+// blockchain.SignMessage, the way SDK clients sign payments and requests.
+func RecoverSigner(message, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature has %d bytes, want %d", len(signature), crypto.SignatureLength)
+	}
+	sig := bytes.Clone(signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(crypto.Keccak256(message)), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// mac authenticates the parts with the token key of the Verifier.
+func (v *Verifier) mac(parts ...[]byte) []byte {
+	h := hmac.New(sha256.New, v.tokenKey)
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+// freeCallToken returns the free-call token of user valid until block
+// expiration: the expiration block followed by its MAC.
+func (v *Verifier) freeCallToken(user common.Address, expiration uint64) []byte {
+	exp := binary.BigEndian.AppendUint64(nil, expiration)
+	return append(exp, v.mac([]byte(PaymentTypeFree), v.group.GroupID[:], user.Bytes(), exp)...)
+}
+
+// checkFreeCallToken checks that token was issued to user by the Verifier
+// and is still valid at block current.
+func (v *Verifier) checkFreeCallToken(token []byte, user common.Address, current uint64) error {
+	if len(token) != 8+sha256.Size {
+		return status.Errorf(codes.Unauthenticated, "free call token is not valid for %s", user.Hex())
+	}
+	expiration := binary.BigEndian.Uint64(token[:8])
+	if !hmac.Equal(token, v.freeCallToken(user, expiration)) {
+		return status.Errorf(codes.Unauthenticated, "free call token is not valid for %s", user.Hex())
+	}
+	if current > expiration {
+		return status.Errorf(codes.Unauthenticated, "free call token of %s ended at block %d, current block %d", user.Hex(), expiration, current)
+	}
+	return nil
+}
+
+// prepaidClaims are the claims of a prepaid token. Tokens are HS256 JSON web
+// tokens, so clients can read when they end.
+type prepaidClaims struct {
+	MPE       string `json:"mpe"`
+	ChannelID string `json:"channel_id"`
+	Nonce     string `json:"nonce"`
+	Expires   int64  `json:"exp"`
+}
+
+// jwtHeader is the encoded header of prepaid tokens.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// prepaidToken returns a prepaid token for channel id at nonce.
+func (v *Verifier) prepaidToken(id, nonce *big.Int) (string, error) {
+	payload, err := json.Marshal(prepaidClaims{
+		MPE:       v.group.MPEAddress.Hex(),
+		ChannelID: id.String(),
+		Nonce:     nonce.String(),
+		Expires:   v.now().Add(v.prepaidTokenLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(v.mac([]byte(signed))), nil
+}
+
+// parsePrepaidToken checks that token was issued by the Verifier for its MPE
+// contract and has not expired, and returns its claims.
+func (v *Verifier) parsePrepaidToken(token string) (*prepaidClaims, error) {
+	invalid := status.Error(codes.Unauthenticated, "prepaid token is not valid")
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return nil, invalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(mac, v.mac([]byte(token[:i]))) {
+		return nil, invalid
+	}
+	header, payload, ok := strings.Cut(token[:i], ".")
+	if !ok || header != jwtHeader {
+		return nil, invalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, invalid
+	}
+	var claims prepaidClaims
+	if err := json.Unmarshal(raw, &claims); err != nil || claims.MPE != v.group.MPEAddress.Hex() {
+		return nil, invalid
+	}
+	if v.now().Unix() >= claims.Expires {
+		return nil, status.Errorf(codes.Unauthenticated, "prepaid token expired at %s", time.Unix(claims.Expires, 0).UTC().Format(time.RFC3339))
+	}
+	return &claims, nil
+}
@@ -0,0 +1,710 @@
+package sdk
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/grpc"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"go.uber.org/zap"
+)
+
+// defaultCrawlWorkers is the number of organizations or services a crawl
+// reads at the same time.
+const defaultCrawlWorkers = 8
+
+// CatalogOption configures a Catalog.
+type CatalogOption func(*catalogOptions)
+
+type catalogOptions struct {
+	workers  int
+	orgIDs   []string
+	noProtos bool
+}
+
+// WithCrawlWorkers sets how many organizations or services are read at the
+// same time (8 by default).
+func WithCrawlWorkers(n int) CatalogOption {
+	return func(o *catalogOptions) { o.workers = n }
+}
+
+// CrawlOrganizations limits the catalog to the given organizations.
+func CrawlOrganizations(orgIDs ...string) CatalogOption {
+	return func(o *catalogOptions) { o.orgIDs = append(o.orgIDs, orgIDs...) }
+}
+
+// WithoutProtos skips reading the proto archives of the services. The
+// catalog is built faster but knows no methods, so method queries match
+// nothing.
+func WithoutProtos() CatalogOption {
+	return func(o *catalogOptions) { o.noProtos = true }
+}
+
+// CatalogService is the catalog entry of a service. Entries are replaced,
+// not modified, when the catalog is refreshed, so they can be kept and read
+// without locking; they must not be modified.
+type CatalogService struct {
+	OrgID       string
+	ServiceID   string
+	OrgName     string
+	MetadataURI string
+	// Metadata is the service metadata, with ProtoFiles set unless the
+	// catalog was built WithoutProtos. It is nil when it could not be read.
+	Metadata *model.ServiceMetadata
+	// Methods are the full paths ("/package.Service/Method") of the methods
+	// declared in the protos, sorted.
+	Methods []string
+	// Err tells why Metadata or Methods are missing. Entries with an error
+	// are not returned by Search.
+	Err error
+}
+
+// DisplayName returns the display name from the metadata.
+func (s *CatalogService) DisplayName() string {
+	if s.Metadata == nil {
+		return ""
+	}
+	return s.Metadata.DisplayName
+}
+
+// Tags returns the tags from the metadata.
+func (s *CatalogService) Tags() []string {
+	if s.Metadata == nil {
+		return nil
+	}
+	return s.Metadata.Tags
+}
+
+// HasMethod reports whether the service declares method, given as a full
+// path ("/package.Service/Method") or a bare method name.
+func (s *CatalogService) HasMethod(method string) bool {
+	for _, key := range methodKeys(method) {
+		for _, m := range s.Methods {
+			if m == key || methodName(m) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasFreeCalls reports whether any group of the service offers free calls.
+func (s *CatalogService) HasFreeCalls() bool {
+	if s.Metadata == nil {
+		return false
+	}
+	for _, g := range s.Metadata.Groups {
+		if g.FreeCalls > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CatalogQuery selects services in Catalog.Search. Zero fields match
+// everything.
+type CatalogQuery struct {
+	// Text matches the organization and service IDs, the organization name
+	// and the display name, case-insensitively, as a substring.
+	Text string
+	// OrgID selects the services of one organization.
+	OrgID string
+	// Tags selects the services carrying all of the tags, case-insensitively.
+	Tags []string
+	// Method selects the services declaring the method, given as a full
+	// path ("/package.Service/Method") or a bare method name. Prices are
+	// then those of the method.
+	Method string
+	// FreeCalls selects the groups offering free calls.
+	FreeCalls bool
+	// MaxPrice selects the groups whose price, in cogs, is at most MaxPrice.
+	MaxPrice *big.Int
+}
+
+// CatalogMatch is a service matching a CatalogQuery, with its cheapest
+// matching group.
+type CatalogMatch struct {
+	Service *CatalogService
+	Group   *model.ServiceGroup
+	// Price is the price in cogs of a call to the queried method in Group,
+	// or the default price of Group when no method was queried.
+	Price *big.Int
+}
+
+// catalogKey identifies a catalog entry.
+type catalogKey struct {
+	orgID, serviceID string
+}
+
+// Catalog is a searchable index of the services in the Registry: their
+// display names, tags, groups with pricing, free calls and endpoints, and
+// the methods declared in their protos. Build it with Core.BuildCatalog and
+// keep it current with Follow, Apply or Refresh. A Catalog is safe for
+// concurrent use.
+type Catalog struct {
+	workers  int
+	orgIDs   map[string]bool // nil for every organization
+	noProtos bool
+	log      *zap.Logger
+
+	listOrgs     func(ctx context.Context) ([]string, error)
+	readOrg      func(ctx context.Context, orgID string) (*model.OrganizationMetaData, error)
+	listServices func(ctx context.Context, orgID string) ([]string, error)
+	serviceURI   func(ctx context.Context, orgID, serviceID string) (string, error)
+	readService  func(ctx context.Context, uri string) (*model.ServiceMetadata, error)
+	readProtos   func(ctx context.Context, metadata *model.ServiceMetadata) (map[string]string, error)
+
+	mu       sync.RWMutex
+	services map[catalogKey]*CatalogService
+	orgErrs  map[string]error
+	byMethod map[string]map[catalogKey]bool // Full paths and bare names
+	byTag    map[string]map[catalogKey]bool // Lower-case tags
+}
+
+// BuildCatalog is like BuildCatalogContext with context.Background().
+func (c *Core) BuildCatalog(opts ...CatalogOption) (*Catalog, error) {
+	return c.BuildCatalogContext(context.Background(), opts...)
+}
+
+// BuildCatalogContext crawls the Registry under ctx and returns the catalog
+// of its services. It fails only when the organizations cannot be listed or
+// ctx is done; organizations and services that cannot be read are reported
+// by Catalog.Err.
+func (c *Core) BuildCatalogContext(ctx context.Context, opts ...CatalogOption) (*Catalog, error) {
+	o := catalogOptions{workers: defaultCrawlWorkers}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cat := newCatalog(o, c.Config.GetLogger())
+	cat.listOrgs = c.evm.GetOrganizationsCtx
+	cat.readOrg = c.evm.GetOrgMetadataCtx
+	cat.listServices = c.evm.ListServicesCtx
+	cat.serviceURI = c.evm.GetServiceMetadataURICtx
+	cat.readService = c.evm.ReadServiceMetadataCtx
+	cat.readProtos = c.evm.ReadServiceProtosCtx
+	if err := cat.RefreshContext(ctx); err != nil {
+		return nil, err
+	}
+	return cat, nil
+}
+
+func newCatalog(o catalogOptions, log *zap.Logger) *Catalog {
+	c := &Catalog{
+		workers:  max(o.workers, 1),
+		noProtos: o.noProtos,
+		log:      log,
+	}
+	if len(o.orgIDs) > 0 {
+		c.orgIDs = make(map[string]bool)
+		for _, id := range o.orgIDs {
+			c.orgIDs[id] = true
+		}
+	}
+	c.reset(nil, nil)
+	return c
+}
+
+// Refresh is like RefreshContext with context.Background().
+func (c *Catalog) Refresh() error {
+	return c.RefreshContext(context.Background())
+}
+
+// RefreshContext crawls the Registry again and replaces the whole catalog.
+// The catalog is left unchanged when the organizations cannot be listed or
+// ctx is done.
+func (c *Catalog) RefreshContext(ctx context.Context) error {
+	var orgIDs []string
+	if c.orgIDs != nil {
+		orgIDs = slices.Sorted(maps.Keys(c.orgIDs))
+	} else {
+		listCtx, cancel := withTimeout(ctx, metadataTimeout)
+		ids, err := c.listOrgs(listCtx)
+		cancel()
+		if err != nil {
+			return err
+		}
+		orgIDs = ids
+	}
+
+	entries, orgErrs := c.crawl(ctx, orgIDs, nil)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.reset(entries, orgErrs)
+	c.log.Debug("catalog refreshed", zap.Int("organizations", len(orgIDs)), zap.Int("services", len(entries)))
+	return nil
+}
+
+// Apply is like ApplyContext with context.Background().
+func (c *Catalog) Apply(ev RegistryEvent) error {
+	return c.ApplyContext(context.Background(), ev)
+}
+
+// ApplyContext updates the catalog for a Registry event, reading only what
+// the event changed: the service of a service event, or the organization
+// and all its services for organization events other than deletion. The
+// metadata carried by ev is used instead of reading it again. The returned
+// error is that of the updated entries, which are stored anyway.
+func (c *Catalog) ApplyContext(ctx context.Context, ev RegistryEvent) error {
+	if c.orgIDs != nil && !c.orgIDs[ev.OrgID] {
+		return nil
+	}
+
+	switch ev.Type {
+	case blockchain.OrganizationDeleted:
+		c.replaceOrg(ev.OrgID, nil, nil)
+		return nil
+	case blockchain.ServiceDeleted:
+		c.remove(catalogKey{ev.OrgID, ev.ServiceID})
+		return nil
+	case blockchain.OrganizationCreated, blockchain.OrganizationModified:
+		entries, orgErrs := c.crawl(ctx, []string{ev.OrgID}, ev.OrgMetadata)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.replaceOrg(ev.OrgID, entries, orgErrs[ev.OrgID])
+		return errors.Join(orgErrs[ev.OrgID], entriesErr(entries))
+	}
+
+	c.mu.RLock()
+	orgName := ""
+	for key, entry := range c.services {
+		if key.orgID == ev.OrgID {
+			orgName = entry.OrgName
+			break
+		}
+	}
+	c.mu.RUnlock()
+	if orgName == "" {
+		readCtx, cancel := withTimeout(ctx, metadataTimeout)
+		if org, err := c.readOrg(readCtx, ev.OrgID); err == nil {
+			orgName = org.OrgName
+		}
+		cancel()
+	}
+
+	metadata := ev.ServiceMetadata
+	if ev.Type == blockchain.ServiceTagsModified {
+		metadata = nil
+	}
+	entry := c.crawlService(ctx, catalogKey{ev.OrgID, ev.ServiceID}, orgName, ev.MetadataURI, metadata)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.put(entry)
+	return entry.Err
+}
+
+// Follow applies the events of w until it stops and returns w.Err. Events
+// that fail to apply are logged. The events are consumed, so w.Events must
+// not be read elsewhere.
+func (c *Catalog) Follow(w *RegistryWatcher) error {
+	for ev := range w.Events() {
+		if err := c.Apply(ev); err != nil {
+			c.log.Warn("failed to update catalog",
+				zap.String("event", string(ev.Type)),
+				zap.String("org_id", ev.OrgID),
+				zap.String("service_id", ev.ServiceID),
+				zap.Error(err))
+		}
+	}
+	return w.Err()
+}
+
+// crawl reads the given organizations and all their services, workers at a
+// time. orgMetadata, when not nil, is used for the single organization
+// instead of reading it.
+func (c *Catalog) crawl(ctx context.Context, orgIDs []string, orgMetadata *model.OrganizationMetaData) ([]*CatalogService, map[string]error) {
+	type org struct {
+		name     string
+		services []string
+		err      error
+	}
+	orgs := make([]org, len(orgIDs))
+	forEachLimit(ctx, c.workers, len(orgIDs), func(i int) {
+		ctx, cancel := withTimeout(ctx, metadataTimeout)
+		defer cancel()
+		metadata := orgMetadata
+		if metadata == nil {
+			var err error
+			if metadata, err = c.readOrg(ctx, orgIDs[i]); err != nil {
+				// The services can be listed without the organization name.
+				orgs[i].err = err
+			}
+		}
+		if metadata != nil {
+			orgs[i].name = metadata.OrgName
+		}
+		services, err := c.listServices(ctx, orgIDs[i])
+		orgs[i].services = services
+		orgs[i].err = errors.Join(orgs[i].err, err)
+	})
+
+	var (
+		keys    []catalogKey
+		names   []string
+		orgErrs = make(map[string]error)
+	)
+	for i, o := range orgs {
+		if o.err != nil {
+			orgErrs[orgIDs[i]] = fmt.Errorf("organization %q: %w", orgIDs[i], o.err)
+		}
+		for _, id := range o.services {
+			keys = append(keys, catalogKey{orgIDs[i], id})
+			names = append(names, o.name)
+		}
+	}
+
+	entries := make([]*CatalogService, len(keys))
+	forEachLimit(ctx, c.workers, len(keys), func(i int) {
+		entries[i] = c.crawlService(ctx, keys[i], names[i], "", nil)
+	})
+	// Entries not crawled because ctx is done are nil.
+	return slices.DeleteFunc(entries, func(e *CatalogService) bool { return e == nil }), orgErrs
+}
+
+// crawlService reads the catalog entry of a service. uri and metadata are
+// read when empty.
+func (c *Catalog) crawlService(ctx context.Context, key catalogKey, orgName, uri string, metadata *model.ServiceMetadata) *CatalogService {
+	ctx, cancel := withTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	entry := &CatalogService{OrgID: key.orgID, ServiceID: key.serviceID, OrgName: orgName, MetadataURI: uri}
+	fail := func(err error) *CatalogService {
+		entry.Err = fmt.Errorf("service %q of organization %q: %w", key.serviceID, key.orgID, err)
+		return entry
+	}
+
+	if entry.MetadataURI == "" {
+		var err error
+		if entry.MetadataURI, err = c.serviceURI(ctx, key.orgID, key.serviceID); err != nil {
+			return fail(err)
+		}
+	}
+	if metadata == nil {
+		var err error
+		if metadata, err = c.readService(ctx, entry.MetadataURI); err != nil {
+			return fail(err)
+		}
+	} else {
+		// The metadata of an event is shared with its other readers.
+		copied := *metadata
+		metadata = &copied
+	}
+	entry.Metadata = metadata
+	if c.noProtos {
+		return entry
+	}
+
+	protoFiles, err := c.readProtos(ctx, metadata)
+	if err != nil {
+		return fail(err)
+	}
+	metadata.ProtoFiles = protoFiles
+	if entry.Methods, err = grpc.ListMethods(protoFiles); err != nil {
+		return fail(err)
+	}
+	return entry
+}
+
+// forEachLimit calls fn for 0 to n-1, at most workers calls at a time, and
+// waits for them. No new call is started once ctx is done.
+func forEachLimit(ctx context.Context, workers, n int, fn func(i int)) {
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+}
+
+// reset replaces the catalog with entries.
+func (c *Catalog) reset(entries []*CatalogService, orgErrs map[string]error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.services = make(map[catalogKey]*CatalogService)
+	c.byMethod = make(map[string]map[catalogKey]bool)
+	c.byTag = make(map[string]map[catalogKey]bool)
+	c.orgErrs = orgErrs
+	if c.orgErrs == nil {
+		c.orgErrs = make(map[string]error)
+	}
+	for _, entry := range entries {
+		c.index(entry)
+	}
+}
+
+// replaceOrg replaces the entries of an organization with entries.
+func (c *Catalog) replaceOrg(orgID string, entries []*CatalogService, orgErr error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.services {
+		if key.orgID == orgID {
+			c.unindex(key)
+		}
+	}
+	delete(c.orgErrs, orgID)
+	if orgErr != nil {
+		c.orgErrs[orgID] = orgErr
+	}
+	for _, entry := range entries {
+		c.index(entry)
+	}
+}
+
+// put adds entry, replacing the previous entry of the service.
+func (c *Catalog) put(entry *CatalogService) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unindex(catalogKey{entry.OrgID, entry.ServiceID})
+	c.index(entry)
+}
+
+// remove drops the entry of a service.
+func (c *Catalog) remove(key catalogKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unindex(key)
+}
+
+// index adds entry to the catalog and its indexes. c.mu must be held.
+func (c *Catalog) index(entry *CatalogService) {
+	key := catalogKey{entry.OrgID, entry.ServiceID}
+	c.services[key] = entry
+	for _, m := range entry.Methods {
+		addKey(c.byMethod, m, key)
+		addKey(c.byMethod, methodName(m), key)
+	}
+	for _, tag := range entry.Tags() {
+		addKey(c.byTag, strings.ToLower(tag), key)
+	}
+}
+
+// unindex removes the entry of key from the catalog and its indexes. c.mu
+// must be held.
+func (c *Catalog) unindex(key catalogKey) {
+	entry, ok := c.services[key]
+	if !ok {
+		return
+	}
+	delete(c.services, key)
+	for _, m := range entry.Methods {
+		removeKey(c.byMethod, m, key)
+		removeKey(c.byMethod, methodName(m), key)
+	}
+	for _, tag := range entry.Tags() {
+		removeKey(c.byTag, strings.ToLower(tag), key)
+	}
+}
+
+func addKey(index map[string]map[catalogKey]bool, term string, key catalogKey) {
+	if index[term] == nil {
+		index[term] = make(map[catalogKey]bool)
+	}
+	index[term][key] = true
+}
+
+func removeKey(index map[string]map[catalogKey]bool, term string, key catalogKey) {
+	delete(index[term], key)
+	if len(index[term]) == 0 {
+		delete(index, term)
+	}
+}
+
+// Services returns every entry of the catalog, sorted by organization and
+// service ID.
+func (c *Catalog) Services() []*CatalogService {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entries := make([]*CatalogService, 0, len(c.services))
+	for _, entry := range c.services {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, compareEntries)
+	return entries
+}
+
+// Service returns the entry of a service.
+func (c *Catalog) Service(orgID, serviceID string) (*CatalogService, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.services[catalogKey{orgID, serviceID}]
+	return entry, ok
+}
+
+// Err returns the errors of the organizations and services that could not
+// be read, joined, or nil if the whole catalog was read.
+func (c *Catalog) Err() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var errs []error
+	for _, orgID := range slices.Sorted(maps.Keys(c.orgErrs)) {
+		errs = append(errs, c.orgErrs[orgID])
+	}
+	entries := make([]*CatalogService, 0, len(c.services))
+	for _, entry := range c.services {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, compareEntries)
+	return errors.Join(append(errs, entriesErr(entries))...)
+}
+
+// Search returns the services matching q with their cheapest matching
+// group, cheapest first.
+func (c *Catalog) Search(q CatalogQuery) []CatalogMatch {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var matches []CatalogMatch
+	for _, entry := range c.candidates(q) {
+		if entry.Err != nil || !entry.matches(q) {
+			continue
+		}
+		if group, price := entry.cheapestGroup(q); group != nil {
+			matches = append(matches, CatalogMatch{Service: entry, Group: group, Price: price})
+		}
+	}
+	slices.SortFunc(matches, func(a, b CatalogMatch) int {
+		return cmp.Or(a.Price.Cmp(b.Price), compareEntries(a.Service, b.Service))
+	})
+	return matches
+}
+
+// Cheapest returns the cheapest match of q, for example the cheapest
+// service with free calls that declares a method.
+func (c *Catalog) Cheapest(q CatalogQuery) (CatalogMatch, bool) {
+	matches := c.Search(q)
+	if len(matches) == 0 {
+		return CatalogMatch{}, false
+	}
+	return matches[0], true
+}
+
+// candidates returns the entries the indexes select for q. c.mu must be
+// held.
+func (c *Catalog) candidates(q CatalogQuery) []*CatalogService {
+	var sets []map[catalogKey]bool
+	if q.Method != "" {
+		set := make(map[catalogKey]bool)
+		for _, term := range methodKeys(q.Method) {
+			for key := range c.byMethod[term] {
+				set[key] = true
+			}
+		}
+		sets = append(sets, set)
+	}
+	for _, tag := range q.Tags {
+		sets = append(sets, c.byTag[strings.ToLower(tag)])
+	}
+
+	var entries []*CatalogService
+	if len(sets) == 0 {
+		for _, entry := range c.services {
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+	slices.SortFunc(sets, func(a, b map[catalogKey]bool) int { return cmp.Compare(len(a), len(b)) })
+outer:
+	for key := range sets[0] {
+		for _, set := range sets[1:] {
+			if !set[key] {
+				continue outer
+			}
+		}
+		entries = append(entries, c.services[key])
+	}
+	return entries
+}
+
+// matches reports whether the entry satisfies the service-level conditions
+// of q that the indexes do not cover.
+func (s *CatalogService) matches(q CatalogQuery) bool {
+	if q.OrgID != "" && s.OrgID != q.OrgID {
+		return false
+	}
+	if q.Text == "" {
+		return true
+	}
+	text := strings.ToLower(q.Text)
+	for _, field := range []string{s.OrgID, s.ServiceID, s.OrgName, s.DisplayName()} {
+		if strings.Contains(strings.ToLower(field), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// cheapestGroup returns the cheapest group of the entry satisfying the
+// group-level conditions of q, and its price.
+func (s *CatalogService) cheapestGroup(q CatalogQuery) (*model.ServiceGroup, *big.Int) {
+	var (
+		best  *model.ServiceGroup
+		price *big.Int
+	)
+	for _, g := range s.Metadata.Groups {
+		if q.FreeCalls && g.FreeCalls <= 0 {
+			continue
+		}
+		p := g.DefaultPrice()
+		if q.Method != "" {
+			p = g.MethodPrice(q.Method)
+		}
+		if q.MaxPrice != nil && p.Cmp(q.MaxPrice) > 0 {
+			continue
+		}
+		if best == nil || p.Cmp(price) < 0 {
+			best, price = g, p
+		}
+	}
+	return best, price
+}
+
+// methodKeys returns the index terms of a queried method: a full path with
+// its leading slash, or a bare name.
+func methodKeys(method string) []string {
+	if !strings.Contains(method, "/") {
+		return []string{method}
+	}
+	return []string{"/" + strings.TrimPrefix(method, "/")}
+}
+
+// methodName returns the bare name of a full method path.
+func methodName(path string) string {
+	_, _, name := model.SplitMethodPath(path)
+	return name
+}
+
+func compareEntries(a, b *CatalogService) int {
+	return cmp.Or(cmp.Compare(a.OrgID, b.OrgID), cmp.Compare(a.ServiceID, b.ServiceID))
+}
+
+// entriesErr joins the errors of entries.
+func entriesErr(entries []*CatalogService) error {
+	var errs []error
+	for _, entry := range entries {
+		if entry.Err != nil {
+			errs = append(errs, entry.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
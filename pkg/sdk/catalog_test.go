@@ -0,0 +1,252 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"go.uber.org/zap"
+)
+
+// fakeRegistry is an in-memory Registry and storage for catalog tests.
+// Services are registered under "org/service" with the metadata URI and the
+// service_api_source equal to that key.
+type fakeRegistry struct {
+	mu       sync.Mutex
+	orgs     map[string][]string
+	metadata map[string]*model.ServiceMetadata
+	protos   map[string]string // Proto source by metadata URI
+
+	active, peak atomic.Int32
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		orgs:     make(map[string][]string),
+		metadata: make(map[string]*model.ServiceMetadata),
+		protos:   make(map[string]string),
+	}
+}
+
+// register adds a service whose default group costs price and offers
+// freeCalls, declaring the given methods of service pkg.Svc.
+func (r *fakeRegistry) register(orgID, serviceID string, price int64, freeCalls int, tags []string, methods ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uri := orgID + "/" + serviceID
+	r.orgs[orgID] = append(r.orgs[orgID], serviceID)
+	r.metadata[uri] = &model.ServiceMetadata{
+		DisplayName:      strings.ToUpper(serviceID),
+		ServiceApiSource: uri,
+		Tags:             tags,
+		Groups: []*model.ServiceGroup{{
+			GroupName: "default_group",
+			Endpoints: []string{"https://" + serviceID + ".example"},
+			FreeCalls: freeCalls,
+			Pricing:   []model.Pricing{{PriceModel: model.PriceModelFixed, PriceInCogs: big.NewInt(price)}},
+		}},
+	}
+	var src strings.Builder
+	src.WriteString("syntax = \"proto3\";\npackage pkg;\nmessage M {}\nservice Svc {\n")
+	for _, m := range methods {
+		src.WriteString("rpc " + m + "(M) returns (M);\n")
+	}
+	src.WriteString("}\n")
+	r.protos[uri] = src.String()
+}
+
+func (r *fakeRegistry) catalog(t *testing.T, opts ...CatalogOption) *Catalog {
+	t.Helper()
+	o := catalogOptions{workers: defaultCrawlWorkers}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c := newCatalog(o, zap.NewNop())
+	c.listOrgs = func(context.Context) ([]string, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		var ids []string
+		for id := range r.orgs {
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+	c.readOrg = func(_ context.Context, orgID string) (*model.OrganizationMetaData, error) {
+		return &model.OrganizationMetaData{OrgID: orgID, OrgName: strings.ToUpper(orgID) + " Inc"}, nil
+	}
+	c.listServices = func(_ context.Context, orgID string) ([]string, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		services, ok := r.orgs[orgID]
+		if !ok {
+			return nil, blockchain.ErrOrganizationNotFound
+		}
+		return services, nil
+	}
+	c.serviceURI = func(_ context.Context, orgID, serviceID string) (string, error) {
+		return orgID + "/" + serviceID, nil
+	}
+	c.readService = func(_ context.Context, uri string) (*model.ServiceMetadata, error) {
+		n := r.active.Add(1)
+		for {
+			peak := r.peak.Load()
+			if n <= peak || r.peak.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		defer r.active.Add(-1)
+		time.Sleep(time.Millisecond)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		metadata, ok := r.metadata[uri]
+		if !ok {
+			return nil, errors.New("file not found")
+		}
+		copied := *metadata
+		return &copied, nil
+	}
+	c.readProtos = func(_ context.Context, metadata *model.ServiceMetadata) (map[string]string, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		src, ok := r.protos[metadata.ServiceApiSource]
+		if !ok {
+			return nil, errors.New("no protos")
+		}
+		return map[string]string{"service.proto": src}, nil
+	}
+	if err := c.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	return c
+}
+
+func matchIDs(matches []CatalogMatch) string {
+	var ids []string
+	for _, m := range matches {
+		ids = append(ids, m.Service.OrgID+"/"+m.Service.ServiceID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestCatalogSearch(t *testing.T) {
+	r := newFakeRegistry()
+	r.register("snet", "calc", 10, 0, []string{"Math"}, "Add", "Mul")
+	r.register("snet", "translate", 5, 3, []string{"NLP"}, "Translate")
+	r.register("acme", "calc", 2, 1, []string{"math", "cheap"}, "Add")
+	r.register("acme", "broken", 1, 1, nil, "Add")
+	r.mu.Lock()
+	delete(r.metadata, "acme/broken")
+	r.mu.Unlock()
+
+	c := r.catalog(t, WithCrawlWorkers(2))
+	if peak := r.peak.Load(); peak > 2 {
+		t.Fatalf("%d concurrent reads; want at most 2", peak)
+	}
+	if n := len(c.Services()); n != 4 {
+		t.Fatalf("%d entries; want 4", n)
+	}
+	if err := c.Err(); err == nil || !strings.Contains(err.Error(), `"broken"`) {
+		t.Fatalf("Err = %v; want the unreadable service", err)
+	}
+	calc, ok := c.Service("snet", "calc")
+	if !ok || calc.OrgName != "SNET Inc" || calc.DisplayName() != "CALC" || !calc.HasMethod("Mul") || !calc.HasMethod("/pkg.Svc/Add") {
+		t.Fatalf("snet/calc entry: %+v", calc)
+	}
+
+	tests := []struct {
+		name  string
+		query CatalogQuery
+		want  string
+	}{
+		{"all", CatalogQuery{}, "acme/calc,snet/translate,snet/calc"},
+		{"bare method", CatalogQuery{Method: "Add"}, "acme/calc,snet/calc"},
+		{"method path", CatalogQuery{Method: "pkg.Svc/Mul"}, "snet/calc"},
+		{"unknown method", CatalogQuery{Method: "Sub"}, ""},
+		{"tags", CatalogQuery{Tags: []string{"MATH", "cheap"}}, "acme/calc"},
+		{"text", CatalogQuery{Text: "transl"}, "snet/translate"},
+		{"org name", CatalogQuery{Text: "snet inc"}, "snet/translate,snet/calc"},
+		{"org", CatalogQuery{OrgID: "snet", Method: "Add"}, "snet/calc"},
+		{"free calls", CatalogQuery{FreeCalls: true}, "acme/calc,snet/translate"},
+		{"max price", CatalogQuery{MaxPrice: big.NewInt(5)}, "acme/calc,snet/translate"},
+	}
+	for _, tt := range tests {
+		if got := matchIDs(c.Search(tt.query)); got != tt.want {
+			t.Errorf("%s: Search = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+
+	best, ok := c.Cheapest(CatalogQuery{FreeCalls: true, Method: "Translate"})
+	if !ok || best.Service.ServiceID != "translate" || best.Price.Int64() != 5 || best.Group.GroupName != "default_group" {
+		t.Fatalf("Cheapest = %+v, %v", best, ok)
+	}
+	if _, ok := c.Cheapest(CatalogQuery{FreeCalls: true, MaxPrice: big.NewInt(1)}); ok {
+		t.Fatal("Cheapest matched a service above MaxPrice")
+	}
+}
+
+func TestCatalogApply(t *testing.T) {
+	r := newFakeRegistry()
+	r.register("snet", "calc", 10, 0, nil, "Add")
+	r.register("snet", "translate", 5, 0, nil, "Translate")
+	c := r.catalog(t, CrawlOrganizations("snet", "acme"))
+	if err := c.Err(); err == nil {
+		t.Fatal("Err = nil; want the missing organization acme")
+	}
+
+	// Metadata carried by the event is used as is.
+	updated := &model.ServiceMetadata{DisplayName: "CALC", ServiceApiSource: "snet/calc", Groups: []*model.ServiceGroup{{GroupName: "default_group", FreeCalls: 5}}}
+	r.mu.Lock()
+	r.protos["snet/calc"] = "syntax = \"proto3\";\nservice Svc { rpc Div(M) returns (M); }\nmessage M {}\n"
+	r.mu.Unlock()
+	ev := RegistryEvent{
+		RegistryEvent:   blockchain.RegistryEvent{Type: blockchain.ServiceMetadataModified, OrgID: "snet", ServiceID: "calc", MetadataURI: "snet/calc"},
+		ServiceMetadata: updated,
+	}
+	if err := c.Apply(ev); err != nil {
+		t.Fatal(err)
+	}
+	if got := matchIDs(c.Search(CatalogQuery{Method: "Div", FreeCalls: true})); got != "snet/calc" {
+		t.Fatalf("after metadata change: Search = %q", got)
+	}
+	if got := matchIDs(c.Search(CatalogQuery{Method: "Add"})); got != "" {
+		t.Fatalf("removed method still indexed: %q", got)
+	}
+	if updated.ProtoFiles != nil {
+		t.Fatal("event metadata modified")
+	}
+
+	c.Apply(RegistryEvent{RegistryEvent: blockchain.RegistryEvent{Type: blockchain.ServiceDeleted, OrgID: "snet", ServiceID: "translate"}})
+	if _, ok := c.Service("snet", "translate"); ok {
+		t.Fatal("deleted service still in catalog")
+	}
+
+	r.register("acme", "echo", 1, 0, nil, "Echo")
+	if err := c.Apply(RegistryEvent{RegistryEvent: blockchain.RegistryEvent{Type: blockchain.OrganizationCreated, OrgID: "acme"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := matchIDs(c.Search(CatalogQuery{Method: "Echo"})); got != "acme/echo" {
+		t.Fatalf("after organization created: Search = %q", got)
+	}
+	if err := c.Err(); err != nil {
+		t.Fatalf("Err = %v after the organization was created", err)
+	}
+
+	r.register("other", "svc", 1, 0, nil, "Echo")
+	c.Apply(RegistryEvent{RegistryEvent: blockchain.RegistryEvent{Type: blockchain.OrganizationCreated, OrgID: "other"}})
+	if _, ok := c.Service("other", "svc"); ok {
+		t.Fatal("event of an organization outside the catalog applied")
+	}
+
+	c.Apply(RegistryEvent{RegistryEvent: blockchain.RegistryEvent{Type: blockchain.OrganizationDeleted, OrgID: "snet"}})
+	if got := matchIDs(c.Search(CatalogQuery{})); got != "acme/echo" {
+		t.Fatalf("after organization deleted: Search = %q", got)
+	}
+}
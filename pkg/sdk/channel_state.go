@@ -0,0 +1,30 @@
+package sdk
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/shamank/snet-sdk-go/pkg/config"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+)
+
+var (
+	// memoryChannelStates is the store used when no ChannelStateFile is
+	// configured. It keeps channel state across strategy switches in-process.
+	memoryChannelStates = payment.NewMemoryChannelStateStore()
+
+	// fileChannelStates shares one store per file, so every client of the
+	// process serializes its writes to that file through the same lock.
+	fileChannelStates sync.Map // cleaned path -> *payment.FileChannelStateStore
+)
+
+// channelStateStore returns the channel state store selected by
+// cfg.ChannelStateFile.
+func channelStateStore(cfg *config.Config) payment.ChannelStateStore {
+	if cfg == nil || cfg.ChannelStateFile == "" {
+		return memoryChannelStates
+	}
+	path := filepath.Clean(cfg.ChannelStateFile)
+	store, _ := fileChannelStates.LoadOrStore(path, payment.NewFileChannelStateStore(path))
+	return store.(*payment.FileChannelStateStore)
+}
@@ -0,0 +1,65 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/grpc"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+)
+
+// ChannelManager returns a manager for the MPE payment channels of the
+// configured signer. Channels of the current service group (same recipient
+// and payment group) report the signed amount recorded by the group's daemon;
+// other channels report only their on-chain state.
+func (s *ServiceClient) ChannelManager() (*blockchain.ChannelManager, error) {
+	auth, err := s.config.RequireSigner()
+	if err != nil {
+		return nil, err
+	}
+	if s.EVMClient == nil {
+		return nil, errors.New("blockchain client not configured")
+	}
+	return s.EVMClient.ChannelManager(auth, blockchain.WithSignedAmountFunc(s.daemonSignedAmount))
+}
+
+// daemonSignedAmount asks the daemon of the current service group for the
+// signed amount of channel. It returns (nil, nil) for channels of other
+// recipients or payment groups, which this daemon does not serve.
+func (s *ServiceClient) daemonSignedAmount(ctx context.Context, channel *blockchain.ChannelInfo) (*big.Int, error) {
+	if s.CurrentOrgGroup == nil || s.ServiceMetadata == nil {
+		return nil, nil
+	}
+	groupID, err := blockchain.DecodePaymentGroupID(s.CurrentOrgGroup.ID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.GroupID != groupID || channel.Recipient != common.HexToAddress(s.CurrentOrgGroup.PaymentDetails.PaymentAddress) {
+		return nil, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.GRPCUnary)
+	defer cancel()
+
+	auth, err := s.config.RequireSigner()
+	if err != nil {
+		return nil, err
+	}
+	block, err := s.EVMClient.GetCurrentBlockNumberCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var reply *payment.ChannelStateReply
+	err = s.invoke(ctx, func(c *grpc.Client) error {
+		var err error
+		reply, err = payment.GetChannelStateFromDaemon(c.GRPC, ctx, s.ServiceMetadata.GetMpeAddr(), channel.ChannelID, block, auth)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(reply.GetCurrentSignedAmount()), nil
+}
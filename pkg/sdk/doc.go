@@ -21,7 +21,10 @@
 //		}
 //
 //		// Initialize SDK
-//		snetSDK := sdk.NewSDK(cfg)
+//		snetSDK, err := sdk.NewSDK(cfg)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
 //		defer snetSDK.Close()
 //
 //		// Create service client
@@ -54,26 +57,43 @@
 // SnetSDK Interface:
 //   - NewServiceClient: Create a client for a specific service
 //   - NewOrganizationClient: Create a client for organization management
+//   - GetBalances: Show the signer's token, escrow and allowance balances
+//   - DepositEscrow/WithdrawEscrow/TransferEscrow: Move ASI into, out of or
+//     between MPE escrow balances
+//   - WatchRegistry: Stream Registry events with the new metadata and get
+//     clients that follow metadata changes (RegistryWatcher)
+//   - BuildCatalog: Crawl the Registry into a Catalog searchable by method,
+//     tags, price and free calls
 //   - Close: Release resources
 //
 // Service Interface (returned by NewServiceClient):
 //   - CallWithJSON: Invoke methods with JSON input/output
 //   - CallWithMap: Invoke methods with Go maps
 //   - CallWithProto: Invoke methods with proto messages
+//   - NewStream: Open client-, server- or bidirectional-streaming calls
+//   - StreamWithJSON/StreamWithMap/StreamWithProto: Server-streaming calls
 //   - SetPaidPaymentStrategy: Use pay-per-call payment
 //   - SetPrePaidPaymentStrategy: Use prepaid payment channels
 //   - SetFreePaymentStrategy: Use free-call tokens
+//   - SetFreeWithPaidFallbackStrategy/SetFreeWithPrePaidFallbackStrategy: Use
+//     free calls, then pay when the quota runs out
 //   - GetFreeCallsAvailable: Check remaining free calls
+//   - GetPrePaidUsage/RenewPrePaidToken: Check and top up the prepaid balance
 //   - ProtoFiles: Access service API definitions (ProtoManager)
 //   - Healthcheck: Check service availability
+//   - Endpoints/CheckEndpoints: Inspect and probe every endpoint of the group
+//   - ChannelManager: List, extend, fund and reclaim the signer's payment channels
 //   - Training: Access model training API
 //   - Organization: Access parent organization
-//   - UpdateServiceMetadata: Update service metadata
+//   - UpdateServiceMetadata: Validate and update service metadata
+//   - ValidateServiceMetadata: Check new metadata without publishing it
 //   - DeleteService: Remove service from registry
 //   - RawGrpc: Direct access to gRPC client
 //
 // Organization Interface (returned by NewOrganizationClient):
-//   - CreateService: Register a new service
+//   - CreateService: Validate metadata and register a new service
+//   - ValidateService: Check service metadata against the organization, the
+//     network and the protos, optionally probing endpoints (WithEndpointProbe)
 //   - ServiceClient: Get client for an existing service
 //   - ListServices: List all services in the organization
 //   - AddMembers: Add members to the organization
@@ -85,6 +105,19 @@
 //   - ChangeOwner: Transfer organization ownership
 //   - DeleteOrganization: Remove organization from registry
 //
+// # Contexts and Deadlines
+//
+// Every network-facing method of SnetSDK, Service, Organization, Healthcheck
+// and training.Client has a ctx-first variant with a Context suffix, for
+// example CallWithJSONContext or UpdateServiceMetadataContext. The plain
+// forms run under context.Background(). Cancellation of ctx is propagated to
+// the daemon, the storage backend and the chain. The configured Timeouts only
+// apply when ctx carries no deadline of its own:
+//
+//	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+//	defer cancel()
+//	response, err := service.CallWithJSONContext(ctx, "method", input)
+//
 // # Payment Strategies
 //
 // The SDK supports three payment models:
@@ -93,6 +126,9 @@
 //   - No payment required
 //   - Service provides free-call tokens
 //   - Limited usage per user/period
+//   - By default, calls switch to paid calls once the free quota runs out and
+//     back when new free calls are granted (SetFreeWithPaidFallbackStrategy,
+//     SetFreeWithPrePaidFallbackStrategy)
 //
 // 2. Paid Calls (MPE escrow):
 //   - Pay per service invocation
@@ -103,6 +139,8 @@
 //   - Pre-fund a payment channel
 //   - Lower per-call overhead
 //   - Suitable for high-volume usage
+//   - Another batch of calls is signed and the token renewed automatically
+//     before the balance runs out or the token expires
 //
 // Switch strategies at runtime:
 //
@@ -119,21 +157,44 @@
 //   - Network: Target network (config.Sepolia or config.Main)
 //
 // Optional fields:
-//   - PrivateKey: Required for paid operations and write operations
+//   - PrivateKey, KeystorePath or ClefURL (or a custom Signer): required for
+//     paid operations and write operations (see package signer)
 //   - RegistryAddr: Custom registry contract address
 //   - IpfsURL: Custom IPFS gateway
 //   - LighthouseURL: Custom Lighthouse gateway
 //   - Debug: Enable verbose logging
+//   - Logger or LogHandler: Where the SDK logs (*zap.Logger or slog.Handler);
+//     the SDK never replaces the global zap logger
+//   - TracerProvider and MeterProvider: OpenTelemetry providers for the SDK's
+//     spans and metrics (default: the global ones; see package telemetry)
 //   - Timeouts: Custom timeout configuration
+//   - EndpointPolicy: "health-first" (default) or "round-robin" across group endpoints
+//   - EndpointHealthcheckInterval: Background endpoint probing interval
+//   - ChannelStateFile: JSON file persisting payment channel nonces and signed amounts
 //
 // # Error Handling
 //
-// All methods return errors that should be checked. Common error scenarios:
-//   - Configuration validation failures
+// The SDK never panics or exits on bad input; all methods return errors that
+// should be checked. Common error scenarios:
+//   - Configuration validation failures (returned by NewSDK)
 //   - Network connectivity issues
-//   - Service not found in registry
-//   - Payment channel issues
-//   - Service invocation failures
+//   - Organization, service or group not found in the registry
+//     (ErrOrganizationNotFound, ErrServiceNotFound, ErrGroupNotFound)
+//   - Payment channel issues (ErrInsufficientChannelFunds, ErrChannelExpired,
+//     ErrChannelNotFound, ErrSignatureRejected)
+//   - Free call and prepaid token issues (ErrFreeCallsExhausted,
+//     ErrPrepaidTokenExpired)
+//   - Daemon outages (ErrDaemonUnavailable)
+//   - Service invocation failures (*CallError, wrapping a *payment.DaemonError
+//     when the daemon answered with a gRPC status)
+//
+// Registry lookup errors wrap the sentinels above, so callers can tell a typo
+// in an ID from an outage:
+//
+//	service, err := snetSDK.NewServiceClient(orgID, serviceID, group)
+//	if errors.Is(err, sdk.ErrOrganizationNotFound) || errors.Is(err, sdk.ErrServiceNotFound) {
+//		return http.StatusNotFound
+//	}
 //
 // Example with proper error handling:
 //
@@ -162,7 +223,10 @@
 // Always call Close() on SDK and service instances to release network connections
 // and other resources:
 //
-//	sdk := sdk.NewSDK(cfg)
+//	sdk, err := sdk.NewSDK(cfg)
+//	if err != nil {
+//		return err
+//	}
 //	defer sdk.Close()
 //
 //	service, _ := sdk.NewServiceClient("org", "service", "group")
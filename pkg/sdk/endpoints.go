@@ -0,0 +1,111 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/config"
+	"github.com/shamank/snet-sdk-go/pkg/grpc"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+)
+
+// newGroupServiceClient connects to every endpoint of the current service
+// group and builds a ServiceClient that balances calls over them according to
+// cfg.EndpointPolicy. Background probes are started when
+// cfg.EndpointHealthcheckInterval is set.
+func newGroupServiceClient(
+	cfg *config.Config,
+	org Organization,
+	orgBC *blockchain.OrgClient,
+	svcBC *blockchain.ServiceClient,
+) (*ServiceClient, error) {
+	policy, err := grpc.ParsePolicy(cfg.EndpointPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := grpc.NewPool(svcBC.CurrentGroup.Endpoints, svcBC.ServiceMetadata.ProtoFiles, policy,
+		grpc.WithTelemetry(cfg.GetTelemetry()), grpc.WithDescriptorCache(svcBC.Cache()), grpc.WithLogger(configLogger(cfg)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to service group %s: %w", svcBC.CurrentGroup.GroupName, err)
+	}
+
+	// Signer setup errors are reported by NewSDK; without a signer the client
+	// is read-only.
+	auth, _ := cfg.GetSigner()
+	sc := newServiceClient(cfg, org, orgBC, svcBC, pool.Client(), auth)
+	sc.endpoints = pool
+	if cfg.EndpointHealthcheckInterval > 0 {
+		sc.startEndpointProbes(cfg.EndpointHealthcheckInterval)
+	}
+	return sc, nil
+}
+
+// Endpoints returns the health of every endpoint of the current service group.
+func (s *ServiceClient) Endpoints() []grpc.EndpointStatus {
+	if s.endpoints == nil {
+		return nil
+	}
+	return s.endpoints.Status()
+}
+
+// CheckEndpoints probes every endpoint of the current service group with the
+// gRPC Healthcheck, marks failing endpoints as bad and restores recovered
+// ones. The GRPCUnary timeout applies only when ctx has no deadline.
+func (s *ServiceClient) CheckEndpoints(ctx context.Context) []grpc.EndpointStatus {
+	if s.endpoints == nil {
+		return nil
+	}
+
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.GRPCUnary)
+	defer cancel()
+
+	s.endpoints.Probe(ctx, func(ctx context.Context, url string, client *grpc.Client) error {
+		hc := newHealthcheckClient(client, &model.ServiceGroup{Endpoints: []string{url}}, s.config, s.logger())
+		_, err := hc.GRPCContext(ctx)
+		return err
+	})
+	return s.endpoints.Status()
+}
+
+// startEndpointProbes runs CheckEndpoints every interval until Close.
+func (s *ServiceClient) startEndpointProbes(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopProbes = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.CheckEndpoints(ctx)
+			}
+		}
+	}()
+}
+
+// invoke runs call on the preferred endpoint and fails over to the next one
+// when an endpoint is Unavailable. Without an endpoint pool it uses the single
+// gRPC client.
+func (s *ServiceClient) invoke(ctx context.Context, call func(*grpc.Client) error) error {
+	if s.endpoints == nil {
+		return call(s.grpcClient)
+	}
+	return s.endpoints.Do(ctx, call)
+}
+
+// currentClient returns the client handed to components that keep one client
+// for their lifetime (payment strategies, health check, training). With an
+// endpoint pool, the RPCs they make through Client.Conn fail over between
+// endpoints.
+func (s *ServiceClient) currentClient() *grpc.Client {
+	if s.endpoints == nil {
+		return s.GRPC
+	}
+	return s.endpoints.Client()
+}
@@ -0,0 +1,72 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+)
+
+// Registry lookup errors returned (wrapped) by NewOrganizationClient,
+// NewServiceClient and Organization.ServiceClient. Test for them with
+// errors.Is.
+var (
+	// ErrOrganizationNotFound reports an organization ID missing from the Registry.
+	ErrOrganizationNotFound = blockchain.ErrOrganizationNotFound
+	// ErrServiceNotFound reports a service ID missing from the organization.
+	ErrServiceNotFound = blockchain.ErrServiceNotFound
+	// ErrGroupNotFound reports a group name missing from the service metadata.
+	ErrGroupNotFound = blockchain.ErrGroupNotFound
+)
+
+// Daemon and payment errors returned (wrapped) by service calls, streams and
+// the Set*PaymentStrategy methods. They are the payment package sentinels,
+// re-exported so callers can react without importing payment:
+//
+//	_, err := service.CallWithJSON("method", input)
+//	switch {
+//	case errors.Is(err, sdk.ErrInsufficientChannelFunds), errors.Is(err, sdk.ErrChannelExpired):
+//		// fund or extend the channel, then retry
+//	case errors.Is(err, sdk.ErrFreeCallsExhausted):
+//		err = service.SetPaidPaymentStrategy()
+//	case errors.Is(err, sdk.ErrDaemonUnavailable):
+//		// back off and retry
+//	}
+var (
+	ErrChannelNotFound          = payment.ErrChannelNotFound
+	ErrInsufficientChannelFunds = payment.ErrInsufficientChannelFunds
+	ErrChannelExpired           = payment.ErrChannelExpired
+	ErrFreeCallsExhausted       = payment.ErrFreeCallsExhausted
+	ErrPrepaidTokenExpired      = payment.ErrPrepaidTokenExpired
+	ErrSignatureRejected        = payment.ErrSignatureRejected
+	ErrDaemonUnavailable        = payment.ErrDaemonUnavailable
+)
+
+// CallError is returned by the Call* and Stream* methods when the request
+// fails. Err is a *payment.DaemonError when the daemon answered with a gRPC
+// status, and the local error otherwise (for example an unknown method or
+// malformed input).
+type CallError struct {
+	Method string
+	Stream bool
+	Err    error
+}
+
+// newCallError wraps the failure of a call to method, classifying daemon
+// statuses.
+func newCallError(method string, stream bool, err error) *CallError {
+	return &CallError{Method: method, Stream: stream, Err: payment.ClassifyDaemonError(err)}
+}
+
+// Error implements error.
+func (e *CallError) Error() string {
+	if e.Stream {
+		return fmt.Sprintf("gRPC stream %s failed: %v", e.Method, e.Err)
+	}
+	return fmt.Sprintf("gRPC call %s failed: %v", e.Method, e.Err)
+}
+
+// Unwrap returns Err.
+func (e *CallError) Unwrap() error {
+	return e.Err
+}
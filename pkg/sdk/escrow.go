@@ -0,0 +1,144 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"github.com/shopspring/decimal"
+)
+
+// GetBalances returns the token balance, MPE escrow balance and MPE allowance
+// of the configured signer.
+func (c *Core) GetBalances() (*blockchain.Balances, error) {
+	return c.GetBalancesContext(context.Background())
+}
+
+// GetBalancesContext is like GetBalances but reads the chain under ctx. The
+// ChainRead timeout applies only when ctx has no deadline.
+func (c *Core) GetBalancesContext(ctx context.Context) (*blockchain.Balances, error) {
+	auth, err := c.RequireSigner()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, c.Timeouts.ChainRead)
+	defer cancel()
+
+	return c.evm.GetBalances(ctx, auth.Address())
+}
+
+// DepositEscrow moves asi ASI from the signer's tokens into its MPE escrow
+// balance, approving the MPE contract first if needed. asi may be any integer
+// type, a float64, a decimal string or a decimal.Decimal (or a pointer to one);
+// the same applies to WithdrawEscrow and TransferEscrow.
+func (c *Core) DepositEscrow(asi any) (*blockchain.MultiPartyEscrowDepositFunds, error) {
+	return c.DepositEscrowContext(context.Background(), asi)
+}
+
+// DepositEscrowContext is like DepositEscrow but submits the transactions and
+// waits for their receipts under ctx. The PaymentEnsure timeout applies only
+// when ctx has no deadline.
+func (c *Core) DepositEscrowContext(ctx context.Context, asi any) (*blockchain.MultiPartyEscrowDepositFunds, error) {
+	key, amount, err := c.escrowArgs(asi)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, c.Timeouts.PaymentEnsure)
+	defer cancel()
+
+	ev, err := c.evm.DepositToEscrow(ctx, key, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deposit to escrow: %w", err)
+	}
+	return ev, nil
+}
+
+// WithdrawEscrow moves asi ASI from the signer's MPE escrow balance back to its
+// tokens.
+func (c *Core) WithdrawEscrow(asi any) (*blockchain.MultiPartyEscrowWithdrawFunds, error) {
+	return c.WithdrawEscrowContext(context.Background(), asi)
+}
+
+// WithdrawEscrowContext is like WithdrawEscrow but submits the transaction and
+// waits for its receipt under ctx.
+func (c *Core) WithdrawEscrowContext(ctx context.Context, asi any) (*blockchain.MultiPartyEscrowWithdrawFunds, error) {
+	key, amount, err := c.escrowArgs(asi)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, c.Timeouts.PaymentEnsure)
+	defer cancel()
+
+	ev, err := c.evm.WithdrawFromEscrow(ctx, key, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to withdraw from escrow: %w", err)
+	}
+	return ev, nil
+}
+
+// TransferEscrow moves asi ASI from the signer's MPE escrow balance to the
+// escrow balance of to.
+func (c *Core) TransferEscrow(to common.Address, asi any) (*blockchain.MultiPartyEscrowTransferFunds, error) {
+	return c.TransferEscrowContext(context.Background(), to, asi)
+}
+
+// TransferEscrowContext is like TransferEscrow but submits the transaction and
+// waits for its receipt under ctx.
+func (c *Core) TransferEscrowContext(ctx context.Context, to common.Address, asi any) (*blockchain.MultiPartyEscrowTransferFunds, error) {
+	key, amount, err := c.escrowArgs(asi)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withTimeout(ctx, c.Timeouts.PaymentEnsure)
+	defer cancel()
+
+	ev, err := c.evm.TransferEscrow(ctx, key, to, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer escrow: %w", err)
+	}
+	return ev, nil
+}
+
+// escrowArgs returns the signer and asi converted to AASI.
+func (c *Core) escrowArgs(asi any) (signer.Signer, *big.Int, error) {
+	key, err := c.RequireSigner()
+	if err != nil {
+		return nil, nil, err
+	}
+	amount, err := asiToAasi(asi)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, amount, nil
+}
+
+// asiToAasi converts an ASI amount with blockchain.AsiToAasi and rejects
+// unsupported types and amounts that round to zero or below. Integers of any
+// width are converted exactly.
+func asiToAasi(asi any) (*big.Int, error) {
+	switch v := asi.(type) {
+	case int, int8, int16, int32, int64:
+		asi = decimal.NewFromInt(reflect.ValueOf(v).Int())
+	case uint, uint8, uint16, uint32, uint64:
+		asi = decimal.NewFromBigInt(new(big.Int).SetUint64(reflect.ValueOf(v).Uint()), 0)
+	case string, float64, decimal.Decimal, *decimal.Decimal:
+	default:
+		return nil, fmt.Errorf("unsupported ASI amount type %T", asi)
+	}
+	amount, err := blockchain.AsiToAasi(asi)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ASI amount %v: %w", asi, err)
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid ASI amount %v: must be positive", asi)
+	}
+	return amount, nil
+}
@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 
 	"github.com/shamank/snet-sdk-go/pkg/config"
@@ -27,6 +26,12 @@ type Healthcheck interface {
 	WebGRPC() (*grpc_health_v1.HealthCheckResponse, error)
 	// HTTP performs an HTTP health check
 	HTTP() (map[string]any, error)
+	// GRPCContext is like GRPC but runs under ctx
+	GRPCContext(ctx context.Context) (*grpc_health_v1.HealthCheckResponse, error)
+	// WebGRPCContext is like WebGRPC but runs under ctx
+	WebGRPCContext(ctx context.Context) (*grpc_health_v1.HealthCheckResponse, error)
+	// HTTPContext is like HTTP but runs under ctx
+	HTTPContext(ctx context.Context) (map[string]any, error)
 }
 
 // healthcheckClient is a concrete implementation of Healthcheck interface.
@@ -35,6 +40,7 @@ type healthcheckClient struct {
 	grpcClient   *grpc.Client        // gRPC client for health check operations
 	serviceGroup *model.ServiceGroup // Service group containing endpoints
 	config       *config.Config      // SDK configuration for debug mode and settings
+	log          *zap.Logger         // Receives debug output and cleanup failures
 }
 
 // newHealthcheckClient creates a new health check client for a service.
@@ -43,20 +49,31 @@ type healthcheckClient struct {
 //   - grpcClient: connected gRPC client to the service daemon
 //   - serviceGroup: service group containing endpoint configuration
 //   - cfg: SDK configuration (used for debug output and timeouts)
+//   - log: logger of the service client
 //
 // Returns a Healthcheck interface implementation.
-func newHealthcheckClient(grpcClient *grpc.Client, serviceGroup *model.ServiceGroup, cfg *config.Config) Healthcheck {
+func newHealthcheckClient(grpcClient *grpc.Client, serviceGroup *model.ServiceGroup, cfg *config.Config, log *zap.Logger) Healthcheck {
 	return &healthcheckClient{
 		grpcClient:   grpcClient,
 		serviceGroup: serviceGroup,
 		config:       cfg,
+		log:          log,
 	}
 }
 
 // GRPC performs a standard gRPC health check against the connected service.
 func (hc *healthcheckClient) GRPC() (*grpc_health_v1.HealthCheckResponse, error) {
-	client := grpc_health_v1.NewHealthClient(hc.grpcClient.GRPC) // Conn — *grpc.ClientConn
-	resp, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	return hc.GRPCContext(context.Background())
+}
+
+// GRPCContext is like GRPC but runs under ctx. The GRPCUnary timeout applies
+// only when ctx has no deadline of its own.
+func (hc *healthcheckClient) GRPCContext(ctx context.Context) (*grpc_health_v1.HealthCheckResponse, error) {
+	ctx, cancel := hc.withTimeout(ctx)
+	defer cancel()
+
+	client := grpc_health_v1.NewHealthClient(hc.grpcClient.Conn())
+	resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
 	if err != nil {
 		return nil, fmt.Errorf("grpc heartbeat failed: %w", err)
 	}
@@ -66,6 +83,13 @@ func (hc *healthcheckClient) GRPC() (*grpc_health_v1.HealthCheckResponse, error)
 // WebGRPC performs a gRPC-Web health check using the gRPC Health protocol
 // over an HTTP/1.1 transport.
 func (hc *healthcheckClient) WebGRPC() (*grpc_health_v1.HealthCheckResponse, error) {
+	return hc.WebGRPCContext(context.Background())
+}
+
+// WebGRPCContext is like WebGRPC but runs under ctx.
+func (hc *healthcheckClient) WebGRPCContext(ctx context.Context) (*grpc_health_v1.HealthCheckResponse, error) {
+	ctx, cancel := hc.withTimeout(ctx)
+	defer cancel()
 
 	healthResp := &grpc_health_v1.HealthCheckResponse{}
 
@@ -79,7 +103,7 @@ func (hc *healthcheckClient) WebGRPC() (*grpc_health_v1.HealthCheckResponse, err
 	binary.BigEndian.PutUint32(frame[1:5], uint32(len(reqBody)))
 	copy(frame[5:], reqBody)
 
-	req, err := http.NewRequest("POST", hc.serviceGroup.Endpoints[0]+"/grpc.health.v1.Health/Check", bytes.NewReader(frame))
+	req, err := http.NewRequestWithContext(ctx, "POST", hc.serviceGroup.Endpoints[0]+"/grpc.health.v1.Health/Check", bytes.NewReader(frame))
 	if err != nil {
 		return nil, err
 	}
@@ -94,13 +118,13 @@ func (hc *healthcheckClient) WebGRPC() (*grpc_health_v1.HealthCheckResponse, err
 	}
 	defer resp.Body.Close()
 
+	endpoint := zap.String("endpoint", hc.serviceGroup.Endpoints[0])
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("Failed to read response body", err)
-		return nil, err
+		return nil, fmt.Errorf("read grpc-web health response: %w", err)
 	}
-	if hc.config.Debug {
-		log.Println("HTTP status:", resp.Status)
+	if hc.debug() {
+		hc.log.Debug("grpc-web health response", endpoint, zap.String("status", resp.Status))
 	}
 
 	i := 0
@@ -110,7 +134,7 @@ func (hc *healthcheckClient) WebGRPC() (*grpc_health_v1.HealthCheckResponse, err
 		i += 5
 
 		if i+int(length) > len(body) {
-			log.Println("Frame length exceeds body size")
+			hc.log.Warn("grpc-web health frame exceeds the response body", endpoint, zap.Uint32("length", length))
 			break
 		}
 
@@ -125,8 +149,8 @@ func (hc *healthcheckClient) WebGRPC() (*grpc_health_v1.HealthCheckResponse, err
 		if err := proto.Unmarshal(payload, healthResp); err != nil {
 			return nil, err
 		}
-		if hc.config.Debug {
-			log.Println("Health status:", healthResp.Status.String())
+		if hc.debug() {
+			hc.log.Debug("grpc-web health status", endpoint, zap.Stringer("status", healthResp.Status))
 		}
 	}
 	return healthResp, err
@@ -135,14 +159,26 @@ func (hc *healthcheckClient) WebGRPC() (*grpc_health_v1.HealthCheckResponse, err
 // HTTP performs a simple HTTP GET request to "<endpoint>/heartbeat"
 // and returns the decoded JSON response payload.
 func (hc *healthcheckClient) HTTP() (map[string]any, error) {
-	resp, err := http.Get(hc.serviceGroup.Endpoints[0] + "/heartbeat")
+	return hc.HTTPContext(context.Background())
+}
+
+// HTTPContext is like HTTP but runs under ctx.
+func (hc *healthcheckClient) HTTPContext(ctx context.Context) (map[string]any, error) {
+	ctx, cancel := hc.withTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hc.serviceGroup.Endpoints[0]+"/heartbeat", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer func(Body io.ReadCloser) {
 		err = Body.Close()
 		if err != nil {
-			zap.L().Error("failed to close heartbeat", zap.Error(err))
+			hc.log.Warn("close heartbeat response", zap.Error(err))
 		}
 	}(resp.Body)
 	if resp.StatusCode != http.StatusOK {
@@ -153,9 +189,23 @@ func (hc *healthcheckClient) HTTP() (map[string]any, error) {
 		return nil, fmt.Errorf("failed to decode heartbeat response: %w", err)
 	}
 
-	if hc.config.Debug {
-		log.Println("Protocol used:", resp.Proto) // e.g. "HTTP/2.0" or "HTTP/1.1"
+	if hc.debug() {
+		hc.log.Debug("heartbeat response", zap.String("endpoint", hc.serviceGroup.Endpoints[0]), zap.String("protocol", resp.Proto))
 	}
 
 	return result, nil
 }
+
+// debug reports whether Config.Debug asks for verbose output.
+func (hc *healthcheckClient) debug() bool {
+	return hc.config != nil && hc.config.Debug
+}
+
+// withTimeout bounds a probe by the GRPCUnary timeout unless ctx already has
+// a deadline.
+func (hc *healthcheckClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if hc.config == nil {
+		return context.WithCancel(ctx)
+	}
+	return withTimeout(ctx, hc.config.Timeouts.GRPCUnary)
+}
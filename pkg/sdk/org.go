@@ -3,12 +3,10 @@ package sdk
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/shamank/snet-sdk-go/pkg/blockchain"
 	"github.com/shamank/snet-sdk-go/pkg/config"
-	"github.com/shamank/snet-sdk-go/pkg/grpc"
 	"github.com/shamank/snet-sdk-go/pkg/model"
 )
 
@@ -18,9 +16,16 @@ type Organization interface {
 	// ServiceClient creates a service client for the specified service and group
 	ServiceClient(serviceID, groupName string) (Service, error)
 
+	// ServiceClientContext is like ServiceClient but fetches the service
+	// metadata and protos under ctx
+	ServiceClientContext(ctx context.Context, serviceID, groupName string) (Service, error)
+
 	// ListServices returns a list of all services in the organization
 	ListServices() ([]string, error)
 
+	// ListServicesContext is like ListServices but reads the registry under ctx
+	ListServicesContext(ctx context.Context) ([]string, error)
+
 	// GetOrgMetadata returns the organization metadata
 	GetOrgMetadata() *model.OrganizationMetaData
 
@@ -33,24 +38,52 @@ type Organization interface {
 	// UpdateMetadata updates the organization metadata URI in the blockchain
 	UpdateMetadata(uri string) (common.Hash, error)
 
+	// UpdateMetadataContext is like UpdateMetadata but submits the transaction under ctx
+	UpdateMetadataContext(ctx context.Context, uri string) (common.Hash, error)
+
 	// AddMembers adds new members to the organization
 	AddMembers(members []common.Address) (common.Hash, error)
 
+	// AddMembersContext is like AddMembers but submits the transaction under ctx
+	AddMembersContext(ctx context.Context, members []common.Address) (common.Hash, error)
+
 	// RemoveMembers removes members from the organization
 	RemoveMembers(members []common.Address) (common.Hash, error)
 
+	// RemoveMembersContext is like RemoveMembers but submits the transaction under ctx
+	RemoveMembersContext(ctx context.Context, members []common.Address) (common.Hash, error)
+
 	// ChangeOwner changes the organization owner
 	ChangeOwner(newOwner common.Address) (common.Hash, error)
 
+	// ChangeOwnerContext is like ChangeOwner but submits the transaction under ctx
+	ChangeOwnerContext(ctx context.Context, newOwner common.Address) (common.Hash, error)
+
 	// DeleteOrganization deletes the organization
 	DeleteOrganization() (common.Hash, error)
 
+	// DeleteOrganizationContext is like DeleteOrganization but submits the transaction under ctx
+	DeleteOrganizationContext(ctx context.Context) (common.Hash, error)
+
 	// UpdateOrgMetadataFull updates organization metadata (uploads to IPFS and updates blockchain)
 	UpdateOrgMetadataFull(metadata *model.OrganizationMetaData) (common.Hash, error)
 
+	// UpdateOrgMetadataFullContext is like UpdateOrgMetadataFull but uploads and submits under ctx
+	UpdateOrgMetadataFullContext(ctx context.Context, metadata *model.OrganizationMetaData) (common.Hash, error)
+
 	// CreateService creates a new service in the organization
 	CreateService(serviceID string, metadata *model.ServiceMetadata) (common.Hash, error)
 
+	// CreateServiceContext is like CreateService but uploads and submits under ctx
+	CreateServiceContext(ctx context.Context, serviceID string, metadata *model.ServiceMetadata) (common.Hash, error)
+
+	// ValidateService checks service metadata against the organization, the
+	// network and its protos without publishing anything
+	ValidateService(metadata *model.ServiceMetadata, opts ...ValidateOption) error
+
+	// ValidateServiceContext is like ValidateService but reads the protos and probes under ctx
+	ValidateServiceContext(ctx context.Context, metadata *model.ServiceMetadata, opts ...ValidateOption) error
+
 	// getBlockchainClient returns access to low-level blockchain operations
 	// (optional, if direct access is needed)
 	getBlockchainClient() *blockchain.OrgClient
@@ -66,8 +99,16 @@ type OrganizationClient struct {
 
 // ServiceClient creates a service client for the specified service and group within this organization.
 func (o *OrganizationClient) ServiceClient(serviceID, groupName string) (Service, error) {
+	return o.ServiceClientContext(context.Background(), serviceID, groupName)
+}
+
+// ServiceClientContext is like ServiceClient but fetches the service metadata
+// and protos under ctx.
+func (o *OrganizationClient) ServiceClientContext(ctx context.Context, serviceID, groupName string) (Service, error) {
+	ctx, cancel := withTimeout(ctx, metadataTimeout)
+	defer cancel()
 
-	serviceClient, err := o.blockchainClient.NewServiceClient(serviceID, groupName)
+	serviceClient, err := o.blockchainClient.NewServiceClientCtx(ctx, serviceID, groupName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create blockchain service client: %w", err)
 	}
@@ -80,22 +121,17 @@ func (o *OrganizationClient) ServiceClient(serviceID, groupName string) (Service
 		return nil, fmt.Errorf("no endpoints available for service group %s", groupName)
 	}
 
-	endpoint := serviceClient.CurrentGroup.Endpoints[0]
-	grpcClient := grpc.NewClient(endpoint, serviceClient.ServiceMetadata.ProtoFiles)
-
-	return newServiceClient(
-		o.config,
-		o,
-		o.blockchainClient,
-		serviceClient,
-		grpcClient,
-		o.config.GetPrivateKey(),
-	), nil
+	return newGroupServiceClient(o.config, o, o.blockchainClient, serviceClient)
 }
 
 // ListServices returns a list of IDs of all services in the organization.
 func (o *OrganizationClient) ListServices() ([]string, error) {
-	services := o.blockchainClient.GetServices()
+	return o.ListServicesContext(context.Background())
+}
+
+// ListServicesContext is like ListServices but reads the registry under ctx.
+func (o *OrganizationClient) ListServicesContext(ctx context.Context) ([]string, error) {
+	services := o.blockchainClient.GetServicesCtx(ctx)
 	return services, nil
 }
 
@@ -116,16 +152,46 @@ func (o *OrganizationClient) GetOrgID() string {
 
 // UpdateMetadata updates the organization metadata URI in the blockchain.
 func (o *OrganizationClient) UpdateMetadata(uri string) (common.Hash, error) {
-	hash := o.blockchainClient.UpdateOrgMetadata(uri)
+	return o.UpdateMetadataContext(context.Background(), uri)
+}
+
+// UpdateMetadataContext is like UpdateMetadata but submits the transaction
+// under ctx.
+func (o *OrganizationClient) UpdateMetadataContext(ctx context.Context, uri string) (common.Hash, error) {
+	auth, err := o.config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	hash, err := o.blockchainClient.UpdateOrgMetadataWithAuthCtx(ctx, auth, uri)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to update metadata: %w", err)
+	}
+
 	return hash, nil
 }
 
 // AddMembers adds new members to the organization.
 func (o *OrganizationClient) AddMembers(members []common.Address) (common.Hash, error) {
+	return o.AddMembersContext(context.Background(), members)
+}
+
+// AddMembersContext is like AddMembers but submits the transaction under ctx.
+func (o *OrganizationClient) AddMembersContext(ctx context.Context, members []common.Address) (common.Hash, error) {
 	if len(members) == 0 {
 		return common.Hash{}, fmt.Errorf("no members to add")
 	}
-	hash := o.blockchainClient.AddMember(members)
+
+	auth, err := o.config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	hash, err := o.blockchainClient.AddOrganizationMembersWithAuthCtx(ctx, auth, members)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to add members: %w", err)
+	}
+
 	return hash, nil
 }
 
@@ -138,16 +204,21 @@ func (o *OrganizationClient) getBlockchainClient() *blockchain.OrgClient {
 
 // RemoveMembers removes members from the organization.
 func (o *OrganizationClient) RemoveMembers(members []common.Address) (common.Hash, error) {
+	return o.RemoveMembersContext(context.Background(), members)
+}
+
+// RemoveMembersContext is like RemoveMembers but submits the transaction under ctx.
+func (o *OrganizationClient) RemoveMembersContext(ctx context.Context, members []common.Address) (common.Hash, error) {
 	if len(members) == 0 {
 		return common.Hash{}, fmt.Errorf("no members to remove")
 	}
 
-	pk := o.config.GetPrivateKey()
-	if pk == nil {
-		return common.Hash{}, fmt.Errorf("private key not configured")
+	auth, err := o.config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
 	}
 
-	hash, err := o.blockchainClient.RemoveOrganizationMembers(pk, members)
+	hash, err := o.blockchainClient.RemoveOrganizationMembersCtx(ctx, auth, members)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to remove members: %w", err)
 	}
@@ -157,12 +228,17 @@ func (o *OrganizationClient) RemoveMembers(members []common.Address) (common.Has
 
 // ChangeOwner changes the organization owner.
 func (o *OrganizationClient) ChangeOwner(newOwner common.Address) (common.Hash, error) {
-	pk := o.config.GetPrivateKey()
-	if pk == nil {
-		return common.Hash{}, fmt.Errorf("private key not configured")
+	return o.ChangeOwnerContext(context.Background(), newOwner)
+}
+
+// ChangeOwnerContext is like ChangeOwner but submits the transaction under ctx.
+func (o *OrganizationClient) ChangeOwnerContext(ctx context.Context, newOwner common.Address) (common.Hash, error) {
+	auth, err := o.config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
 	}
 
-	hash, err := o.blockchainClient.ChangeOrganizationOwner(pk, newOwner)
+	hash, err := o.blockchainClient.ChangeOrganizationOwnerCtx(ctx, auth, newOwner)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to change owner: %w", err)
 	}
@@ -172,12 +248,18 @@ func (o *OrganizationClient) ChangeOwner(newOwner common.Address) (common.Hash,
 
 // DeleteOrganization deletes the organization.
 func (o *OrganizationClient) DeleteOrganization() (common.Hash, error) {
-	pk := o.config.GetPrivateKey()
-	if pk == nil {
-		return common.Hash{}, fmt.Errorf("private key not configured")
+	return o.DeleteOrganizationContext(context.Background())
+}
+
+// DeleteOrganizationContext is like DeleteOrganization but submits the
+// transaction under ctx.
+func (o *OrganizationClient) DeleteOrganizationContext(ctx context.Context) (common.Hash, error) {
+	auth, err := o.config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
 	}
 
-	hash, err := o.blockchainClient.DeleteOrganization(pk)
+	hash, err := o.blockchainClient.DeleteOrganizationCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to delete organization: %w", err)
 	}
@@ -187,12 +269,18 @@ func (o *OrganizationClient) DeleteOrganization() (common.Hash, error) {
 
 // UpdateOrgMetadataFull updates organization metadata (uploads to IPFS and updates blockchain).
 func (o *OrganizationClient) UpdateOrgMetadataFull(metadata *model.OrganizationMetaData) (common.Hash, error) {
-	pk := o.config.GetPrivateKey()
-	if pk == nil {
-		return common.Hash{}, fmt.Errorf("private key not configured")
+	return o.UpdateOrgMetadataFullContext(context.Background(), metadata)
+}
+
+// UpdateOrgMetadataFullContext is like UpdateOrgMetadataFull but uploads the
+// metadata and submits the transaction under ctx.
+func (o *OrganizationClient) UpdateOrgMetadataFullContext(ctx context.Context, metadata *model.OrganizationMetaData) (common.Hash, error) {
+	auth, err := o.config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := withTimeout(ctx, metadataTimeout)
 	defer cancel()
 
 	// Upload metadata to IPFS
@@ -202,7 +290,7 @@ func (o *OrganizationClient) UpdateOrgMetadataFull(metadata *model.OrganizationM
 	}
 
 	// Update URI in blockchain
-	hash, err := o.blockchainClient.UpdateOrgMetadataWithAuth(pk, uri)
+	hash, err := o.blockchainClient.UpdateOrgMetadataWithAuthCtx(ctx, auth, uri)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to update metadata in blockchain: %w", err)
 	}
@@ -212,14 +300,30 @@ func (o *OrganizationClient) UpdateOrgMetadataFull(metadata *model.OrganizationM
 
 // CreateService creates a new service in the organization.
 func (o *OrganizationClient) CreateService(serviceID string, metadata *model.ServiceMetadata) (common.Hash, error) {
-	pk := o.config.GetPrivateKey()
-	if pk == nil {
-		return common.Hash{}, fmt.Errorf("private key not configured")
+	return o.CreateServiceContext(context.Background(), serviceID, metadata)
+}
+
+// CreateServiceContext is like CreateService but uploads the metadata and
+// submits the transaction under ctx. The metadata is checked with
+// ValidateServiceContext first, and nothing is published if it is invalid.
+// ProtoFiles of metadata without a service_api_source are published first
+// (see grpc.PublishProtos).
+func (o *OrganizationClient) CreateServiceContext(ctx context.Context, serviceID string, metadata *model.ServiceMetadata) (common.Hash, error) {
+	auth, err := o.config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := withTimeout(ctx, metadataTimeout)
 	defer cancel()
 
+	if err := o.ValidateServiceContext(ctx, metadata); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid service metadata: %w", err)
+	}
+	if metadata, err = publishProtos(ctx, o.blockchainClient.Storage, metadata); err != nil {
+		return common.Hash{}, err
+	}
+
 	// Upload service metadata to IPFS
 	uri, err := o.blockchainClient.Storage.UploadJSON(ctx, metadata)
 	if err != nil {
@@ -227,7 +331,7 @@ func (o *OrganizationClient) CreateService(serviceID string, metadata *model.Ser
 	}
 
 	// Create service in blockchain
-	hash, err := o.blockchainClient.CreateServiceRegistration(pk, serviceID, []byte(uri))
+	hash, err := o.blockchainClient.CreateServiceRegistrationCtx(ctx, auth, serviceID, []byte(uri))
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create service registration: %w", err)
 	}
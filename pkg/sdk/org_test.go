@@ -42,16 +42,15 @@ func (e *testError) Error() string {
 
 func TestCreateOrganization_PrivateKeyValidation(t *testing.T) {
 	cfg := &config.Config{PrivateKey: "", RPCAddr: "tests"}
-	//evm := &blockchain.EVMClient{Storage: &mockStorage{}}
-	sdk := NewSDK(cfg)
+	sdk := &Core{evm: &blockchain.EVMClient{Storage: &mockStorage{}}, Config: cfg}
 
 	metadata := &model.OrganizationMetaData{OrgName: "Test"}
 	_, err := sdk.CreateOrganization("test-org", metadata, nil)
 	if err == nil {
-		t.Fatal("expected error when private key not configured")
+		t.Fatal("expected error when signer not configured")
 	}
-	if !contains(err.Error(), "private key not configured") {
-		t.Errorf("expected 'private key not configured' error, got: %v", err)
+	if !contains(err.Error(), "signer not configured") {
+		t.Errorf("expected 'signer not configured' error, got: %v", err)
 	}
 }
 
@@ -60,10 +59,7 @@ func TestCreateOrganization_IPFSUploadFailure(t *testing.T) {
 		PrivateKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 	}
 
-	sdk := NewSDK(cfg)
-
-	//mockStorageFailure := &mockStorage{shouldFail: true}
-	//evm := &blockchain.EVMClient{Storage: mockStorageFailure}
+	sdk := &Core{evm: &blockchain.EVMClient{Storage: &mockStorage{shouldFail: true}}, Config: cfg}
 
 	metadata := &model.OrganizationMetaData{OrgName: "Test"}
 	_, err := sdk.CreateOrganization("test-org", metadata, nil)
@@ -88,10 +84,10 @@ func TestOrganizationClient_RemoveMembers_Validation(t *testing.T) {
 		t.Errorf("expected 'no members to remove' error, got: %v", err)
 	}
 
-	// Test without private key
+	// Test without signer
 	_, err = orgClient.RemoveMembers([]common.Address{common.HexToAddress("0x1")})
-	if err == nil || !contains(err.Error(), "private key not configured") {
-		t.Errorf("expected 'private key not configured' error, got: %v", err)
+	if err == nil || !contains(err.Error(), "signer not configured") {
+		t.Errorf("expected 'signer not configured' error, got: %v", err)
 	}
 }
 
@@ -100,8 +96,8 @@ func TestOrganizationClient_ChangeOwner_Validation(t *testing.T) {
 	orgClient := &OrganizationClient{config: cfg}
 
 	_, err := orgClient.ChangeOwner(common.HexToAddress("0x123"))
-	if err == nil || !contains(err.Error(), "private key not configured") {
-		t.Errorf("expected 'private key not configured' error, got: %v", err)
+	if err == nil || !contains(err.Error(), "signer not configured") {
+		t.Errorf("expected 'signer not configured' error, got: %v", err)
 	}
 }
 
@@ -110,8 +106,8 @@ func TestOrganizationClient_DeleteOrganization_Validation(t *testing.T) {
 	orgClient := &OrganizationClient{config: cfg}
 
 	_, err := orgClient.DeleteOrganization()
-	if err == nil || !contains(err.Error(), "private key not configured") {
-		t.Errorf("expected 'private key not configured' error, got: %v", err)
+	if err == nil || !contains(err.Error(), "signer not configured") {
+		t.Errorf("expected 'signer not configured' error, got: %v", err)
 	}
 }
 
@@ -121,8 +117,8 @@ func TestOrganizationClient_UpdateOrgMetadataFull_Validation(t *testing.T) {
 
 	metadata := &model.OrganizationMetaData{OrgName: "Test"}
 	_, err := orgClient.UpdateOrgMetadataFull(metadata)
-	if err == nil || !contains(err.Error(), "private key not configured") {
-		t.Errorf("expected 'private key not configured' error, got: %v", err)
+	if err == nil || !contains(err.Error(), "signer not configured") {
+		t.Errorf("expected 'signer not configured' error, got: %v", err)
 	}
 }
 
@@ -132,8 +128,8 @@ func TestOrganizationClient_CreateService_Validation(t *testing.T) {
 
 	metadata := &model.ServiceMetadata{DisplayName: "Test"}
 	_, err := orgClient.CreateService("test-service", metadata)
-	if err == nil || !contains(err.Error(), "private key not configured") {
-		t.Errorf("expected 'private key not configured' error, got: %v", err)
+	if err == nil || !contains(err.Error(), "signer not configured") {
+		t.Errorf("expected 'signer not configured' error, got: %v", err)
 	}
 }
 
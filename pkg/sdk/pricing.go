@@ -0,0 +1,124 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/shamank/snet-sdk-go/pkg/grpc"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"google.golang.org/protobuf/proto"
+)
+
+// priceDeriver calls the dynamic pricing method name with the same input as
+// the priced call and returns the price it reports.
+type priceDeriver func(ctx context.Context, c *grpc.Client, name string) (*big.Int, error)
+
+// pricedContext names the called method in ctx so the payment strategy can
+// apply per-method pricing. When the service metadata maps the method to a
+// dynamic pricing method, that method is called through derive first and its
+// price is attached with payment.WithDynamicPrice. The pricing method is called
+// without payment headers. Unknown methods are left for the call itself to
+// report.
+func (s *ServiceClient) pricedContext(ctx context.Context, method string, derive priceDeriver) (context.Context, error) {
+	client := s.currentClient()
+	if client == nil {
+		return ctx, nil
+	}
+	path, err := client.MethodPath(method)
+	if err != nil {
+		return ctx, nil
+	}
+	ctx = payment.WithMethod(ctx, path)
+
+	priceMethod := s.dynamicPriceMethod(path)
+	if priceMethod == "" || derive == nil {
+		return ctx, nil
+	}
+
+	_, _, name := model.SplitMethodPath(priceMethod)
+	var price *big.Int
+	err = s.invoke(ctx, func(c *grpc.Client) error {
+		price, err = derive(ctx, c, name)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive dynamic price from %s: %w", priceMethod, err)
+	}
+	return payment.WithDynamicPrice(ctx, price), nil
+}
+
+// dynamicPriceMethod returns the pricing method mapped to the method path in
+// the service metadata, accepting keys with or without the leading slash.
+func (s *ServiceClient) dynamicPriceMethod(path string) string {
+	if s.ServiceMetadata == nil {
+		return ""
+	}
+	mapping := s.ServiceMetadata.DynamicPriceMethodMapping
+	if m, ok := mapping[path]; ok {
+		return m
+	}
+	return mapping[strings.TrimPrefix(path, "/")]
+}
+
+// jsonPriceDeriver derives the price by calling the pricing method with a JSON body.
+func jsonPriceDeriver(input []byte) priceDeriver {
+	return func(ctx context.Context, c *grpc.Client, name string) (*big.Int, error) {
+		out, err := c.CallWithJSON(ctx, name, input)
+		if err != nil {
+			return nil, err
+		}
+		var reply map[string]any
+		if err := json.Unmarshal(out, &reply); err != nil {
+			return nil, err
+		}
+		return parsePrice(reply["price"])
+	}
+}
+
+// mapPriceDeriver derives the price by calling the pricing method with a map body.
+func mapPriceDeriver(params map[string]any) priceDeriver {
+	return func(ctx context.Context, c *grpc.Client, name string) (*big.Int, error) {
+		reply, err := c.CallWithMap(ctx, name, params)
+		if err != nil {
+			return nil, err
+		}
+		return parsePrice(reply["price"])
+	}
+}
+
+// protoPriceDeriver derives the price by calling the pricing method with a proto message.
+func protoPriceDeriver(input proto.Message) priceDeriver {
+	return func(ctx context.Context, c *grpc.Client, name string) (*big.Int, error) {
+		reply, err := c.CallWithProto(ctx, name, input)
+		if err != nil {
+			return nil, err
+		}
+		msg := reply.ProtoReflect()
+		field := msg.Descriptor().Fields().ByName("price")
+		if field == nil {
+			return nil, fmt.Errorf("pricing reply %s has no price field", msg.Descriptor().FullName())
+		}
+		return parsePrice(msg.Get(field).Interface())
+	}
+}
+
+// parsePrice converts the "price" field of a pricing reply (the daemon's
+// PriceInCogs message) to cogs. protojson renders uint64 as a string, so both
+// strings and numbers are accepted.
+func parsePrice(v any) (*big.Int, error) {
+	switch n := v.(type) {
+	case nil:
+		return nil, fmt.Errorf("pricing reply has no price")
+	case float64:
+		v, _ = big.NewFloat(n).Int(nil)
+	}
+	price, ok := new(big.Int).SetString(fmt.Sprint(v), 10)
+	if !ok || price.Sign() < 0 {
+		return nil, fmt.Errorf("invalid price %v", v)
+	}
+	return price, nil
+}
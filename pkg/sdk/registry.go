@@ -0,0 +1,389 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"go.uber.org/zap"
+)
+
+// ErrWatcherClosed is returned by the client getters of a RegistryWatcher
+// that has been closed.
+var ErrWatcherClosed = errors.New("registry watcher closed")
+
+// defaultRegistryEventBuffer is the capacity of the Events channel.
+const defaultRegistryEventBuffer = 64
+
+// RegistryEvent is a Registry event together with the metadata it points
+// to. OrgMetadata is set for OrganizationCreated and OrganizationModified,
+// ServiceMetadata for ServiceCreated and ServiceMetadataModified, unless
+// metadata resolution is disabled. MetadataErr reports a failure to read
+// the metadata; the event is delivered anyway.
+type RegistryEvent struct {
+	blockchain.RegistryEvent
+	OrgMetadata     *model.OrganizationMetaData
+	ServiceMetadata *model.ServiceMetadata
+	MetadataErr     error
+}
+
+// RegistryWatchOption configures a RegistryWatcher.
+type RegistryWatchOption func(*registryWatchOptions)
+
+type registryWatchOptions struct {
+	orgIDs     []string
+	start      *uint64
+	noMetadata bool
+	buffer     int
+	closeDelay time.Duration
+}
+
+// WatchOrganizations limits the watcher to events of the given organizations.
+func WatchOrganizations(orgIDs ...string) RegistryWatchOption {
+	return func(o *registryWatchOptions) { o.orgIDs = append(o.orgIDs, orgIDs...) }
+}
+
+// WatchFromBlock replays the events from block on before following new ones.
+// By default only events after the watcher starts are delivered.
+func WatchFromBlock(block uint64) RegistryWatchOption {
+	return func(o *registryWatchOptions) { o.start = &block }
+}
+
+// WithoutMetadata delivers the events without reading the metadata they
+// point to. Cached clients are still invalidated.
+func WithoutMetadata() RegistryWatchOption {
+	return func(o *registryWatchOptions) { o.noMetadata = true }
+}
+
+// WithEventBuffer sets the capacity of the Events channel (64 by default).
+// The watcher stops reading the chain while the channel is full.
+func WithEventBuffer(n int) RegistryWatchOption {
+	return func(o *registryWatchOptions) { o.buffer = n }
+}
+
+// serviceKey identifies a cached service client.
+type serviceKey struct {
+	orgID, serviceID, groupName string
+}
+
+// orgKey identifies a cached organization client.
+type orgKey struct {
+	orgID, groupName string
+}
+
+// RegistryWatcher streams the organization and service events of the
+// Registry contract, decoded and, by default, with the new metadata read
+// from storage. It also hands out organization and service clients and drops
+// them from its cache when an event changes their metadata, so that
+// long-running processes pick up metadata changes without a restart: fetch
+// the client from the watcher each time instead of keeping it.
+//
+// A dropped service client keeps working for the GRPCStream timeout, so that
+// calls in flight can finish, and is closed after that. Close closes every
+// client of the watcher.
+type RegistryWatcher struct {
+	events chan RegistryEvent
+	cancel context.CancelFunc
+	done   chan struct{}
+	log    *zap.Logger
+
+	noMetadata  bool
+	closeDelay  time.Duration
+	readOrg     func(ctx context.Context, orgID string) (*model.OrganizationMetaData, error)
+	readService func(ctx context.Context, uri string) (*model.ServiceMetadata, error)
+	newOrg      func(ctx context.Context, orgID, groupName string) (Organization, error)
+	newService  func(ctx context.Context, orgID, serviceID, groupName string) (*ServiceClient, error)
+	forget      func(orgID, serviceID string) // Drops cached Registry lookups
+
+	mu       sync.Mutex
+	err      error
+	closed   bool
+	version  uint64 // Bumped on every invalidation
+	orgs     map[orgKey]Organization
+	services map[serviceKey]*ServiceClient
+	retired  map[*ServiceClient]*time.Timer
+}
+
+// WatchRegistry is like WatchRegistryContext with context.Background().
+func (c *Core) WatchRegistry(opts ...RegistryWatchOption) (*RegistryWatcher, error) {
+	return c.WatchRegistryContext(context.Background(), opts...)
+}
+
+// WatchRegistryContext starts a RegistryWatcher that runs until ctx is done
+// or Close is called. Subscriptions need a WebSocket RPCAddr (ws:// or
+// wss://).
+func (c *Core) WatchRegistryContext(ctx context.Context, opts ...RegistryWatchOption) (*RegistryWatcher, error) {
+	o := registryWatchOptions{buffer: defaultRegistryEventBuffer, closeDelay: c.Config.Timeouts.GRPCStream}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	w := newRegistryWatcher(o, c.Config.GetLogger())
+	w.readOrg = c.evm.GetOrgMetadataCtx
+	w.readService = c.evm.ReadServiceMetadataCtx
+	w.forget = c.evm.InvalidateRegistryCache
+	w.newOrg = c.NewOrganizationClientContext
+	w.newService = func(ctx context.Context, orgID, serviceID, groupName string) (*ServiceClient, error) {
+		svc, err := c.NewServiceClientContext(ctx, orgID, serviceID, groupName)
+		if err != nil {
+			return nil, err
+		}
+		return svc.(*ServiceClient), nil
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	logs := make(chan blockchain.RegistryEvent)
+	sub, err := c.evm.WatchRegistryEventsCtx(ctx, o.start, logs, o.orgIDs...)
+	if err != nil {
+		w.cancel()
+		return nil, fmt.Errorf("failed to watch registry: %w", err)
+	}
+	go func() {
+		defer sub.Unsubscribe()
+		w.run(ctx, logs, sub.Err())
+	}()
+	return w, nil
+}
+
+func newRegistryWatcher(o registryWatchOptions, log *zap.Logger) *RegistryWatcher {
+	return &RegistryWatcher{
+		events:     make(chan RegistryEvent, o.buffer),
+		done:       make(chan struct{}),
+		log:        log,
+		noMetadata: o.noMetadata,
+		closeDelay: o.closeDelay,
+		forget:     func(string, string) {},
+		orgs:       make(map[orgKey]Organization),
+		services:   make(map[serviceKey]*ServiceClient),
+		retired:    make(map[*ServiceClient]*time.Timer),
+	}
+}
+
+// run handles the events from logs until ctx is done or errc delivers the
+// error that ended the subscription.
+func (w *RegistryWatcher) run(ctx context.Context, logs <-chan blockchain.RegistryEvent, errc <-chan error) {
+	defer close(w.done)
+	defer close(w.events)
+	defer w.closeClients()
+
+	for {
+		select {
+		case ev := <-logs:
+			w.invalidate(ev)
+			out := w.resolve(ctx, ev)
+			select {
+			case w.events <- out:
+			case <-ctx.Done():
+				return
+			}
+		case err := <-errc:
+			if err != nil {
+				w.mu.Lock()
+				w.err = err
+				w.mu.Unlock()
+				w.log.Warn("registry subscription failed", zap.Error(err))
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Events returns the channel the decoded events are delivered on. It is
+// closed when the watcher stops; Err then tells why.
+func (w *RegistryWatcher) Events() <-chan RegistryEvent {
+	return w.events
+}
+
+// Err returns the error that stopped the subscription, or nil if the watcher
+// is running or was stopped by Close or its context.
+func (w *RegistryWatcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close stops the watcher and closes every client it handed out. It is safe
+// to call multiple times.
+func (w *RegistryWatcher) Close() {
+	w.cancel()
+	<-w.done
+}
+
+// OrganizationClient is like OrganizationClientContext with context.Background().
+func (w *RegistryWatcher) OrganizationClient(orgID, groupName string) (Organization, error) {
+	return w.OrganizationClientContext(context.Background(), orgID, groupName)
+}
+
+// OrganizationClientContext returns a client of the organization group,
+// reading the current metadata under ctx the first time and after an event
+// changed it.
+func (w *RegistryWatcher) OrganizationClientContext(ctx context.Context, orgID, groupName string) (Organization, error) {
+	key := orgKey{orgID, groupName}
+	for {
+		w.mu.Lock()
+		if w.closed {
+			w.mu.Unlock()
+			return nil, ErrWatcherClosed
+		}
+		if org, ok := w.orgs[key]; ok {
+			w.mu.Unlock()
+			return org, nil
+		}
+		version := w.version
+		w.mu.Unlock()
+
+		org, err := w.newOrg(ctx, orgID, groupName)
+		if err != nil {
+			return nil, err
+		}
+
+		w.mu.Lock()
+		// Retry when the metadata changed while it was being read.
+		if w.version == version && !w.closed {
+			w.orgs[key] = org
+			w.mu.Unlock()
+			return org, nil
+		}
+		w.mu.Unlock()
+	}
+}
+
+// ServiceClient is like ServiceClientContext with context.Background().
+func (w *RegistryWatcher) ServiceClient(orgID, serviceID, groupName string) (Service, error) {
+	return w.ServiceClientContext(context.Background(), orgID, serviceID, groupName)
+}
+
+// ServiceClientContext returns a client of the service group, creating it
+// under ctx the first time and after an event changed the metadata of the
+// service or its organization. The client belongs to the watcher; do not
+// close it.
+func (w *RegistryWatcher) ServiceClientContext(ctx context.Context, orgID, serviceID, groupName string) (Service, error) {
+	key := serviceKey{orgID, serviceID, groupName}
+	for {
+		w.mu.Lock()
+		if w.closed {
+			w.mu.Unlock()
+			return nil, ErrWatcherClosed
+		}
+		if svc, ok := w.services[key]; ok {
+			w.mu.Unlock()
+			return svc, nil
+		}
+		version := w.version
+		w.mu.Unlock()
+
+		svc, err := w.newService(ctx, orgID, serviceID, groupName)
+		if err != nil {
+			return nil, err
+		}
+
+		w.mu.Lock()
+		if other, ok := w.services[key]; ok && !w.closed {
+			// Another caller created the client first.
+			w.mu.Unlock()
+			svc.closeConnections()
+			return other, nil
+		}
+		// Retry when the metadata changed while the client was being created.
+		if w.version == version && !w.closed {
+			w.services[key] = svc
+			w.mu.Unlock()
+			return svc, nil
+		}
+		w.mu.Unlock()
+		svc.closeConnections()
+	}
+}
+
+// invalidate drops the cached clients whose metadata ev changes. Events of
+// an organization drop the clients of all its services too, since they carry
+// the payment groups of the organization. Tag changes drop nothing. The
+// Registry lookups of the metadata cache, if any, are dropped as well.
+func (w *RegistryWatcher) invalidate(ev blockchain.RegistryEvent) {
+	if ev.Type == blockchain.ServiceTagsModified {
+		return
+	}
+	w.forget(ev.OrgID, ev.ServiceID)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.version++
+	if !ev.IsServiceEvent() {
+		for key := range w.orgs {
+			if key.orgID == ev.OrgID {
+				delete(w.orgs, key)
+			}
+		}
+	}
+	for key, svc := range w.services {
+		if key.orgID == ev.OrgID && (!ev.IsServiceEvent() || key.serviceID == ev.ServiceID) {
+			delete(w.services, key)
+			w.retire(svc)
+		}
+	}
+}
+
+// retire closes the connections of svc after the close delay. w.mu must be
+// held.
+func (w *RegistryWatcher) retire(svc *ServiceClient) {
+	w.retired[svc] = time.AfterFunc(w.closeDelay, func() {
+		w.mu.Lock()
+		_, ok := w.retired[svc]
+		delete(w.retired, svc)
+		w.mu.Unlock()
+		if ok {
+			svc.closeConnections()
+		}
+	})
+}
+
+// resolve reads the metadata ev points to.
+func (w *RegistryWatcher) resolve(ctx context.Context, ev blockchain.RegistryEvent) RegistryEvent {
+	out := RegistryEvent{RegistryEvent: ev}
+	if w.noMetadata {
+		return out
+	}
+
+	ctx, cancel := withTimeout(ctx, metadataTimeout)
+	defer cancel()
+	switch ev.Type {
+	case blockchain.OrganizationCreated, blockchain.OrganizationModified:
+		out.OrgMetadata, out.MetadataErr = w.readOrg(ctx, ev.OrgID)
+	case blockchain.ServiceCreated, blockchain.ServiceMetadataModified:
+		out.ServiceMetadata, out.MetadataErr = w.readService(ctx, ev.MetadataURI)
+	}
+	if out.MetadataErr != nil {
+		w.log.Warn("failed to read registry metadata",
+			zap.String("event", string(ev.Type)),
+			zap.String("org_id", ev.OrgID),
+			zap.String("service_id", ev.ServiceID),
+			zap.Error(out.MetadataErr))
+	}
+	return out
+}
+
+// closeClients closes every cached and retired service client and empties
+// the cache.
+func (w *RegistryWatcher) closeClients() {
+	w.mu.Lock()
+	w.closed = true
+	var clients []*ServiceClient
+	for _, svc := range w.services {
+		clients = append(clients, svc)
+	}
+	for svc, timer := range w.retired {
+		timer.Stop()
+		clients = append(clients, svc)
+	}
+	w.orgs, w.services, w.retired = nil, nil, nil
+	w.mu.Unlock()
+
+	for _, svc := range clients {
+		svc.closeConnections()
+	}
+}
@@ -0,0 +1,159 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"go.uber.org/zap"
+)
+
+// testRegistryWatcher returns a running watcher fed by the returned channels
+// whose clients count how often they were created and closed.
+func testRegistryWatcher(t *testing.T) (w *RegistryWatcher, logs chan blockchain.RegistryEvent, errc chan error, created, closed *atomic.Int32) {
+	t.Helper()
+	w = newRegistryWatcher(registryWatchOptions{buffer: 1}, zap.NewNop())
+	created, closed = new(atomic.Int32), new(atomic.Int32)
+	w.readOrg = func(_ context.Context, orgID string) (*model.OrganizationMetaData, error) {
+		return &model.OrganizationMetaData{OrgID: orgID}, nil
+	}
+	w.readService = func(_ context.Context, uri string) (*model.ServiceMetadata, error) {
+		if uri == "" {
+			return nil, errors.New("no such file")
+		}
+		return &model.ServiceMetadata{DisplayName: uri}, nil
+	}
+	w.newOrg = func(_ context.Context, orgID, groupName string) (Organization, error) {
+		created.Add(1)
+		return &OrganizationClient{}, nil
+	}
+	w.newService = func(context.Context, string, string, string) (*ServiceClient, error) {
+		created.Add(1)
+		return &ServiceClient{stopProbes: func() { closed.Add(1) }}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	logs, errc = make(chan blockchain.RegistryEvent), make(chan error, 1)
+	go w.run(ctx, logs, errc)
+	t.Cleanup(w.Close)
+	return w, logs, errc, created, closed
+}
+
+// nextRegistryEvent sends ev to the watcher and returns the event it delivers.
+func nextRegistryEvent(t *testing.T, w *RegistryWatcher, logs chan<- blockchain.RegistryEvent, ev blockchain.RegistryEvent) RegistryEvent {
+	t.Helper()
+	logs <- ev
+	select {
+	case out := <-w.Events():
+		return out
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s: no event delivered", ev.Type)
+		return RegistryEvent{}
+	}
+}
+
+func TestRegistryWatcherResolvesMetadata(t *testing.T) {
+	w, logs, _, _, _ := testRegistryWatcher(t)
+
+	ev := nextRegistryEvent(t, w, logs, blockchain.RegistryEvent{Type: blockchain.OrganizationModified, OrgID: "snet"})
+	if ev.OrgMetadata == nil || ev.OrgMetadata.OrgID != "snet" || ev.MetadataErr != nil {
+		t.Fatalf("OrganizationModified: %+v", ev)
+	}
+	ev = nextRegistryEvent(t, w, logs, blockchain.RegistryEvent{Type: blockchain.ServiceMetadataModified, OrgID: "snet", ServiceID: "calc", MetadataURI: "ipfs://Qm2"})
+	if ev.ServiceMetadata == nil || ev.ServiceMetadata.DisplayName != "ipfs://Qm2" {
+		t.Fatalf("ServiceMetadataModified: %+v", ev)
+	}
+	ev = nextRegistryEvent(t, w, logs, blockchain.RegistryEvent{Type: blockchain.ServiceCreated, OrgID: "snet", ServiceID: "calc"})
+	if ev.ServiceMetadata != nil || ev.MetadataErr == nil {
+		t.Fatalf("ServiceCreated with unreadable metadata: %+v", ev)
+	}
+	ev = nextRegistryEvent(t, w, logs, blockchain.RegistryEvent{Type: blockchain.ServiceDeleted, OrgID: "snet", ServiceID: "calc"})
+	if ev.OrgMetadata != nil || ev.ServiceMetadata != nil || ev.MetadataErr != nil {
+		t.Fatalf("ServiceDeleted: %+v", ev)
+	}
+}
+
+func TestRegistryWatcherInvalidatesClients(t *testing.T) {
+	w, logs, _, created, closed := testRegistryWatcher(t)
+
+	calc, err := w.ServiceClient("snet", "calc", "default_group")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ServiceClient("snet", "translate", "default_group"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.OrganizationClient("snet", "default_group"); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := w.ServiceClient("snet", "calc", "default_group"); again != calc || created.Load() != 3 {
+		t.Fatalf("cached client not reused: %d clients created", created.Load())
+	}
+
+	nextRegistryEvent(t, w, logs, blockchain.RegistryEvent{Type: blockchain.ServiceTagsModified, OrgID: "snet", ServiceID: "calc"})
+	if again, _ := w.ServiceClient("snet", "calc", "default_group"); again != calc {
+		t.Fatal("tag change dropped the client")
+	}
+
+	nextRegistryEvent(t, w, logs, blockchain.RegistryEvent{Type: blockchain.ServiceMetadataModified, OrgID: "snet", ServiceID: "calc", MetadataURI: "ipfs://Qm2"})
+	fresh, err := w.ServiceClient("snet", "calc", "default_group")
+	if err != nil || fresh == calc {
+		t.Fatalf("client not recreated after a metadata change: %v", err)
+	}
+	if _, err := w.ServiceClient("snet", "translate", "default_group"); err != nil || created.Load() != 4 {
+		t.Fatalf("metadata change of one service dropped another: %d clients created", created.Load())
+	}
+	waitFor(t, func() bool { return closed.Load() == 1 }, "dropped client closed")
+
+	nextRegistryEvent(t, w, logs, blockchain.RegistryEvent{Type: blockchain.OrganizationModified, OrgID: "snet"})
+	for _, id := range []string{"calc", "translate"} {
+		if _, err := w.ServiceClient("snet", id, "default_group"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.OrganizationClient("snet", "default_group"); err != nil {
+		t.Fatal(err)
+	}
+	if created.Load() != 7 {
+		t.Fatalf("organization change did not drop every client: %d clients created", created.Load())
+	}
+	waitFor(t, func() bool { return closed.Load() == 3 }, "dropped clients closed")
+}
+
+func TestRegistryWatcherStopsOnSubscriptionError(t *testing.T) {
+	w, _, errc, _, closed := testRegistryWatcher(t)
+	if _, err := w.ServiceClient("snet", "calc", "default_group"); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("connection lost")
+	errc <- failure
+	if _, ok := <-w.Events(); ok {
+		t.Fatal("Events not closed")
+	}
+	if !errors.Is(w.Err(), failure) {
+		t.Fatalf("Err = %v; want %v", w.Err(), failure)
+	}
+	if closed.Load() != 1 {
+		t.Fatal("cached client not closed")
+	}
+	if _, err := w.ServiceClient("snet", "calc", "default_group"); !errors.Is(err, ErrWatcherClosed) {
+		t.Fatalf("ServiceClient after stop: err = %v; want %v", err, ErrWatcherClosed)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool, what string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting: %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -5,9 +5,7 @@ package sdk
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -18,45 +16,83 @@ import (
 	"go.uber.org/zap"
 )
 
+// metadataTimeout bounds registry and storage round-trips (metadata reads and
+// uploads) when the caller's context carries no deadline.
+const metadataTimeout = 120 * time.Second
+
 // SnetSDK is the public interface for constructing per-service clients and
-// releasing resources. Note: the Core implementation also offers a context-aware
-// constructor (Core.NewServiceClient) that includes a context parameter.
+// releasing resources. Every method has a ctx-first *Context variant; the
+// plain forms use context.Background().
 type SnetSDK interface {
 	// NewServiceClient creates a client bound to the given org/service/group.
 	// Implementations may fetch metadata from on-chain registry/IPFS and
 	// initialize a gRPC client to the service endpoint.
 	NewServiceClient(orgID, serviceID, groupName string) (Service, error)
 
+	// NewServiceClientContext is like NewServiceClient but fetches metadata under ctx.
+	NewServiceClientContext(ctx context.Context, orgID, serviceID, groupName string) (Service, error)
+
 	// NewOrganizationClient creates an organization client for the specified organization and group
 	NewOrganizationClient(orgID, groupName string) (Organization, error)
 
+	// NewOrganizationClientContext is like NewOrganizationClient but fetches metadata under ctx.
+	NewOrganizationClientContext(ctx context.Context, orgID, groupName string) (Organization, error)
+
 	// CreateOrganization Create new organization
 	CreateOrganization(orgID string, metadata *model.OrganizationMetaData, members []common.Address) (common.Hash, error)
 
+	// CreateOrganizationContext is like CreateOrganization but uploads and submits under ctx.
+	CreateOrganizationContext(ctx context.Context, orgID string, metadata *model.OrganizationMetaData, members []common.Address) (common.Hash, error)
+
 	// GetOrganizations Get all organizations from registry smart contract as string array
 	GetOrganizations() ([]string, error)
 
-	// Close releases resources associated with the SDK instance.
-	Close()
-}
+	// GetOrganizationsContext is like GetOrganizations but reads the registry under ctx.
+	GetOrganizationsContext(ctx context.Context) ([]string, error)
 
-// init configures a default global zap logger for the SDK. Applications may
-// replace it with zap.ReplaceGlobals(...) if they need custom logging.
-func init() {
-	c := zap.Config{
-		Level:            zap.NewAtomicLevelAt(zap.InfoLevel),
-		Development:      false,
-		Encoding:         "console",
-		EncoderConfig:    zap.NewDevelopmentEncoderConfig(),
-		OutputPaths:      []string{"stdout"},
-		ErrorOutputPaths: []string{"stderr"},
-	}
+	// GetBalances returns the token balance, MPE escrow balance and MPE
+	// allowance of the configured signer, in AASI.
+	GetBalances() (*blockchain.Balances, error)
 
-	logger, err := c.Build()
-	if err != nil {
-		panic(err)
-	}
-	zap.ReplaceGlobals(logger)
+	// GetBalancesContext is like GetBalances but reads the chain under ctx.
+	GetBalancesContext(ctx context.Context) (*blockchain.Balances, error)
+
+	// DepositEscrow moves an amount of ASI from the signer's tokens into its
+	// MPE escrow balance. asi accepts the types of blockchain.AsiToAasi.
+	DepositEscrow(asi any) (*blockchain.MultiPartyEscrowDepositFunds, error)
+
+	// DepositEscrowContext is like DepositEscrow but submits and waits under ctx.
+	DepositEscrowContext(ctx context.Context, asi any) (*blockchain.MultiPartyEscrowDepositFunds, error)
+
+	// WithdrawEscrow moves an amount of ASI from the signer's MPE escrow
+	// balance back to its tokens.
+	WithdrawEscrow(asi any) (*blockchain.MultiPartyEscrowWithdrawFunds, error)
+
+	// WithdrawEscrowContext is like WithdrawEscrow but submits and waits under ctx.
+	WithdrawEscrowContext(ctx context.Context, asi any) (*blockchain.MultiPartyEscrowWithdrawFunds, error)
+
+	// TransferEscrow moves an amount of ASI from the signer's MPE escrow
+	// balance to the escrow balance of another address.
+	TransferEscrow(to common.Address, asi any) (*blockchain.MultiPartyEscrowTransferFunds, error)
+
+	// TransferEscrowContext is like TransferEscrow but submits and waits under ctx.
+	TransferEscrowContext(ctx context.Context, to common.Address, asi any) (*blockchain.MultiPartyEscrowTransferFunds, error)
+
+	// WatchRegistry streams the organization and service events of the
+	// Registry and hands out clients that follow metadata changes.
+	WatchRegistry(opts ...RegistryWatchOption) (*RegistryWatcher, error)
+
+	// WatchRegistryContext is like WatchRegistry but the watcher stops when ctx is done.
+	WatchRegistryContext(ctx context.Context, opts ...RegistryWatchOption) (*RegistryWatcher, error)
+
+	// BuildCatalog crawls the Registry into a searchable Catalog of services.
+	BuildCatalog(opts ...CatalogOption) (*Catalog, error)
+
+	// BuildCatalogContext is like BuildCatalog but crawls under ctx.
+	BuildCatalogContext(ctx context.Context, opts ...CatalogOption) (*Catalog, error)
+
+	// Close releases resources associated with the SDK instance.
+	Close()
 }
 
 // Core is the concrete SDK implementation. It embeds the initialized EVM
@@ -64,7 +100,6 @@ func init() {
 type Core struct {
 	evm *blockchain.EVMClient
 	*config.Config
-	prvKey *ecdsa.PrivateKey
 }
 
 // GetEvm returns the EVM client for advanced operations like organization creation.
@@ -74,44 +109,113 @@ func (c *Core) GetEvm() *blockchain.EVMClient {
 }
 
 // NewSDK initializes the SDK Core with validated configuration and a connected
-// EVM client. It applies default timeout values and aborts the process if the
+// EVM client. It applies default timeout values and returns an error if the
 // configuration is invalid or the Ethereum client cannot be initialized.
-func NewSDK(config *config.Config) SnetSDK {
-	err := config.Validate()
-	if err != nil {
-		zap.L().Fatal("Invalid config", zap.Error(err))
+func NewSDK(config *config.Config) (SnetSDK, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
 	config.Timeouts = config.Timeouts.WithDefaults()
+	log := config.GetLogger()
+	tel := config.GetTelemetry()
 
 	storageClient := storage.NewStorage(config.IpfsURL, config.LighthouseURL)
+	storageClient.Telemetry = tel
+	var metadataStorage storage.Storage = storageClient
+
+	registryAddr := config.RegistryAddr
+	if registryAddr == "" {
+		registryAddr = config.Network.RegistryAddr
+	}
+	evmOpts := []blockchain.EvmOption{blockchain.WithLogger(log), blockchain.WithTelemetry(tel)}
+	if config.Cache.Dir != "" {
+		cache, err := newCache(config.Cache, log)
+		if err != nil {
+			return nil, err
+		}
+		metadataStorage = storage.NewCachedStorage(storageClient, cache)
+		evmOpts = append(evmOpts, blockchain.WithCache(cache))
+	}
+	if config.Network.MPEAddr != "" {
+		evmOpts = append(evmOpts, blockchain.WithMPEAddress(common.HexToAddress(config.Network.MPEAddr)))
+	}
+	if config.Network.TokenAddr != "" {
+		evmOpts = append(evmOpts, blockchain.WithTokenAddress(common.HexToAddress(config.Network.TokenAddr)))
+	}
 
-	evmClient, err := blockchain.InitEvm(config.Network.ChainID, config.RPCAddr, config.RegistryAddr, storageClient)
+	evmClient, err := blockchain.InitEvm(config.Network.ChainID, config.RPCAddr, registryAddr, metadataStorage, evmOpts...)
 	if err != nil {
-		zap.L().Error("Init ethereum client failed", zap.Error(err))
-		os.Exit(-1)
+		return nil, fmt.Errorf("init ethereum client: %w", err)
 	}
 
-	address, prvKey, err := blockchain.ParsePrivateKeyECDSA(config.PrivateKey)
+	// Resolve the signer once, before clients share the config.
+	s, err := config.GetSigner()
 	if err != nil {
-		zap.L().Warn("some methods disabled: private key parsing failed", zap.Error(err))
+		log.Warn("some methods disabled: signer setup failed", zap.Error(err))
 	}
 
-	if config.Debug {
-		zap.L().Debug("signer address", zap.String("addr", address.Hex()))
+	if config.Debug && s != nil {
+		log.Debug("signer address", zap.String("addr", s.Address().Hex()))
 	}
 
 	return &Core{
 		evmClient,
 		config,
-		prvKey,
+	}, nil
+}
+
+// newCache opens the metadata cache configured by cfg.
+func newCache(cfg config.CacheConfig, log *zap.Logger) (*storage.Cache, error) {
+	opts := []storage.CacheOption{storage.WithCacheLogger(log)}
+	if cfg.TTL > 0 {
+		opts = append(opts, storage.WithCacheTTL(cfg.TTL))
+	}
+	if cfg.StaleWhileRevalidate {
+		opts = append(opts, storage.WithStaleWhileRevalidate())
+	}
+	if cfg.Offline {
+		opts = append(opts, storage.WithOfflineMode())
+	}
+	cache, err := storage.NewCache(cfg.Dir, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("open metadata cache: %w", err)
+	}
+	return cache, nil
+}
+
+// configLogger returns the logger of cfg, or zap.L() without a config.
+func configLogger(cfg *config.Config) *zap.Logger {
+	if cfg == nil {
+		return zap.L()
 	}
+	return cfg.GetLogger()
+}
+
+// MustNewSDK is like NewSDK but panics if the SDK cannot be initialized.
+//
+// Deprecated: MustNewSDK is kept for callers that have not migrated to the
+// error-returning NewSDK yet. Use NewSDK and handle the error.
+func MustNewSDK(config *config.Config) SnetSDK {
+	sdk, err := NewSDK(config)
+	if err != nil {
+		panic(fmt.Errorf("init SDK: %w", err))
+	}
+	return sdk
 }
 
 // NewOrganizationClient creates a new organization client for the specified organization and group.
 func (c *Core) NewOrganizationClient(orgID, groupName string) (Organization, error) {
+	return c.NewOrganizationClientContext(context.Background(), orgID, groupName)
+}
 
-	client, err := c.GetEvm().NewOrgClient(orgID, groupName)
+// NewOrganizationClientContext is like NewOrganizationClient but reads the
+// registry and the organization metadata under ctx.
+func (c *Core) NewOrganizationClientContext(ctx context.Context, orgID, groupName string) (Organization, error) {
+	ctx, cancel := withTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	client, err := c.GetEvm().NewOrgClientCtx(ctx, orgID, groupName)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +229,12 @@ func (c *Core) NewOrganizationClient(orgID, groupName string) (Organization, err
 
 // GetOrganizations  - get all organizations as array
 func (c *Core) GetOrganizations() ([]string, error) {
-	return c.GetEvm().GetOrganizations()
+	return c.GetOrganizationsContext(context.Background())
+}
+
+// GetOrganizationsContext is like GetOrganizations but reads the registry under ctx.
+func (c *Core) GetOrganizationsContext(ctx context.Context) ([]string, error) {
+	return c.GetEvm().GetOrganizationsCtx(ctx)
 }
 
 // CreateOrganization creates a new organization in the Registry with the given metadata.
@@ -141,12 +250,18 @@ func (c *Core) GetOrganizations() ([]string, error) {
 //
 // Returns transaction hash and error if any.
 func (c *Core) CreateOrganization(orgID string, metadata *model.OrganizationMetaData, members []common.Address) (common.Hash, error) {
-	pk := c.Config.GetPrivateKey()
-	if pk == nil {
-		return common.Hash{}, fmt.Errorf("private key not configured")
+	return c.CreateOrganizationContext(context.Background(), orgID, metadata, members)
+}
+
+// CreateOrganizationContext is like CreateOrganization but uploads the metadata
+// and submits the transaction under ctx.
+func (c *Core) CreateOrganizationContext(ctx context.Context, orgID string, metadata *model.OrganizationMetaData, members []common.Address) (common.Hash, error) {
+	auth, err := c.Config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := withTimeout(ctx, metadataTimeout)
 	defer cancel()
 
 	// Upload metadata to IPFS
@@ -156,7 +271,7 @@ func (c *Core) CreateOrganization(orgID string, metadata *model.OrganizationMeta
 	}
 
 	// Create organization in blockchain
-	hash, err := c.evm.CreateOrganization(pk, orgID, []byte(uri), members)
+	hash, err := c.evm.CreateOrganizationCtx(ctx, auth, orgID, []byte(uri), members)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to create organization: %w", err)
 	}
@@ -2,7 +2,7 @@ package sdk
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"errors"
 	"testing"
 	"time"
 
@@ -12,6 +12,16 @@ import (
 	"github.com/shamank/snet-sdk-go/pkg/grpc"
 	"github.com/shamank/snet-sdk-go/pkg/model"
 	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestCoreNewServiceClient(t *testing.T) {
@@ -34,9 +44,15 @@ func TestCoreNewServiceClient(t *testing.T) {
 	}
 
 	grpcClient := &grpc.Client{}
-	cfg := &config.Config{}
+	core, logs := observer.New(zap.DebugLevel)
+	cfg := &config.Config{Logger: zap.New(core)}
 
 	sc := newServiceClient(cfg, nil, orgBC, svcBC, grpcClient, nil)
+	sc.logger().Info("ready")
+	if entries := logs.AllUntimed(); len(entries) != 1 || len(entries[0].Context) != 3 ||
+		entries[0].ContextMap()["org"] != "org" || entries[0].ContextMap()["service"] != "svc" || entries[0].ContextMap()["group"] != "default" {
+		t.Fatalf("log entries = %+v; want the config logger scoped to org, service and group", entries)
+	}
 
 	if sc.GRPC != grpcClient {
 		t.Fatal("expected GRPC client to be set")
@@ -68,20 +84,20 @@ func TestCoreNewServiceClient(t *testing.T) {
 }
 
 type mockStrategyFactory struct {
-	paidFn    func(context.Context, *blockchain.EVMClient, *grpc.Client, *model.ServiceMetadata, *ecdsa.PrivateKey, *model.ServiceGroup, *model.OrganizationGroup) (payment.Strategy, error)
-	prePaidFn func(context.Context, *blockchain.EVMClient, *grpc.Client, common.Address, *model.ServiceGroup, *model.OrganizationGroup, string, uint64) (payment.Strategy, error)
-	freeFn    func(*blockchain.EVMClient, *grpc.Client, string, string, string, *ecdsa.PrivateKey, *uint64) (payment.Strategy, error)
+	paidFn    func(context.Context, *blockchain.EVMClient, *grpc.Client, *model.ServiceMetadata, signer.Signer, *model.ServiceGroup, *model.OrganizationGroup) (payment.Strategy, error)
+	prePaidFn func(context.Context, *blockchain.EVMClient, *grpc.Client, common.Address, *model.ServiceGroup, *model.OrganizationGroup, signer.Signer, uint64) (payment.Strategy, error)
+	freeFn    func(*blockchain.EVMClient, *grpc.Client, string, string, string, signer.Signer, *uint64) (payment.Strategy, error)
 }
 
-func (m *mockStrategyFactory) Paid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, metadata *model.ServiceMetadata, key *ecdsa.PrivateKey, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup) (payment.Strategy, error) {
+func (m *mockStrategyFactory) Paid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, metadata *model.ServiceMetadata, key signer.Signer, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup) (payment.Strategy, error) {
 	return m.paidFn(ctx, evm, grpcCli, metadata, key, serviceGroup, orgGroup)
 }
 
-func (m *mockStrategyFactory) PrePaid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, mpeAddr common.Address, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup, privateKey string, count uint64) (payment.Strategy, error) {
-	return m.prePaidFn(ctx, evm, grpcCli, mpeAddr, serviceGroup, orgGroup, privateKey, count)
+func (m *mockStrategyFactory) PrePaid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, mpeAddr common.Address, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup, key signer.Signer, count uint64) (payment.Strategy, error) {
+	return m.prePaidFn(ctx, evm, grpcCli, mpeAddr, serviceGroup, orgGroup, key, count)
 }
 
-func (m *mockStrategyFactory) Free(evm *blockchain.EVMClient, grpcCli *grpc.Client, orgID, serviceID, groupID string, key *ecdsa.PrivateKey, extend *uint64) (payment.Strategy, error) {
+func (m *mockStrategyFactory) Free(evm *blockchain.EVMClient, grpcCli *grpc.Client, orgID, serviceID, groupID string, key signer.Signer, extend *uint64) (payment.Strategy, error) {
 	return m.freeFn(evm, grpcCli, orgID, serviceID, groupID, key, extend)
 }
 
@@ -112,7 +128,7 @@ func TestServiceClientSetPaidStrategyUsesFactory(t *testing.T) {
 	stub := &stubStrategy{}
 	called := false
 	factory := &mockStrategyFactory{
-		paidFn: func(context.Context, *blockchain.EVMClient, *grpc.Client, *model.ServiceMetadata, *ecdsa.PrivateKey, *model.ServiceGroup, *model.OrganizationGroup) (payment.Strategy, error) {
+		paidFn: func(context.Context, *blockchain.EVMClient, *grpc.Client, *model.ServiceMetadata, signer.Signer, *model.ServiceGroup, *model.OrganizationGroup) (payment.Strategy, error) {
 			called = true
 			return stub, nil
 		},
@@ -125,7 +141,7 @@ func TestServiceClientSetPaidStrategyUsesFactory(t *testing.T) {
 		ServiceMetadata:     &model.ServiceMetadata{},
 		CurrentServiceGroup: &model.ServiceGroup{},
 		CurrentOrgGroup:     &model.OrganizationGroup{},
-		SignerPrivateKey:    nil,
+		Signer:              nil,
 		config:              &config.Config{RPCAddr: "wss://test.example", Timeouts: config.Timeouts{PaymentEnsure: time.Second}},
 	}
 
@@ -140,11 +156,51 @@ func TestServiceClientSetPaidStrategyUsesFactory(t *testing.T) {
 	}
 }
 
+func TestServiceClientSetPaidStrategyContextKeepsCallerDeadline(t *testing.T) {
+	var got time.Time
+	factory := &mockStrategyFactory{
+		paidFn: func(ctx context.Context, _ *blockchain.EVMClient, _ *grpc.Client, _ *model.ServiceMetadata, _ signer.Signer, _ *model.ServiceGroup, _ *model.OrganizationGroup) (payment.Strategy, error) {
+			got, _ = ctx.Deadline()
+			return &stubStrategy{}, nil
+		},
+	}
+
+	sc := &ServiceClient{
+		strategies: factory,
+		config:     &config.Config{RPCAddr: "wss://test.example", Timeouts: config.Timeouts{PaymentEnsure: time.Second}},
+	}
+
+	want := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.Background(), want)
+	defer cancel()
+
+	if err := sc.SetPaidPaymentStrategyContext(ctx); err != nil {
+		t.Fatalf("SetPaidPaymentStrategyContext error: %v", err)
+	}
+	if !got.Equal(want) {
+		t.Fatalf("expected caller deadline %v to win over PaymentEnsure, got %v", want, got)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	ctx, cancel := withTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatal("expected timeout to apply to a context without deadline")
+	}
+
+	ctx, cancel = withTimeout(context.Background(), 0)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("expected no deadline when d <= 0")
+	}
+}
+
 func TestServiceClientSetPrepaidStrategy(t *testing.T) {
 	stub := &stubStrategy{}
 	called := false
 	factory := &mockStrategyFactory{
-		prePaidFn: func(context.Context, *blockchain.EVMClient, *grpc.Client, common.Address, *model.ServiceGroup, *model.OrganizationGroup, string, uint64) (payment.Strategy, error) {
+		prePaidFn: func(context.Context, *blockchain.EVMClient, *grpc.Client, common.Address, *model.ServiceGroup, *model.OrganizationGroup, signer.Signer, uint64) (payment.Strategy, error) {
 			called = true
 			return stub, nil
 		},
@@ -175,22 +231,22 @@ func TestServiceClientSetFreeStrategy(t *testing.T) {
 	stub := &stubStrategy{}
 	called := false
 	factory := &mockStrategyFactory{
-		freeFn: func(*blockchain.EVMClient, *grpc.Client, string, string, string, *ecdsa.PrivateKey, *uint64) (payment.Strategy, error) {
+		freeFn: func(*blockchain.EVMClient, *grpc.Client, string, string, string, signer.Signer, *uint64) (payment.Strategy, error) {
 			called = true
 			return stub, nil
 		},
 	}
 
 	sc := &ServiceClient{
-		EVMClient:        &blockchain.EVMClient{},
-		GRPC:             &grpc.Client{},
-		strategies:       factory,
-		ServiceMetadata:  &model.ServiceMetadata{},
-		CurrentOrgGroup:  &model.OrganizationGroup{},
-		OrgID:            "org",
-		ServiceID:        "svc",
-		config:           &config.Config{Timeouts: config.Timeouts{StrategyRefresh: time.Second}},
-		SignerPrivateKey: nil,
+		EVMClient:       &blockchain.EVMClient{},
+		GRPC:            &grpc.Client{},
+		strategies:      factory,
+		ServiceMetadata: &model.ServiceMetadata{},
+		CurrentOrgGroup: &model.OrganizationGroup{},
+		OrgID:           "org",
+		ServiceID:       "svc",
+		config:          &config.Config{Timeouts: config.Timeouts{StrategyRefresh: time.Second}},
+		Signer:          nil,
 	}
 
 	if err := sc.SetFreePaymentStrategy(); err != nil {
@@ -203,3 +259,267 @@ func TestServiceClientSetFreeStrategy(t *testing.T) {
 		t.Fatal("expected Refresh to be executed")
 	}
 }
+
+type stubReservation struct {
+	committed, rolledBack bool
+}
+
+func (r *stubReservation) Commit()   { r.committed = true }
+func (r *stubReservation) Rollback() { r.rolledBack = true }
+
+type stubReserver struct {
+	stubStrategy
+	last *stubReservation
+	err  error
+}
+
+func (s *stubReserver) Reserve(ctx context.Context) (context.Context, payment.Reservation, error) {
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+	s.last = &stubReservation{}
+	return ctx, s.last, nil
+}
+
+func TestServiceClientPaymentMetadataCommitsOrRollsBack(t *testing.T) {
+	reserver := &stubReserver{}
+	sc := &ServiceClient{strategy: reserver}
+
+	cases := []struct {
+		name     string
+		err      error
+		rollback bool
+	}{
+		{"success", nil, false},
+		{"daemon error", status.Error(codes.PermissionDenied, "payment rejected"), false},
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), true},
+		{"local error", errors.New("method not found"), true},
+	}
+	for _, tc := range cases {
+		_, done, err := sc.paymentMetadata(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		done(tc.err)
+		if reserver.last.rolledBack != tc.rollback || reserver.last.committed == tc.rollback {
+			t.Fatalf("%s: committed=%v rolledBack=%v", tc.name, reserver.last.committed, reserver.last.rolledBack)
+		}
+	}
+}
+
+func TestServiceClientFailsCallWhosePaymentCannotBeReserved(t *testing.T) {
+	errSign := errors.New("signer is required")
+	sc := &ServiceClient{strategy: &stubReserver{err: errSign}}
+	called := false
+	err := sc.invokeOnce(context.Background(), func(context.Context, *grpc.Client) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, errSign) || called {
+		t.Fatalf("invokeOnce = %v, called = %v; want the reservation error before any call", err, called)
+	}
+}
+
+type escrowReserver struct {
+	stubReserver
+}
+
+func (s *escrowReserver) Reserve(ctx context.Context) (context.Context, payment.Reservation, error) {
+	ctx, r, err := s.stubReserver.Reserve(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return metadata.AppendToOutgoingContext(ctx, payment.PaymentTypeHeader, "escrow"), r, nil
+}
+
+func TestServiceClientTracesCallAndPayment(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	cfg := &config.Config{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))}
+	orgBC := &blockchain.OrgClient{OrganizationMetaData: &model.OrganizationMetaData{OrgID: "org"}}
+	svcBC := &blockchain.ServiceClient{ServiceID: "svc", CurrentGroup: &model.ServiceGroup{GroupName: "default"}}
+	sc := newServiceClient(cfg, nil, orgBC, svcBC, nil, nil)
+	sc.strategy = &escrowReserver{}
+
+	ctx, end := sc.startCall(context.Background(), "add")
+	if err := sc.refresh(ctx, sc.strategy); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	paid, done, err := sc.paymentMetadata(ctx)
+	if err != nil {
+		t.Fatalf("paymentMetadata: %v", err)
+	}
+	if !trace.SpanFromContext(paid).SpanContext().Equal(trace.SpanFromContext(ctx).SpanContext()) {
+		t.Fatal("the call does not continue under the call span")
+	}
+	done(nil)
+	end(nil)
+
+	ended := spans.Ended()
+	names := make([]string, len(ended))
+	for i, span := range ended {
+		names[i] = span.Name()
+	}
+	if len(ended) != 3 || names[0] != "payment.Refresh" || names[1] != "payment.Reserve" || names[2] != "sdk.Call" {
+		t.Fatalf("spans = %v; want payment.Refresh, payment.Reserve, sdk.Call", names)
+	}
+	call := ended[2]
+	for _, span := range ended[:2] {
+		if span.Parent().SpanID() != call.SpanContext().SpanID() {
+			t.Fatalf("%s is not a child of sdk.Call", span.Name())
+		}
+	}
+	attrs := make(map[string]string)
+	for _, kv := range call.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	want := map[string]string{
+		string(telemetry.OrgKey):         "org",
+		string(telemetry.ServiceKey):     "svc",
+		string(telemetry.GroupKey):       "default",
+		string(telemetry.MethodKey):      "add",
+		string(telemetry.PaymentTypeKey): "escrow",
+	}
+	for k, v := range want {
+		if attrs[k] != v {
+			t.Fatalf("sdk.Call attributes = %v; want %s=%s", attrs, k, v)
+		}
+	}
+}
+
+func TestAsiToAasi(t *testing.T) {
+	tests := []struct {
+		name    string
+		asi     any
+		want    string
+		wantErr bool
+	}{
+		{"string", "1.5", "1500000000000000000", false},
+		{"int64", int64(2), "2000000000000000000", false},
+		{"float64", 0.25, "250000000000000000", false},
+		{"zero", "0", "", true},
+		{"negative", int64(-1), "", true},
+		{"below one aasi", "0.0000000000000000001", "", true},
+		{"not a number", "ten", "", true},
+		{"int", 5, "5000000000000000000", false},
+		{"uint8", uint8(3), "3000000000000000000", false},
+		{"large uint64", uint64(1) << 63, "9223372036854775808000000000000000000", false},
+		{"unsupported type", true, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := asiToAasi(tt.asi)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("asiToAasi(%v) = %s; want error", tt.asi, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("asiToAasi(%v): %v", tt.asi, err)
+			}
+			if got.String() != tt.want {
+				t.Fatalf("asiToAasi(%v) = %s; want %s", tt.asi, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSDKReturnsConfigError(t *testing.T) {
+	sdk, err := NewSDK(&config.Config{})
+	if err == nil {
+		t.Fatal("expected error for a config without RPC address")
+	}
+	if sdk != nil {
+		t.Fatalf("NewSDK returned %v with error %v", sdk, err)
+	}
+}
+
+func TestCallErrorClassifiesDaemonStatus(t *testing.T) {
+	err := error(newCallError("/svc/Method", false, status.Error(codes.Unauthenticated, "payment channel \"1\" is near to be expired")))
+
+	if !errors.Is(err, ErrChannelExpired) {
+		t.Fatalf("errors.Is(%v, ErrChannelExpired) = false", err)
+	}
+	var callErr *CallError
+	if !errors.As(err, &callErr) || callErr.Method != "/svc/Method" {
+		t.Fatalf("errors.As(%v, *CallError) failed", err)
+	}
+	var daemonErr *payment.DaemonError
+	if !errors.As(err, &daemonErr) || daemonErr.Code != codes.Unauthenticated {
+		t.Fatalf("errors.As(%v, *payment.DaemonError) failed", err)
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("status.Code = %s, want Unauthenticated", status.Code(err))
+	}
+
+	local := errors.New("method not found in provided proto files")
+	if err := newCallError("/svc/Missing", false, local); !errors.Is(err, local) {
+		t.Fatalf("local error not wrapped: %v", err)
+	}
+}
+
+type freeCounterStub struct {
+	stubStrategy
+	available uint64
+}
+
+func (s *freeCounterStub) GetFreeCallsAvailable(context.Context) (uint64, error) {
+	return s.available, nil
+}
+
+func TestServiceClientRetriesWithFallbackWhenFreeCallsRunOut(t *testing.T) {
+	paid := &stubStrategy{}
+	fallback := payment.NewFallbackStrategy(&freeCounterStub{available: 10}, func(context.Context) (payment.Strategy, error) {
+		return paid, nil
+	})
+	if err := fallback.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	sc := &ServiceClient{strategy: fallback}
+
+	calls := 0
+	err := sc.invokePaid(context.Background(), func(context.Context, *grpc.Client) error {
+		calls++
+		if calls == 1 {
+			return status.Error(codes.Unauthenticated, "free call limit has been exceeded, calls made = 10, total free calls eligible = 10")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("invokePaid: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("call attempted %d times; want 2", calls)
+	}
+	if fallback.UsingFree() {
+		t.Fatal("strategy still uses free calls after the daemon reported them exhausted")
+	}
+}
+
+func TestServiceClientGetPrePaidUsage(t *testing.T) {
+	sc := &ServiceClient{strategy: &stubStrategy{}}
+	if _, err := sc.GetPrePaidUsage(); err == nil {
+		t.Fatal("expected an error for a non-prepaid strategy")
+	}
+
+	fallback := payment.NewFallbackStrategy(&freeCounterStub{}, func(context.Context) (payment.Strategy, error) {
+		return &payment.PrepaidStrategy{}, nil
+	})
+	if err := fallback.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	sc = &ServiceClient{strategy: fallback}
+	usage, err := sc.GetPrePaidUsage()
+	if err != nil {
+		t.Fatalf("GetPrePaidUsage through fallback: %v", err)
+	}
+	if usage.Remaining.Sign() != 0 || usage.RemainingCalls != 0 {
+		t.Fatalf("usage before the first token = %+v; want zero", usage)
+	}
+}
+
+func TestImportLeavesGlobalLoggerAlone(t *testing.T) {
+	if zap.L().Core().Enabled(zap.ErrorLevel) {
+		t.Fatal("the global zap logger was replaced on import")
+	}
+}
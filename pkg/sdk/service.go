@@ -2,11 +2,11 @@ package sdk
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -15,31 +15,42 @@ import (
 	"github.com/shamank/snet-sdk-go/pkg/grpc"
 	"github.com/shamank/snet-sdk-go/pkg/model"
 	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
 	"github.com/shamank/snet-sdk-go/pkg/training"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
 // paymentStrategyFactory constructs payment strategies for the service client.
 // Test doubles can implement this interface to intercept strategy creation.
 type paymentStrategyFactory interface {
-	Paid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, metadata *model.ServiceMetadata, key *ecdsa.PrivateKey, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup) (payment.Strategy, error)
-	PrePaid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, mpeAddr common.Address, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup, privateKey string, count uint64) (payment.Strategy, error)
-	Free(evm *blockchain.EVMClient, grpcCli *grpc.Client, orgID, serviceID, groupID string, key *ecdsa.PrivateKey, extend *uint64) (payment.Strategy, error)
+	Paid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, metadata *model.ServiceMetadata, key signer.Signer, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup) (payment.Strategy, error)
+	PrePaid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, mpeAddr common.Address, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup, key signer.Signer, count uint64) (payment.Strategy, error)
+	Free(evm *blockchain.EVMClient, grpcCli *grpc.Client, orgID, serviceID, groupID string, key signer.Signer, extend *uint64) (payment.Strategy, error)
 }
 
-// defaultStrategyFactory provides the production constructors for payment strategies.
-type defaultStrategyFactory struct{}
+// defaultStrategyFactory provides the production constructors for payment
+// strategies. Paid and prepaid strategies persist channel state in store, and
+// all strategies log to log.
+type defaultStrategyFactory struct {
+	store payment.ChannelStateStore
+	log   *zap.Logger
+}
 
-func (defaultStrategyFactory) Paid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, metadata *model.ServiceMetadata, key *ecdsa.PrivateKey, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup) (payment.Strategy, error) {
-	return payment.NewPaidStrategy(ctx, evm, grpcCli, metadata, key, serviceGroup, orgGroup)
+func (f defaultStrategyFactory) Paid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, metadata *model.ServiceMetadata, key signer.Signer, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup) (payment.Strategy, error) {
+	return payment.NewPaidStrategy(ctx, evm, grpcCli, metadata, key, serviceGroup, orgGroup, payment.WithChannelStateStore(f.store), payment.WithPaidLogger(f.log))
 }
 
-func (defaultStrategyFactory) PrePaid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, mpeAddr common.Address, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup, privateKey string, count uint64) (payment.Strategy, error) {
-	return payment.NewPrePaidStrategy(ctx, evm, grpcCli, mpeAddr, serviceGroup, orgGroup, privateKey, count)
+func (f defaultStrategyFactory) PrePaid(ctx context.Context, evm *blockchain.EVMClient, grpcCli *grpc.Client, mpeAddr common.Address, serviceGroup *model.ServiceGroup, orgGroup *model.OrganizationGroup, key signer.Signer, count uint64) (payment.Strategy, error) {
+	return payment.NewPrePaidStrategy(ctx, evm, grpcCli, mpeAddr, serviceGroup, orgGroup, key, count, payment.WithPrepaidChannelStateStore(f.store), payment.WithPrepaidLogger(f.log))
 }
 
-func (defaultStrategyFactory) Free(evm *blockchain.EVMClient, grpcCli *grpc.Client, orgID, serviceID, groupID string, key *ecdsa.PrivateKey, extend *uint64) (payment.Strategy, error) {
-	return payment.NewFreeStrategy(evm, grpcCli, orgID, serviceID, groupID, key, extend)
+func (f defaultStrategyFactory) Free(evm *blockchain.EVMClient, grpcCli *grpc.Client, orgID, serviceID, groupID string, key signer.Signer, extend *uint64) (payment.Strategy, error) {
+	return payment.NewFreeStrategy(evm, grpcCli, orgID, serviceID, groupID, key, extend, payment.WithFreeLogger(f.log))
 }
 
 // Service defines the high-level client API for invoking service methods and
@@ -57,23 +68,87 @@ type Service interface {
 	// CallWithProto calls a service method using a concrete protobuf message
 	// for the request and returns the protobuf response.
 	CallWithProto(method string, input proto.Message) (proto.Message, error)
+	// CallWithMapContext is like CallWithMap but runs under ctx. The GRPCUnary
+	// timeout applies only when ctx has no deadline of its own.
+	CallWithMapContext(ctx context.Context, method string, params map[string]any) (map[string]any, error)
+	// CallWithJSONContext is like CallWithJSON but runs under ctx.
+	CallWithJSONContext(ctx context.Context, method string, input []byte) ([]byte, error)
+	// CallWithProtoContext is like CallWithProto but runs under ctx.
+	CallWithProtoContext(ctx context.Context, method string, input proto.Message) (proto.Message, error)
+	// NewStream opens a client-, server- or bidirectional-streaming call.
+	// Payment metadata is attached once, when the stream is opened, and the
+	// stream is bounded by the GRPCStream timeout.
+	NewStream(method string) (*grpc.Stream, error)
+	// StreamWithJSON opens a server-streaming call with a single JSON request
+	// and returns the stream to read responses from.
+	StreamWithJSON(method string, input []byte) (*grpc.Stream, error)
+	// StreamWithMap opens a server-streaming call with a single map-based
+	// request and returns the stream to read responses from.
+	StreamWithMap(method string, params map[string]any) (*grpc.Stream, error)
+	// StreamWithProto opens a server-streaming call with a single protobuf
+	// request and returns the stream to read responses from.
+	StreamWithProto(method string, input proto.Message) (*grpc.Stream, error)
+	// NewStreamContext is like NewStream but derives the stream context from
+	// ctx. The GRPCStream timeout applies only when ctx has no deadline.
+	NewStreamContext(ctx context.Context, method string) (*grpc.Stream, error)
+	// StreamWithJSONContext is like StreamWithJSON but derives the stream context from ctx.
+	StreamWithJSONContext(ctx context.Context, method string, input []byte) (*grpc.Stream, error)
+	// StreamWithMapContext is like StreamWithMap but derives the stream context from ctx.
+	StreamWithMapContext(ctx context.Context, method string, params map[string]any) (*grpc.Stream, error)
+	// StreamWithProtoContext is like StreamWithProto but derives the stream context from ctx.
+	StreamWithProtoContext(ctx context.Context, method string, input proto.Message) (*grpc.Stream, error)
 	// SetPaidPaymentStrategy configures the escrow (MPE) strategy. It ensures
 	// there is a usable payment channel and prepares signatures for subsequent
 	// calls. Requires a valid signer private key.
 	SetPaidPaymentStrategy() error
+	// SetPaidPaymentStrategyContext is like SetPaidPaymentStrategy but runs
+	// the channel setup under ctx.
+	SetPaidPaymentStrategyContext(ctx context.Context) error
 	// SetPrePaidPaymentStrategy configures the prepaid strategy. It prepares an
 	// allowance based on call count and obtains tokens on Refresh. Requires a
 	// valid signer private key in the SDK config.
 	SetPrePaidPaymentStrategy(count uint64) error
+	// SetPrePaidPaymentStrategyContext is like SetPrePaidPaymentStrategy but
+	// runs the channel setup and token refresh under ctx.
+	SetPrePaidPaymentStrategyContext(ctx context.Context, count uint64) error
 
 	// SetFreePaymentStrategy configures the free-call strategy and obtains a
 	// short-lived free-call token on Refresh. Optional extendBlocks controls
 	// token lifetime in blocks (daemon-dependent).
 	SetFreePaymentStrategy(extendBlocks ...uint64) error
+	// SetFreePaymentStrategyContext is like SetFreePaymentStrategy but
+	// fetches the free-call token under ctx.
+	SetFreePaymentStrategyContext(ctx context.Context, extendBlocks ...uint64) error
+
+	// SetFreeWithPaidFallbackStrategy uses free calls while any are left and
+	// switches to the escrow strategy when they run out, and back to free
+	// calls when the daemon grants new ones.
+	SetFreeWithPaidFallbackStrategy() error
+	// SetFreeWithPaidFallbackStrategyContext is like
+	// SetFreeWithPaidFallbackStrategy but runs the setup under ctx.
+	SetFreeWithPaidFallbackStrategyContext(ctx context.Context) error
+	// SetFreeWithPrePaidFallbackStrategy is like SetFreeWithPaidFallbackStrategy
+	// but falls back to a prepaid strategy provisioned for count calls.
+	SetFreeWithPrePaidFallbackStrategy(count uint64) error
+	// SetFreeWithPrePaidFallbackStrategyContext is like
+	// SetFreeWithPrePaidFallbackStrategy but runs the setup under ctx.
+	SetFreeWithPrePaidFallbackStrategyContext(ctx context.Context, count uint64) error
 
 	// GetFreeCallsAvailable returns the remaining number of free calls for the
 	// current user/token.
 	GetFreeCallsAvailable() (uint64, error)
+	// GetFreeCallsAvailableContext is like GetFreeCallsAvailable but queries
+	// the daemon under ctx.
+	GetFreeCallsAvailableContext(ctx context.Context) (uint64, error)
+
+	// GetPrePaidUsage returns the planned, used and remaining amounts and the
+	// expiration of the prepaid token.
+	GetPrePaidUsage() (payment.PrepaidUsage, error)
+	// RenewPrePaidToken signs another batch of prepaid calls and renews the
+	// prepaid token.
+	RenewPrePaidToken() error
+	// RenewPrePaidTokenContext is like RenewPrePaidToken but runs under ctx.
+	RenewPrePaidTokenContext(ctx context.Context) error
 
 	// ProtoFiles returns a proto file manager for this service
 	ProtoFiles() grpc.ProtoManager
@@ -87,6 +162,17 @@ type Service interface {
 	// Healthcheck performs a simple health check against the service daemon
 	Healthcheck() Healthcheck
 
+	// Endpoints returns the health of every endpoint of the service group
+	Endpoints() []grpc.EndpointStatus
+
+	// CheckEndpoints probes every endpoint of the service group, marking
+	// failing endpoints as bad and restoring recovered ones
+	CheckEndpoints(ctx context.Context) []grpc.EndpointStatus
+
+	// ChannelManager returns a manager to list, extend, fund and reclaim the
+	// signer's MPE payment channels
+	ChannelManager() (*blockchain.ChannelManager, error)
+
 	// GetCurrentOrgGroup returns the current organization group
 	GetCurrentOrgGroup() *model.OrganizationGroup
 
@@ -101,9 +187,20 @@ type Service interface {
 
 	// UpdateServiceMetadata updates the service metadata (uploads to IPFS and updates blockchain)
 	UpdateServiceMetadata(metadata *model.ServiceMetadata) (common.Hash, error)
+	// UpdateServiceMetadataContext is like UpdateServiceMetadata but uploads
+	// and submits the transaction under ctx.
+	UpdateServiceMetadataContext(ctx context.Context, metadata *model.ServiceMetadata) (common.Hash, error)
+	// ValidateServiceMetadata checks new metadata against the organization,
+	// the network and its protos without publishing anything.
+	ValidateServiceMetadata(metadata *model.ServiceMetadata, opts ...ValidateOption) error
+	// ValidateServiceMetadataContext is like ValidateServiceMetadata but reads
+	// the protos and probes under ctx.
+	ValidateServiceMetadataContext(ctx context.Context, metadata *model.ServiceMetadata, opts ...ValidateOption) error
 
 	// DeleteService deletes the service registration from blockchain
 	DeleteService() (common.Hash, error)
+	// DeleteServiceContext is like DeleteService but submits the transaction under ctx.
+	DeleteServiceContext(ctx context.Context) (common.Hash, error)
 
 	// RawGrpc returns direct access to the gRPC client (advanced usage)
 	RawGrpc() *grpc.Client
@@ -120,6 +217,8 @@ type Service interface {
 type ServiceClient struct {
 	*blockchain.EVMClient
 	GRPC                *grpc.Client
+	strategyMu          sync.RWMutex // Guards strategy
+	strategyInit        sync.Mutex   // Serializes automatic strategy selection
 	strategy            payment.Strategy
 	config              *config.Config
 	org                 Organization
@@ -132,9 +231,13 @@ type ServiceClient struct {
 	OrgMetadata         *model.OrganizationMetaData
 	ServiceMetadata     *model.ServiceMetadata
 	CurrentOrgGroup     *model.OrganizationGroup
-	SignerPrivateKey    *ecdsa.PrivateKey
+	Signer              signer.Signer
 	trainingClient      training.Client
 	strategies          paymentStrategyFactory
+	endpoints           *grpc.Pool           // All endpoints of the service group; nil for single-endpoint clients
+	stopProbes          context.CancelFunc   // Stops background endpoint probes
+	log                 *zap.Logger          // Logger scoped to the org, service and group
+	tel                 *telemetry.Telemetry // Telemetry scoped to the org, service and group
 }
 
 // newServiceClient wires together the runtime-facing ServiceClient wrapper using
@@ -146,7 +249,7 @@ func newServiceClient(
 	orgBC *blockchain.OrgClient,
 	svcBC *blockchain.ServiceClient,
 	grpcClient *grpc.Client,
-	signer *ecdsa.PrivateKey,
+	auth signer.Signer,
 ) *ServiceClient {
 	sc := &ServiceClient{
 		EVMClient:           nil,
@@ -163,7 +266,7 @@ func newServiceClient(
 		OrgMetadata:         nil,
 		ServiceMetadata:     nil,
 		CurrentOrgGroup:     nil,
-		SignerPrivateKey:    signer,
+		Signer:              auth,
 		trainingClient:      nil,
 	}
 
@@ -183,13 +286,31 @@ func newServiceClient(
 		}
 	}
 
+	fields := []zap.Field{zap.String("org", sc.OrgID), zap.String("service", sc.ServiceID)}
+	attrs := []attribute.KeyValue{telemetry.OrgKey.String(sc.OrgID), telemetry.ServiceKey.String(sc.ServiceID)}
+	if sc.CurrentServiceGroup != nil {
+		fields = append(fields, zap.String("group", sc.CurrentServiceGroup.GroupName))
+		attrs = append(attrs, telemetry.GroupKey.String(sc.CurrentServiceGroup.GroupName))
+	}
+	sc.log = configLogger(cfg).With(fields...)
+	sc.tel = configTelemetry(cfg).With(attrs...)
+
 	return sc
 }
 
+// logger returns the logger of the service client, falling back to the
+// configured one for clients not built by newServiceClient.
+func (s *ServiceClient) logger() *zap.Logger {
+	if s.log != nil {
+		return s.log
+	}
+	return configLogger(s.config)
+}
+
 // strategyFactory returns the strategy factory to use, defaulting to production constructors.
 func (s *ServiceClient) strategyFactory() paymentStrategyFactory {
 	if s.strategies == nil {
-		s.strategies = defaultStrategyFactory{}
+		s.strategies = defaultStrategyFactory{store: channelStateStore(s.config), log: s.logger()}
 	}
 	return s.strategies
 }
@@ -206,39 +327,18 @@ func (s *ServiceClient) getBlockchainClient() *blockchain.ServiceClient {
 // NewServiceClient builds a Service client from organization and blockchain service.
 // It creates a gRPC connection to the service endpoint and prepares for RPC calls.
 func (c *Core) NewServiceClient(orgID, serviceID, groupName string) (Service, error) {
+	return c.NewServiceClientContext(context.Background(), orgID, serviceID, groupName)
+}
 
-	orgClient, err := c.NewOrganizationClient(orgID, groupName)
-	if err != nil {
-		return nil, err
-	}
-
-	serviceClient, err := orgClient.ServiceClient(serviceID, groupName)
+// NewServiceClientContext is like NewServiceClient but reads the registry,
+// metadata and protos under ctx.
+func (c *Core) NewServiceClientContext(ctx context.Context, orgID, serviceID, groupName string) (Service, error) {
+	orgClient, err := c.NewOrganizationClientContext(ctx, orgID, groupName)
 	if err != nil {
 		return nil, err
 	}
 
-	if serviceClient == nil {
-		return nil, fmt.Errorf("blockchain service client is required")
-	}
-
-	if len(serviceClient.GetCurrentServiceGroup().Endpoints) == 0 {
-		return nil, fmt.Errorf("no endpoints available for service group %s",
-			serviceClient.GetCurrentServiceGroup().GroupName)
-	}
-
-	// Create gRPC client to the first endpoint
-	// TODO: endpoint selection strategy (currently takes the first endpoint)
-	endpoint := serviceClient.GetCurrentServiceGroup().Endpoints[0]
-	grpcClient := grpc.NewClient(endpoint, serviceClient.ProtoFiles().Get())
-
-	return newServiceClient(
-		c.Config,
-		orgClient,
-		orgClient.getBlockchainClient(),
-		serviceClient.getBlockchainClient(),
-		grpcClient,
-		c.prvKey,
-	), nil
+	return orgClient.ServiceClientContext(ctx, serviceID, groupName)
 }
 
 // Organization returns the organization this service belongs to
@@ -285,9 +385,10 @@ func (s *ServiceClient) Healthcheck() Healthcheck {
 		group = s.srvClient.CurrentGroup
 	}
 	return newHealthcheckClient(
-		s.grpcClient,
+		s.currentClient(),
 		group,
 		s.config,
+		s.logger(),
 	)
 }
 
@@ -304,21 +405,22 @@ func (s *ServiceClient) Training() training.Client {
 			blockNumber = s.EVMClient.GetCurrentBlockNumber
 		}
 
-		var priv *ecdsa.PrivateKey
-		if s.config != nil {
-			priv = s.config.GetPrivateKey()
+		auth := s.Signer
+		if auth == nil && s.config != nil {
+			auth, _ = s.config.GetSigner()
 		}
 
 		s.trainingClient = training.NewTrainingClient(
 			s.OrgID,
 			s.ServiceID,
 			s.CurrentServiceGroup.GroupName,
-			s.grpcClient,
-			priv,
+			s.currentClient(),
+			auth,
 			s.config.Timeouts.GRPCUnary,
 			s.config.Timeouts.GRPCStream,
 			blockNumber,
-			s.strategy,
+			s.currentStrategy(),
+			training.WithLogger(s.logger()),
 		)
 	}
 	return s.trainingClient
@@ -328,78 +430,200 @@ func (s *ServiceClient) Training() training.Client {
 // ensures a valid channel (funds/expiration). It does not perform a Refresh
 // because escrow calls sign per-request.
 func (s *ServiceClient) SetPaidPaymentStrategy() error {
+	return s.SetPaidPaymentStrategyContext(context.Background())
+}
+
+// SetPaidPaymentStrategyContext is like SetPaidPaymentStrategy but runs the
+// channel setup under ctx. The PaymentEnsure timeout applies only when ctx has
+// no deadline.
+func (s *ServiceClient) SetPaidPaymentStrategyContext(ctx context.Context) error {
 	if err := s.validateWebSocketRPC(); err != nil {
 		return err
 	}
 
-	ctx, cancel := s.withTimeout(context.Background(), s.ensureTimeout())
+	ctx, cancel := withTimeout(ctx, s.ensureTimeout())
 	defer cancel()
 
+	strategy, err := s.newPaidStrategy(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.setStrategy(strategy)
+	return nil
+}
+
+// newPaidStrategy builds the escrow strategy, ensuring a usable channel.
+func (s *ServiceClient) newPaidStrategy(ctx context.Context) (payment.Strategy, error) {
+	auth, err := s.config.GetSigner()
+	if err != nil {
+		return nil, err
+	}
 	strategy, err := s.strategyFactory().Paid(
 		ctx,
 		s.EVMClient,
-		s.GRPC,
+		s.currentClient(),
 		s.ServiceMetadata,
-		s.config.GetPrivateKey(),
+		auth,
 		s.CurrentServiceGroup,
 		s.CurrentOrgGroup,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create paid strategy: %w", err)
+		return nil, fmt.Errorf("failed to create paid strategy: %w", err)
 	}
-
-	s.strategy = strategy
-	return nil
+	return strategy, nil
 }
 
 // SetPrePaidPaymentStrategy initializes the prepaid strategy and immediately
 // refreshes the daemon-issued token. The count parameter indicates the number
 // of calls to provision in the initial signed allowance.
 func (s *ServiceClient) SetPrePaidPaymentStrategy(count uint64) error {
+	return s.SetPrePaidPaymentStrategyContext(context.Background(), count)
+}
+
+// SetPrePaidPaymentStrategyContext is like SetPrePaidPaymentStrategy but runs
+// the channel setup and token refresh under ctx.
+func (s *ServiceClient) SetPrePaidPaymentStrategyContext(ctx context.Context, count uint64) error {
 	if err := s.validateWebSocketRPC(); err != nil {
 		return err
 	}
 
-	ctx, cancel := s.withTimeout(context.Background(), s.ensureTimeout())
+	ctx, cancel := withTimeout(ctx, s.ensureTimeout())
 	defer cancel()
 
-	strategy, err := s.strategyFactory().PrePaid(ctx, s.EVMClient, s.GRPC, s.ServiceMetadata.GetMpeAddr(), s.CurrentServiceGroup, s.CurrentOrgGroup, s.config.PrivateKey, count)
+	strategy, err := s.newPrePaidStrategy(ctx, count)
 	if err != nil {
-		return fmt.Errorf("failed to create prepaid strategy: %w", err)
+		return err
 	}
 
-	s.strategy = strategy
+	s.setStrategy(strategy)
 
-	if err := s.strategy.Refresh(ctx); err != nil {
+	if err := s.refresh(ctx, strategy); err != nil {
 		return fmt.Errorf("failed to refresh prepaid strategy: %w", err)
 	}
 
 	return nil
 }
 
+// newPrePaidStrategy builds the prepaid strategy for count calls without
+// refreshing its token.
+func (s *ServiceClient) newPrePaidStrategy(ctx context.Context, count uint64) (payment.Strategy, error) {
+	auth, err := s.config.GetSigner()
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := s.strategyFactory().PrePaid(ctx, s.EVMClient, s.currentClient(), s.ServiceMetadata.GetMpeAddr(), s.CurrentServiceGroup, s.CurrentOrgGroup, auth, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prepaid strategy: %w", err)
+	}
+	return strategy, nil
+}
+
 // SetFreePaymentStrategy initializes the free-call strategy and fetches a
 // short-lived token. If extendBlocks is provided, it is forwarded to request a
 // custom token lifetime (daemon may ignore or cap it).
 func (s *ServiceClient) SetFreePaymentStrategy(extendBlocks ...uint64) error {
-	strategy, err := s.strategyFactory().Free(s.EVMClient, s.GRPC, s.OrgID, s.ServiceID, s.CurrentOrgGroup.ID, s.SignerPrivateKey, optionalUint64(extendBlocks...))
+	return s.SetFreePaymentStrategyContext(context.Background(), extendBlocks...)
+}
+
+// SetFreePaymentStrategyContext is like SetFreePaymentStrategy but fetches the
+// free-call token under ctx.
+func (s *ServiceClient) SetFreePaymentStrategyContext(ctx context.Context, extendBlocks ...uint64) error {
+	strategy, err := s.strategyFactory().Free(s.EVMClient, s.currentClient(), s.OrgID, s.ServiceID, s.CurrentOrgGroup.ID, s.Signer, optionalUint64(extendBlocks...))
 	if err != nil {
 		return err
 	}
-	s.strategy = strategy
-	ctx, cancel := s.withTimeout(context.Background(), s.config.Timeouts.StrategyRefresh)
+	s.setStrategy(strategy)
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.StrategyRefresh)
+	defer cancel()
+	return s.refresh(ctx, strategy)
+}
+
+// SetFreeWithPaidFallbackStrategy uses free calls while the daemon reports
+// some left and switches to the escrow (MPE) strategy once they run out,
+// without failing the call that hits the limit. The payment channel is only
+// opened when the first paid call is needed. While paying, the client
+// periodically checks for new free calls and switches back to them.
+func (s *ServiceClient) SetFreeWithPaidFallbackStrategy() error {
+	return s.SetFreeWithPaidFallbackStrategyContext(context.Background())
+}
+
+// SetFreeWithPaidFallbackStrategyContext is like SetFreeWithPaidFallbackStrategy
+// but fetches the free-call token (and, if no free calls are left, sets up the
+// channel) under ctx.
+func (s *ServiceClient) SetFreeWithPaidFallbackStrategyContext(ctx context.Context) error {
+	return s.setFallbackStrategy(ctx, s.newPaidStrategy)
+}
+
+// SetFreeWithPrePaidFallbackStrategy is like SetFreeWithPaidFallbackStrategy
+// but falls back to a prepaid strategy provisioned for count calls.
+func (s *ServiceClient) SetFreeWithPrePaidFallbackStrategy(count uint64) error {
+	return s.SetFreeWithPrePaidFallbackStrategyContext(context.Background(), count)
+}
+
+// SetFreeWithPrePaidFallbackStrategyContext is like
+// SetFreeWithPrePaidFallbackStrategy but runs the setup under ctx.
+func (s *ServiceClient) SetFreeWithPrePaidFallbackStrategyContext(ctx context.Context, count uint64) error {
+	return s.setFallbackStrategy(ctx, func(ctx context.Context) (payment.Strategy, error) {
+		strategy, err := s.newPrePaidStrategy(ctx, count)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.refresh(ctx, strategy); err != nil {
+			return nil, fmt.Errorf("failed to refresh prepaid strategy: %w", err)
+		}
+		return strategy, nil
+	})
+}
+
+// setFallbackStrategy installs a payment.FallbackStrategy that pays with free
+// calls first and with the strategy built by newFallback afterwards. The
+// fallback is built under the PaymentEnsure timeout, detached from the
+// deadline of the call that triggers it.
+func (s *ServiceClient) setFallbackStrategy(ctx context.Context, newFallback func(context.Context) (payment.Strategy, error)) error {
+	strategy, err := s.strategyFactory().Free(s.EVMClient, s.currentClient(), s.OrgID, s.ServiceID, s.CurrentOrgGroup.ID, s.Signer, nil)
+	if err != nil {
+		return err
+	}
+	free, ok := strategy.(payment.FreeCallCounter)
+	if !ok {
+		return fmt.Errorf("free strategy %T does not report free calls available", strategy)
+	}
+
+	fallback := payment.NewFallbackStrategy(free, func(ctx context.Context) (payment.Strategy, error) {
+		if err := s.validateWebSocketRPC(); err != nil {
+			return nil, err
+		}
+		ctx, cancel := withTimeout(context.WithoutCancel(ctx), s.ensureTimeout())
+		defer cancel()
+		return newFallback(ctx)
+	}, payment.WithFallbackLogger(s.logger()))
+
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.StrategyRefresh)
 	defer cancel()
-	return strategy.Refresh(ctx)
+	if err := s.refresh(ctx, fallback); err != nil {
+		return err
+	}
+	s.setStrategy(fallback)
+	return nil
 }
 
 // GetFreeCallsAvailable returns the number of remaining free calls for the
-// current user/token. It requires the active strategy to be FreeStrategy.
+// current user/token. It requires the active strategy to be FreeStrategy or
+// a free-call fallback strategy.
 func (s *ServiceClient) GetFreeCallsAvailable() (uint64, error) {
-	freeStrat, ok := s.strategy.(*payment.FreeStrategy)
+	return s.GetFreeCallsAvailableContext(context.Background())
+}
+
+// GetFreeCallsAvailableContext is like GetFreeCallsAvailable but queries the
+// daemon under ctx.
+func (s *ServiceClient) GetFreeCallsAvailableContext(ctx context.Context) (uint64, error) {
+	freeStrat, ok := s.currentStrategy().(payment.FreeCallCounter)
 	if !ok {
 		return 0, errors.New("current strategy is not FreeStrategy")
 	}
 
-	ctx, cancel := s.withTimeout(context.Background(), s.config.Timeouts.StrategyRefresh)
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.StrategyRefresh)
 	defer cancel()
 
 	available, err := freeStrat.GetFreeCallsAvailable(ctx)
@@ -410,23 +634,86 @@ func (s *ServiceClient) GetFreeCallsAvailable() (uint64, error) {
 	return available, nil
 }
 
+// GetPrePaidUsage returns the prepaid balance of the current token: the
+// planned and used amounts, the calls left and the token expiration. It
+// requires the active strategy to be a prepaid strategy, or a free-call
+// fallback strategy that has switched to one.
+func (s *ServiceClient) GetPrePaidUsage() (payment.PrepaidUsage, error) {
+	prepaid := s.prepaidStrategy()
+	if prepaid == nil {
+		return payment.PrepaidUsage{}, errors.New("current strategy is not PrepaidStrategy")
+	}
+	return prepaid.Usage(), nil
+}
+
+// RenewPrePaidToken signs another batch of the call count given to
+// SetPrePaidPaymentStrategy and requests a new prepaid token. Calls renew the
+// token automatically before it runs out or expires; use this to top up
+// ahead of a burst of calls.
+func (s *ServiceClient) RenewPrePaidToken() error {
+	return s.RenewPrePaidTokenContext(context.Background())
+}
+
+// RenewPrePaidTokenContext is like RenewPrePaidToken but runs under ctx. The
+// StrategyRefresh timeout applies only when ctx has no deadline.
+func (s *ServiceClient) RenewPrePaidTokenContext(ctx context.Context) error {
+	prepaid := s.prepaidStrategy()
+	if prepaid == nil {
+		return errors.New("current strategy is not PrepaidStrategy")
+	}
+
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.StrategyRefresh)
+	defer cancel()
+
+	if err := prepaid.Renew(ctx); err != nil {
+		return fmt.Errorf("failed to renew prepaid token: %w", err)
+	}
+	return nil
+}
+
+// prepaidStrategy returns the active prepaid strategy, looking through a
+// fallback strategy, or nil.
+func (s *ServiceClient) prepaidStrategy() *payment.PrepaidStrategy {
+	strategy := s.currentStrategy()
+	if fallback, ok := strategy.(*payment.FallbackStrategy); ok {
+		strategy = fallback.Fallback()
+	}
+	prepaid, _ := strategy.(*payment.PrepaidStrategy)
+	return prepaid
+}
+
 // CallWithMap invokes a method with a map-based request. Payment metadata is
 // injected by the current strategy into the outgoing context.
 func (s *ServiceClient) CallWithMap(method string, params map[string]any) (map[string]any, error) {
-	err := s.setDefaultStrategy()
+	return s.CallWithMapContext(context.Background(), method, params)
+}
+
+// CallWithMapContext is like CallWithMap but runs under ctx. The GRPCUnary timeout
+// applies only when ctx has no deadline of its own.
+func (s *ServiceClient) CallWithMapContext(ctx context.Context, method string, params map[string]any) (_ map[string]any, err error) {
+	ctx, end := s.startCall(ctx, method)
+	defer func() { end(err) }()
+
+	err = s.setDefaultStrategy(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("can't auto set payment strategy; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy manually %v", err)
+		return nil, fmt.Errorf("can't auto set payment strategy; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy manually: %w", err)
 	}
-	//if s.strategy == nil {
-	//	return nil, errors.New("payment strategy not set; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy first")
-	//}
 
-	ctx, cancel := s.withTimeout(context.Background(), s.config.Timeouts.GRPCUnary)
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.GRPCUnary)
 	defer cancel()
 
-	resp, err := s.grpcClient.CallWithMap(s.strategy.GRPCMetadata(ctx), method, params)
+	ctx, err = s.pricedContext(ctx, method, mapPriceDeriver(params))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp map[string]any
+	err = s.invokePaid(ctx, func(ctx context.Context, c *grpc.Client) error {
+		resp, err = c.CallWithMap(ctx, method, params)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("gRPC call failed: %w", err)
+		return nil, newCallError(method, false, err)
 	}
 
 	return resp, nil
@@ -435,54 +722,193 @@ func (s *ServiceClient) CallWithMap(method string, params map[string]any) (map[s
 // CallWithJSON invokes a method with raw JSON request bytes. The JSON is mapped
 // to the protobuf input type using service descriptors.
 func (s *ServiceClient) CallWithJSON(method string, input []byte) ([]byte, error) {
-	err := s.setDefaultStrategy()
+	return s.CallWithJSONContext(context.Background(), method, input)
+}
+
+// CallWithJSONContext is like CallWithJSON but runs under ctx. The GRPCUnary timeout
+// applies only when ctx has no deadline of its own.
+func (s *ServiceClient) CallWithJSONContext(ctx context.Context, method string, input []byte) (_ []byte, err error) {
+	ctx, end := s.startCall(ctx, method)
+	defer func() { end(err) }()
+
+	err = s.setDefaultStrategy(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("can't auto set payment strategy; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy manually %v", err)
+		return nil, fmt.Errorf("can't auto set payment strategy; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy manually: %w", err)
 	}
-	//if s.strategy == nil {
-	//	return nil, errors.New("payment strategy not set; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy first")
-	//}
 
-	ctx, cancel := s.withTimeout(context.Background(), s.config.Timeouts.GRPCUnary)
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.GRPCUnary)
 	defer cancel()
 
-	resp, err := s.grpcClient.CallWithJSON(s.strategy.GRPCMetadata(ctx), method, input)
+	ctx, err = s.pricedContext(ctx, method, jsonPriceDeriver(input))
 	if err != nil {
-		return nil, fmt.Errorf("gRPC call failed: %w", err)
+		return nil, err
+	}
+
+	var resp []byte
+	err = s.invokePaid(ctx, func(ctx context.Context, c *grpc.Client) error {
+		resp, err = c.CallWithJSON(ctx, method, input)
+		return err
+	})
+	if err != nil {
+		return nil, newCallError(method, false, err)
 	}
 
 	return resp, nil
 }
 
-func (s *ServiceClient) setDefaultStrategy() error {
-	if s.strategy != nil {
+// setDefaultStrategy picks a payment strategy on first use: free calls falling
+// back to escrow when the group offers them, escrow otherwise.
+func (s *ServiceClient) setDefaultStrategy(ctx context.Context) error {
+	if s.currentStrategy() != nil {
 		return nil
 	}
+
+	s.strategyInit.Lock()
+	defer s.strategyInit.Unlock()
+	if s.currentStrategy() != nil {
+		return nil
+	}
+
 	if s.CurrentServiceGroup.FreeCalls > 0 {
-		if s.SetFreePaymentStrategy() == nil {
+		if s.SetFreeWithPaidFallbackStrategyContext(ctx) == nil {
 			return nil
 		}
 	}
-	return s.SetPaidPaymentStrategy()
+	return s.SetPaidPaymentStrategyContext(ctx)
+}
+
+// currentStrategy returns the active payment strategy, or nil if none is set.
+func (s *ServiceClient) currentStrategy() payment.Strategy {
+	s.strategyMu.RLock()
+	defer s.strategyMu.RUnlock()
+	return s.strategy
+}
+
+// setStrategy replaces the active payment strategy.
+func (s *ServiceClient) setStrategy(strategy payment.Strategy) {
+	s.strategyMu.Lock()
+	s.strategy = strategy
+	s.strategyMu.Unlock()
+}
+
+// invokePaid runs call on an endpoint with the payment metadata of the active
+// strategy and settles the payment reservation with the outcome. When the
+// daemon rejects a free call because the quota ran out and the strategy can
+// fall back to paying, the call is retried once with the fallback. When it
+// rejects an expired prepaid token, the token is refreshed and the call
+// retried once.
+func (s *ServiceClient) invokePaid(ctx context.Context, call func(context.Context, *grpc.Client) error) error {
+	err := s.invokeOnce(ctx, call)
+	switch classified := payment.ClassifyDaemonError(err); {
+	case errors.Is(classified, payment.ErrFreeCallsExhausted):
+		if fallback, ok := s.currentStrategy().(*payment.FallbackStrategy); ok && fallback.FreeCallsExhausted() {
+			err = s.invokeOnce(ctx, call)
+		}
+	case errors.Is(classified, payment.ErrPrepaidTokenExpired):
+		if prepaid := s.prepaidStrategy(); prepaid != nil {
+			if rerr := s.refresh(ctx, prepaid); rerr != nil {
+				return fmt.Errorf("failed to refresh expired prepaid token: %w", rerr)
+			}
+			err = s.invokeOnce(ctx, call)
+		}
+	}
+	return err
+}
+
+// invokeOnce runs call once with payment metadata; see invokePaid.
+func (s *ServiceClient) invokeOnce(ctx context.Context, call func(context.Context, *grpc.Client) error) error {
+	ctx, done, err := s.paymentMetadata(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to attach payment: %w", err)
+	}
+	err = s.invoke(ctx, func(c *grpc.Client) error {
+		return call(ctx, c)
+	})
+	done(err)
+	return err
+}
+
+// paymentMetadata decorates ctx with the headers of the active payment
+// strategy. The returned done func must be called with the outcome of the
+// call: for strategies that reserve a signed amount per call it commits the
+// reservation when the call reached the daemon and rolls it back otherwise.
+// If the payment cannot be reserved (for example because signing failed),
+// nothing is reserved and the call must not be made.
+func (s *ServiceClient) paymentMetadata(ctx context.Context) (context.Context, func(error), error) {
+	strategy := s.currentStrategy()
+	reserver, ok := strategy.(payment.Reserver)
+	if !ok {
+		ctx, err := s.tracePayment(ctx, "payment.GRPCMetadata", func(ctx context.Context) (context.Context, error) {
+			return strategy.GRPCMetadata(ctx), nil
+		})
+		return ctx, func(error) {}, err
+	}
+
+	var reservation payment.Reservation
+	ctx, err := s.tracePayment(ctx, "payment.Reserve", func(ctx context.Context) (context.Context, error) {
+		var err error
+		ctx, reservation, err = reserver.Reserve(ctx)
+		return ctx, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, func(err error) {
+		if reachedDaemon(err) {
+			reservation.Commit()
+			return
+		}
+		reservation.Rollback()
+	}, nil
+}
+
+// reachedDaemon reports whether a call that ended with err may have been
+// accepted by the daemon. Local failures (no gRPC status, such as an unknown
+// method or malformed input) and Unavailable mean the request was never
+// delivered; any other outcome is assumed to have consumed the payment.
+func reachedDaemon(err error) bool {
+	if err == nil {
+		return true
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	return st.Code() != codes.Unavailable
 }
 
 // CallWithProto invokes a method with a concrete protobuf request message and
 // returns the protobuf response message.
 func (s *ServiceClient) CallWithProto(method string, input proto.Message) (proto.Message, error) {
-	err := s.setDefaultStrategy()
+	return s.CallWithProtoContext(context.Background(), method, input)
+}
+
+// CallWithProtoContext is like CallWithProto but runs under ctx. The GRPCUnary timeout
+// applies only when ctx has no deadline of its own.
+func (s *ServiceClient) CallWithProtoContext(ctx context.Context, method string, input proto.Message) (_ proto.Message, err error) {
+	ctx, end := s.startCall(ctx, method)
+	defer func() { end(err) }()
+
+	err = s.setDefaultStrategy(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("can't auto set payment strategy; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy manually %v", err)
+		return nil, fmt.Errorf("can't auto set payment strategy; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy manually: %w", err)
 	}
-	//if s.strategy == nil {
-	//	return nil, errors.New("payment strategy not set; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy first")
-	//}
 
-	ctx, cancel := s.withTimeout(context.Background(), s.config.Timeouts.GRPCUnary)
+	ctx, cancel := withTimeout(ctx, s.config.Timeouts.GRPCUnary)
 	defer cancel()
 
-	resp, err := s.grpcClient.CallWithProto(s.strategy.GRPCMetadata(ctx), method, input)
+	ctx, err = s.pricedContext(ctx, method, protoPriceDeriver(input))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp proto.Message
+	err = s.invokePaid(ctx, func(ctx context.Context, c *grpc.Client) error {
+		resp, err = c.CallWithProto(ctx, method, input)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("gRPC call failed: %w", err)
+		return nil, newCallError(method, false, err)
 	}
 
 	return resp, nil
@@ -490,16 +916,32 @@ func (s *ServiceClient) CallWithProto(method string, input proto.Message) (proto
 
 // UpdateServiceMetadata updates the service metadata (uploads to IPFS and updates blockchain)
 func (s *ServiceClient) UpdateServiceMetadata(metadata *model.ServiceMetadata) (common.Hash, error) {
-	pk := s.config.GetPrivateKey()
-	if pk == nil {
-		return common.Hash{}, fmt.Errorf("private key not configured")
+	return s.UpdateServiceMetadataContext(context.Background(), metadata)
+}
+
+// UpdateServiceMetadataContext is like UpdateServiceMetadata but uploads the
+// metadata and submits the transaction under ctx. The metadata is checked
+// with ValidateServiceMetadataContext first, and nothing is published if it
+// is invalid. ProtoFiles of metadata without a service_api_source are
+// published first (see grpc.PublishProtos).
+func (s *ServiceClient) UpdateServiceMetadataContext(ctx context.Context, metadata *model.ServiceMetadata) (common.Hash, error) {
+	auth, err := s.config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
 	}
 
 	bcClient := s.getBlockchainClient()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := withTimeout(ctx, metadataTimeout)
 	defer cancel()
 
+	if err := s.ValidateServiceMetadataContext(ctx, metadata); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid service metadata: %w", err)
+	}
+	if metadata, err = publishProtos(ctx, bcClient.Storage, metadata); err != nil {
+		return common.Hash{}, err
+	}
+
 	// Upload metadata to IPFS
 	uri, err := bcClient.Storage.UploadJSON(ctx, metadata)
 	if err != nil {
@@ -507,7 +949,7 @@ func (s *ServiceClient) UpdateServiceMetadata(metadata *model.ServiceMetadata) (
 	}
 
 	// Update service metadata in blockchain
-	hash, err := bcClient.UpdateServiceMetadata(pk, []byte(uri))
+	hash, err := bcClient.UpdateServiceMetadataCtx(ctx, auth, []byte(uri))
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to update service metadata: %w", err)
 	}
@@ -517,14 +959,19 @@ func (s *ServiceClient) UpdateServiceMetadata(metadata *model.ServiceMetadata) (
 
 // DeleteService deletes the service registration from blockchain
 func (s *ServiceClient) DeleteService() (common.Hash, error) {
-	pk := s.config.GetPrivateKey()
-	if pk == nil {
-		return common.Hash{}, fmt.Errorf("private key not configured")
+	return s.DeleteServiceContext(context.Background())
+}
+
+// DeleteServiceContext is like DeleteService but submits the transaction under ctx.
+func (s *ServiceClient) DeleteServiceContext(ctx context.Context) (common.Hash, error) {
+	auth, err := s.config.RequireSigner()
+	if err != nil {
+		return common.Hash{}, err
 	}
 
 	bcClient := s.getBlockchainClient()
 
-	hash, err := bcClient.DeleteServiceWithAuth(pk)
+	hash, err := bcClient.DeleteServiceWithAuthCtx(ctx, auth)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to delete service: %w", err)
 	}
@@ -534,18 +981,30 @@ func (s *ServiceClient) DeleteService() (common.Hash, error) {
 
 // Close releases the underlying gRPC connection. It is safe to call multiple times.
 func (s *ServiceClient) Close() {
-	if s.grpcClient != nil {
-		_ = s.grpcClient.Close()
-	}
+	s.closeConnections()
 	if s.EVMClient != nil {
 		s.EVMClient.Close()
 	}
 }
 
-// withTimeout returns a derived context with the given timeout. A cancelable
-// context is returned when d <= 0.
-func (s *ServiceClient) withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
-	if d <= 0 {
+// closeConnections stops the endpoint probes and closes the gRPC connections
+// of the client, leaving the EVM client, which may be shared, open.
+func (s *ServiceClient) closeConnections() {
+	if s.stopProbes != nil {
+		s.stopProbes()
+	}
+	if s.endpoints != nil {
+		_ = s.endpoints.Close()
+	} else if s.grpcClient != nil {
+		_ = s.grpcClient.Close()
+	}
+}
+
+// withTimeout returns a derived context with the given timeout. The caller's
+// deadline takes precedence: a cancelable context is returned when ctx already
+// has a deadline or when d <= 0.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || d <= 0 {
 		return context.WithCancel(ctx)
 	}
 	return context.WithTimeout(ctx, d)
@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/shamank/snet-sdk-go/pkg/blockchain"
 	"github.com/shamank/snet-sdk-go/pkg/config"
@@ -253,8 +255,8 @@ func TestServiceClient_UpdateServiceMetadata_Validation(t *testing.T) {
 	metadata := &model.ServiceMetadata{DisplayName: "Test"}
 	_, err := srvClient.UpdateServiceMetadata(metadata)
 
-	if err == nil || !containsSubstring(err.Error(), "private key not configured") {
-		t.Errorf("expected 'private key not configured' error, got: %v", err)
+	if err == nil || !containsSubstring(err.Error(), "signer not configured") {
+		t.Errorf("expected 'signer not configured' error, got: %v", err)
 	}
 }
 
@@ -264,8 +266,8 @@ func TestServiceClient_DeleteService_Validation(t *testing.T) {
 
 	_, err := srvClient.DeleteService()
 
-	if err == nil || !containsSubstring(err.Error(), "private key not configured") {
-		t.Errorf("expected 'private key not configured' error, got: %v", err)
+	if err == nil || !containsSubstring(err.Error(), "signer not configured") {
+		t.Errorf("expected 'signer not configured' error, got: %v", err)
 	}
 }
 
@@ -435,3 +437,62 @@ func TestServiceClient_SetPrePaidPaymentStrategy_RequiresWebSocket(t *testing.T)
 		}
 	})
 }
+
+func TestNewGroupServiceClientUsesEveryEndpoint(t *testing.T) {
+	cfg := &config.Config{EndpointPolicy: "round-robin", Timeouts: config.Timeouts{GRPCUnary: 2 * time.Second}}
+	svcBC := &blockchain.ServiceClient{
+		ServiceID: "svc",
+		ServiceMetadata: &model.ServiceMetadata{
+			ProtoFiles: map[string]string{"svc.proto": "syntax = \"proto3\"; package svc; message Ping {}"},
+		},
+		CurrentGroup: &model.ServiceGroup{GroupName: "default_group", Endpoints: []string{"127.0.0.1:1", "127.0.0.1:2"}},
+	}
+
+	sc, err := newGroupServiceClient(cfg, nil, nil, svcBC)
+	if err != nil {
+		t.Fatalf("newGroupServiceClient error: %v", err)
+	}
+	defer sc.Close()
+
+	if got := len(sc.Endpoints()); got != 2 {
+		t.Fatalf("expected 2 endpoints, got %d", got)
+	}
+
+	for _, st := range sc.CheckEndpoints(context.Background()) {
+		if st.Healthy || st.LastError == nil {
+			t.Fatalf("expected unreachable endpoint %s to be marked unhealthy: %+v", st.URL, st)
+		}
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	for _, v := range []any{"1500", float64(1500), uint64(1500)} {
+		price, err := parsePrice(v)
+		if err != nil || price.Int64() != 1500 {
+			t.Fatalf("parsePrice(%#v) = %v, %v; want 1500", v, price, err)
+		}
+	}
+	for _, v := range []any{nil, "abc", "-1"} {
+		if _, err := parsePrice(v); err == nil {
+			t.Fatalf("parsePrice(%#v): expected error", v)
+		}
+	}
+}
+
+func TestServiceClientDynamicPriceMethod(t *testing.T) {
+	sc := &ServiceClient{ServiceMetadata: &model.ServiceMetadata{
+		DynamicPriceMethodMapping: map[string]string{
+			"/calc.Calculator/add": "/calc.Calculator/price_add",
+			"calc.Calculator/mul":  "/calc.Calculator/price_mul",
+		},
+	}}
+	if got := sc.dynamicPriceMethod("/calc.Calculator/add"); got != "/calc.Calculator/price_add" {
+		t.Fatalf("unexpected pricing method for add: %q", got)
+	}
+	if got := sc.dynamicPriceMethod("/calc.Calculator/mul"); got != "/calc.Calculator/price_mul" {
+		t.Fatalf("unexpected pricing method for mul: %q", got)
+	}
+	if got := sc.dynamicPriceMethod("/calc.Calculator/div"); got != "" {
+		t.Fatalf("expected no pricing method for div, got %q", got)
+	}
+}
@@ -0,0 +1,93 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shamank/snet-sdk-go/pkg/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// NewStream opens a streaming call for a method declared with "stream" in the
+// service protos. The payment strategy's metadata is attached once for the
+// whole stream and the stream lifetime is bounded by Timeouts.GRPCStream.
+func (s *ServiceClient) NewStream(method string) (*grpc.Stream, error) {
+	return s.NewStreamContext(context.Background(), method)
+}
+
+// NewStreamContext is like NewStream but derives the stream context from ctx.
+// The GRPCStream timeout applies only when ctx has no deadline of its own.
+func (s *ServiceClient) NewStreamContext(ctx context.Context, method string) (*grpc.Stream, error) {
+	return s.openStream(ctx, method, func(c *grpc.Client, ctx context.Context, opts ...grpc.StreamOption) (*grpc.Stream, error) {
+		return c.NewStream(ctx, method, opts...)
+	})
+}
+
+// StreamWithJSON opens a server-streaming call, sends the JSON request and
+// half-closes the stream. Responses are read from the returned stream.
+func (s *ServiceClient) StreamWithJSON(method string, input []byte) (*grpc.Stream, error) {
+	return s.StreamWithJSONContext(context.Background(), method, input)
+}
+
+// StreamWithJSONContext is like StreamWithJSON but derives the stream context from ctx.
+func (s *ServiceClient) StreamWithJSONContext(ctx context.Context, method string, input []byte) (*grpc.Stream, error) {
+	return s.openStream(ctx, method, func(c *grpc.Client, ctx context.Context, opts ...grpc.StreamOption) (*grpc.Stream, error) {
+		return c.StreamWithJSON(ctx, method, input, opts...)
+	})
+}
+
+// StreamWithMap opens a server-streaming call, sends the map-based request and
+// half-closes the stream. Responses are read from the returned stream.
+func (s *ServiceClient) StreamWithMap(method string, params map[string]any) (*grpc.Stream, error) {
+	return s.StreamWithMapContext(context.Background(), method, params)
+}
+
+// StreamWithMapContext is like StreamWithMap but derives the stream context from ctx.
+func (s *ServiceClient) StreamWithMapContext(ctx context.Context, method string, params map[string]any) (*grpc.Stream, error) {
+	return s.openStream(ctx, method, func(c *grpc.Client, ctx context.Context, opts ...grpc.StreamOption) (*grpc.Stream, error) {
+		return c.StreamWithMap(ctx, method, params, opts...)
+	})
+}
+
+// StreamWithProto opens a server-streaming call, sends the protobuf request
+// and half-closes the stream. Responses are read from the returned stream.
+func (s *ServiceClient) StreamWithProto(method string, input proto.Message) (*grpc.Stream, error) {
+	return s.StreamWithProtoContext(context.Background(), method, input)
+}
+
+// StreamWithProtoContext is like StreamWithProto but derives the stream context from ctx.
+func (s *ServiceClient) StreamWithProtoContext(ctx context.Context, method string, input proto.Message) (*grpc.Stream, error) {
+	return s.openStream(ctx, method, func(c *grpc.Client, ctx context.Context, opts ...grpc.StreamOption) (*grpc.Stream, error) {
+		return c.StreamWithProto(ctx, method, input, opts...)
+	})
+}
+
+// openStream ensures a payment strategy is set, decorates the context with its
+// metadata once and opens the stream with the configured stream timeout,
+// failing over to another endpoint if the preferred one is unavailable.
+// Streams are priced per method; dynamic pricing applies to unary calls only.
+func (s *ServiceClient) openStream(ctx context.Context, method string, open func(c *grpc.Client, ctx context.Context, opts ...grpc.StreamOption) (*grpc.Stream, error)) (_ *grpc.Stream, err error) {
+	ctx, end := s.startCall(ctx, method)
+	defer func() { end(err) }()
+
+	err = s.setDefaultStrategy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't auto set payment strategy; call SetPaidPaymentStrategy, SetPrePaidPaymentStrategy, or SetFreePaymentStrategy manually: %w", err)
+	}
+
+	ctx, err = s.pricedContext(ctx, method, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var stream *grpc.Stream
+	err = s.invokePaid(ctx, func(ctx context.Context, c *grpc.Client) error {
+		stream, err = open(c, ctx, grpc.WithStreamTimeout(s.config.Timeouts.GRPCStream))
+		return err
+	})
+	if err != nil {
+		return nil, newCallError(method, true, err)
+	}
+
+	return stream, nil
+}
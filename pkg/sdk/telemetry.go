@@ -0,0 +1,80 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shamank/snet-sdk-go/pkg/config"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// configTelemetry returns the telemetry of cfg, or nil (telemetry.Global())
+// without a config.
+func configTelemetry(cfg *config.Config) *telemetry.Telemetry {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.GetTelemetry()
+}
+
+// telemetry returns the telemetry of the service client, falling back to the
+// configured one for clients not built by newServiceClient.
+func (s *ServiceClient) telemetry() *telemetry.Telemetry {
+	if s.tel != nil {
+		return s.tel
+	}
+	return configTelemetry(s.config)
+}
+
+// startCall starts the span of a call to method. The returned context carries
+// the span and the telemetry of the service client, so the payment strategy
+// and the gRPC connection trace under it; end must be called with the outcome
+// of the call to end the span and record the call metrics.
+func (s *ServiceClient) startCall(ctx context.Context, method string) (_ context.Context, end func(error)) {
+	tel := s.telemetry()
+	start := time.Now()
+	attr := telemetry.MethodKey.String(method)
+	ctx, span := tel.Start(telemetry.NewContext(ctx, tel), "sdk.Call", attr)
+	return ctx, func(err error) {
+		telemetry.End(span, err)
+		tel.RecordCall(ctx, time.Since(start), err, attr)
+	}
+}
+
+// refresh refreshes strategy under a span.
+func (s *ServiceClient) refresh(ctx context.Context, strategy payment.Strategy) error {
+	tel := s.telemetry()
+	ctx, span := tel.Start(telemetry.NewContext(ctx, tel), "payment.Refresh",
+		attribute.String("snet.payment.strategy", fmt.Sprintf("%T", strategy)))
+	err := strategy.Refresh(ctx)
+	telemetry.End(span, err)
+	return err
+}
+
+// tracePayment runs attach, which decorates ctx with payment headers, under a
+// span named name. The payment type sent to the daemon is recorded on that
+// span and on the span of the call; an error of attach ends the span with it.
+// The returned context keeps the span of the call as the parent of what
+// follows.
+func (s *ServiceClient) tracePayment(ctx context.Context, name string, attach func(context.Context) (context.Context, error)) (context.Context, error) {
+	call := trace.SpanFromContext(ctx)
+	ctx, span := s.telemetry().Start(ctx, name)
+	ctx, err := attach(ctx)
+	if err != nil {
+		telemetry.End(span, err)
+		return nil, err
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if kind := md.Get(payment.PaymentTypeHeader); len(kind) > 0 {
+		attr := telemetry.PaymentTypeKey.String(kind[0])
+		span.SetAttributes(attr)
+		call.SetAttributes(attr)
+	}
+	span.End()
+	return trace.ContextWithSpan(ctx, call), nil
+}
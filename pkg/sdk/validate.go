@@ -0,0 +1,266 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shamank/snet-sdk-go/pkg/config"
+	"github.com/shamank/snet-sdk-go/pkg/grpc"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/storage"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// ValidateOption configures ValidateService and ValidateServiceMetadata.
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	probe bool
+}
+
+// WithEndpointProbe also dials every endpoint of every group and requires it
+// to answer the gRPC health check with SERVING.
+func WithEndpointProbe() ValidateOption {
+	return func(o *validateOptions) { o.probe = true }
+}
+
+// ValidateService checks service metadata before it is published with
+// CreateService. See ValidateServiceContext.
+func (o *OrganizationClient) ValidateService(metadata *model.ServiceMetadata, opts ...ValidateOption) error {
+	return o.ValidateServiceContext(context.Background(), metadata, opts...)
+}
+
+// ValidateServiceContext checks service metadata before it is published:
+// the checks of ServiceMetadata.Validate, that every group is a group of the
+// organization, that the MPE address is the one of the network, and that
+// the protos compile and declare every method the pricing, dynamic pricing
+// and training methods refer to. The protos are taken from
+// ServiceMetadata.ProtoFiles, or read from service_api_source. With
+// WithEndpointProbe the endpoints are health checked too. Every problem is
+// reported at once as model.ValidationErrors.
+func (o *OrganizationClient) ValidateServiceContext(ctx context.Context, metadata *model.ServiceMetadata, opts ...ValidateOption) error {
+	v := serviceValidator{config: o.config, log: configLogger(o.config)}
+	if bc := o.blockchainClient; bc != nil {
+		v.org = bc.OrganizationMetaData
+		if bc.EVMClient != nil {
+			v.mpeAddress = bc.MPEAddress
+			v.readProtos = bc.ReadServiceProtosCtx
+		}
+	}
+	return v.validate(ctx, metadata, opts...)
+}
+
+// ValidateServiceMetadata checks new metadata of the service before it is
+// published with UpdateServiceMetadata. See ValidateServiceMetadataContext.
+func (s *ServiceClient) ValidateServiceMetadata(metadata *model.ServiceMetadata, opts ...ValidateOption) error {
+	return s.ValidateServiceMetadataContext(context.Background(), metadata, opts...)
+}
+
+// ValidateServiceMetadataContext is like
+// OrganizationClient.ValidateServiceContext, against the organization of
+// the service.
+func (s *ServiceClient) ValidateServiceMetadataContext(ctx context.Context, metadata *model.ServiceMetadata, opts ...ValidateOption) error {
+	v := serviceValidator{org: s.OrgMetadata, config: s.config, log: s.logger()}
+	if bc := s.getBlockchainClient(); bc != nil && bc.EVMClient != nil {
+		v.mpeAddress = bc.EVMClient.MPEAddress
+		v.readProtos = bc.EVMClient.ReadServiceProtosCtx
+	}
+	return v.validate(ctx, metadata, opts...)
+}
+
+// serviceValidator checks service metadata against what it is published
+// into. Checks whose input is missing (no organization, no MPE address, no
+// storage) are skipped.
+type serviceValidator struct {
+	org        *model.OrganizationMetaData
+	mpeAddress common.Address
+	readProtos func(context.Context, *model.ServiceMetadata) (map[string]string, error)
+	config     *config.Config
+	log        *zap.Logger
+}
+
+func (v serviceValidator) validate(ctx context.Context, metadata *model.ServiceMetadata, opts ...ValidateOption) error {
+	if metadata == nil {
+		return errors.New("service metadata is nil")
+	}
+	var o validateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctx, cancel := withTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	var errs model.ValidationErrors
+	if err := metadata.Validate(); err != nil {
+		if !errors.As(err, &errs) {
+			return err
+		}
+	}
+
+	if v.org != nil {
+		for i, g := range metadata.Groups {
+			if g == nil || g.GroupName == "" {
+				continue
+			}
+			if !slices.ContainsFunc(v.org.Groups, func(og *model.OrganizationGroup) bool {
+				return og != nil && og.GroupName == g.GroupName
+			}) {
+				errs.Add(fmt.Sprintf("groups[%d].group_name", i), fmt.Errorf("%q is not a group of organization %q", g.GroupName, v.org.OrgID))
+			}
+		}
+	}
+
+	if v.mpeAddress != (common.Address{}) && common.IsHexAddress(metadata.MPEAddress) &&
+		common.HexToAddress(metadata.MPEAddress) != v.mpeAddress {
+		errs.Add("mpe_address", fmt.Errorf("%s is not the MultiPartyEscrow contract of the network (%s)", metadata.MPEAddress, v.mpeAddress.Hex()))
+	}
+
+	v.checkProtos(ctx, metadata, &errs)
+	if o.probe {
+		v.probeEndpoints(ctx, metadata, &errs)
+	}
+	return errs.Err()
+}
+
+// checkProtos compiles the protos of the service and checks the methods the
+// metadata refers to.
+func (v serviceValidator) checkProtos(ctx context.Context, metadata *model.ServiceMetadata, errs *model.ValidationErrors) {
+	files := metadata.ProtoFiles
+	if len(files) == 0 {
+		if v.readProtos == nil || (metadata.ServiceApiSource == "" && metadata.ModelIpfsHash == "") {
+			return
+		}
+		var err error
+		if files, err = v.readProtos(ctx, metadata); err != nil {
+			errs.Add("service_api_source", fmt.Errorf("failed to read protos: %w", err))
+			return
+		}
+	}
+	if _, err := grpc.Compile(files); err != nil {
+		errs.Add("service_api_source", fmt.Errorf("protos do not compile: %w", err))
+		return
+	}
+	methods, err := grpc.ListMethods(files)
+	if err != nil {
+		errs.Add("service_api_source", err)
+		return
+	}
+	if len(methods) == 0 {
+		errs.Add("service_api_source", errors.New("protos declare no methods"))
+		return
+	}
+
+	for i, g := range metadata.Groups {
+		if g == nil {
+			continue
+		}
+		for j, p := range g.Pricing {
+			if p.PriceModel != model.PriceModelFixedPerMethod {
+				continue
+			}
+			for k, details := range p.PricingDetails {
+				for l, mp := range details.MethodPricing {
+					if mp.MethodName != "" && !declaresMethod(methods, p.PackageName, details.ServiceName, mp.MethodName) {
+						field := fmt.Sprintf("groups[%d].pricing[%d].details[%d].method_pricing[%d].method_name", i, j, k, l)
+						errs.Add(field, fmt.Errorf("method %q of service %q is not declared in the protos", mp.MethodName, details.ServiceName))
+					}
+				}
+			}
+		}
+	}
+	for _, method := range slices.Sorted(maps.Keys(metadata.DynamicPriceMethodMapping)) {
+		for _, m := range []string{method, metadata.DynamicPriceMethodMapping[method]} {
+			pkg, service, name := model.SplitMethodPath(m)
+			if service != "" && !declaresMethod(methods, pkg, service, name) {
+				errs.Add("dynamicpricing", fmt.Errorf("method %q is not declared in the protos", m))
+			}
+		}
+	}
+	for i, m := range metadata.TrainingMethods {
+		pkg, service, name := model.SplitMethodPath(m)
+		if !declaresMethod(methods, pkg, service, name) {
+			errs.Add(fmt.Sprintf("training_methods[%d]", i), fmt.Errorf("method %q is not declared in the protos", m))
+		}
+	}
+}
+
+// publishProtos uploads the ProtoFiles of metadata that has no
+// service_api_source yet and returns metadata pointing at the archive.
+// Other metadata is returned as is.
+func publishProtos(ctx context.Context, st storage.Storage, metadata *model.ServiceMetadata) (*model.ServiceMetadata, error) {
+	if metadata.ServiceApiSource != "" || metadata.ModelIpfsHash != "" || len(metadata.ProtoFiles) == 0 {
+		return metadata, nil
+	}
+	published, err := grpc.PublishProtos(ctx, st, metadata, metadata.ProtoFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish protos: %w", err)
+	}
+	return published, nil
+}
+
+// declaresMethod reports whether one of the full method paths matches name,
+// and pkg and service when they are not empty.
+func declaresMethod(methods []string, pkg, service, name string) bool {
+	return slices.ContainsFunc(methods, func(path string) bool {
+		p, s, n := model.SplitMethodPath(path)
+		return n == name && (pkg == "" || p == pkg) && (service == "" || s == service)
+	})
+}
+
+// probeEndpoints health checks every endpoint of every group concurrently.
+func (v serviceValidator) probeEndpoints(ctx context.Context, metadata *model.ServiceMetadata, errs *model.ValidationErrors) {
+	type probe struct {
+		field, endpoint string
+		probed          bool
+		err             error
+	}
+	var probes []probe
+	for i, g := range metadata.Groups {
+		if g == nil {
+			continue
+		}
+		for j, endpoint := range g.Endpoints {
+			if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+				probes = append(probes, probe{field: fmt.Sprintf("groups[%d].endpoints[%d]", i, j), endpoint: endpoint})
+			}
+		}
+	}
+
+	var dial time.Duration
+	if v.config != nil {
+		dial = v.config.Timeouts.WithDefaults().Dial
+	}
+	forEachLimit(ctx, defaultCrawlWorkers, len(probes), func(i int) {
+		p := &probes[i]
+		p.probed = true
+		conn, err := grpc.DialEndpoint(ctx, p.endpoint, dial)
+		if err != nil {
+			p.err = fmt.Errorf("unreachable: %w", err)
+			return
+		}
+		defer conn.Close()
+		hc := newHealthcheckClient(&grpc.Client{GRPC: conn}, &model.ServiceGroup{Endpoints: []string{p.endpoint}}, v.config, v.log)
+		resp, err := hc.GRPCContext(ctx)
+		switch {
+		case err != nil:
+			p.err = fmt.Errorf("health check failed: %w", err)
+		case resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING:
+			p.err = fmt.Errorf("not serving (%s)", resp.GetStatus())
+		}
+	})
+	for _, p := range probes {
+		switch {
+		case p.err != nil:
+			errs.Add(p.field, p.err)
+		case !p.probed:
+			errs.Add(p.field, fmt.Errorf("not probed: %w", ctx.Err()))
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shamank/snet-sdk-go/pkg/config"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	validateMPE    = "0x5e592F9b1d303183d963635f895f0f0C48284f4e"
+	validateProto  = "syntax = \"proto3\";\npackage example;\nmessage M {}\nservice Calculator {\n  rpc add(M) returns (M);\n  rpc train_add(M) returns (M);\n}\n"
+	validateSigner = "0x7DF35C98f41F3Af0df1dc4c7F7D4C19a71Dd059F"
+)
+
+func fieldsOf(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var errs model.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error %v is not ValidationErrors", err)
+	}
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	return strings.Join(fields, ",")
+}
+
+func TestServiceValidator(t *testing.T) {
+	v := serviceValidator{
+		org:        &model.OrganizationMetaData{OrgID: "snet", Groups: []*model.OrganizationGroup{{GroupName: "default_group"}}},
+		mpeAddress: common.HexToAddress(validateMPE),
+		readProtos: func(context.Context, *model.ServiceMetadata) (map[string]string, error) {
+			return map[string]string{"calc.proto": validateProto}, nil
+		},
+		log: zap.NewNop(),
+	}
+	builder := func() *model.ServiceMetadataBuilder {
+		return model.NewServiceMetadataBuilder("Calculator").
+			MPEAddress(validateMPE).
+			APISource("ipfs://QmProtos")
+	}
+
+	valid, err := builder().
+		Group("default_group", []string{"https://calc.example:7000"},
+			model.FixedPrice(big.NewInt(10)),
+			model.PricePerMethod("example", "Calculator", "add", big.NewInt(2))).
+		TrainingMethods("/example.Calculator/train_add").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.validate(context.Background(), valid); err != nil {
+		t.Fatalf("valid metadata: %v", err)
+	}
+
+	invalid, err := builder().
+		MPEAddress(validateSigner).
+		Group("other_group", []string{"https://calc.example:7000"},
+			model.FixedPrice(big.NewInt(10)),
+			model.PricePerMethod("example", "Calculator", "sub", big.NewInt(2))).
+		DynamicPricing("/example.Calculator/add", "/example.Calculator/add_price").
+		TrainingMethods("train_sub").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "groups[0].group_name,mpe_address,groups[0].pricing[1].details[0].method_pricing[0].method_name,dynamicpricing,training_methods[0]"
+	if got := fieldsOf(t, v.validate(context.Background(), invalid)); got != want {
+		t.Fatalf("fields = %s\nwant %s", got, want)
+	}
+
+	// ProtoFiles are used instead of reading service_api_source.
+	broken := *valid
+	broken.ProtoFiles = map[string]string{"calc.proto": "syntax = \"proto3\";\nservice Calculator {"}
+	if got := fieldsOf(t, v.validate(context.Background(), &broken)); got != "service_api_source" {
+		t.Fatalf("uncompilable protos: fields = %s", got)
+	}
+}
+
+func TestServiceValidatorProbe(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		if strings.Contains(err.Error(), "operation not permitted") {
+			t.Skip("network operations not permitted in sandbox")
+		}
+		t.Fatalf("listen: %v", err)
+	}
+	hs := health.NewServer()
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, hs)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	metadata, err := model.NewServiceMetadataBuilder("Calculator").
+		MPEAddress(validateMPE).
+		APISource("ipfs://QmProtos").
+		Group("default_group", []string{"http://" + lis.Addr().String()}, model.FixedPrice(big.NewInt(1))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := serviceValidator{config: &config.Config{}, log: zap.NewNop()}
+	if err := v.validate(context.Background(), metadata, WithEndpointProbe()); err != nil {
+		t.Fatalf("serving endpoint: %v", err)
+	}
+
+	hs.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	err = v.validate(context.Background(), metadata, WithEndpointProbe())
+	if got := fieldsOf(t, err); got != "groups[0].endpoints[0]" || !strings.Contains(err.Error(), "NOT_SERVING") {
+		t.Fatalf("not serving endpoint: %v", err)
+	}
+}
+
+// uploadStorage stores uploaded files in memory.
+type uploadStorage struct {
+	files map[string][]byte
+}
+
+func (s *uploadStorage) ReadFile(_ context.Context, uri string) ([]byte, error) {
+	data, ok := s.files[uri]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (s *uploadStorage) UploadJSON(context.Context, interface{}) (string, error) {
+	return "", errors.New("unexpected JSON upload")
+}
+
+func (s *uploadStorage) Upload(_ context.Context, data []byte) (string, error) {
+	s.files["ipfs://QmProtos"] = data
+	return "ipfs://QmProtos", nil
+}
+
+func TestPublishProtos(t *testing.T) {
+	metadata, err := model.NewServiceMetadataBuilder("Calculator").
+		MPEAddress(validateMPE).
+		ProtoFiles(map[string]string{"calc.proto": validateProto}).
+		Group("default_group", []string{"https://calc.example:7000"}, model.FixedPrice(big.NewInt(1))).
+		Build()
+	if err != nil {
+		t.Fatalf("Build with ProtoFiles only: %v", err)
+	}
+
+	st := &uploadStorage{files: make(map[string][]byte)}
+	published, err := publishProtos(context.Background(), st, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published.ServiceApiSource != "ipfs://QmProtos" || len(st.files) != 1 {
+		t.Fatalf("ServiceApiSource = %q after %d uploads", published.ServiceApiSource, len(st.files))
+	}
+	if again, _ := publishProtos(context.Background(), st, published); again != published {
+		t.Fatal("published metadata published again")
+	}
+}
@@ -0,0 +1,136 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// ClefSigner signs through an external signer speaking Clef's JSON-RPC API
+// (account_signData, account_signTransaction). The key never enters the
+// process; the external signer may ask for approval of every request, so
+// callers should pass contexts with generous deadlines.
+type ClefSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+// DialClef connects to the external signer at endpoint (HTTP, WebSocket or
+// IPC path) and returns a signer for address. A zero address selects the
+// first account the signer lists.
+func DialClef(ctx context.Context, endpoint string, address common.Address) (*ClefSigner, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("dial external signer: %w", err)
+	}
+	s, err := NewClefSigner(ctx, client, address)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewClefSigner returns a signer for address that uses client. A zero address
+// selects the first account the signer lists.
+func NewClefSigner(ctx context.Context, client *rpc.Client, address common.Address) (*ClefSigner, error) {
+	if address == (common.Address{}) {
+		var list []common.Address
+		if err := client.CallContext(ctx, &list, "account_list"); err != nil {
+			return nil, fmt.Errorf("list external signer accounts: %w", err)
+		}
+		if len(list) == 0 {
+			return nil, errors.New("external signer has no accounts")
+		}
+		address = list[0]
+	}
+	return &ClefSigner{client: client, address: address}, nil
+}
+
+// Address implements Signer.
+func (s *ClefSigner) Address() common.Address {
+	return s.address
+}
+
+// SignMessage implements Signer using account_signData with the text/plain
+// content type.
+func (s *ClefSigner) SignMessage(ctx context.Context, message []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	address := common.NewMixedcaseAddress(s.address)
+	if err := s.client.CallContext(ctx, &signature, "account_signData", accounts.MimetypeTextPlain, &address, hexutil.Encode(message)); err != nil {
+		return nil, fmt.Errorf("external signer: sign message: %w", err)
+	}
+	if len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("external signer: signature has %d bytes, want %d", len(signature), crypto.SignatureLength)
+	}
+	if signature[64] == 27 || signature[64] == 28 {
+		// Clef returns legacy Ethereum V values.
+		signature[64] -= 27
+	}
+	return signature, nil
+}
+
+// SignTx implements Signer using account_signTransaction. Legacy, access-list
+// and dynamic-fee transactions are supported.
+func (s *ClefSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	var to *common.MixedcaseAddress
+	if tx.To() != nil {
+		t := common.NewMixedcaseAddress(*tx.To())
+		to = &t
+	}
+	args := &apitypes.SendTxArgs{
+		From:  common.NewMixedcaseAddress(s.address),
+		To:    to,
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Value: hexutil.Big(*tx.Value()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Input: &data,
+	}
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("external signer: unsupported transaction type %d", tx.Type())
+	}
+	if chainID != nil && chainID.Sign() != 0 {
+		args.ChainID = (*hexutil.Big)(chainID)
+	}
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+
+	var result struct {
+		Raw hexutil.Bytes      `json:"raw"`
+		Tx  *types.Transaction `json:"tx"`
+	}
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("external signer: sign transaction: %w", err)
+	}
+	if result.Tx != nil {
+		return result.Tx, nil
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(result.Raw); err != nil {
+		return nil, fmt.Errorf("external signer: decode signed transaction: %w", err)
+	}
+	return signed, nil
+}
+
+// Close closes the connection to the external signer.
+func (s *ClefSigner) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,39 @@
+// Package signer abstracts where the SDK's signing keys live.
+//
+// Every signature the SDK produces (MPE payment claims, free-call and
+// prepaid token requests, channel state requests, training authorizations
+// and transactions) goes through a Signer:
+//
+//	type Signer interface {
+//		Address() common.Address
+//		SignMessage(ctx context.Context, message []byte) ([]byte, error)
+//		SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+//	}
+//
+// SignMessage signs with the Ethereum personal-message prefix (EIP-191) and
+// returns R || S || V with V in {0, 1}.
+//
+// # Implementations
+//
+// Raw key, held in process memory:
+//
+//	s, err := signer.ParsePrivateKey("0x...")
+//
+// Encrypted go-ethereum keystore file, decrypted once when opened:
+//
+//	s, err := signer.NewKeystoreSigner("UTC--2024-...--0x1234...", passphrase)
+//
+// External signer over JSON-RPC (Clef or a compatible service), so the key
+// never enters the process:
+//
+//	s, err := signer.DialClef(ctx, "http://localhost:8550", common.HexToAddress("0x1234..."))
+//	defer s.Close()
+//
+// Clef asks for approval of every request unless its rules allow it, which
+// matters for paid calls: each one signs a payment claim.
+//
+// # Configuration
+//
+// The sdk package builds the signer from config.Config: Signer if set,
+// otherwise PrivateKey, KeystorePath or ClefURL (see config.Config.GetSigner).
+package signer
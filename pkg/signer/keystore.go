@@ -0,0 +1,39 @@
+package signer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+// KeystoreSigner signs with a key loaded from an encrypted go-ethereum
+// keystore (Web3 Secret Storage) JSON file. The key stays encrypted at rest;
+// it is decrypted once, when the signer is opened, and then kept in memory.
+// Use a ClefSigner to keep the key out of the process entirely.
+type KeystoreSigner struct {
+	*PrivateKeySigner
+	path string
+}
+
+// NewKeystoreSigner decrypts the keystore file at path with passphrase.
+func NewKeystoreSigner(path, passphrase string) (*KeystoreSigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore file: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt keystore file %s: %w", path, err)
+	}
+	s, err := NewPrivateKeySigner(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &KeystoreSigner{PrivateKeySigner: s, path: path}, nil
+}
+
+// Path returns the keystore file the key was loaded from.
+func (s *KeystoreSigner) Path() string {
+	return s.path
+}
@@ -0,0 +1,77 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer signs on behalf of a single Ethereum account. The SDK uses it for
+// every signature it produces: payment claims, daemon requests and
+// transactions.
+//
+// Implementations must be safe for concurrent use.
+type Signer interface {
+	// Address returns the account the signer signs for.
+	Address() common.Address
+	// SignMessage signs message with the Ethereum personal-message prefix
+	// (EIP-191), i.e. it signs
+	//
+	//	keccak256("\x19Ethereum Signed Message:\n" || len(message) || message)
+	//
+	// and returns the 65-byte signature R || S || V with V in {0, 1}.
+	SignMessage(ctx context.Context, message []byte) ([]byte, error)
+	// SignTx signs tx for chainID and returns the signed transaction.
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// PrivateKeySigner signs with an ECDSA private key held in process memory.
+type PrivateKeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewPrivateKeySigner returns a signer for key.
+func NewPrivateKeySigner(key *ecdsa.PrivateKey) (*PrivateKeySigner, error) {
+	if key == nil {
+		return nil, errors.New("private key is nil")
+	}
+	return &PrivateKeySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}, nil
+}
+
+// ParsePrivateKey returns a signer for a hex-encoded private key, with or
+// without the "0x" prefix.
+func ParsePrivateKey(hexKey string) (*PrivateKeySigner, error) {
+	hexKey = strings.TrimPrefix(hexKey, "0x")
+	if len(hexKey) != 64 {
+		return nil, fmt.Errorf("private key must be 32 bytes (64 hex characters), got %d", len(hexKey))
+	}
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse hex private key: %w", err)
+	}
+	return NewPrivateKeySigner(key)
+}
+
+// Address implements Signer.
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignMessage implements Signer.
+func (s *PrivateKeySigner) SignMessage(_ context.Context, message []byte) ([]byte, error) {
+	return crypto.Sign(accounts.TextHash(message), s.key)
+}
+
+// SignTx implements Signer.
+func (s *PrivateKeySigner) SignTx(_ context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
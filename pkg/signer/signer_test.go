@@ -0,0 +1,183 @@
+package signer
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+func mustKey(t *testing.T) (*PrivateKeySigner, common.Address) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	s, err := NewPrivateKeySigner(key)
+	if err != nil {
+		t.Fatalf("NewPrivateKeySigner: %v", err)
+	}
+	return s, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// checkSigner verifies that s signs messages and transactions as want.
+func checkSigner(t *testing.T, s Signer, want common.Address) {
+	t.Helper()
+	ctx := context.Background()
+	if s.Address() != want {
+		t.Fatalf("Address = %s; want %s", s.Address(), want)
+	}
+
+	message := []byte("snet")
+	sig, err := s.SignMessage(ctx, message)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if len(sig) != crypto.SignatureLength || sig[64] > 1 {
+		t.Fatalf("signature = %x; want 65 bytes with V in {0, 1}", sig)
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(message), sig)
+	if err != nil {
+		t.Fatalf("SigToPub: %v", err)
+	}
+	if got := crypto.PubkeyToAddress(*pub); got != want {
+		t.Fatalf("message signed by %s; want %s", got, want)
+	}
+
+	chainID := big.NewInt(11155111)
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     3,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(5),
+	})
+	signed, err := s.SignTx(ctx, tx, chainID)
+	if err != nil {
+		t.Fatalf("SignTx: %v", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		t.Fatalf("Sender: %v", err)
+	}
+	if from != want || signed.Nonce() != 3 || signed.Value().Int64() != 5 {
+		t.Fatalf("signed tx from %s nonce %d value %s; want %s, 3, 5", from, signed.Nonce(), signed.Value(), want)
+	}
+}
+
+func TestPrivateKeySigner(t *testing.T) {
+	s, addr := mustKey(t)
+	checkSigner(t, s, addr)
+
+	parsed, err := ParsePrivateKey(hexutil.Encode(crypto.FromECDSA(s.key)))
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	if parsed.Address() != addr {
+		t.Fatalf("parsed address = %s; want %s", parsed.Address(), addr)
+	}
+
+	for _, bad := range []string{"", "0x1234", "zz" + hexutil.Encode(crypto.FromECDSA(s.key))[4:]} {
+		if _, err := ParsePrivateKey(bad); err == nil {
+			t.Fatalf("ParsePrivateKey(%q) succeeded; want error", bad)
+		}
+	}
+	if _, err := NewPrivateKeySigner(nil); err == nil {
+		t.Fatal("NewPrivateKeySigner(nil) succeeded; want error")
+	}
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	key, addr := mustKey(t)
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(key.key, "secret")
+	if err != nil {
+		t.Fatalf("ImportECDSA: %v", err)
+	}
+
+	s, err := NewKeystoreSigner(account.URL.Path, "secret")
+	if err != nil {
+		t.Fatalf("NewKeystoreSigner: %v", err)
+	}
+	if s.Path() != account.URL.Path {
+		t.Fatalf("Path = %s; want %s", s.Path(), account.URL.Path)
+	}
+	checkSigner(t, s, addr)
+
+	if _, err := NewKeystoreSigner(account.URL.Path, "wrong"); err == nil {
+		t.Fatal("NewKeystoreSigner with a wrong passphrase succeeded; want error")
+	}
+	if _, err := NewKeystoreSigner(filepath.Join(t.TempDir(), "missing.json"), "secret"); err == nil {
+		t.Fatal("NewKeystoreSigner with a missing file succeeded; want error")
+	}
+}
+
+// fakeClef implements the account_* methods of Clef's external API with a
+// local key.
+type fakeClef struct {
+	key *PrivateKeySigner
+}
+
+func (f *fakeClef) List() []common.Address {
+	return []common.Address{f.key.Address()}
+}
+
+func (f *fakeClef) SignData(_ string, _ common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	sig, err := f.key.SignMessage(context.Background(), data)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27 // Clef returns legacy V values
+	return sig, nil
+}
+
+type signTxResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func (f *fakeClef) SignTransaction(args apitypes.SendTxArgs) (*signTxResult, error) {
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := f.key.SignTx(context.Background(), tx, (*big.Int)(args.ChainID))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &signTxResult{Raw: raw, Tx: signed}, nil
+}
+
+func TestClefSigner(t *testing.T) {
+	key, addr := mustKey(t)
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", &fakeClef{key: key}); err != nil {
+		t.Fatalf("RegisterName: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	client := rpc.DialInProc(server)
+	s, err := NewClefSigner(context.Background(), client, common.Address{})
+	if err != nil {
+		t.Fatalf("NewClefSigner: %v", err)
+	}
+	defer s.Close()
+
+	checkSigner(t, s, addr)
+}
@@ -0,0 +1,175 @@
+package snettest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+)
+
+// mineInterval is how often the harness looks for pending transactions to
+// mine.
+const mineInterval = 10 * time.Millisecond
+
+// startChain starts the simulated chain, funds Owner and User with ETH,
+// deploys the contracts as Owner and gives userTokens to User.
+func (h *Harness) startChain(userTokens *big.Int) error {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	ipc := "snettest-" + hex.EncodeToString(suffix) + ".ipc"
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	h.Backend = simulated.NewBackend(types.GenesisAlloc{
+		h.Owner.Address: {Balance: balance},
+		h.User.Address:  {Balance: balance},
+	}, func(nodeConf *node.Config, _ *ethconfig.Config) {
+		nodeConf.IPCPath = ipc
+	})
+	h.endpoint = (&node.Config{IPCPath: ipc}).IPCEndpoint()
+
+	client := h.Backend.Client()
+	auth, err := h.transactor(h.Owner)
+	if err != nil {
+		return err
+	}
+	supply := new(big.Int).Mul(big.NewInt(1_000_000_000), cogsPerASI)
+	var (
+		tx    *types.Transaction
+		token *blockchain.FetchToken
+	)
+	h.TokenAddress, tx, token, err = blockchain.DeployFetchToken(auth, client, "SingularityNET Token", "ASI", supply)
+	if err = h.mined(tx, err); err != nil {
+		return fmt.Errorf("deploy token: %w", err)
+	}
+	h.RegistryAddress, tx, _, err = blockchain.DeployRegistry(auth, client)
+	if err = h.mined(tx, err); err != nil {
+		return fmt.Errorf("deploy Registry: %w", err)
+	}
+	h.MPEAddress, tx, _, err = blockchain.DeployMultiPartyEscrow(auth, client, h.TokenAddress)
+	if err = h.mined(tx, err); err != nil {
+		return fmt.Errorf("deploy MultiPartyEscrow: %w", err)
+	}
+	tx, err = token.Transfer(auth, h.User.Address, userTokens)
+	if err = h.mined(tx, err); err != nil {
+		return fmt.Errorf("transfer tokens to user: %w", err)
+	}
+	return nil
+}
+
+// register registers the organization and the service in the Registry as
+// Owner.
+func (h *Harness) register(orgURI, serviceURI string) error {
+	registry, err := blockchain.NewRegistry(h.RegistryAddress, h.Backend.Client())
+	if err != nil {
+		return err
+	}
+	auth, err := h.transactor(h.Owner)
+	if err != nil {
+		return err
+	}
+	orgID := blockchain.StringToBytes32(h.OrgID)
+	tx, err := registry.CreateOrganization(auth, orgID, []byte(orgURI), []common.Address{h.Owner.Address})
+	if err = h.mined(tx, err); err != nil {
+		return fmt.Errorf("create organization: %w", err)
+	}
+	tx, err = registry.CreateServiceRegistration(auth, orgID, blockchain.StringToBytes32(h.ServiceID), []byte(serviceURI))
+	if err = h.mined(tx, err); err != nil {
+		return fmt.Errorf("create service registration: %w", err)
+	}
+	return nil
+}
+
+// transactor returns transaction options signed by account.
+func (h *Harness) transactor(account Account) (*bind.TransactOpts, error) {
+	return bind.NewKeyedTransactorWithChainID(account.Key, big.NewInt(ChainID))
+}
+
+// mined mines tx, the result of a contract call that returned err, and
+// checks that it succeeded.
+func (h *Harness) mined(tx *types.Transaction, err error) error {
+	if err != nil {
+		return err
+	}
+	h.Backend.Commit()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, h.Backend.Client(), tx)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+	}
+	return nil
+}
+
+// automine mines a block whenever transactions are pending, so that clients
+// waiting for their transactions see them mined. The returned function stops
+// it.
+func (h *Harness) automine() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(mineInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if n, err := h.Backend.Client().PendingTransactionCount(ctx); err == nil && n > 0 {
+					h.Backend.Commit()
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// chainReader reads the payment channels of the harness chain for the
+// Verifier of the daemon.
+type chainReader struct {
+	h *Harness
+}
+
+// Channel reads payment channel id from the MultiPartyEscrow contract.
+func (r chainReader) Channel(ctx context.Context, id *big.Int) (blockchain.MultiPartyEscrowChannel, error) {
+	mpe, err := blockchain.NewMultiPartyEscrow(r.h.MPEAddress, r.h.Backend.Client())
+	if err != nil {
+		return blockchain.MultiPartyEscrowChannel{}, err
+	}
+	ch, err := mpe.Channels(&bind.CallOpts{Context: ctx}, id)
+	if err != nil {
+		return blockchain.MultiPartyEscrowChannel{}, err
+	}
+	return blockchain.MultiPartyEscrowChannel{
+		Sender:     ch.Sender,
+		Recipient:  ch.Recipient,
+		GroupID:    ch.GroupId,
+		Value:      ch.Value,
+		Nonce:      ch.Nonce,
+		Expiration: ch.Expiration,
+		Signer:     ch.Signer,
+	}, nil
+}
+
+// BlockNumber returns the number of the latest block.
+func (r chainReader) BlockNumber(ctx context.Context) (uint64, error) {
+	return r.h.Backend.Client().BlockNumber(ctx)
+}
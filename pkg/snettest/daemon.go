@@ -0,0 +1,170 @@
+package snettest
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shamank/snet-sdk-go/pkg/provider"
+	"github.com/shamank/snet-sdk-go/pkg/training"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Payment is a call accepted by the daemon stub and what was paid for it.
+type Payment struct {
+	// Method is the full gRPC method name of the call.
+	Method string
+	// Type is the payment type: "escrow", "free-call" or "prepaid-call".
+	Type string
+	// User is the channel signer of paid calls and the user of free calls.
+	User common.Address
+	// ChannelID is the channel the call was paid from; nil for free calls.
+	ChannelID *big.Int
+	// Amount is the price charged in cogs; zero for free calls.
+	Amount *big.Int
+}
+
+// daemonConfig describes the service group served by a Daemon and how it
+// reads the chain.
+type daemonConfig struct {
+	group   provider.Group
+	chain   provider.ChannelReader
+	methods map[string]protoreflect.MethodDescriptor
+	handler Handler
+}
+
+// Daemon is an in-process stub of the SingularityNET daemon. It serves one
+// service group over plaintext gRPC: the service calls, checked and charged
+// by a provider.Verifier like the daemon does, the PaymentChannelStateService,
+// FreeCallStateService and TokenService of the Verifier, and the training
+// Daemon service the SDK relies on. All state is kept in memory.
+type Daemon struct {
+	cfg      daemonConfig
+	verifier *provider.Verifier
+	store    *provider.MemoryStore
+	lis      net.Listener
+	server   *grpc.Server
+
+	mu        sync.Mutex
+	models    map[string]*training.ModelResponse
+	lastModel int
+	payments  []Payment
+}
+
+// newDaemon starts a Daemon on a local port.
+func newDaemon(cfg daemonConfig) (*Daemon, error) {
+	store := provider.NewMemoryStore()
+	verifier, err := provider.NewVerifier(cfg.chain, cfg.group, provider.WithStore(store))
+	if err != nil {
+		return nil, err
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	d := &Daemon{
+		cfg:      cfg,
+		verifier: verifier,
+		store:    store,
+		lis:      lis,
+		models:   make(map[string]*training.ModelResponse),
+	}
+	d.server = grpc.NewServer(grpc.UnknownServiceHandler(d.serveCall))
+	verifier.Register(d.server)
+	training.RegisterDaemonServer(d.server, trainingService{d: d})
+	grpc_health_v1.RegisterHealthServer(d.server, health.NewServer())
+	go func() { _ = d.server.Serve(lis) }()
+	return d, nil
+}
+
+// Endpoint returns the endpoint of the daemon, as listed in the service
+// metadata.
+func (d *Daemon) Endpoint() string {
+	return "http://" + d.lis.Addr().String()
+}
+
+// Payments returns the calls accepted so far, oldest first.
+func (d *Daemon) Payments() []Payment {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Payment(nil), d.payments...)
+}
+
+// SignedAmount returns the amount last signed for channel id, in cogs.
+func (d *Daemon) SignedAmount(id *big.Int) *big.Int {
+	st, err := d.store.LoadChannel(context.Background(), d.cfg.group.MPEAddress, id)
+	if err != nil || st.SignedAmount == nil {
+		return new(big.Int)
+	}
+	return st.SignedAmount
+}
+
+// FreeCallsUsed returns the number of free calls made by user.
+func (d *Daemon) FreeCallsUsed(user common.Address) uint64 {
+	used, _ := d.store.FreeCallsUsed(context.Background(), d.cfg.group.GroupID, user)
+	return used
+}
+
+// Close stops the daemon.
+func (d *Daemon) Close() {
+	d.server.Stop()
+}
+
+// serveCall serves the calls of the service.
+func (d *Daemon) serveCall(_ any, stream grpc.ServerStream) error {
+	name, _ := grpc.MethodFromServerStream(stream)
+	method, ok := d.cfg.methods[name]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", name)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return status.Errorf(codes.Unimplemented, "streaming method %s is not supported", name)
+	}
+	req := dynamicpb.NewMessage(method.Input())
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	if err := d.charge(stream.Context(), name); err != nil {
+		return err
+	}
+	resp, err := d.cfg.handler(stream.Context(), method, req)
+	if err != nil {
+		return err
+	}
+	return stream.SendMsg(resp)
+}
+
+// charge checks the payment of a call of method with the Verifier and
+// records it.
+func (d *Daemon) charge(ctx context.Context, method string) error {
+	p, err := d.verifier.Verify(ctx, method)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.payments = append(d.payments, Payment{Method: p.Method, Type: p.Type, User: p.User, ChannelID: p.ChannelID, Amount: p.Price})
+	return nil
+}
+
+// checkSigner returns an Unauthenticated error unless signature is a
+// signature of message by want (see blockchain.SignMessage).
+func checkSigner(message, signature []byte, want common.Address) error {
+	got, err := provider.RecoverSigner(message, signature)
+	if err != nil {
+		return status.Errorf(codes.Unauthenticated, "incorrect signature: %v", err)
+	}
+	if got != want {
+		return status.Errorf(codes.Unauthenticated, "signature of %s does not match %s", got.Hex(), want.Hex())
+	}
+	return nil
+}
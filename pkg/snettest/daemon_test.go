@@ -0,0 +1,312 @@
+package snettest
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"github.com/shamank/snet-sdk-go/pkg/provider"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const testBlock = 100
+
+var (
+	testMPE       = common.HexToAddress("0x5e592F9b1d303183d963635f895f0f0C48284f4e")
+	testRecipient = common.HexToAddress("0x3bb9b2499c283cec176e7C707Ecb495B7a961ebf")
+	testGroupID   = [32]byte{1, 2, 3}
+)
+
+// testChain is a chain at testBlock holding channels.
+type testChain map[int64]blockchain.MultiPartyEscrowChannel
+
+func (c testChain) Channel(_ context.Context, id *big.Int) (blockchain.MultiPartyEscrowChannel, error) {
+	return c[id.Int64()], nil
+}
+
+func (c testChain) BlockNumber(context.Context) (uint64, error) {
+	return testBlock, nil
+}
+
+// testDaemon is a Daemon serving the calculator for the payment channels in
+// channels, with a client connection to it.
+type testDaemon struct {
+	*Daemon
+	conn     *grpc.ClientConn
+	method   protoreflect.MethodDescriptor
+	channels testChain
+}
+
+func newTestDaemon(t *testing.T, freeCalls uint64) *testDaemon {
+	t.Helper()
+	methods, err := compileMethods(map[string]string{"example_service.proto": CalculatorProto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	td := &testDaemon{channels: make(testChain)}
+	td.Daemon, err = newDaemon(daemonConfig{
+		group: provider.Group{
+			OrgID:          "org",
+			ServiceID:      "calc",
+			GroupID:        testGroupID,
+			PaymentAddress: testRecipient,
+			MPEAddress:     testMPE,
+			Price:          provider.FixedPrice(big.NewInt(10)),
+			FreeCalls:      freeCalls,
+		},
+		chain:   td.channels,
+		methods: methods,
+		handler: Calculator,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(td.Close)
+
+	td.conn, err = grpc.NewClient(strings.TrimPrefix(td.Endpoint(), "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = td.conn.Close() })
+	td.method = methods["/example_service.Calculator/add"]
+	return td
+}
+
+// openChannel adds channel id, signed by key, to the chain of the daemon.
+func (td *testDaemon) openChannel(id int64, key *ecdsa.PrivateKey, value int64) {
+	td.channels[id] = blockchain.MultiPartyEscrowChannel{
+		Sender:     crypto.PubkeyToAddress(key.PublicKey),
+		Recipient:  testRecipient,
+		GroupID:    testGroupID,
+		Value:      big.NewInt(value),
+		Nonce:      big.NewInt(0),
+		Expiration: big.NewInt(testBlock + 1000),
+		Signer:     crypto.PubkeyToAddress(key.PublicKey),
+	}
+}
+
+// add calls the add method of the calculator with the given metadata.
+func (td *testDaemon) add(a, b float32, kv ...string) (float32, error) {
+	in := dynamicpb.NewMessage(td.method.Input())
+	in.Set(td.method.Input().Fields().ByName("a"), protoreflect.ValueOfFloat32(a))
+	in.Set(td.method.Input().Fields().ByName("b"), protoreflect.ValueOfFloat32(b))
+	out := dynamicpb.NewMessage(td.method.Output())
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(kv...))
+	if err := td.conn.Invoke(ctx, "/example_service.Calculator/add", in, out); err != nil {
+		return 0, err
+	}
+	return float32(out.Get(td.method.Output().Fields().ByName("value")).Float()), nil
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, message []byte) []byte {
+	t.Helper()
+	s, err := signer.NewPrivateKeySigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := blockchain.SignMessage(context.Background(), s, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+// claimMessage is the MPE claim message signed by escrow and prepaid clients.
+func claimMessage(mpe common.Address, id, nonce, amount *big.Int) []byte {
+	return bytes.Join([][]byte{
+		[]byte(payment.PrefixInSignature),
+		mpe.Bytes(),
+		common.BigToHash(id).Bytes(),
+		common.BigToHash(nonce).Bytes(),
+		common.BigToHash(amount).Bytes(),
+	}, nil)
+}
+
+// freeCallMessage is the message signed for free-call tokens and free calls
+// to the test daemon; token is nil when requesting a token.
+func freeCallMessage(user string, block uint64, token []byte) []byte {
+	return bytes.Join([][]byte{
+		[]byte(payment.FreeCallPrefixSignature),
+		[]byte(user),
+		[]byte("org"),
+		[]byte("calc"),
+		[]byte(base64.StdEncoding.EncodeToString(testGroupID[:])),
+		common.BigToHash(new(big.Int).SetUint64(block)).Bytes(),
+		token,
+	}, nil)
+}
+
+func escrowHeaders(t *testing.T, key *ecdsa.PrivateKey, id, amount int64) []string {
+	t.Helper()
+	sig := sign(t, key, claimMessage(testMPE, big.NewInt(id), big.NewInt(0), big.NewInt(amount)))
+	return []string{
+		payment.PaymentTypeHeader, "escrow",
+		payment.PaymentChannelIDHeader, strconv.FormatInt(id, 10),
+		payment.PaymentChannelNonceHeader, "0",
+		payment.PaymentChannelAmountHeader, strconv.FormatInt(amount, 10),
+		payment.PaymentChannelSignatureHeader, string(sig),
+	}
+}
+
+func TestDaemon_EscrowCalls(t *testing.T) {
+	td := newTestDaemon(t, 0)
+	user, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	td.openChannel(7, user, 25)
+
+	if got, err := td.add(2, 3, escrowHeaders(t, user, 7, 10)...); err != nil || got != 5 {
+		t.Fatalf("first call = %v, %v; want 5", got, err)
+	}
+	if _, err := td.add(2, 3); err == nil {
+		t.Fatal("call without payment succeeded")
+	}
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    error
+	}{
+		{"same amount again", escrowHeaders(t, user, 7, 10), payment.ErrSignatureRejected},
+		{"signed by another key", escrowHeaders(t, other, 7, 20), payment.ErrSignatureRejected},
+		{"more than the channel value", escrowHeaders(t, user, 7, 30), payment.ErrInsufficientChannelFunds},
+		{"unknown channel", escrowHeaders(t, user, 8, 10), payment.ErrChannelNotFound},
+	}
+	for _, tt := range tests {
+		_, err := td.add(2, 3, tt.headers...)
+		if !errors.Is(payment.ClassifyDaemonError(err), tt.want) {
+			t.Errorf("%s: err = %v; want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := td.add(2, 3, escrowHeaders(t, user, 7, 20)...); err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+	if got := td.SignedAmount(big.NewInt(7)); got.Int64() != 20 {
+		t.Fatalf("SignedAmount = %v; want 20", got)
+	}
+	payments := td.Payments()
+	if len(payments) != 2 || payments[1].Amount.Int64() != 10 || payments[1].User != crypto.PubkeyToAddress(user.PublicKey) {
+		t.Fatalf("Payments = %+v; want two escrow payments of 10", payments)
+	}
+
+	state, err := payment.NewPaymentChannelStateServiceClient(td.conn).GetChannelState(context.Background(), &payment.ChannelStateRequest{
+		ChannelId:    big.NewInt(7).Bytes(),
+		Signature:    sign(t, user, channelStateMessage(7, testBlock)),
+		CurrentBlock: testBlock,
+	})
+	if err != nil {
+		t.Fatalf("GetChannelState: %v", err)
+	}
+	if new(big.Int).SetBytes(state.CurrentSignedAmount).Int64() != 20 || state.UsedAmount != 20 {
+		t.Fatalf("GetChannelState = %v; want signed and used amounts of 20", state)
+	}
+}
+
+func channelStateMessage(id int64, block uint64) []byte {
+	return bytes.Join([][]byte{
+		[]byte(payment.PrefixGetChannelState),
+		testMPE.Bytes(),
+		common.BigToHash(big.NewInt(id)).Bytes(),
+		math.U256Bytes(new(big.Int).SetUint64(block)),
+	}, nil)
+}
+
+func TestDaemon_FreeCalls(t *testing.T) {
+	td := newTestDaemon(t, 1)
+	user, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(user.PublicKey).Hex()
+	client := payment.NewFreeCallStateServiceClient(td.conn)
+
+	token, err := client.GetFreeCallToken(context.Background(), &payment.GetFreeCallTokenRequest{
+		Address:      addr,
+		Signature:    sign(t, user, freeCallMessage(addr, testBlock, nil)),
+		CurrentBlock: testBlock,
+	})
+	if err != nil {
+		t.Fatalf("GetFreeCallToken: %v", err)
+	}
+	if token.TokenExpirationBlock <= testBlock {
+		t.Fatalf("TokenExpirationBlock = %d", token.TokenExpirationBlock)
+	}
+
+	headers := []string{
+		payment.PaymentTypeHeader, "free-call",
+		payment.FreeCallAuthTokenHeader, string(token.Token),
+		payment.FreeCallUserAddressHeader, addr,
+		payment.PaymentChannelSignatureHeader, string(sign(t, user, freeCallMessage(addr, testBlock, token.Token))),
+		payment.CurrentBlockNumberHeader, strconv.Itoa(testBlock),
+	}
+	if _, err := td.add(1, 1, headers...); err != nil {
+		t.Fatalf("free call: %v", err)
+	}
+	if _, err := td.add(1, 1, headers...); !errors.Is(payment.ClassifyDaemonError(err), payment.ErrFreeCallsExhausted) {
+		t.Fatalf("second free call: err = %v; want %v", err, payment.ErrFreeCallsExhausted)
+	}
+
+	reply, err := client.GetFreeCallsAvailable(context.Background(), &payment.FreeCallStateRequest{
+		Address:       addr,
+		FreeCallToken: token.Token,
+		Signature:     sign(t, user, freeCallMessage(addr, testBlock, token.Token)),
+		CurrentBlock:  testBlock,
+	})
+	if err != nil || reply.FreeCallsAvailable != 0 {
+		t.Fatalf("GetFreeCallsAvailable = %v, %v; want 0", reply, err)
+	}
+	if used := td.FreeCallsUsed(common.HexToAddress(addr)); used != 1 {
+		t.Fatalf("FreeCallsUsed = %d; want 1", used)
+	}
+}
+
+func TestDaemon_PrepaidToken(t *testing.T) {
+	td := newTestDaemon(t, 0)
+	user, _ := crypto.GenerateKey()
+	td.openChannel(3, user, 100)
+
+	claim := sign(t, user, claimMessage(testMPE, big.NewInt(3), big.NewInt(0), big.NewInt(20)))
+	block := math.U256Bytes(big.NewInt(testBlock))
+	reply, err := payment.NewTokenServiceClient(td.conn).GetToken(context.Background(), &payment.TokenRequest{
+		ChannelId:      3,
+		CurrentNonce:   0,
+		SignedAmount:   20,
+		Signature:      sign(t, user, append(bytes.Clone(claim), block...)),
+		CurrentBlock:   testBlock,
+		ClaimSignature: claim,
+	})
+	if err != nil {
+		t.Fatalf("GetToken: %v", err)
+	}
+	if reply.PlannedAmount != 20 || reply.UsedAmount != 0 {
+		t.Fatalf("GetToken = %v; want planned 20 and used 0", reply)
+	}
+
+	headers := []string{
+		payment.PaymentTypeHeader, "prepaid-call",
+		payment.PaymentChannelIDHeader, "3",
+		payment.PaymentChannelNonceHeader, "0",
+		payment.PrePaidAuthTokenHeader, reply.Token,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := td.add(1, 2, headers...); err != nil {
+			t.Fatalf("prepaid call %d: %v", i+1, err)
+		}
+	}
+	if _, err := td.add(1, 2, headers...); !errors.Is(payment.ClassifyDaemonError(err), payment.ErrInsufficientChannelFunds) {
+		t.Fatalf("third prepaid call: err = %v; want %v", err, payment.ErrInsufficientChannelFunds)
+	}
+}
@@ -0,0 +1,61 @@
+// Package snettest runs a local SingularityNET deployment for tests of code
+// built on the SDK, with no network access, testnet tokens or real daemon.
+//
+// A Harness bundles:
+//   - a simulated Ethereum chain (go-ethereum's ethclient/simulated) that
+//     mines transactions as soon as they are sent;
+//   - the ASI token, Registry and MultiPartyEscrow contracts, deployed from
+//     the bindings of package blockchain;
+//   - an organization with one payment group and one service registered in
+//     the Registry, their metadata and proto files kept in an in-memory
+//     Storage that also serves as the Lighthouse gateway;
+//   - a Daemon: an in-process gRPC stub of the SingularityNET daemon that
+//     checks and charges escrow, free and prepaid calls with a
+//     provider.Verifier, serves the channel-state, free-call, token and
+//     training APIs, and answers the service calls with a Handler.
+//
+// By default the service is the calculator of the SingularityNET example
+// service (see CalculatorProto), priced at 10 cogs per call, and User holds
+// 1000 ASI. Options change the organization, service, price, free calls and
+// API.
+//
+// # Usage Example
+//
+//	func TestAdd(t *testing.T) {
+//		h, err := snettest.New(snettest.WithFreeCalls(2))
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		defer h.Close()
+//
+//		core, err := sdk.NewSDK(h.Config())
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		defer core.Close()
+//
+//		svc, err := core.NewServiceClient(h.OrgID, h.ServiceID, h.GroupName)
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		defer svc.Close()
+//		if err := svc.SetFreeWithPaidFallbackStrategy(); err != nil {
+//			t.Fatal(err)
+//		}
+//
+//		out, err := svc.CallWithJSON("add", []byte(`{"a": 2, "b": 3}`))
+//		// out is {"value":5}
+//	}
+//
+// Daemon.Payments lists the calls the daemon accepted and what they were
+// charged, and Harness.Backend gives direct access to the chain, for example
+// to mine blocks until a payment channel expires.
+//
+// # Limitations
+//
+// The daemon stub serves unary service methods only, charges the fixed price
+// of the group for every call, and keeps its state in memory. Training models
+// move through their statuses at once. The chain is reachable over IPC, so
+// Harness.Config only works in the process that started the Harness or on
+// the same machine.
+package snettest
@@ -0,0 +1,126 @@
+package snettest
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Handler serves the calls of the service behind the daemon stub once their
+// payment is accepted. req is a message of the input type of method; the
+// result must be a message of its output type. Errors should be gRPC status
+// errors.
+type Handler func(ctx context.Context, method protoreflect.MethodDescriptor, req *dynamicpb.Message) (proto.Message, error)
+
+// CalculatorProto is the API of the default service: the calculator of the
+// SingularityNET example service.
+const CalculatorProto = `syntax = "proto3";
+
+package example_service;
+
+message Numbers {
+    float a = 1;
+    float b = 2;
+}
+
+message Result {
+    float value = 1;
+}
+
+service Calculator {
+    rpc add(Numbers) returns (Result) {}
+    rpc sub(Numbers) returns (Result) {}
+    rpc mul(Numbers) returns (Result) {}
+    rpc div(Numbers) returns (Result) {}
+}
+`
+
+// Calculator is the Handler of CalculatorProto.
+func Calculator(_ context.Context, method protoreflect.MethodDescriptor, req *dynamicpb.Message) (proto.Message, error) {
+	in := method.Input().Fields()
+	a := req.Get(in.ByName("a")).Float()
+	b := req.Get(in.ByName("b")).Float()
+
+	var value float64
+	switch method.Name() {
+	case "add":
+		value = a + b
+	case "sub":
+		value = a - b
+	case "mul":
+		value = a * b
+	case "div":
+		if b == 0 {
+			return nil, status.Error(codes.InvalidArgument, "division by zero")
+		}
+		value = a / b
+	default:
+		return nil, status.Errorf(codes.Unimplemented, "method %s not implemented", method.Name())
+	}
+
+	out := dynamicpb.NewMessage(method.Output())
+	out.Set(method.Output().Fields().ByName("value"), protoreflect.ValueOfFloat32(float32(value)))
+	return out, nil
+}
+
+// compileMethods compiles the proto sources (file name to content) and
+// returns their methods by full gRPC name ("/package.Service/method").
+func compileMethods(protoFiles map[string]string) (map[string]protoreflect.MethodDescriptor, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(protoFiles),
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), slices.Sorted(maps.Keys(protoFiles))...)
+	if err != nil {
+		return nil, fmt.Errorf("compile proto files: %w", err)
+	}
+
+	methods := make(map[string]protoreflect.MethodDescriptor)
+	for _, file := range files {
+		for i := 0; i < file.Services().Len(); i++ {
+			service := file.Services().Get(i)
+			for j := 0; j < service.Methods().Len(); j++ {
+				method := service.Methods().Get(j)
+				methods[fmt.Sprintf("/%s/%s", service.FullName(), method.Name())] = method
+			}
+		}
+	}
+	return methods, nil
+}
+
+// protoArchive packs the proto sources into a tar.gz archive, the format of
+// the service_api_source of service metadata.
+func protoArchive(protoFiles map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range slices.Sorted(maps.Keys(protoFiles)) {
+		content := protoFiles[name]
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
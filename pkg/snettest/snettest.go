@@ -0,0 +1,296 @@
+package snettest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/shamank/snet-sdk-go/pkg/config"
+	"github.com/shamank/snet-sdk-go/pkg/model"
+	"github.com/shamank/snet-sdk-go/pkg/provider"
+)
+
+// ChainID is the chain ID of the simulated chain.
+const ChainID = 1337
+
+// cogsPerASI is the number of cogs in one ASI token.
+var cogsPerASI = big.NewInt(100_000_000)
+
+// Account is an Ethereum account of the harness.
+type Account struct {
+	Key     *ecdsa.PrivateKey
+	Address common.Address
+}
+
+// newAccount returns an account with a random key.
+func newAccount() (Account, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return Account{}, err
+	}
+	return Account{Key: key, Address: crypto.PubkeyToAddress(key.PublicKey)}, nil
+}
+
+// Harness is a local SingularityNET deployment: a simulated chain with the
+// token, Registry and MultiPartyEscrow contracts, one organization with one
+// service registered in the Registry, the metadata of both in Storage, and a
+// Daemon serving the service.
+//
+// The organization and the payment address of its group belong to Owner.
+// User holds ETH and tokens and is the account of the SDK configuration
+// returned by Config. Transactions are mined as soon as they are sent.
+type Harness struct {
+	// Backend is the simulated chain. Commit mines a block; AdjustTime
+	// moves the clock forward.
+	Backend *simulated.Backend
+	// Owner owns the organization, the service and the payment address.
+	Owner Account
+	// User is the account SDK clients call the service with.
+	User Account
+
+	TokenAddress    common.Address
+	RegistryAddress common.Address
+	MPEAddress      common.Address
+
+	// Storage holds the organization and service metadata and the proto
+	// archive of the service.
+	Storage *Storage
+	// Daemon serves the service.
+	Daemon *Daemon
+
+	OrgID     string
+	ServiceID string
+	GroupName string
+	// GroupID is the base64 payment group ID, as in the organization
+	// metadata.
+	GroupID string
+
+	// endpoint is the IPC endpoint of the chain.
+	endpoint   string
+	stopMining func()
+}
+
+// options holds the settings of New.
+type options struct {
+	orgID      string
+	serviceID  string
+	groupName  string
+	price      *big.Int
+	freeCalls  uint64
+	userTokens *big.Int
+	protoFiles map[string]string
+	handler    Handler
+}
+
+// Option configures New.
+type Option func(*options)
+
+// WithOrganization sets the organization ID (default "snettest").
+func WithOrganization(orgID string) Option {
+	return func(o *options) { o.orgID = orgID }
+}
+
+// WithService sets the service ID (default "calculator").
+func WithService(serviceID string) Option {
+	return func(o *options) { o.serviceID = serviceID }
+}
+
+// WithGroup sets the payment group name (default "default_group").
+func WithGroup(name string) Option {
+	return func(o *options) { o.groupName = name }
+}
+
+// WithPrice sets the fixed price of a call in cogs (default 10).
+func WithPrice(cogs *big.Int) Option {
+	return func(o *options) { o.price = cogs }
+}
+
+// WithFreeCalls sets the number of free calls of every user (default 0).
+func WithFreeCalls(n uint64) Option {
+	return func(o *options) { o.freeCalls = n }
+}
+
+// WithUserTokens sets the tokens given to User, in cogs (default 1000 ASI).
+func WithUserTokens(cogs *big.Int) Option {
+	return func(o *options) { o.userTokens = cogs }
+}
+
+// WithProto replaces the calculator with the service described by protoFiles
+// (file name to content) and served by handler. The files must not be named
+// training.proto, which the SDK reserves for the training API.
+func WithProto(protoFiles map[string]string, handler Handler) Option {
+	return func(o *options) {
+		o.protoFiles = protoFiles
+		o.handler = handler
+	}
+}
+
+// New starts a Harness. Close releases it.
+func New(opts ...Option) (h *Harness, err error) {
+	o := options{
+		orgID:      "snettest",
+		serviceID:  "calculator",
+		groupName:  "default_group",
+		price:      big.NewInt(10),
+		userTokens: new(big.Int).Mul(big.NewInt(1000), cogsPerASI),
+		protoFiles: map[string]string{"example_service.proto": CalculatorProto},
+		handler:    Calculator,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.price == nil || o.price.Sign() < 0 {
+		return nil, errors.New("price must not be negative")
+	}
+	if _, ok := o.protoFiles["training.proto"]; ok {
+		return nil, errors.New("proto file name training.proto is reserved")
+	}
+	methods, err := compileMethods(o.protoFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	h = &Harness{OrgID: o.orgID, ServiceID: o.serviceID, GroupName: o.groupName}
+	defer func() {
+		if err != nil {
+			h.Close()
+		}
+	}()
+	if h.Owner, err = newAccount(); err != nil {
+		return nil, err
+	}
+	if h.User, err = newAccount(); err != nil {
+		return nil, err
+	}
+	var groupID [32]byte
+	if _, err = rand.Read(groupID[:]); err != nil {
+		return nil, err
+	}
+	h.GroupID = base64.StdEncoding.EncodeToString(groupID[:])
+	h.Storage = NewStorage()
+
+	if err = h.startChain(o.userTokens); err != nil {
+		return nil, err
+	}
+	h.Daemon, err = newDaemon(daemonConfig{
+		group: provider.Group{
+			OrgID:          o.orgID,
+			ServiceID:      o.serviceID,
+			GroupID:        groupID,
+			PaymentAddress: h.Owner.Address,
+			MPEAddress:     h.MPEAddress,
+			Price:          provider.FixedPrice(o.price),
+			FreeCalls:      o.freeCalls,
+		},
+		chain:   chainReader{h: h},
+		methods: methods,
+		handler: o.handler,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("start daemon: %w", err)
+	}
+
+	orgURI, err := h.Storage.UploadJSON(context.Background(), h.orgMetadata())
+	if err != nil {
+		return nil, err
+	}
+	api, err := protoArchive(o.protoFiles)
+	if err != nil {
+		return nil, fmt.Errorf("pack proto files: %w", err)
+	}
+	serviceURI, err := h.Storage.UploadJSON(context.Background(), h.serviceMetadata(h.Storage.Add(api), o.price, o.freeCalls))
+	if err != nil {
+		return nil, err
+	}
+	if err = h.register(orgURI, serviceURI); err != nil {
+		return nil, err
+	}
+	h.stopMining = h.automine()
+	return h, nil
+}
+
+// orgMetadata returns the metadata of the organization.
+func (h *Harness) orgMetadata() *model.OrganizationMetaData {
+	return &model.OrganizationMetaData{
+		OrgName: h.OrgID,
+		OrgID:   h.OrgID,
+		Groups: []*model.OrganizationGroup{{
+			ID:        h.GroupID,
+			GroupName: h.GroupName,
+			PaymentDetails: model.Payment{
+				PaymentAddress:             h.Owner.Address.Hex(),
+				PaymentExpirationThreshold: big.NewInt(100),
+				PaymentChannelStorageType:  "etcd",
+				PaymentChannelStorageClient: model.PaymentChannelStorageClient{
+					ConnectionTimeout: "5s",
+					RequestTimeout:    "3s",
+					Endpoints:         []string{"http://127.0.0.1:2379"},
+				},
+			},
+		}},
+	}
+}
+
+// serviceMetadata returns the metadata of the service.
+func (h *Harness) serviceMetadata(apiURI string, price *big.Int, freeCalls uint64) *model.ServiceMetadata {
+	return &model.ServiceMetadata{
+		Version:          1,
+		DisplayName:      h.ServiceID,
+		Encoding:         "proto",
+		ServiceType:      "grpc",
+		ServiceApiSource: apiURI,
+		MPEAddress:       h.MPEAddress.Hex(),
+		Groups: []*model.ServiceGroup{{
+			GroupName: h.GroupName,
+			Endpoints: []string{h.Daemon.Endpoint()},
+			Pricing: []model.Pricing{{
+				PriceModel:  model.PriceModelFixed,
+				PriceInCogs: price,
+				Default:     true,
+			}},
+			FreeCalls:      int(freeCalls),
+			FreeCallSigner: h.Owner.Address.Hex(),
+		}},
+	}
+}
+
+// Config returns an SDK configuration that uses the harness: the chain, its
+// contracts, Storage as the Lighthouse gateway and the key of User.
+func (h *Harness) Config() *config.Config {
+	return &config.Config{
+		RPCAddr: h.endpoint,
+		Network: config.Network{
+			ChainID:      fmt.Sprint(ChainID),
+			Name:         "snettest",
+			RegistryAddr: h.RegistryAddress.Hex(),
+			MPEAddr:      h.MPEAddress.Hex(),
+			TokenAddr:    h.TokenAddress.Hex(),
+		},
+		PrivateKey:    hex.EncodeToString(crypto.FromECDSA(h.User.Key)),
+		LighthouseURL: h.Storage.URL(),
+	}
+}
+
+// Close stops the daemon, the chain and the storage gateway.
+func (h *Harness) Close() {
+	if h.stopMining != nil {
+		h.stopMining()
+	}
+	if h.Daemon != nil {
+		h.Daemon.Close()
+	}
+	if h.Backend != nil {
+		_ = h.Backend.Close()
+	}
+	if h.Storage != nil {
+		h.Storage.Close()
+	}
+}
@@ -0,0 +1,141 @@
+package snettest
+
+import (
+	"archive/zip"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shamank/snet-sdk-go/pkg/sdk"
+	"github.com/shamank/snet-sdk-go/pkg/training"
+)
+
+func newService(t *testing.T, opts ...Option) (*Harness, sdk.Service) {
+	t.Helper()
+	h, err := New(opts...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(h.Close)
+
+	core, err := sdk.NewSDK(h.Config())
+	if err != nil {
+		t.Fatalf("NewSDK: %v", err)
+	}
+	t.Cleanup(core.Close)
+	svc, err := core.NewServiceClient(h.OrgID, h.ServiceID, h.GroupName)
+	if err != nil {
+		t.Fatalf("NewServiceClient: %v", err)
+	}
+	t.Cleanup(svc.Close)
+	return h, svc
+}
+
+func TestHarness_PaidCalls(t *testing.T) {
+	h, svc := newService(t)
+	if err := svc.SetPaidPaymentStrategy(); err != nil {
+		t.Fatalf("SetPaidPaymentStrategy: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		out, err := svc.CallWithJSON("add", []byte(`{"a": 2, "b": 3}`))
+		if err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+		if string(out) != `{"value":5}` {
+			t.Fatalf("call %d = %s; want {\"value\":5}", i+1, out)
+		}
+	}
+
+	payments := h.Daemon.Payments()
+	if len(payments) != 3 {
+		t.Fatalf("daemon accepted %d payments; want 3", len(payments))
+	}
+	if got := h.Daemon.SignedAmount(payments[0].ChannelID); got.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("signed amount = %v; want 30", got)
+	}
+}
+
+func TestHarness_FreeCallsThenPaid(t *testing.T) {
+	h, svc := newService(t, WithFreeCalls(2))
+	if err := svc.SetFreeWithPaidFallbackStrategy(); err != nil {
+		t.Fatalf("SetFreeWithPaidFallbackStrategy: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := svc.CallWithJSON("mul", []byte(`{"a": 2, "b": 3}`)); err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+	}
+
+	var types []string
+	for _, p := range h.Daemon.Payments() {
+		types = append(types, p.Type)
+	}
+	if len(types) != 3 || types[0] != "free-call" || types[1] != "free-call" || types[2] != "escrow" {
+		t.Fatalf("payment types = %v; want two free calls and one escrow call", types)
+	}
+	if used := h.Daemon.FreeCallsUsed(h.User.Address); used != 2 {
+		t.Fatalf("FreeCallsUsed = %d; want 2", used)
+	}
+}
+
+func TestHarness_PrepaidCalls(t *testing.T) {
+	h, svc := newService(t, WithPrice(big.NewInt(5)))
+	if err := svc.SetPrePaidPaymentStrategy(2); err != nil {
+		t.Fatalf("SetPrePaidPaymentStrategy: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := svc.CallWithJSON("sub", []byte(`{"a": 5, "b": 3}`)); err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+	}
+	for _, p := range h.Daemon.Payments() {
+		if p.Type != "prepaid-call" || p.Amount.Cmp(big.NewInt(5)) != 0 {
+			t.Fatalf("payment = %+v; want a prepaid call of 5 cogs", p)
+		}
+	}
+}
+
+func TestHarness_Training(t *testing.T) {
+	h, svc := newService(t)
+	if err := svc.SetPaidPaymentStrategy(); err != nil {
+		t.Fatalf("SetPaidPaymentStrategy: %v", err)
+	}
+	tc := svc.Training()
+
+	model, err := tc.CreateModel(&training.ModelParams{
+		Name:            "calc",
+		GrpcServiceName: "example_service.Calculator",
+		GrpcMethodName:  "add",
+	})
+	if err != nil {
+		t.Fatalf("CreateModel: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "data.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("data.csv")
+	_, _ = w.Write([]byte("a,b,value\n1,2,3\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	if err := tc.UploadAndValidate(&training.UploadValidateRequest{ModelID: model.ModelId, ZipPath: path}); err != nil {
+		t.Fatalf("UploadAndValidate: %v", err)
+	}
+	if st, err := tc.TrainModel(model.ModelId); err != nil || st != training.Status_TRAINING {
+		t.Fatalf("TrainModel = %v, %v; want TRAINING", st, err)
+	}
+	got, err := tc.GetModel(model.ModelId)
+	if err != nil || got.Status != training.Status_READY_TO_USE {
+		t.Fatalf("GetModel = %v, %v; want READY_TO_USE", got, err)
+	}
+	if payments := h.Daemon.Payments(); len(payments) != 1 || payments[0].Method != training.Daemon_UploadAndValidate_FullMethodName {
+		t.Fatalf("payments = %+v; want the upload only", payments)
+	}
+}
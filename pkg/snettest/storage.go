@@ -0,0 +1,102 @@
+package snettest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/shamank/snet-sdk-go/pkg/storage"
+)
+
+// Storage is an in-memory, content-addressed storage.Storage. Files are
+// addressed by the hex SHA-256 of their content under "filecoin://" URIs, and
+// served over HTTP as a Lighthouse gateway, so that SDK clients using URL as
+// their LighthouseURL read the files added here.
+type Storage struct {
+	mu     sync.RWMutex
+	files  map[string][]byte
+	server *httptest.Server
+}
+
+var (
+	_ storage.Storage  = (*Storage)(nil)
+	_ storage.Uploader = (*Storage)(nil)
+)
+
+// NewStorage returns an empty Storage with its gateway started. Close stops
+// the gateway.
+func NewStorage() *Storage {
+	s := &Storage{files: make(map[string][]byte)}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the base URL of the Lighthouse gateway, for
+// config.Config.LighthouseURL.
+func (s *Storage) URL() string {
+	return s.server.URL + "/ipfs/"
+}
+
+// Add stores data and returns its URI.
+func (s *Storage) Add(data []byte) string {
+	sum := sha256.Sum256(data)
+	id := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	s.files[id] = append([]byte(nil), data...)
+	s.mu.Unlock()
+	return storage.FilecoinPrefix + id
+}
+
+// ReadFile returns the file stored under uri, given with or without its
+// "filecoin://" or "ipfs://" prefix.
+func (s *Storage) ReadFile(_ context.Context, uri string) ([]byte, error) {
+	id := strings.TrimPrefix(strings.TrimPrefix(uri, storage.FilecoinPrefix), storage.IpfsPrefix)
+
+	s.mu.RLock()
+	data, ok := s.files[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("file %q not found", uri)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Upload stores data and returns its URI.
+func (s *Storage) Upload(_ context.Context, data []byte) (string, error) {
+	return s.Add(data), nil
+}
+
+// UploadJSON stores the JSON encoding of data and returns its URI.
+func (s *Storage) UploadJSON(_ context.Context, data any) (string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("marshal JSON: %w", err)
+	}
+	return s.Add(raw), nil
+}
+
+// Close stops the gateway.
+func (s *Storage) Close() {
+	s.server.Close()
+}
+
+func (s *Storage) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	id, ok := strings.CutPrefix(r.URL.Path, "/ipfs/")
+	if !ok || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+	data, err := s.ReadFile(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write(data)
+}
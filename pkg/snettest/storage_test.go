@@ -0,0 +1,66 @@
+package snettest
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/shamank/snet-sdk-go/pkg/storage"
+)
+
+func TestStorage_ReadFileAndGateway(t *testing.T) {
+	s := NewStorage()
+	defer s.Close()
+
+	uri, err := s.UploadJSON(context.Background(), map[string]string{"org_id": "snettest"})
+	if err != nil {
+		t.Fatalf("UploadJSON: %v", err)
+	}
+	if !strings.HasPrefix(uri, storage.FilecoinPrefix) {
+		t.Fatalf("UploadJSON URI = %q; want a %s URI", uri, storage.FilecoinPrefix)
+	}
+	if again := s.Add([]byte(`{"org_id":"snettest"}`)); again != uri {
+		t.Fatalf("Add of the same content = %q; want %q", again, uri)
+	}
+
+	// The SDK storage client reads filecoin:// URIs from the gateway.
+	client := storage.NewStorage("", s.URL())
+	for _, st := range []storage.Storage{s, client} {
+		raw, err := st.ReadFile(context.Background(), uri)
+		if err != nil {
+			t.Fatalf("%T.ReadFile: %v", st, err)
+		}
+		var got map[string]string
+		if err := json.Unmarshal(raw, &got); err != nil || got["org_id"] != "snettest" {
+			t.Fatalf("%T.ReadFile = %s, %v", st, raw, err)
+		}
+	}
+
+	if _, err := s.ReadFile(context.Background(), storage.FilecoinPrefix+"missing"); err == nil {
+		t.Fatal("ReadFile of a missing file succeeded")
+	}
+}
+
+func TestProtoArchive_CompilesAndPacks(t *testing.T) {
+	files := map[string]string{"example_service.proto": CalculatorProto}
+	methods, err := compileMethods(files)
+	if err != nil {
+		t.Fatalf("compileMethods: %v", err)
+	}
+	if len(methods) != 4 || methods["/example_service.Calculator/add"] == nil {
+		t.Fatalf("compileMethods = %v; want the four calculator methods", methods)
+	}
+
+	archive, err := protoArchive(files)
+	if err != nil {
+		t.Fatalf("protoArchive: %v", err)
+	}
+	got, err := storage.ParseProtoFiles(archive)
+	if err != nil {
+		t.Fatalf("ParseProtoFiles: %v", err)
+	}
+	if got["example_service.proto"] != CalculatorProto {
+		t.Fatalf("ParseProtoFiles = %v; want the calculator proto", got)
+	}
+}
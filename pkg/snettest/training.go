@@ -0,0 +1,354 @@
+package snettest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/shamank/snet-sdk-go/pkg/training"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// trainingService implements the training Daemon service. Models are kept in
+// memory and move through their statuses at once: a model is VALIDATED as
+// soon as its dataset is uploaded or validated, and READY_TO_USE as soon as
+// it is trained. Uploads are charged the price of a call; the other methods
+// are free, like they are on the daemon.
+type trainingService struct {
+	training.UnimplementedDaemonServer
+	d *Daemon
+}
+
+// authorize returns the address that signed auth for method.
+func authorize(auth *training.AuthorizationDetails, method string) (common.Address, error) {
+	if auth == nil {
+		return common.Address{}, status.Error(codes.Unauthenticated, "authorization is required")
+	}
+	if auth.GetMessage() != method {
+		return common.Address{}, status.Errorf(codes.Unauthenticated, "authorization is for %q, not %q", auth.GetMessage(), method)
+	}
+	if !common.IsHexAddress(auth.GetSignerAddress()) {
+		return common.Address{}, status.Errorf(codes.Unauthenticated, "invalid signer address %q", auth.GetSignerAddress())
+	}
+	signer := common.HexToAddress(auth.GetSignerAddress())
+	message := bytes.Join([][]byte{
+		[]byte(auth.GetMessage()),
+		signer.Bytes(),
+		math.U256Bytes(new(big.Int).SetUint64(auth.GetCurrentBlock())),
+	}, nil)
+	if err := checkSigner(message, auth.GetSignature(), signer); err != nil {
+		return common.Address{}, err
+	}
+	return signer, nil
+}
+
+// canAccess reports whether user may see and use model.
+func canAccess(model *training.ModelResponse, user common.Address) bool {
+	if model.GetIsPublic() || common.HexToAddress(model.GetCreatedByAddress()) == user {
+		return true
+	}
+	return slices.ContainsFunc(model.GetAddressList(), func(addr string) bool {
+		return common.HexToAddress(addr) == user
+	})
+}
+
+// modelLocked returns model id if user may access it. d.mu must be held.
+func (d *Daemon) modelLocked(id string, user common.Address) (*training.ModelResponse, error) {
+	model, ok := d.models[id]
+	if !ok || model.GetStatus() == training.Status_DELETED || !canAccess(model, user) {
+		return nil, status.Errorf(codes.NotFound, "model %q not found", id)
+	}
+	return model, nil
+}
+
+// setStatus moves model id to st on behalf of user and returns the model.
+func (d *Daemon) setStatus(id string, user common.Address, st training.Status) (*training.ModelResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	model, err := d.modelLocked(id, user)
+	if err != nil {
+		return nil, err
+	}
+	model.Status = st
+	model.UpdatedDate = time.Now().UTC().Format(time.RFC3339)
+	model.UpdatedByAddress = user.Hex()
+	return model, nil
+}
+
+func (s trainingService) CreateModel(_ context.Context, req *training.NewModelRequest) (*training.ModelResponse, error) {
+	user, err := authorize(req.GetAuthorization(), "create_model")
+	if err != nil {
+		return nil, err
+	}
+	m := req.GetModel()
+	if m.GetName() == "" || m.GetGrpcMethodName() == "" || m.GetGrpcServiceName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name, grpc_method_name and grpc_service_name are required")
+	}
+
+	d := s.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastModel++
+	now := time.Now().UTC().Format(time.RFC3339)
+	model := &training.ModelResponse{
+		ModelId:          strconv.Itoa(d.lastModel),
+		Status:           training.Status_CREATED,
+		CreatedDate:      now,
+		UpdatedDate:      now,
+		Name:             m.GetName(),
+		Description:      m.GetDescription(),
+		GrpcMethodName:   m.GetGrpcMethodName(),
+		GrpcServiceName:  m.GetGrpcServiceName(),
+		AddressList:      m.GetAddressList(),
+		IsPublic:         m.GetIsPublic(),
+		CreatedByAddress: user.Hex(),
+		UpdatedByAddress: user.Hex(),
+	}
+	d.models[model.ModelId] = model
+	return proto.Clone(model).(*training.ModelResponse), nil
+}
+
+func (s trainingService) ValidateModelPrice(_ context.Context, req *training.AuthValidateRequest) (*training.PriceInBaseUnit, error) {
+	user, err := authorize(req.GetAuthorization(), "validate_model_price")
+	if err != nil {
+		return nil, err
+	}
+	return s.price(req.GetModelId(), user, training.Daemon_ValidateModel_FullMethodName)
+}
+
+func (s trainingService) TrainModelPrice(_ context.Context, req *training.CommonRequest) (*training.PriceInBaseUnit, error) {
+	user, err := authorize(req.GetAuthorization(), "train_model_price")
+	if err != nil {
+		return nil, err
+	}
+	return s.price(req.GetModelId(), user, training.Daemon_TrainModel_FullMethodName)
+}
+
+// price returns the price of a call of method for model id.
+func (s trainingService) price(id string, user common.Address, method string) (*training.PriceInBaseUnit, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	if _, err := s.d.modelLocked(id, user); err != nil {
+		return nil, err
+	}
+	return &training.PriceInBaseUnit{Price: s.d.cfg.group.Price(method).Uint64()}, nil
+}
+
+func (s trainingService) UploadAndValidate(stream grpc.ClientStreamingServer[training.UploadAndValidateRequest, training.StatusResponse]) error {
+	var (
+		user    common.Address
+		modelID string
+	)
+	for first := true; ; first = false {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			if user, err = authorize(req.GetAuthorization(), "upload_and_validate"); err != nil {
+				return err
+			}
+			modelID = req.GetUploadInput().GetModelId()
+		}
+	}
+	if modelID == "" {
+		return status.Error(codes.InvalidArgument, "no dataset uploaded")
+	}
+
+	method, _ := grpc.MethodFromServerStream(stream)
+	if err := s.d.charge(stream.Context(), method); err != nil {
+		return err
+	}
+	if _, err := s.d.setStatus(modelID, user, training.Status_VALIDATED); err != nil {
+		return err
+	}
+	return stream.SendAndClose(&training.StatusResponse{Status: training.Status_VALIDATING})
+}
+
+func (s trainingService) ValidateModel(_ context.Context, req *training.AuthValidateRequest) (*training.StatusResponse, error) {
+	user, err := authorize(req.GetAuthorization(), "validate_model")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.d.setStatus(req.GetModelId(), user, training.Status_VALIDATED); err != nil {
+		return nil, err
+	}
+	return &training.StatusResponse{Status: training.Status_VALIDATING}, nil
+}
+
+func (s trainingService) TrainModel(_ context.Context, req *training.CommonRequest) (*training.StatusResponse, error) {
+	user, err := authorize(req.GetAuthorization(), "train_model")
+	if err != nil {
+		return nil, err
+	}
+
+	d := s.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	model, err := d.modelLocked(req.GetModelId(), user)
+	if err != nil {
+		return nil, err
+	}
+	if model.GetStatus() != training.Status_VALIDATED {
+		return nil, status.Errorf(codes.FailedPrecondition, "model %q is %s, not VALIDATED", model.GetModelId(), model.GetStatus())
+	}
+	model.Status = training.Status_READY_TO_USE
+	model.UpdatedByAddress = user.Hex()
+	return &training.StatusResponse{Status: training.Status_TRAINING}, nil
+}
+
+func (s trainingService) DeleteModel(_ context.Context, req *training.CommonRequest) (*training.StatusResponse, error) {
+	user, err := authorize(req.GetAuthorization(), "delete_model")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.d.setStatus(req.GetModelId(), user, training.Status_DELETED); err != nil {
+		return nil, err
+	}
+	return &training.StatusResponse{Status: training.Status_DELETED}, nil
+}
+
+func (s trainingService) GetModel(_ context.Context, req *training.CommonRequest) (*training.ModelResponse, error) {
+	user, err := authorize(req.GetAuthorization(), "get_model")
+	if err != nil {
+		return nil, err
+	}
+
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	model, err := s.d.modelLocked(req.GetModelId(), user)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(model).(*training.ModelResponse), nil
+}
+
+func (s trainingService) GetAllModels(_ context.Context, req *training.AllModelsRequest) (*training.ModelsResponse, error) {
+	user, err := authorize(req.GetAuthorization(), "get_all_models")
+	if err != nil {
+		return nil, err
+	}
+
+	d := s.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var models []*training.ModelResponse
+	for id := 1; id <= d.lastModel; id++ {
+		model, ok := d.models[strconv.Itoa(id)]
+		if !ok || !canAccess(model, user) || !matchModel(model, req) {
+			continue
+		}
+		models = append(models, proto.Clone(model).(*training.ModelResponse))
+	}
+	if size := req.GetPageSize(); size > 0 {
+		start := min(req.GetPage()*size, uint64(len(models)))
+		end := min(start+size, uint64(len(models)))
+		models = models[start:end]
+	}
+	return &training.ModelsResponse{ListOfModels: models}, nil
+}
+
+// matchModel reports whether model passes the filters of req. Deleted models
+// only match when req asks for them.
+func matchModel(model *training.ModelResponse, req *training.AllModelsRequest) bool {
+	if len(req.GetStatuses()) > 0 {
+		if !slices.Contains(req.GetStatuses(), model.GetStatus()) {
+			return false
+		}
+	} else if model.GetStatus() == training.Status_DELETED {
+		return false
+	}
+	switch {
+	case req.IsPublic != nil && req.GetIsPublic() != model.GetIsPublic():
+		return false
+	case req.GetGrpcMethodName() != "" && req.GetGrpcMethodName() != model.GetGrpcMethodName():
+		return false
+	case req.GetGrpcServiceName() != "" && req.GetGrpcServiceName() != model.GetGrpcServiceName():
+		return false
+	case req.GetName() != "" && req.GetName() != model.GetName():
+		return false
+	case req.GetCreatedByAddress() != "" && common.HexToAddress(req.GetCreatedByAddress()) != common.HexToAddress(model.GetCreatedByAddress()):
+		return false
+	}
+	return true
+}
+
+func (s trainingService) UpdateModel(_ context.Context, req *training.UpdateModelRequest) (*training.ModelResponse, error) {
+	user, err := authorize(req.GetAuthorization(), "update_model")
+	if err != nil {
+		return nil, err
+	}
+
+	d := s.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	model, err := d.modelLocked(req.GetModelId(), user)
+	if err != nil {
+		return nil, err
+	}
+	if common.HexToAddress(model.GetCreatedByAddress()) != user {
+		return nil, status.Errorf(codes.PermissionDenied, "only the creator may update model %q", model.GetModelId())
+	}
+	if req.ModelName != nil {
+		model.Name = req.GetModelName()
+	}
+	if req.Description != nil {
+		model.Description = req.GetDescription()
+	}
+	if req.AddressList != nil {
+		model.AddressList = req.GetAddressList()
+	}
+	model.UpdatedDate = time.Now().UTC().Format(time.RFC3339)
+	model.UpdatedByAddress = user.Hex()
+	return proto.Clone(model).(*training.ModelResponse), nil
+}
+
+// GetTrainingMetadata reports training as enabled for every unary method of
+// the service.
+func (s trainingService) GetTrainingMetadata(context.Context, *emptypb.Empty) (*training.TrainingMetadata, error) {
+	methods := make(map[string]*structpb.ListValue)
+	for _, method := range s.d.cfg.methods {
+		if method.IsStreamingClient() || method.IsStreamingServer() {
+			continue
+		}
+		service := string(method.Parent().FullName())
+		if methods[service] == nil {
+			methods[service] = &structpb.ListValue{}
+		}
+		methods[service].Values = append(methods[service].Values, structpb.NewStringValue(string(method.Name())))
+	}
+	for _, list := range methods {
+		slices.SortFunc(list.Values, func(a, b *structpb.Value) int {
+			return strings.Compare(a.GetStringValue(), b.GetStringValue())
+		})
+	}
+	return &training.TrainingMetadata{TrainingEnabled: true, TrainingInProto: false, TrainingMethods: methods}, nil
+}
+
+func (s trainingService) GetMethodMetadata(context.Context, *training.MethodMetadataRequest) (*training.MethodMetadata, error) {
+	return &training.MethodMetadata{
+		MaxModelsPerUser:           10,
+		DatasetMaxSizeMb:           100,
+		DatasetMaxCountFiles:       100,
+		DatasetMaxSizeSingleFileMb: 10,
+		DatasetFilesType:           "jpg, png, csv, txt",
+		DatasetType:                "zip",
+		DatasetDescription:         "any dataset is accepted",
+	}, nil
+}
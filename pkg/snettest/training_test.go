@@ -0,0 +1,90 @@
+package snettest
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shamank/snet-sdk-go/pkg/training"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func trainingAuth(t *testing.T, key *ecdsa.PrivateKey, method string) *training.AuthorizationDetails {
+	t.Helper()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	message := bytes.Join([][]byte{[]byte(method), addr.Bytes(), math.U256Bytes(big.NewInt(testBlock))}, nil)
+	return &training.AuthorizationDetails{
+		CurrentBlock:  testBlock,
+		Message:       method,
+		Signature:     sign(t, key, message),
+		SignerAddress: addr.Hex(),
+	}
+}
+
+func TestTraining_ModelLifecycle(t *testing.T) {
+	td := newTestDaemon(t, 0)
+	client := training.NewDaemonClient(td.conn)
+	ctx := context.Background()
+	owner, _ := crypto.GenerateKey()
+	stranger, _ := crypto.GenerateKey()
+
+	model, err := client.CreateModel(ctx, &training.NewModelRequest{
+		Authorization: trainingAuth(t, owner, "create_model"),
+		Model:         &training.NewModel{Name: "m", GrpcServiceName: "example_service.Calculator", GrpcMethodName: "add"},
+	})
+	if err != nil || model.Status != training.Status_CREATED {
+		t.Fatalf("CreateModel = %v, %v; want a CREATED model", model, err)
+	}
+	if _, err := client.CreateModel(ctx, &training.NewModelRequest{
+		Authorization: trainingAuth(t, owner, "get_model"),
+		Model:         &training.NewModel{Name: "m", GrpcServiceName: "s", GrpcMethodName: "m"},
+	}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("CreateModel signed for another method: err = %v; want Unauthenticated", err)
+	}
+
+	train := &training.CommonRequest{Authorization: trainingAuth(t, owner, "train_model"), ModelId: model.ModelId}
+	if _, err := client.TrainModel(ctx, train); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("TrainModel before validation: err = %v; want FailedPrecondition", err)
+	}
+	if _, err := client.ValidateModel(ctx, &training.AuthValidateRequest{Authorization: trainingAuth(t, owner, "validate_model"), ModelId: model.ModelId}); err != nil {
+		t.Fatalf("ValidateModel: %v", err)
+	}
+	if st, err := client.TrainModel(ctx, train); err != nil || st.Status != training.Status_TRAINING {
+		t.Fatalf("TrainModel = %v, %v; want TRAINING", st, err)
+	}
+	price, err := client.TrainModelPrice(ctx, &training.CommonRequest{Authorization: trainingAuth(t, owner, "train_model_price"), ModelId: model.ModelId})
+	if err != nil || price.Price != 10 {
+		t.Fatalf("TrainModelPrice = %v, %v; want 10", price, err)
+	}
+
+	got, err := client.GetModel(ctx, &training.CommonRequest{Authorization: trainingAuth(t, owner, "get_model"), ModelId: model.ModelId})
+	if err != nil || got.Status != training.Status_READY_TO_USE {
+		t.Fatalf("GetModel = %v, %v; want READY_TO_USE", got, err)
+	}
+	if _, err := client.GetModel(ctx, &training.CommonRequest{Authorization: trainingAuth(t, stranger, "get_model"), ModelId: model.ModelId}); status.Code(err) != codes.NotFound {
+		t.Fatalf("GetModel of a private model by another user: err = %v; want NotFound", err)
+	}
+	list, err := client.GetAllModels(ctx, &training.AllModelsRequest{Authorization: trainingAuth(t, stranger, "get_all_models")})
+	if err != nil || len(list.ListOfModels) != 0 {
+		t.Fatalf("GetAllModels by another user = %v, %v; want no models", list, err)
+	}
+
+	if _, err := client.DeleteModel(ctx, &training.CommonRequest{Authorization: trainingAuth(t, owner, "delete_model"), ModelId: model.ModelId}); err != nil {
+		t.Fatalf("DeleteModel: %v", err)
+	}
+	list, err = client.GetAllModels(ctx, &training.AllModelsRequest{Authorization: trainingAuth(t, owner, "get_all_models")})
+	if err != nil || len(list.ListOfModels) != 0 {
+		t.Fatalf("GetAllModels after delete = %v, %v; want no models", list, err)
+	}
+
+	meta, err := client.GetTrainingMetadata(ctx, &emptypb.Empty{})
+	if err != nil || !meta.TrainingEnabled || len(meta.TrainingMethods["example_service.Calculator"].GetValues()) != 4 {
+		t.Fatalf("GetTrainingMetadata = %v, %v; want the four calculator methods", meta, err)
+	}
+}
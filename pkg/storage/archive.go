@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// PackProtoFiles builds the tar.gz archive ParseProtoFiles reads: one
+// regular file per entry of protos, keyed by file name, in name order and
+// without directory entries.
+func PackProtoFiles(protos map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for _, name := range slices.Sorted(maps.Keys(protos)) {
+		if !strings.HasSuffix(name, ".proto") {
+			return nil, fmt.Errorf("%s is not a .proto file", name)
+		}
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(protos[name])),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(tw, protos[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
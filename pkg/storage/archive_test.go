@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestPackProtoFiles(t *testing.T) {
+	protos := map[string]string{
+		"service.proto":        "syntax = \"proto3\";\nimport \"common/types.proto\";\n",
+		"common/types.proto":   "syntax = \"proto3\";\n",
+		"common/options.proto": "",
+	}
+	archive, err := PackProtoFiles(protos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isGzipFile(archive) {
+		t.Fatal("archive is not gzip-compressed")
+	}
+	got, err := ParseProtoFiles(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got, protos) {
+		t.Fatalf("ParseProtoFiles = %v; want %v", got, protos)
+	}
+
+	again, _ := PackProtoFiles(protos)
+	if string(again) != string(archive) {
+		t.Fatal("archive is not reproducible")
+	}
+	if _, err := PackProtoFiles(map[string]string{"README.md": ""}); err == nil {
+		t.Fatal("non-proto file packed")
+	}
+}
@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultCacheTTL is how long a cached mutable entry, such as a Registry
+// lookup, is used before it is fetched again.
+const DefaultCacheTTL = 15 * time.Minute
+
+// revalidateTimeout bounds a background refresh of a stale entry.
+const revalidateTimeout = 2 * time.Minute
+
+// ErrNotCached is returned in offline mode for entries that are not in the
+// cache.
+var ErrNotCached = errors.New("not in cache")
+
+// ErrOffline is returned in offline mode by operations that need the network.
+var ErrOffline = errors.New("cache is offline")
+
+// Cache is an on-disk cache of metadata files, proto archives, compiled
+// descriptors and Registry lookups, one file per entry under a directory.
+//
+// Content-addressed entries (files read by CID) never change, so they are
+// served from the cache whenever present. Mutable entries expire after the
+// TTL. By default an expired entry is fetched again before it is returned;
+// with stale-while-revalidate it is returned at once and refreshed in the
+// background. When a fetch fails, the expired entry is served instead. In
+// offline mode nothing is fetched: entries are served regardless of age and
+// missing ones fail with ErrNotCached.
+//
+// A Cache is safe for concurrent use, also by several processes sharing the
+// directory.
+type Cache struct {
+	dir     string
+	ttl     time.Duration
+	stale   bool
+	offline bool
+	log     *zap.Logger
+	now     func() time.Time
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+}
+
+// CacheOption configures a Cache.
+type CacheOption func(*Cache)
+
+// WithCacheTTL sets how long mutable entries are used before they are
+// fetched again (DefaultCacheTTL by default).
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) { c.ttl = ttl }
+}
+
+// WithStaleWhileRevalidate serves expired mutable entries at once and
+// refreshes them in the background.
+func WithStaleWhileRevalidate() CacheOption {
+	return func(c *Cache) { c.stale = true }
+}
+
+// WithOfflineMode serves only from the cache and never fetches.
+func WithOfflineMode() CacheOption {
+	return func(c *Cache) { c.offline = true }
+}
+
+// WithCacheLogger makes the cache log failed writes and refreshes to l
+// instead of zap.L().
+func WithCacheLogger(l *zap.Logger) CacheOption {
+	return func(c *Cache) { c.log = l }
+}
+
+// NewCache opens the cache in dir, creating the directory if needed.
+func NewCache(dir string, opts ...CacheOption) (*Cache, error) {
+	c := &Cache{
+		dir:        dir,
+		ttl:        DefaultCacheTTL,
+		log:        zap.L(),
+		now:        time.Now,
+		refreshing: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+	return c, nil
+}
+
+// Offline reports whether the cache is in offline mode.
+func (c *Cache) Offline() bool {
+	return c.offline
+}
+
+// Content returns the content-addressed entry key, calling fetch and
+// storing its result when the entry is missing.
+func (c *Cache) Content(ctx context.Context, key string, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if data, _, ok := c.Load(key); ok {
+		return data, nil
+	}
+	if c.offline {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotCached)
+	}
+	data, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, data)
+	return data, nil
+}
+
+// Lookup returns the mutable entry key, calling fetch when it is missing or
+// expired as described on Cache.
+func (c *Cache) Lookup(ctx context.Context, key string, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	data, age, ok := c.Load(key)
+	switch {
+	case ok && (c.offline || age < c.ttl):
+		return data, nil
+	case c.offline:
+		return nil, fmt.Errorf("%s: %w", key, ErrNotCached)
+	case ok && c.stale:
+		c.revalidate(ctx, key, fetch)
+		return data, nil
+	}
+
+	fresh, err := fetch(ctx)
+	if err != nil {
+		if ok {
+			c.log.Warn("serving expired cache entry", zap.String("key", key), zap.Error(err))
+			return data, nil
+		}
+		return nil, err
+	}
+	c.store(key, fresh)
+	return fresh, nil
+}
+
+// revalidate refreshes key in the background unless a refresh is running.
+func (c *Cache) revalidate(ctx context.Context, key string, fetch func(ctx context.Context) ([]byte, error)) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), revalidateTimeout)
+	go func() {
+		defer cancel()
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+		data, err := fetch(ctx)
+		if err != nil {
+			c.log.Warn("failed to refresh cache entry", zap.String("key", key), zap.Error(err))
+			return
+		}
+		c.store(key, data)
+	}()
+}
+
+// Load returns the entry key and its age.
+func (c *Cache) Load(key string) (data []byte, age time.Duration, ok bool) {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, false
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, 0, false
+	}
+	return data, c.now().Sub(info.ModTime()), true
+}
+
+// Store writes the entry key. The file is replaced atomically, so readers
+// never see a partial entry.
+func (c *Cache) Store(key string, data []byte) error {
+	if c.offline {
+		return ErrOffline
+	}
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// store is like Store but only logs failures: a cache that cannot be
+// written still serves what the fetch returned.
+func (c *Cache) store(key string, data []byte) {
+	if err := c.Store(key, data); err != nil {
+		c.log.Warn("failed to write cache entry", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// Delete removes the entry key, so that the next read fetches it again.
+func (c *Cache) Delete(key string) error {
+	if err := os.Remove(c.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the file of key: the hex SHA-256 of the key, so that any key
+// is a valid file name.
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// CachedStorage is a Storage that reads files through a Cache. Files are
+// content-addressed, so a file once read is never fetched again.
+type CachedStorage struct {
+	backend Storage
+	cache   *Cache
+}
+
+// NewCachedStorage returns a Storage reading from backend through cache.
+func NewCachedStorage(backend Storage, cache *Cache) *CachedStorage {
+	return &CachedStorage{backend: backend, cache: cache}
+}
+
+// Cache returns the cache of s.
+func (s *CachedStorage) Cache() *Cache {
+	return s.cache
+}
+
+// ReadFile returns the file id from the cache, reading it from the backend
+// the first time. The ipfs:// and filecoin:// forms of a CID share an entry.
+func (s *CachedStorage) ReadFile(ctx context.Context, id string) ([]byte, error) {
+	return s.cache.Content(ctx, "file/"+formatHash(id), func(ctx context.Context) ([]byte, error) {
+		return s.backend.ReadFile(ctx, id)
+	})
+}
+
+// UploadJSON uploads data through the backend. It fails with ErrOffline in
+// offline mode.
+func (s *CachedStorage) UploadJSON(ctx context.Context, data interface{}) (string, error) {
+	if s.cache.offline {
+		return "", ErrOffline
+	}
+	return s.backend.UploadJSON(ctx, data)
+}
+
+// Upload uploads raw data through the backend. It fails with ErrOffline in
+// offline mode, and with ErrUploadNotSupported when the backend is not an
+// Uploader.
+func (s *CachedStorage) Upload(ctx context.Context, data []byte) (string, error) {
+	if s.cache.offline {
+		return "", ErrOffline
+	}
+	uploader, ok := s.backend.(Uploader)
+	if !ok {
+		return "", ErrUploadNotSupported
+	}
+	return uploader.Upload(ctx, data)
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingFetch returns a fetch function returning data and the number of
+// times it was called.
+func countingFetch(data string, err error) (func(context.Context) ([]byte, error), *int) {
+	calls := new(int)
+	return func(context.Context) ([]byte, error) {
+		*calls++
+		if err != nil {
+			return nil, err
+		}
+		return []byte(data), nil
+	}, calls
+}
+
+func TestCacheContent(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	fetch, calls := countingFetch("v1", nil)
+	for i := 0; i < 2; i++ {
+		got, err := c.Content(ctx, "file/Qm1", fetch)
+		if err != nil || string(got) != "v1" {
+			t.Fatalf("Content = %q, %v", got, err)
+		}
+	}
+	if *calls != 1 {
+		t.Fatalf("fetched %d times; want 1", *calls)
+	}
+
+	offline, err := NewCache(dir, WithOfflineMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := offline.Content(ctx, "file/Qm1", fetch); err != nil || string(got) != "v1" {
+		t.Fatalf("offline hit: Content = %q, %v", got, err)
+	}
+	if _, err := offline.Content(ctx, "file/Qm2", fetch); !errors.Is(err, ErrNotCached) {
+		t.Fatalf("offline miss: err = %v; want %v", err, ErrNotCached)
+	}
+	if *calls != 1 {
+		t.Fatal("offline cache fetched")
+	}
+	if err := offline.Store("file/Qm2", nil); !errors.Is(err, ErrOffline) {
+		t.Fatalf("offline Store: err = %v; want %v", err, ErrOffline)
+	}
+}
+
+func TestCacheLookupExpires(t *testing.T) {
+	c, err := NewCache(t.TempDir(), WithCacheTTL(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := c.Store("registry/org/snet", []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+
+	fetch, calls := countingFetch("v2", nil)
+	if got, _ := c.Lookup(ctx, "registry/org/snet", fetch); string(got) != "v1" || *calls != 0 {
+		t.Fatalf("fresh entry: Lookup = %q after %d fetches", got, *calls)
+	}
+
+	c.now = func() time.Time { return time.Now().Add(time.Hour) }
+	failing, _ := countingFetch("", errors.New("rpc down"))
+	if got, err := c.Lookup(ctx, "registry/org/snet", failing); err != nil || string(got) != "v1" {
+		t.Fatalf("failed refresh: Lookup = %q, %v; want expired entry", got, err)
+	}
+	if got, _ := c.Lookup(ctx, "registry/org/snet", fetch); string(got) != "v2" || *calls != 1 {
+		t.Fatalf("expired entry: Lookup = %q after %d fetches", got, *calls)
+	}
+	if _, err := c.Lookup(ctx, "registry/org/other", failing); err == nil {
+		t.Fatal("missing entry with failing fetch: expected error")
+	}
+
+	if err := c.Delete("registry/org/snet"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := c.Load("registry/org/snet"); ok {
+		t.Fatal("entry not deleted")
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	c, err := NewCache(t.TempDir(), WithCacheTTL(time.Minute), WithStaleWhileRevalidate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Store("registry/org/snet", []byte("v1")); err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	refreshed := make(chan struct{})
+	fetch := func(context.Context) ([]byte, error) {
+		<-refreshed
+		return []byte("v2"), nil
+	}
+	got, err := c.Lookup(context.Background(), "registry/org/snet", fetch)
+	if err != nil || string(got) != "v1" {
+		t.Fatalf("Lookup = %q, %v; want stale entry", got, err)
+	}
+	close(refreshed)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if data, _, _ := c.Load("registry/org/snet"); string(data) == "v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("entry not refreshed in the background")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// fakeStorage serves files from a map and counts reads.
+type fakeStorage struct {
+	files map[string]string
+	reads int
+}
+
+func (s *fakeStorage) ReadFile(_ context.Context, id string) ([]byte, error) {
+	s.reads++
+	data, ok := s.files[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(data), nil
+}
+
+func (s *fakeStorage) UploadJSON(context.Context, interface{}) (string, error) {
+	return "ipfs://Qm3", nil
+}
+
+func TestCachedStorage(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &fakeStorage{files: map[string]string{"ipfs://Qm1": "metadata"}}
+	s := NewCachedStorage(backend, c)
+	ctx := context.Background()
+
+	for _, id := range []string{"ipfs://Qm1", "filecoin://Qm1", "Qm1"} {
+		if got, err := s.ReadFile(ctx, id); err != nil || string(got) != "metadata" {
+			t.Fatalf("ReadFile(%q) = %q, %v", id, got, err)
+		}
+	}
+	if backend.reads != 1 {
+		t.Fatalf("backend read %d times; want 1", backend.reads)
+	}
+	if _, err := s.UploadJSON(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	offline, err := NewCache(dir, WithOfflineMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = NewCachedStorage(backend, offline)
+	if got, err := s.ReadFile(ctx, "ipfs://Qm1"); err != nil || string(got) != "metadata" {
+		t.Fatalf("offline ReadFile = %q, %v", got, err)
+	}
+	if _, err := s.UploadJSON(ctx, nil); !errors.Is(err, ErrOffline) {
+		t.Fatalf("offline UploadJSON: err = %v; want %v", err, ErrOffline)
+	}
+}
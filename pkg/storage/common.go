@@ -6,11 +6,14 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/ipfs/kubo/client/rpc"
+	"github.com/shamank/snet-sdk-go/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +30,16 @@ type Storage interface {
 	UploadJSON(ctx context.Context, data interface{}) (string, error)
 }
 
+// Uploader is implemented by storages that can also store raw files, such as
+// proto archives. Client, CachedStorage and the snettest storage implement it.
+type Uploader interface {
+	Upload(ctx context.Context, data []byte) (string, error)
+}
+
+// ErrUploadNotSupported is returned when a file is published through a
+// Storage that does not implement Uploader.
+var ErrUploadNotSupported = errors.New("storage does not support file uploads")
+
 // LighthouseFetcher fetches content from a Lighthouse gateway.
 type LighthouseFetcher interface {
 	Fetch(endpoint, cid string) ([]byte, error)
@@ -46,6 +59,8 @@ type Client struct {
 	*rpc.HttpApi
 	// LighthouseUrl is the base URL of the Lighthouse HTTP gateway.
 	LighthouseUrl string
+	// Telemetry traces ReadFile. Nil uses telemetry.Global().
+	Telemetry *telemetry.Telemetry
 
 	lighthouseFetcher LighthouseFetcher
 	ipfsFetcher       IPFSFetcher
@@ -78,6 +93,12 @@ func (s *Client) ReadFile(ctx context.Context, hash string) (rawFile []byte, err
 		defer cancel()
 	}
 
+	ctx, span := s.Telemetry.Start(ctx, "storage.ReadFile", attribute.String("storage.uri", hash))
+	defer func() {
+		span.SetAttributes(attribute.Int("storage.size", len(rawFile)))
+		telemetry.End(span, err)
+	}()
+
 	if s.lighthouseFetcher == nil {
 		s.lighthouseFetcher = defaultLighthouseFetcher{}
 	}
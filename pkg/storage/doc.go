@@ -105,6 +105,10 @@
 //   - Uncompressed tar archives
 //   - Gzip-compressed tar.gz archives
 //
+// PackProtoFiles builds the same tar.gz layout from a map of protos, for
+// publishing. Storages that implement Uploader (Client, CachedStorage) upload
+// such raw files; grpc.PublishProtos combines both steps.
+//
 // # CID Formats
 //
 // Both IPFS and Lighthouse use Content Identifiers (CIDs):
@@ -123,21 +127,23 @@
 //
 // # Caching
 //
-// For production applications, consider caching:
-//
-//	var metadataCache = make(map[string][]byte)
+// Cache keeps metadata files, proto archives, compiled descriptors and
+// Registry lookups on disk, one file per entry. CachedStorage reads files
+// through it; files are addressed by CID and therefore never expire:
 //
-//	func fetchWithCache(cid string) ([]byte, error) {
-//		if cached, ok := metadataCache[cid]; ok {
-//			return cached, nil
-//		}
-//		data, err := client.FetchFromIPFS(cid)
-//		if err != nil {
-//			return nil, err
-//		}
-//		metadataCache[cid] = data
-//		return data, nil
+//	cache, err := storage.NewCache("/var/cache/snet",
+//		storage.WithCacheTTL(10*time.Minute),
+//		storage.WithStaleWhileRevalidate(),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
 //	}
+//	cached := storage.NewCachedStorage(client, cache)
+//
+// Mutable entries (Cache.Lookup) expire after the TTL; with
+// stale-while-revalidate an expired entry is served at once and refreshed in
+// the background. WithOfflineMode serves only what is cached and fails with
+// ErrNotCached otherwise. The SDK sets all of this up from Config.Cache.
 //
 // # Error Handling
 //
@@ -202,6 +202,18 @@ func (c *Client) UploadJSON(ctx context.Context, data interface{}) (string, erro
 		return "", fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
+	return c.Upload(ctx, jsonData)
+}
+
+// Upload uploads raw data, such as a proto archive, to IPFS.
+// Returns the IPFS URI (ipfs://<hash>) on success.
+func (c *Client) Upload(ctx context.Context, data []byte) (string, error) {
+	if ctx == nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+	}
+
 	if c.ipfsFetcher == nil {
 		c.ipfsFetcher = newIPFSFetcher(c.HttpApi)
 	}
@@ -212,7 +224,7 @@ func (c *Client) UploadJSON(ctx context.Context, data interface{}) (string, erro
 		return "", fmt.Errorf("ipfs fetcher does not support uploads")
 	}
 
-	return uploader.Upload(ctx, jsonData)
+	return uploader.Upload(ctx, data)
 }
 
 // Upload uploads data to IPFS and returns the IPFS URI (ipfs://<hash>).
@@ -276,7 +288,7 @@ func NewIPFSClient(url string) (client *rpc.HttpApi, err error) {
 	}
 	client, err = rpc.NewURLApiWithClient(url, &httpClient)
 	if err != nil {
-		zap.L().Panic("Connection failed to IPFS", zap.String("url", url), zap.Error(err))
+		return nil, fmt.Errorf("connect to IPFS at %s: %w", url, err)
 	}
-	return client, err
+	return client, nil
 }
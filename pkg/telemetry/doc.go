@@ -0,0 +1,50 @@
+// Package telemetry holds the SDK's OpenTelemetry instrumentation: the
+// tracer, the metric instruments and the attribute keys shared by the sdk,
+// payment, grpc, blockchain and storage packages.
+//
+// Instrumentation is always on but costs next to nothing until the
+// application installs OpenTelemetry providers, either globally
+// (otel.SetTracerProvider, otel.SetMeterProvider) or for one SDK instance
+// through config.Config.TracerProvider and MeterProvider.
+//
+// # Spans
+//
+// A paid call produces a tree like this one:
+//
+//	sdk.Call                          rpc.method, snet.org_id, snet.service_id, snet.group, snet.payment.type
+//	├── payment.Refresh               strategy setup or prepaid token renewal, when due
+//	│   ├── eth_getBlockByNumber      block number fetch
+//	│   ├── escrow.PaymentChannelStateService/GetChannelState
+//	│   └── payment.Sign
+//	├── payment.Reserve               snet.payment.cogs, snet.payment.channel_id
+//	│   └── payment.Sign
+//	└── example.Calculator/add        the daemon call itself
+//
+// Chain requests are named after their JSON-RPC method (eth_call,
+// eth_sendRawTransaction, ...); blockchain.WaitForTransaction and
+// storage reads (storage.ReadFile) have spans of their own.
+//
+// # Metrics
+//
+//	snet.sdk.calls          counter    calls made through sdk.ServiceClient
+//	snet.sdk.call.failures  counter    calls that returned an error
+//	snet.sdk.call.duration  histogram  call duration in seconds, payment included
+//	snet.sdk.cogs.signed    counter    cogs of paid and prepaid calls that reached the daemon
+//
+// Measurements carry the org, service, group and method attributes.
+//
+// # Usage
+//
+//	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+//	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+//	snetSDK, err := sdk.NewSDK(&config.Config{
+//		RPCAddr:        "wss://sepolia.infura.io/ws/v3/YOUR_KEY",
+//		Network:        config.Sepolia,
+//		TracerProvider: tp,
+//		MeterProvider:  mp,
+//	})
+//
+// Components used on their own take a Telemetry directly (grpc.WithTelemetry,
+// blockchain.WithTelemetry, storage.Client.Telemetry); code that is only
+// handed a context reads it with FromContext.
+package telemetry
@@ -0,0 +1,184 @@
+package telemetry
+
+import (
+	"context"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the SDK's tracer and meter.
+const ScopeName = "github.com/shamank/snet-sdk-go"
+
+// Metric names.
+const (
+	CallsMetric        = "snet.sdk.calls"
+	CallFailuresMetric = "snet.sdk.call.failures"
+	CallDurationMetric = "snet.sdk.call.duration"
+	CogsSignedMetric   = "snet.sdk.cogs.signed"
+)
+
+// Attribute keys set on spans and metrics.
+const (
+	OrgKey         = attribute.Key("snet.org_id")
+	ServiceKey     = attribute.Key("snet.service_id")
+	GroupKey       = attribute.Key("snet.group")
+	MethodKey      = attribute.Key("rpc.method")
+	PaymentTypeKey = attribute.Key("snet.payment.type")
+	CogsKey        = attribute.Key("snet.payment.cogs")
+	ChannelKey     = attribute.Key("snet.payment.channel_id")
+)
+
+// Cogs returns the CogsKey attribute for amount. The amount is recorded as a
+// decimal string because it does not always fit in an int64.
+func Cogs(amount *big.Int) attribute.KeyValue {
+	if amount == nil {
+		return CogsKey.String("0")
+	}
+	return CogsKey.String(amount.String())
+}
+
+// Telemetry creates the SDK's spans and records its metrics. A nil
+// *Telemetry is valid and behaves like Global().
+type Telemetry struct {
+	tracer   trace.Tracer
+	calls    metric.Int64Counter
+	failures metric.Int64Counter
+	duration metric.Float64Histogram
+	cogs     metric.Float64Counter
+	attrs    []attribute.KeyValue
+}
+
+// New returns a Telemetry that traces with tp and records metrics with mp.
+// A nil provider selects the global one (otel.GetTracerProvider,
+// otel.GetMeterProvider), which discards everything until the application
+// installs an SDK.
+func New(tp trace.TracerProvider, mp metric.MeterProvider) *Telemetry {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(ScopeName)
+	fallback := noop.NewMeterProvider().Meter(ScopeName)
+
+	t := &Telemetry{tracer: tp.Tracer(ScopeName)}
+	var err error
+	if t.calls, err = meter.Int64Counter(CallsMetric,
+		metric.WithDescription("Service calls made through the SDK."),
+		metric.WithUnit("{call}")); err != nil {
+		otel.Handle(err)
+		t.calls, _ = fallback.Int64Counter(CallsMetric)
+	}
+	if t.failures, err = meter.Int64Counter(CallFailuresMetric,
+		metric.WithDescription("Service calls that returned an error."),
+		metric.WithUnit("{call}")); err != nil {
+		otel.Handle(err)
+		t.failures, _ = fallback.Int64Counter(CallFailuresMetric)
+	}
+	if t.duration, err = meter.Float64Histogram(CallDurationMetric,
+		metric.WithDescription("Duration of service calls, including payment and retries."),
+		metric.WithUnit("s")); err != nil {
+		otel.Handle(err)
+		t.duration, _ = fallback.Float64Histogram(CallDurationMetric)
+	}
+	if t.cogs, err = meter.Float64Counter(CogsSignedMetric,
+		metric.WithDescription("Cogs authorised by paid and prepaid calls that reached the daemon."),
+		metric.WithUnit("{cog}")); err != nil {
+		otel.Handle(err)
+		t.cogs, _ = fallback.Float64Counter(CogsSignedMetric)
+	}
+	return t
+}
+
+var global = sync.OnceValue(func() *Telemetry { return New(nil, nil) })
+
+// Global returns the Telemetry of the global providers. Because the global
+// providers delegate to the ones installed later with otel.SetTracerProvider
+// and otel.SetMeterProvider, it may be obtained before they are set.
+func Global() *Telemetry {
+	return global()
+}
+
+// With returns a copy of t that adds attrs to every span it starts and every
+// measurement it records.
+func (t *Telemetry) With(attrs ...attribute.KeyValue) *Telemetry {
+	t = t.orGlobal()
+	c := *t
+	c.attrs = append(slices.Clip(t.attrs), attrs...)
+	return &c
+}
+
+// Start starts a client span named name as a child of the span in ctx.
+func (t *Telemetry) Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	t = t.orGlobal()
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(t.attrs...),
+		trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it failed when err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// RecordCall counts a service call that took elapsed and ended with err.
+func (t *Telemetry) RecordCall(ctx context.Context, elapsed time.Duration, err error, attrs ...attribute.KeyValue) {
+	t = t.orGlobal()
+	set := metric.WithAttributeSet(t.attributeSet(attrs))
+	t.calls.Add(ctx, 1, set)
+	if err != nil {
+		t.failures.Add(ctx, 1, set)
+	}
+	t.duration.Record(ctx, elapsed.Seconds(), set)
+}
+
+// RecordCogs adds amount to the cogs signed counter.
+func (t *Telemetry) RecordCogs(ctx context.Context, amount *big.Int, attrs ...attribute.KeyValue) {
+	if amount == nil || amount.Sign() <= 0 {
+		return
+	}
+	t = t.orGlobal()
+	f, _ := new(big.Float).SetInt(amount).Float64()
+	t.cogs.Add(ctx, f, metric.WithAttributeSet(t.attributeSet(attrs)))
+}
+
+func (t *Telemetry) attributeSet(attrs []attribute.KeyValue) attribute.Set {
+	return attribute.NewSet(append(slices.Clip(t.attrs), attrs...)...)
+}
+
+func (t *Telemetry) orGlobal() *Telemetry {
+	if t == nil {
+		return Global()
+	}
+	return t
+}
+
+type contextKey struct{}
+
+// NewContext returns a child context carrying t, for code that is handed a
+// context rather than a Telemetry (payment strategies, for example).
+func NewContext(ctx context.Context, t *Telemetry) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the Telemetry set by NewContext, or nil, which behaves
+// like Global().
+func FromContext(ctx context.Context) *Telemetry {
+	t, _ := ctx.Value(contextKey{}).(*Telemetry)
+	return t
+}
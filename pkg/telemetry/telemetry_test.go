@@ -0,0 +1,116 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestTelemetry(t *testing.T) (*Telemetry, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	tel := New(
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	)
+	return tel, spans, reader
+}
+
+// sums collects the values of the sum metrics in reader by name.
+func sums(t *testing.T, reader *sdkmetric.ManualReader) map[string]float64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	out := make(map[string]float64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, p := range data.DataPoints {
+					out[m.Name] += float64(p.Value)
+				}
+			case metricdata.Sum[float64]:
+				for _, p := range data.DataPoints {
+					out[m.Name] += p.Value
+				}
+			}
+		}
+	}
+	return out
+}
+
+func TestTelemetry_SpansCarryScopedAttributes(t *testing.T) {
+	tel, spans, _ := newTestTelemetry(t)
+	scoped := tel.With(OrgKey.String("snet"), ServiceKey.String("example"))
+
+	ctx, parent := scoped.Start(context.Background(), "sdk.Call", MethodKey.String("add"))
+	_, child := FromContext(NewContext(ctx, scoped)).Start(ctx, "payment.Reserve", Cogs(big.NewInt(25)))
+	End(child, nil)
+	End(parent, errors.New("boom"))
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("ended %d spans; want 2", len(ended))
+	}
+	reserve, call := ended[0], ended[1]
+	if reserve.Parent().SpanID() != call.SpanContext().SpanID() {
+		t.Fatal("payment.Reserve is not a child of sdk.Call")
+	}
+	want := map[attribute.Key]string{OrgKey: "snet", ServiceKey: "example", CogsKey: "25"}
+	for _, kv := range reserve.Attributes() {
+		if v, ok := want[kv.Key]; ok && kv.Value.AsString() == v {
+			delete(want, kv.Key)
+		}
+	}
+	if len(want) != 0 {
+		t.Fatalf("payment.Reserve attributes %v miss %v", reserve.Attributes(), want)
+	}
+	if call.Status().Code != codes.Error || len(call.Events()) == 0 {
+		t.Fatalf("failed call span status = %v, events = %d; want an error and its event", call.Status(), len(call.Events()))
+	}
+	if len(tel.attrs) != 0 {
+		t.Fatal("With modified the receiver")
+	}
+}
+
+func TestTelemetry_RecordsCallsFailuresAndCogs(t *testing.T) {
+	tel, _, reader := newTestTelemetry(t)
+	ctx := context.Background()
+
+	tel.RecordCall(ctx, time.Second, nil)
+	tel.RecordCall(ctx, time.Second, errors.New("unavailable"))
+	tel.RecordCogs(ctx, big.NewInt(40))
+	tel.RecordCogs(ctx, big.NewInt(2))
+	tel.RecordCogs(ctx, nil)
+
+	got := sums(t, reader)
+	want := map[string]float64{CallsMetric: 2, CallFailuresMetric: 1, CogsSignedMetric: 42}
+	for name, v := range want {
+		if got[name] != v {
+			t.Fatalf("%s = %v; want %v (all: %v)", name, got[name], v, got)
+		}
+	}
+}
+
+func TestTelemetry_NilUsesGlobal(t *testing.T) {
+	var tel *Telemetry
+	if FromContext(context.Background()) != nil {
+		t.Fatal("FromContext without NewContext returned a Telemetry")
+	}
+	_, span := tel.Start(context.Background(), "noop")
+	End(span, nil)
+	tel.RecordCall(context.Background(), time.Millisecond, nil)
+	tel.RecordCogs(context.Background(), big.NewInt(1))
+}
@@ -153,8 +153,8 @@
 //	// Get current block
 //	currentBlock, err := ethClient.BlockNumber(ctx)
 //
-//	// Create auth with recent block
-//	auth, err := training.CreateAuth(methodName, currentBlock, privateKey)
+//	// Sign method name, signer address and block with the client's signer
+//	sig, err := signer.SignMessage(ctx, keccak256(methodName || address || currentBlock))
 //
 // Signature Format:
 //
@@ -7,7 +7,6 @@ package training
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
 	_ "embed"
 	"errors"
 	"fmt"
@@ -17,12 +16,11 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/shamank/snet-sdk-go/pkg/blockchain"
 	"github.com/shamank/snet-sdk-go/pkg/grpc"
 	"github.com/shamank/snet-sdk-go/pkg/payment"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/metadata"
 )
@@ -50,6 +48,22 @@ type Client interface {
 
 	TrainModelPrice(modelID string) (uint64, error)
 	TrainModel(modelID string) (Status, error)
+
+	// Context-aware variants. The configured timeouts apply only when ctx has
+	// no deadline of its own.
+
+	GetMetadataContext(ctx context.Context) (*TrainingMetadata, error)
+	GetMethodMetadataContext(ctx context.Context, request *MethodMetadataRequest) (*MethodMetadata, error)
+	GetAllModelsContext(ctx context.Context, r GetAllModelsFilters) (*ModelsResponse, error)
+	GetModelContext(ctx context.Context, modelId string) (*ModelResponse, error)
+	CreateModelContext(ctx context.Context, model *ModelParams) (*ModelResponse, error)
+	DeleteModelContext(ctx context.Context, modelID string) (Status, error)
+	UpdateModelContext(ctx context.Context, r *UpdateModelRequest) (*ModelResponse, error)
+	UploadAndValidateContext(ctx context.Context, r *UploadValidateRequest) error
+	ValidateModelPriceContext(ctx context.Context, modelID, TrainingDataLink string) (price uint64, err error)
+	ValidateModelContext(ctx context.Context, modelID, TrainingDataLink string) (Status, error)
+	TrainModelPriceContext(ctx context.Context, modelID string) (uint64, error)
+	TrainModelContext(ctx context.Context, modelID string) (Status, error)
 }
 
 // TrainingClient is the concrete implementation of the Client interface
@@ -58,32 +72,74 @@ type TrainingClient struct {
 	DaemonClient
 	timeout            time.Duration
 	streamTimeout      time.Duration
-	privateKey         *ecdsa.PrivateKey
+	signer             signer.Signer
 	currentBlockNumber func() (*big.Int, error)
 	OrgID              string
 	SrvID              string
 	GroupID            string
 	strat              payment.Strategy
+	log                *zap.Logger
+}
+
+// Option configures a TrainingClient.
+type Option func(*TrainingClient)
+
+// WithLogger makes the client log to l instead of zap.L().
+func WithLogger(l *zap.Logger) Option {
+	return func(c *TrainingClient) {
+		c.log = l
+	}
 }
 
 // NewTrainingClient creates a new training client with the provided gRPC client,
-// private key for signing requests, and a function to retrieve the current block number.
-func NewTrainingClient(orgID, srvID, groupID string, client *grpc.Client, priv *ecdsa.PrivateKey, timeout, streamTimeout time.Duration, currentBlockNumber func() (*big.Int, error), strat payment.Strategy) *TrainingClient {
-	return &TrainingClient{
-		DaemonClient:       NewDaemonClient(client.GRPC),
+// signer for authorizing requests, and a function to retrieve the current block number.
+func NewTrainingClient(orgID, srvID, groupID string, client *grpc.Client, s signer.Signer, timeout, streamTimeout time.Duration, currentBlockNumber func() (*big.Int, error), strat payment.Strategy, opts ...Option) *TrainingClient {
+	c := &TrainingClient{
+		DaemonClient:       NewDaemonClient(client.Conn()),
 		timeout:            timeout,
 		streamTimeout:      streamTimeout,
-		privateKey:         priv,
+		signer:             s,
 		currentBlockNumber: currentBlockNumber,
 		OrgID:              orgID,
 		SrvID:              srvID,
 		GroupID:            groupID,
 		strat:              strat,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// logger returns the logger set with WithLogger, or zap.L().
+func (c *TrainingClient) logger() *zap.Logger {
+	if c.log != nil {
+		return c.log
+	}
+	return zap.L()
+}
+
+// sign authorizes a call of the training method at block with the client's
+// signer (see getSignature).
+func (c *TrainingClient) sign(ctx context.Context, method string, block *big.Int) ([]byte, error) {
+	signature, err := getSignature(ctx, method, block, c.signer)
+	if err != nil {
+		return nil, err
+	}
+	c.logger().Debug("signed training request",
+		zap.String("method", method),
+		zap.Stringer("block", block),
+		zap.String("signer", c.signer.Address().Hex()))
+	return signature, nil
 }
 
 func (c *TrainingClient) GetMethodMetadata(request *MethodMetadataRequest) (*MethodMetadata, error) {
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	return c.GetMethodMetadataContext(context.Background(), request)
+}
+
+// GetMethodMetadataContext is like GetMethodMetadata but runs under ctx.
+func (c *TrainingClient) GetMethodMetadataContext(ctx context.Context, request *MethodMetadataRequest) (*MethodMetadata, error) {
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	metadata, err := c.DaemonClient.GetMethodMetadata(ctx, request)
@@ -101,6 +157,11 @@ type UploadValidateRequest struct {
 }
 
 func (c *TrainingClient) UploadAndValidate(r *UploadValidateRequest) error {
+	return c.UploadAndValidateContext(context.Background(), r)
+}
+
+// UploadAndValidateContext is like UploadAndValidate but runs under ctx.
+func (c *TrainingClient) UploadAndValidateContext(ctx context.Context, r *UploadValidateRequest) error {
 	if r == nil {
 		return errors.New("nil request")
 	}
@@ -112,7 +173,7 @@ func (c *TrainingClient) UploadAndValidate(r *UploadValidateRequest) error {
 	}
 
 	// stream timeout
-	ctx, cancel := c.withTimeout(context.Background(), c.streamTimeout)
+	ctx, cancel := c.withTimeout(ctx, c.streamTimeout)
 	defer cancel()
 
 	err := c.strat.Refresh(ctx)
@@ -127,12 +188,12 @@ func (c *TrainingClient) UploadAndValidate(r *UploadValidateRequest) error {
 	if err != nil {
 		return err
 	}
-	sig, err := getSignature("upload_and_validate", block, c.privateKey)
+	sig, err := c.sign(ctx, "upload_and_validate", block)
 	if err != nil {
 		return err
 	}
 	auth := newAuth(
-		blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(),
+		c.signer.Address().Hex(),
 		"upload_and_validate",
 		block.Uint64(),
 		sig,
@@ -222,7 +283,12 @@ func (c *TrainingClient) UploadAndValidate(r *UploadValidateRequest) error {
 }
 
 func (c *TrainingClient) ValidateModelPrice(modelID, TrainingDataLink string) (price uint64, err error) {
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	return c.ValidateModelPriceContext(context.Background(), modelID, TrainingDataLink)
+}
+
+// ValidateModelPriceContext is like ValidateModelPrice but runs under ctx.
+func (c *TrainingClient) ValidateModelPriceContext(ctx context.Context, modelID, TrainingDataLink string) (price uint64, err error) {
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	block, err := c.currentBlockNumber()
@@ -230,13 +296,13 @@ func (c *TrainingClient) ValidateModelPrice(modelID, TrainingDataLink string) (p
 		return 0, err
 	}
 
-	signature, err := getSignature("validate_model_price", block, c.privateKey)
+	signature, err := c.sign(ctx, "validate_model_price", block)
 	if err != nil {
 		return 0, err
 	}
 
 	r := &AuthValidateRequest{
-		Authorization:    newAuth(blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(), "validate_model_price", block.Uint64(), signature),
+		Authorization:    newAuth(c.signer.Address().Hex(), "validate_model_price", block.Uint64(), signature),
 		ModelId:          modelID,
 		TrainingDataLink: TrainingDataLink,
 	}
@@ -249,7 +315,12 @@ func (c *TrainingClient) ValidateModelPrice(modelID, TrainingDataLink string) (p
 }
 
 func (c *TrainingClient) ValidateModel(modelID, TrainingDataLink string) (Status, error) {
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	return c.ValidateModelContext(context.Background(), modelID, TrainingDataLink)
+}
+
+// ValidateModelContext is like ValidateModel but runs under ctx.
+func (c *TrainingClient) ValidateModelContext(ctx context.Context, modelID, TrainingDataLink string) (Status, error) {
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	block, err := c.currentBlockNumber()
@@ -257,13 +328,13 @@ func (c *TrainingClient) ValidateModel(modelID, TrainingDataLink string) (Status
 		return Status_ERRORED, err
 	}
 
-	signature, err := getSignature("validate_model", block, c.privateKey)
+	signature, err := c.sign(ctx, "validate_model", block)
 	if err != nil {
 		return Status_ERRORED, err
 	}
 
 	r := &AuthValidateRequest{
-		Authorization:    newAuth(blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(), "validate_model", block.Uint64(), signature),
+		Authorization:    newAuth(c.signer.Address().Hex(), "validate_model", block.Uint64(), signature),
 		ModelId:          modelID,
 		TrainingDataLink: TrainingDataLink,
 	}
@@ -276,7 +347,12 @@ func (c *TrainingClient) ValidateModel(modelID, TrainingDataLink string) (Status
 }
 
 func (c *TrainingClient) TrainModelPrice(modelID string) (uint64, error) {
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	return c.TrainModelPriceContext(context.Background(), modelID)
+}
+
+// TrainModelPriceContext is like TrainModelPrice but runs under ctx.
+func (c *TrainingClient) TrainModelPriceContext(ctx context.Context, modelID string) (uint64, error) {
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	block, err := c.currentBlockNumber()
@@ -284,13 +360,13 @@ func (c *TrainingClient) TrainModelPrice(modelID string) (uint64, error) {
 		return 0, err
 	}
 
-	signature, err := getSignature("train_model_price", block, c.privateKey)
+	signature, err := c.sign(ctx, "train_model_price", block)
 	if err != nil {
 		return 0, err
 	}
 
 	r := &CommonRequest{
-		Authorization: newAuth(blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(), "train_model_price", block.Uint64(), signature),
+		Authorization: newAuth(c.signer.Address().Hex(), "train_model_price", block.Uint64(), signature),
 		ModelId:       modelID,
 	}
 
@@ -302,7 +378,12 @@ func (c *TrainingClient) TrainModelPrice(modelID string) (uint64, error) {
 }
 
 func (c *TrainingClient) TrainModel(modelID string) (Status, error) {
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	return c.TrainModelContext(context.Background(), modelID)
+}
+
+// TrainModelContext is like TrainModel but runs under ctx.
+func (c *TrainingClient) TrainModelContext(ctx context.Context, modelID string) (Status, error) {
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	block, err := c.currentBlockNumber()
@@ -310,13 +391,13 @@ func (c *TrainingClient) TrainModel(modelID string) (Status, error) {
 		return Status_ERRORED, err
 	}
 
-	signature, err := getSignature("train_model", block, c.privateKey)
+	signature, err := c.sign(ctx, "train_model", block)
 	if err != nil {
 		return Status_ERRORED, err
 	}
 
 	r := &CommonRequest{
-		Authorization: newAuth(blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(), "train_model", block.Uint64(), signature),
+		Authorization: newAuth(c.signer.Address().Hex(), "train_model", block.Uint64(), signature),
 		ModelId:       modelID,
 	}
 
@@ -328,7 +409,12 @@ func (c *TrainingClient) TrainModel(modelID string) (Status, error) {
 }
 
 func (c *TrainingClient) DeleteModel(modelID string) (Status, error) {
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	return c.DeleteModelContext(context.Background(), modelID)
+}
+
+// DeleteModelContext is like DeleteModel but runs under ctx.
+func (c *TrainingClient) DeleteModelContext(ctx context.Context, modelID string) (Status, error) {
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	block, err := c.currentBlockNumber()
@@ -336,13 +422,13 @@ func (c *TrainingClient) DeleteModel(modelID string) (Status, error) {
 		return Status_ERRORED, err
 	}
 
-	signature, err := getSignature("delete_model", block, c.privateKey)
+	signature, err := c.sign(ctx, "delete_model", block)
 	if err != nil {
 		return Status_ERRORED, err
 	}
 
 	r := &CommonRequest{
-		Authorization: newAuth(blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(), "delete_model", block.Uint64(), signature),
+		Authorization: newAuth(c.signer.Address().Hex(), "delete_model", block.Uint64(), signature),
 		ModelId:       modelID,
 	}
 
@@ -354,25 +440,30 @@ func (c *TrainingClient) DeleteModel(modelID string) (Status, error) {
 }
 
 func (c *TrainingClient) UpdateModel(request *UpdateModelRequest) (*ModelResponse, error) {
+	return c.UpdateModelContext(context.Background(), request)
+}
+
+// UpdateModelContext is like UpdateModel but runs under ctx.
+func (c *TrainingClient) UpdateModelContext(ctx context.Context, request *UpdateModelRequest) (*ModelResponse, error) {
 
 	block, err := c.currentBlockNumber()
 	if err != nil {
 		return nil, err
 	}
-	signature, err := getSignature("update_model", block, c.privateKey)
+	signature, err := c.sign(ctx, "update_model", block)
 	if err != nil {
 		return nil, err
 	}
 
 	var req = &UpdateModelRequest{
-		Authorization: newAuth(blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(), "update_model", block.Uint64(), signature),
+		Authorization: newAuth(c.signer.Address().Hex(), "update_model", block.Uint64(), signature),
 		ModelId:       request.ModelId,
 		ModelName:     request.ModelName,
 		Description:   request.Description,
 		AddressList:   request.AddressList,
 	}
 
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	resp, err := c.DaemonClient.UpdateModel(ctx, req)
@@ -384,7 +475,12 @@ func (c *TrainingClient) UpdateModel(request *UpdateModelRequest) (*ModelRespons
 
 // GetMetadata retrieves training daemon metadata including supported operations.
 func (c *TrainingClient) GetMetadata() (*TrainingMetadata, error) {
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	return c.GetMetadataContext(context.Background())
+}
+
+// GetMetadataContext is like GetMetadata but runs under ctx.
+func (c *TrainingClient) GetMetadataContext(ctx context.Context) (*TrainingMetadata, error) {
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	metadata, err := c.GetTrainingMetadata(ctx, nil)
@@ -408,12 +504,17 @@ type GetAllModelsFilters struct {
 // GetAllModels retrieves all available models from the training daemon.
 // The request is authenticated with the client's private key.
 func (c *TrainingClient) GetAllModels(r GetAllModelsFilters) (*ModelsResponse, error) {
+	return c.GetAllModelsContext(context.Background(), r)
+}
+
+// GetAllModelsContext is like GetAllModels but runs under ctx.
+func (c *TrainingClient) GetAllModelsContext(ctx context.Context, r GetAllModelsFilters) (*ModelsResponse, error) {
 
 	block, err := c.currentBlockNumber()
 	if err != nil {
 		return nil, err
 	}
-	signature, err := getSignature("get_all_models", block, c.privateKey)
+	signature, err := c.sign(ctx, "get_all_models", block)
 	if err != nil {
 		return nil, err
 	}
@@ -423,7 +524,7 @@ func (c *TrainingClient) GetAllModels(r GetAllModelsFilters) (*ModelsResponse, e
 	}
 
 	var allModelsReq = &AllModelsRequest{
-		Authorization:    newAuth(blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(), "get_all_models", block.Uint64(), signature),
+		Authorization:    newAuth(c.signer.Address().Hex(), "get_all_models", block.Uint64(), signature),
 		Statuses:         r.Statuses,
 		IsPublic:         r.IsPublic,
 		GrpcMethodName:   r.GrpcMethodName,
@@ -434,7 +535,7 @@ func (c *TrainingClient) GetAllModels(r GetAllModelsFilters) (*ModelsResponse, e
 		Page:             r.Page,
 	}
 
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	resp, err := c.DaemonClient.GetAllModels(ctx, allModelsReq)
@@ -447,22 +548,27 @@ func (c *TrainingClient) GetAllModels(r GetAllModelsFilters) (*ModelsResponse, e
 // GetModel retrieves a specific model by its ID from the training daemon.
 // The request is authenticated with the client's private key.
 func (c *TrainingClient) GetModel(modelId string) (*ModelResponse, error) {
+	return c.GetModelContext(context.Background(), modelId)
+}
+
+// GetModelContext is like GetModel but runs under ctx.
+func (c *TrainingClient) GetModelContext(ctx context.Context, modelId string) (*ModelResponse, error) {
 
 	block, err := c.currentBlockNumber()
 	if err != nil {
 		return nil, err
 	}
-	signature, err := getSignature("get_model", block, c.privateKey)
+	signature, err := c.sign(ctx, "get_model", block)
 	if err != nil {
 		return nil, err
 	}
 
 	var req = &CommonRequest{
-		Authorization: newAuth(blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(), "get_model", block.Uint64(), signature),
+		Authorization: newAuth(c.signer.Address().Hex(), "get_model", block.Uint64(), signature),
 		ModelId:       modelId,
 	}
 
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	resp, err := c.DaemonClient.GetModel(ctx, req)
@@ -486,18 +592,23 @@ type ModelParams struct {
 // CreateModel creates a new model with the specified name and description.
 // The request is authenticated with the client's private key.
 func (c *TrainingClient) CreateModel(r *ModelParams) (*ModelResponse, error) {
+	return c.CreateModelContext(context.Background(), r)
+}
+
+// CreateModelContext is like CreateModel but runs under ctx.
+func (c *TrainingClient) CreateModelContext(ctx context.Context, r *ModelParams) (*ModelResponse, error) {
 
 	block, err := c.currentBlockNumber()
 	if err != nil {
 		return nil, err
 	}
-	signature, err := getSignature("create_model", block, c.privateKey)
+	signature, err := c.sign(ctx, "create_model", block)
 	if err != nil {
 		return nil, err
 	}
 
 	var req = &NewModelRequest{
-		Authorization: newAuth(blockchain.GetAddressFromPrivateKeyECDSA(c.privateKey).Hex(), "create_model", block.Uint64(), signature),
+		Authorization: newAuth(c.signer.Address().Hex(), "create_model", block.Uint64(), signature),
 		Model: &NewModel{
 			Name:            r.Name,
 			Description:     r.Description,
@@ -511,7 +622,7 @@ func (c *TrainingClient) CreateModel(r *ModelParams) (*ModelResponse, error) {
 		},
 	}
 
-	ctx, cancel := c.withTimeout(context.Background(), c.timeout)
+	ctx, cancel := c.withTimeout(ctx, c.timeout)
 	defer cancel()
 
 	resp, err := c.DaemonClient.CreateModel(ctx, req)
@@ -553,27 +664,22 @@ func newAuth(addr, msg string, blockNumber uint64, signature []byte) *Authorizat
 // Parameters:
 //   - methodName: name of the training method being called
 //   - blockNumber: current block number for freshness verification
-//   - privateKey: ECDSA private key for signing
+//   - s: signer whose address is embedded in the message
 //
 // Returns:
 //   - signature: 65-byte signature (R||S||V)
 //   - error: if signing fails
-func getSignature(methodName string, blockNumber *big.Int, privateKey *ecdsa.PrivateKey) (signature []byte, err error) {
+func getSignature(ctx context.Context, methodName string, blockNumber *big.Int, s signer.Signer) (signature []byte, err error) {
+	address := s.Address()
 	message := bytes.Join([][]byte{
 		[]byte(methodName),
-		crypto.PubkeyToAddress(privateKey.PublicKey).Bytes(),
+		address.Bytes(),
 		math.U256Bytes(blockNumber),
 	}, nil)
-	hash := crypto.Keccak256(
-		blockchain.HashPrefix32Bytes,
-		crypto.Keccak256(message),
-	)
-	signature, err = crypto.Sign(hash, privateKey)
+	signature, err = blockchain.SignMessage(ctx, s, message)
 	if err != nil {
 		return nil, fmt.Errorf("can't sign message: %w", err)
 	}
-	zap.L().Debug("signed with", zap.String("addr", common.BytesToAddress(crypto.PubkeyToAddress(privateKey.PublicKey).Bytes()).Hex()))
-
 	return signature, nil
 }
 
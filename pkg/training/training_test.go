@@ -2,6 +2,7 @@ package training
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"math/big"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/shamank/snet-sdk-go/pkg/blockchain"
+	"github.com/shamank/snet-sdk-go/pkg/signer"
 )
 
 func mustGenerateKey(t *testing.T) *ecdsa.PrivateKey {
@@ -20,6 +22,15 @@ func mustGenerateKey(t *testing.T) *ecdsa.PrivateKey {
 	return k
 }
 
+func mustSigner(t *testing.T, k *ecdsa.PrivateKey) signer.Signer {
+	t.Helper()
+	s, err := signer.NewPrivateKeySigner(k)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	return s
+}
+
 func TestNewAuth(t *testing.T) {
 	addr := "0x1234567890123456789012345678901234567890"
 	msg := "test_method"
@@ -54,7 +65,7 @@ func TestGetSignature(t *testing.T) {
 	methodName := "create_model"
 	blockNumber := big.NewInt(12345)
 
-	signature, err := getSignature(methodName, blockNumber, priv)
+	signature, err := getSignature(context.Background(), methodName, blockNumber, mustSigner(t, priv))
 	if err != nil {
 		t.Fatalf("getSignature failed: %v", err)
 	}
@@ -103,7 +114,7 @@ func TestGetSignature_DifferentMethods(t *testing.T) {
 	signatures := make(map[string][]byte)
 
 	for _, method := range methods {
-		sig, err := getSignature(method, blockNumber, priv)
+		sig, err := getSignature(context.Background(), method, blockNumber, mustSigner(t, priv))
 		if err != nil {
 			t.Fatalf("getSignature(%s) failed: %v", method, err)
 		}
@@ -126,12 +137,12 @@ func TestGetSignature_DifferentBlocks(t *testing.T) {
 	priv := mustGenerateKey(t)
 	methodName := "test_method"
 
-	sig1, err := getSignature(methodName, big.NewInt(100), priv)
+	sig1, err := getSignature(context.Background(), methodName, big.NewInt(100), mustSigner(t, priv))
 	if err != nil {
 		t.Fatalf("getSignature failed: %v", err)
 	}
 
-	sig2, err := getSignature(methodName, big.NewInt(101), priv)
+	sig2, err := getSignature(context.Background(), methodName, big.NewInt(101), mustSigner(t, priv))
 	if err != nil {
 		t.Fatalf("getSignature failed: %v", err)
 	}
@@ -147,12 +158,12 @@ func TestGetSignature_DifferentKeys(t *testing.T) {
 	methodName := "test_method"
 	blockNumber := big.NewInt(100)
 
-	sig1, err := getSignature(methodName, blockNumber, priv1)
+	sig1, err := getSignature(context.Background(), methodName, blockNumber, mustSigner(t, priv1))
 	if err != nil {
 		t.Fatalf("getSignature failed: %v", err)
 	}
 
-	sig2, err := getSignature(methodName, blockNumber, priv2)
+	sig2, err := getSignature(context.Background(), methodName, blockNumber, mustSigner(t, priv2))
 	if err != nil {
 		t.Fatalf("getSignature failed: %v", err)
 	}
@@ -169,12 +180,12 @@ func TestNewTrainingClient(t *testing.T) {
 	}
 
 	client := &TrainingClient{
-		privateKey:         priv,
+		signer:             mustSigner(t, priv),
 		currentBlockNumber: currentBlockNumber,
 	}
 
-	if client.privateKey != priv {
-		t.Fatal("private key not set correctly")
+	if client.signer.Address() != crypto.PubkeyToAddress(priv.PublicKey) {
+		t.Fatal("signer not set correctly")
 	}
 
 	if client.currentBlockNumber == nil {